@@ -0,0 +1,62 @@
+// Package buildinfo describes a binary for the version commands of the CLIs
+// in this repository. Each main keeps its own buildTime and gitCommit
+// variables, stamped with -ldflags "-X main.buildTime=<RFC3339>
+// -X main.gitCommit=<sha>", and passes them in.
+package buildinfo
+
+import (
+	"encoding/json"
+	"io"
+	"runtime"
+	"runtime/debug"
+)
+
+// Unknown is the value of a field nothing stamped.
+const Unknown = "unknown"
+
+// Info is what version --json prints.
+type Info struct {
+	Name      string `json:"name"`
+	Version   string `json:"version"`
+	BuildTime string `json:"buildTime"`
+	GoVersion string `json:"goVersion"`
+	Commit    string `json:"commit"`
+}
+
+// New fills in the Go version and, when commit was not stamped, the VCS
+// revision that builds from a git checkout carry anyway.
+func New(name, version, buildTime, commit string) Info {
+	info := Info{
+		Name:      name,
+		Version:   version,
+		BuildTime: buildTime,
+		GoVersion: runtime.Version(),
+		Commit:    commit,
+	}
+	if info.Commit == Unknown {
+		if revision := vcsRevision(); revision != "" {
+			info.Commit = revision
+		}
+	}
+	return info
+}
+
+func vcsRevision() string {
+	build, ok := debug.ReadBuildInfo()
+	if !ok {
+		return ""
+	}
+	for _, setting := range build.Settings {
+		if setting.Key == "vcs.revision" {
+			return setting.Value
+		}
+	}
+	return ""
+}
+
+// WriteJSON writes info as indented JSON.
+func (info Info) WriteJSON(out io.Writer) error {
+	encoder := json.NewEncoder(out)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(info)
+}
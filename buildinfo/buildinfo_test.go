@@ -0,0 +1,38 @@
+package buildinfo
+
+import (
+	"bytes"
+	"encoding/json"
+	"runtime"
+	"testing"
+)
+
+func TestNew(t *testing.T) {
+	info := New("fgo", "1.2.3", "2026-01-02T03:04:05Z", "abc123")
+	want := Info{Name: "fgo", Version: "1.2.3", BuildTime: "2026-01-02T03:04:05Z", GoVersion: runtime.Version(), Commit: "abc123"}
+	if info != want {
+		t.Errorf("New = %+v, want %+v", info, want)
+	}
+
+	// Test binaries carry no VCS stamp, so an unstamped commit stays unknown.
+	if info := New("fgo", "1.2.3", Unknown, Unknown); info.Commit != Unknown || info.BuildTime != Unknown {
+		t.Errorf("New with nothing stamped = %+v", info)
+	}
+}
+
+func TestWriteJSON(t *testing.T) {
+	var buf bytes.Buffer
+	info := New("ghx", "0.1.0", Unknown, "abc123")
+	if err := info.WriteJSON(&buf); err != nil {
+		t.Fatal(err)
+	}
+	var decoded map[string]string
+	if err := json.Unmarshal(buf.Bytes(), &decoded); err != nil {
+		t.Fatalf("WriteJSON wrote invalid JSON %q: %v", buf.String(), err)
+	}
+	for _, key := range []string{"name", "version", "buildTime", "goVersion", "commit"} {
+		if _, ok := decoded[key]; !ok {
+			t.Errorf("WriteJSON output lacks %q: %s", key, buf.String())
+		}
+	}
+}
@@ -0,0 +1,232 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/dzonerzy/go-snap/snap"
+)
+
+type commandAlias struct {
+	Name    string
+	Command string
+	Args    []string
+}
+
+func (a commandAlias) Expansion() string {
+	return strings.TrimSpace(a.Command + " " + strings.Join(a.Args, " "))
+}
+
+var (
+	commandAliases   []commandAlias
+	aliasNamePattern = regexp.MustCompile(`^[A-Za-z][A-Za-z0-9_-]*$`)
+)
+
+func aliasesPath() (string, error) {
+	homeDir, err := os.UserHomeDir()
+	if err != nil {
+		return "", fmt.Errorf("determine home directory: %w", err)
+	}
+	return filepath.Join(homeDir, ".flow", "aliases.toml"), nil
+}
+
+func loadAliasValues() (string, map[string]string, error) {
+	path, err := aliasesPath()
+	if err != nil {
+		return "", nil, err
+	}
+	values, err := loadFlatTOML(path)
+	if err != nil {
+		return "", nil, err
+	}
+	return path, values, nil
+}
+
+// registerAliases adds aliases from ~/.flow/aliases.toml to the catalog so
+// they show up in help and the palette. Invalid entries are reported on
+// warn and skipped rather than blocking every command.
+func registerAliases(warn io.Writer) {
+	_, values, err := loadAliasValues()
+	if err != nil {
+		fmt.Fprintf(warn, "ℹ️ Ignoring aliases: %v\n", err)
+		return
+	}
+
+	names := make([]string, 0, len(values))
+	for name := range values {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		alias, err := parseCommandAlias(name, values[name])
+		if err != nil {
+			fmt.Fprintf(warn, "ℹ️ Ignoring alias %q: %v\n", name, err)
+			continue
+		}
+		commandAliases = append(commandAliases, alias)
+	}
+
+	for _, alias := range commandAliases {
+		commandCatalog = append(commandCatalog, commandInfo{
+			name:        alias.Name,
+			description: "Alias for " + alias.Expansion(),
+		})
+	}
+}
+
+func parseCommandAlias(name, expansion string) (commandAlias, error) {
+	if !aliasNamePattern.MatchString(name) {
+		return commandAlias{}, fmt.Errorf("alias names must start with a letter and contain only letters, digits, '-' or '_'")
+	}
+	if isBuiltinCommand(name) {
+		return commandAlias{}, fmt.Errorf("%q is a built-in command and cannot be shadowed", name)
+	}
+
+	fields := strings.Fields(expansion)
+	if len(fields) == 0 {
+		return commandAlias{}, fmt.Errorf("alias expands to nothing")
+	}
+	if !isBuiltinCommand(fields[0]) {
+		return commandAlias{}, fmt.Errorf("unknown command %q", fields[0])
+	}
+	return commandAlias{Name: name, Command: fields[0], Args: fields[1:]}, nil
+}
+
+// isBuiltinCommand reports whether name is a registered command. Aliases are
+// appended to the catalog after every built-in, so they are excluded here.
+func isBuiltinCommand(name string) bool {
+	if name == "help" {
+		return true
+	}
+	for _, entry := range commandCatalog[:len(commandCatalog)-len(commandAliases)] {
+		if entry.name == name {
+			return true
+		}
+	}
+	return false
+}
+
+func aliasesFileHeader() string {
+	return fmt.Sprintf("%s command aliases: name = \"command [args...]\"", commandName)
+}
+
+func findCommandAlias(name string) (commandAlias, bool) {
+	for _, alias := range commandAliases {
+		if alias.Name == name {
+			return alias, true
+		}
+	}
+	return commandAlias{}, false
+}
+
+// expandCommandAlias rewrites `fgo <alias> extra...` into the aliased command
+// with its fixed arguments followed by any extra ones.
+func expandCommandAlias(args []string) []string {
+	if len(args) == 0 {
+		return args
+	}
+	alias, ok := findCommandAlias(args[0])
+	if !ok {
+		return args
+	}
+	expanded := append([]string{alias.Command}, alias.Args...)
+	return append(expanded, args[1:]...)
+}
+
+func runAlias(ctx *snap.Context) error {
+	usage := func() {
+		fmt.Fprintf(ctx.Stderr(), "Usage: %s alias <list|set <name> <command> [args...]|unset <name>|path>\n", commandName)
+	}
+
+	action := "list"
+	if ctx.NArgs() > 0 {
+		action = strings.TrimSpace(ctx.Arg(0))
+	}
+
+	switch action {
+	case "list":
+		if ctx.NArgs() > 1 {
+			usage()
+			return fmt.Errorf("alias list takes no arguments")
+		}
+		return aliasList(ctx)
+	case "path":
+		path, err := aliasesPath()
+		if err != nil {
+			return reportError(ctx, err)
+		}
+		fmt.Fprintln(ctx.Stdout(), path)
+		return nil
+	case "set":
+		if ctx.NArgs() < 3 {
+			usage()
+			return fmt.Errorf("alias set expects a name and a command")
+		}
+		name := strings.TrimSpace(ctx.Arg(1))
+		parts := make([]string, 0, ctx.NArgs()-2)
+		for i := 2; i < ctx.NArgs(); i++ {
+			parts = append(parts, ctx.Arg(i))
+		}
+		expansion := strings.Join(parts, " ")
+		if _, err := parseCommandAlias(name, expansion); err != nil {
+			return reportError(ctx, err)
+		}
+
+		path, values, err := loadAliasValues()
+		if err != nil {
+			return reportError(ctx, err)
+		}
+		values[name] = expansion
+		if err := writeFlatTOML(path, aliasesFileHeader(), values); err != nil {
+			return reportError(ctx, err)
+		}
+		fmt.Fprintf(ctx.Stdout(), "✔️ %s → %s\n", name, expansion)
+		return nil
+	case "unset":
+		if ctx.NArgs() != 2 {
+			usage()
+			return fmt.Errorf("alias unset expects 1 name, got %d arguments", ctx.NArgs()-1)
+		}
+		name := strings.TrimSpace(ctx.Arg(1))
+		path, values, err := loadAliasValues()
+		if err != nil {
+			return reportError(ctx, err)
+		}
+		if _, ok := values[name]; !ok {
+			return reportError(ctx, fmt.Errorf("no alias named %q", name))
+		}
+		delete(values, name)
+		if err := writeFlatTOML(path, aliasesFileHeader(), values); err != nil {
+			return reportError(ctx, err)
+		}
+		fmt.Fprintf(ctx.Stdout(), "✔️ Removed alias %s\n", name)
+		return nil
+	default:
+		usage()
+		return fmt.Errorf("unknown alias action %q", action)
+	}
+}
+
+func aliasList(ctx *snap.Context) error {
+	if len(commandAliases) == 0 {
+		fmt.Fprintf(ctx.Stdout(), "No aliases defined. Add one with: %s alias set <name> <command> [args...]\n", commandName)
+		return nil
+	}
+
+	width := 0
+	for _, alias := range commandAliases {
+		if len(alias.Name) > width {
+			width = len(alias.Name)
+		}
+	}
+	for _, alias := range commandAliases {
+		fmt.Fprintf(ctx.Stdout(), "%-*s  →  %s\n", width, alias.Name, alias.Expansion())
+	}
+	return nil
+}
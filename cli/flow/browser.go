@@ -0,0 +1,118 @@
+package main
+
+import (
+	"fmt"
+	"os/exec"
+	"sort"
+	"strings"
+)
+
+const flowBrowserEnv = "FLOW_BROWSER"
+
+type browserApp struct {
+	// Name is the application name AppleScript addresses.
+	Name string
+	// URLExpr reads the frontmost tab URL inside a `tell application` block.
+	URLExpr string
+}
+
+// Safari and Chromium-based browsers agree on `tabs of front window`.
+const browserTabsExpr = `if (count of windows) is 0 then error "%[1]s has no open windows"
+	set AppleScript's text item delimiters to linefeed
+	return (URL of every tab of front window) as text`
+
+// Safari exposes tabs through documents; Chromium-based browsers use windows and tabs.
+var supportedBrowsers = map[string]browserApp{
+	"safari": {Name: "Safari", URLExpr: `if not (exists front document) then error "Safari has no front document"
+	return URL of front document`},
+	"chrome": {Name: "Google Chrome", URLExpr: `if (count of windows) is 0 then error "Google Chrome has no open windows"
+	return URL of active tab of front window`},
+	"arc": {Name: "Arc", URLExpr: `if (count of windows) is 0 then error "Arc has no open windows"
+	return URL of active tab of front window`},
+	"brave": {Name: "Brave Browser", URLExpr: `if (count of windows) is 0 then error "Brave Browser has no open windows"
+	return URL of active tab of front window`},
+}
+
+// configuredBrowser returns the browser selected by FLOW_BROWSER, defaulting to Safari.
+func configuredBrowser() (browserApp, error) {
+	key, ok := lookupSetting(flowBrowserEnv)
+	if !ok {
+		return supportedBrowsers["safari"], nil
+	}
+
+	browser, ok := supportedBrowsers[strings.ToLower(key)]
+	if !ok {
+		names := make([]string, 0, len(supportedBrowsers))
+		for name := range supportedBrowsers {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		return browserApp{}, fmt.Errorf("unsupported %s=%q (expected one of %s)", flowBrowserEnv, key, strings.Join(names, ", "))
+	}
+	return browser, nil
+}
+
+// frontmostBrowserURL returns the active tab URL of the configured browser
+// along with the browser's display name.
+func frontmostBrowserURL() (string, string, error) {
+	browser, err := configuredBrowser()
+	if err != nil {
+		return "", "", err
+	}
+
+	output, err := runBrowserScript(browser, browser.URLExpr)
+	if err != nil {
+		return "", browser.Name, err
+	}
+
+	url := strings.TrimSpace(output)
+	if url == "" || url == "missing value" {
+		return "", browser.Name, fmt.Errorf("front %s tab URL is empty", browser.Name)
+	}
+
+	return url, browser.Name, nil
+}
+
+// frontWindowTabURLs lists every tab URL in the configured browser's front window.
+func frontWindowTabURLs() ([]string, string, error) {
+	browser, err := configuredBrowser()
+	if err != nil {
+		return nil, "", err
+	}
+
+	output, err := runBrowserScript(browser, fmt.Sprintf(browserTabsExpr, browser.Name))
+	if err != nil {
+		return nil, browser.Name, err
+	}
+
+	var urls []string
+	for _, line := range strings.Split(output, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || line == "missing value" {
+			continue
+		}
+		urls = append(urls, line)
+	}
+	return urls, browser.Name, nil
+}
+
+func runBrowserScript(browser browserApp, body string) (string, error) {
+	if _, err := exec.LookPath("osascript"); err != nil {
+		return "", fmt.Errorf("osascript not found in PATH: %w", err)
+	}
+
+	script := fmt.Sprintf(`if application "%[1]s" is not running then error "%[1]s is not running"
+tell application "%[1]s"
+	%[2]s
+end tell`, browser.Name, body)
+
+	output, err := flowCommand("osascript", "-e", script).CombinedOutput()
+	if err != nil {
+		trimmed := strings.TrimSpace(string(output))
+		if trimmed != "" {
+			return "", fmt.Errorf("osascript: %s", trimmed)
+		}
+		return "", fmt.Errorf("osascript failed: %w", err)
+	}
+	return string(output), nil
+}
@@ -0,0 +1,178 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/dzonerzy/go-snap/snap"
+	"github.com/ktr0731/go-fuzzyfinder"
+)
+
+// githubReservedPaths are top-level github.com paths that are not owners.
+var githubReservedPaths = map[string]bool{
+	"about": true, "collections": true, "explore": true, "features": true,
+	"login": true, "marketplace": true, "new": true, "notifications": true,
+	"orgs": true, "pulls": true, "issues": true, "search": true,
+	"settings": true, "sponsors": true, "topics": true, "trending": true,
+}
+
+type browserTabTarget struct {
+	URL      string
+	Label    string
+	Ref      string
+	PRNumber int
+}
+
+func (t browserTabTarget) isPR() bool {
+	return t.PRNumber > 0
+}
+
+func runOpenBrowserTabs(ctx *snap.Context) error {
+	if ctx.NArgs() != 0 {
+		fmt.Fprintf(ctx.Stderr(), "Usage: %s openBrowserTabs\n", commandName)
+		return fmt.Errorf("expected 0 arguments, got %d", ctx.NArgs())
+	}
+
+	tabURLs, browser, err := frontWindowTabURLs()
+	if err != nil {
+		return reportError(ctx, fmt.Errorf("list browser tabs: %w", err))
+	}
+
+	targets, ignored := githubTabTargets(tabURLs)
+	if len(targets) == 0 {
+		fmt.Fprintf(ctx.Stdout(), "No GitHub repository or pull request tabs found in the front %s window (%d tab(s) checked).\n", browser, len(tabURLs))
+		return nil
+	}
+
+	indices, err := fuzzyfinder.FindMulti(
+		targets,
+		func(i int) string {
+			return targets[i].Label
+		},
+		fuzzyfinder.WithPromptString("openBrowserTabs (tab to select)> "),
+	)
+	if err != nil {
+		if errors.Is(err, fuzzyfinder.ErrAbort) {
+			return errUserAbort
+		}
+		return reportError(ctx, fmt.Errorf("select tabs: %w", err))
+	}
+
+	var cloned, skipped, failed []string
+	for _, idx := range indices {
+		target := targets[idx]
+
+		dest, err := browserTabDestination(target)
+		if err != nil {
+			failed = append(failed, fmt.Sprintf("%s (%v)", target.Label, err))
+			continue
+		}
+		if _, err := os.Stat(dest); err == nil {
+			skipped = append(skipped, fmt.Sprintf("%s (already at %s)", target.Label, dest))
+			continue
+		}
+
+		fmt.Fprintf(ctx.Stdout(), "Cloning %s\n", target.Label)
+		if target.isPR() {
+			dest, err = clonePullRequest(ctx, target.Ref)
+		} else {
+			dest, err = cloneRepository(ctx, target.Ref)
+		}
+		if err != nil {
+			failed = append(failed, fmt.Sprintf("%s (%v)", target.Label, err))
+			continue
+		}
+		cloned = append(cloned, fmt.Sprintf("%s -> %s", target.Label, dest))
+	}
+
+	fmt.Fprintln(ctx.Stdout())
+	for _, line := range cloned {
+		fmt.Fprintf(ctx.Stdout(), "✔️ Cloned %s\n", line)
+	}
+	for _, line := range skipped {
+		fmt.Fprintf(ctx.Stdout(), "ℹ️ Skipped %s\n", line)
+	}
+	for _, line := range failed {
+		fmt.Fprintf(ctx.Stderr(), "Failed %s\n", line)
+	}
+	fmt.Fprintf(ctx.Stdout(), "Cloned %d, skipped %d, failed %d; ignored %d non-GitHub tab(s).\n", len(cloned), len(skipped), len(failed), ignored)
+
+	if len(failed) > 0 {
+		return fmt.Errorf("%d tab(s) failed to clone", len(failed))
+	}
+	return nil
+}
+
+// githubTabTargets keeps GitHub repository and pull request URLs, deduplicated,
+// and reports how many tabs were ignored.
+func githubTabTargets(tabURLs []string) ([]browserTabTarget, int) {
+	var targets []browserTabTarget
+	seen := make(map[string]bool)
+	ignored := 0
+
+	for _, raw := range tabURLs {
+		u, err := url.Parse(raw)
+		if err != nil || !strings.EqualFold(u.Host, "github.com") {
+			ignored++
+			continue
+		}
+
+		segments := strings.Split(strings.Trim(u.Path, "/"), "/")
+		if len(segments) < 2 || segments[0] == "" || segments[1] == "" || githubReservedPaths[strings.ToLower(segments[0])] {
+			ignored++
+			continue
+		}
+		owner, repo := segments[0], strings.TrimSuffix(segments[1], ".git")
+
+		var target browserTabTarget
+		if len(segments) >= 4 && segments[2] == "pull" {
+			if _, _, number, err := parsePullRequestRef(fmt.Sprintf("https://github.com/%s/%s/pull/%s", owner, repo, segments[3])); err == nil {
+				target = browserTabTarget{
+					URL:      raw,
+					Label:    fmt.Sprintf("%s/%s#%d", owner, repo, number),
+					Ref:      fmt.Sprintf("%s/%s#%d", owner, repo, number),
+					PRNumber: number,
+				}
+			}
+		}
+		if target.Ref == "" {
+			target = browserTabTarget{
+				URL:   raw,
+				Label: fmt.Sprintf("%s/%s", owner, repo),
+				Ref:   fmt.Sprintf("https://github.com/%s/%s", owner, repo),
+			}
+		}
+
+		if seen[target.Label] {
+			continue
+		}
+		seen[target.Label] = true
+		targets = append(targets, target)
+	}
+
+	return targets, ignored
+}
+
+func browserTabDestination(target browserTabTarget) (string, error) {
+	if target.isPR() {
+		_, repo, number, err := parsePullRequestRef(target.Ref)
+		if err != nil {
+			return "", err
+		}
+		return pullRequestCloneDestination(repo, number)
+	}
+
+	owner, repo, _, err := parseGitHubCloneInfo(target.Ref)
+	if err != nil {
+		return "", err
+	}
+	homeDir, err := os.UserHomeDir()
+	if err != nil {
+		return "", fmt.Errorf("determine home directory: %w", err)
+	}
+	return filepath.Join(homeDir, "gh", owner, repo), nil
+}
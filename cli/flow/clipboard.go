@@ -0,0 +1,171 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os/exec"
+	"runtime"
+	"strings"
+	"unicode"
+
+	"github.com/dzonerzy/go-snap/snap"
+)
+
+func runClipboard(ctx *snap.Context) error {
+	usage := func() {
+		fmt.Fprintf(ctx.Stderr(), "Usage: %s clipboard [--trim] [--slug] [--json-pretty] | --write <text>\n", commandName)
+	}
+
+	var trim, slug, jsonPretty, write bool
+	var writeText string
+	for i := 0; i < ctx.NArgs(); i++ {
+		arg := ctx.Arg(i)
+		switch {
+		case strings.TrimSpace(arg) == "":
+		case arg == "--trim":
+			trim = true
+		case arg == "--slug":
+			slug = true
+		case arg == "--json-pretty":
+			jsonPretty = true
+		case arg == "--write":
+			i++
+			if i >= ctx.NArgs() {
+				usage()
+				return reportError(ctx, fmt.Errorf("--write requires a value"))
+			}
+			write, writeText = true, ctx.Arg(i)
+		case strings.HasPrefix(arg, "--write="):
+			write, writeText = true, strings.TrimPrefix(arg, "--write=")
+		default:
+			usage()
+			return reportError(ctx, fmt.Errorf("unexpected argument %q", arg))
+		}
+	}
+
+	if write {
+		if trim || slug || jsonPretty {
+			usage()
+			return reportError(ctx, fmt.Errorf("--write cannot be combined with transform flags"))
+		}
+		if err := writeClipboardText(writeText); err != nil {
+			return reportError(ctx, fmt.Errorf("write clipboard: %w", err))
+		}
+		fmt.Fprintf(ctx.Stdout(), "✔️ Copied %d characters to the clipboard\n", len([]rune(writeText)))
+		return nil
+	}
+
+	text, err := readClipboardText()
+	if err != nil {
+		return reportError(ctx, fmt.Errorf("read clipboard: %w", err))
+	}
+
+	if trim {
+		text = strings.TrimSpace(text)
+	}
+	if slug {
+		text = slugify(text)
+	}
+	if jsonPretty {
+		var pretty bytes.Buffer
+		if err := json.Indent(&pretty, []byte(text), "", "  "); err != nil {
+			fmt.Fprintf(ctx.Stderr(), "ℹ️ Clipboard is not valid JSON (%v); printing it unchanged\n", err)
+		} else {
+			text = pretty.String()
+		}
+	}
+
+	fmt.Fprint(ctx.Stdout(), text)
+	if !strings.HasSuffix(text, "\n") {
+		fmt.Fprintln(ctx.Stdout())
+	}
+	return nil
+}
+
+// slugify turns free text such as an issue title into a lowercase,
+// hyphen-separated name that is safe for branches and directories.
+func slugify(text string) string {
+	var b strings.Builder
+	pendingDash := false
+	for _, r := range strings.ToLower(text) {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			if pendingDash && b.Len() > 0 {
+				b.WriteByte('-')
+			}
+			pendingDash = false
+			b.WriteRune(r)
+			continue
+		}
+		pendingDash = true
+	}
+	return b.String()
+}
+
+type clipCommand struct {
+	name string
+	args []string
+}
+
+// clipboardReadCandidates lists the commands readClipboardText tries, in
+// order. Windows has no pbpaste equivalent on PATH, so PowerShell's
+// Get-Clipboard is tried first there.
+func clipboardReadCandidates(goos string) []clipCommand {
+	candidates := []clipCommand{
+		{name: "pbpaste"},
+		{name: "wl-paste"},
+		{name: "xclip", args: []string{"-selection", "clipboard", "-o"}},
+	}
+	if goos == "windows" {
+		windows := clipCommand{name: "powershell", args: []string{"-NoProfile", "-Command", "Get-Clipboard -Raw"}}
+		candidates = append([]clipCommand{windows}, candidates...)
+	}
+	return candidates
+}
+
+func clipCommandNames(candidates []clipCommand) string {
+	names := make([]string, len(candidates))
+	for i, candidate := range candidates {
+		names[i] = candidate.name
+	}
+	return strings.Join(names, ", ")
+}
+
+// clipboardWriteCandidates mirrors clipboardReadCandidates for writing.
+func clipboardWriteCandidates(goos string) []clipCommand {
+	candidates := []clipCommand{
+		{name: "pbcopy"},
+		{name: "wl-copy"},
+		{name: "xclip", args: []string{"-selection", "clipboard"}},
+	}
+	if goos == "windows" {
+		windows := clipCommand{name: "powershell", args: []string{"-NoProfile", "-Command", "$input | Set-Clipboard"}}
+		candidates = append([]clipCommand{windows}, candidates...)
+	}
+	return candidates
+}
+
+func writeClipboardText(text string) error {
+	candidates := clipboardWriteCandidates(runtime.GOOS)
+
+	sawCommand := false
+	var lastErr error
+	for _, candidate := range candidates {
+		if _, err := exec.LookPath(candidate.name); err != nil {
+			continue
+		}
+		sawCommand = true
+		cmd := flowCommand(candidate.name, candidate.args...)
+		cmd.Stdin = strings.NewReader(text)
+		if err := cmd.Run(); err != nil {
+			lastErr = fmt.Errorf("%s: %w", candidate.name, err)
+			continue
+		}
+		return nil
+	}
+
+	if !sawCommand {
+		return fmt.Errorf("no clipboard utility found (tried %s)", clipCommandNames(candidates))
+	}
+	return lastErr
+}
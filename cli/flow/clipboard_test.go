@@ -0,0 +1,21 @@
+package main
+
+import "testing"
+
+func TestClipboardReadCandidatesIncludesWindowsOnWindows(t *testing.T) {
+	candidates := clipboardReadCandidates("windows")
+	if len(candidates) == 0 || candidates[0].name != "powershell" {
+		t.Fatalf("expected powershell first on windows, got %q", clipCommandNames(candidates))
+	}
+	if got := candidates[0].args[len(candidates[0].args)-1]; got != "Get-Clipboard -Raw" {
+		t.Fatalf("expected Get-Clipboard command, got %q", got)
+	}
+}
+
+func TestClipboardReadCandidatesKeepsUnixOrder(t *testing.T) {
+	for _, goos := range []string{"darwin", "linux"} {
+		if got := clipCommandNames(clipboardReadCandidates(goos)); got != "pbpaste, wl-paste, xclip" {
+			t.Fatalf("%s: unexpected candidate order %q", goos, got)
+		}
+	}
+}
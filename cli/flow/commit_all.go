@@ -0,0 +1,202 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"os/exec"
+	"strings"
+
+	"lang/gitutil"
+
+	"github.com/dzonerzy/go-snap/snap"
+)
+
+const commitAllUsage = "[--dry-run] [--sign] [--co-author \"Name <email>\"]... [--ticket-prefix]"
+
+// changedFile is one entry of `git status --porcelain -z`. Paths has the new
+// path first and, for a staged rename or copy, the original after it.
+type changedFile struct {
+	Status    string
+	Paths     []string
+	Untracked bool
+}
+
+func runCommitAll(ctx *snap.Context) error {
+	usage := func() {
+		fmt.Fprintf(ctx.Stderr(), "Usage: %s commitAll %s\n", commandName, commitAllUsage)
+	}
+
+	// commitAll stages each file itself, so the staging flags
+	// parseCommitOptions knows are refused rather than ignored.
+	dryRun := false
+	staging := ""
+	opts, err := parseCommitOptions(ctx, "commitAll [--dry-run]", func(arg string) bool {
+		switch arg {
+		case "--dry-run", "-n":
+			dryRun = true
+		case "--all", "-a", "--patch", "-p", "--staged-only", "--staged":
+			staging = arg
+		default:
+			return false
+		}
+		return true
+	})
+	if err != nil {
+		return err
+	}
+	if staging != "" {
+		usage()
+		return reportError(ctx, fmt.Errorf("%s does not apply; commitAll stages each file itself", staging))
+	}
+
+	if err := gitutil.EnsureRepository(flowCtx); err != nil {
+		return err
+	}
+	if exists, err := gitutil.RefExists(flowCtx, "HEAD"); err != nil {
+		return reportError(ctx, err)
+	} else if !exists {
+		return reportError(ctx, fmt.Errorf("commitAll needs an initial commit to diff against"))
+	}
+
+	files, err := listChangedFiles()
+	if err != nil {
+		return reportError(ctx, err)
+	}
+	if len(files) == 0 {
+		fmt.Fprintln(ctx.Stdout(), "Nothing to commit.")
+		return nil
+	}
+
+	apiKey, err := resolveOpenAIKey(ctx.Context())
+	if err != nil {
+		return reportError(ctx, err)
+	}
+
+	committed := 0
+	for i, file := range files {
+		fmt.Fprintf(ctx.Stdout(), "[%d/%d] %s %s\n", i+1, len(files), file.Status, strings.Join(file.Paths, " <- "))
+
+		diff, err := changedFileDiff(file)
+		if err != nil {
+			return reportError(ctx, err)
+		}
+		if strings.TrimSpace(diff) == "" {
+			// Mode-only or submodule changes can diff as empty; commit them
+			// under a plain message rather than asking the model about nothing.
+			diff = fmt.Sprintf("%s %s\n", file.Status, file.Paths[0])
+		}
+
+		payload, err := proposeCommitMessage(ctx, apiKey, diff)
+		if err != nil {
+			return commitAllStopped(ctx, committed, err)
+		}
+		if opts.ticketPrefix {
+			if err := applyTicketPrefix(ctx, payload); err != nil {
+				return commitAllStopped(ctx, committed, err)
+			}
+		}
+		printProposedMessage(ctx, payload)
+		if dryRun {
+			continue
+		}
+
+		if err := commitChangedFile(ctx, file, payload, opts); err != nil {
+			return commitAllStopped(ctx, committed, err)
+		}
+		committed++
+	}
+
+	if dryRun {
+		fmt.Fprintf(ctx.Stdout(), "ℹ️ Dry run: %d commit(s) planned, nothing staged or committed\n", len(files))
+		return nil
+	}
+	fmt.Fprintf(ctx.Stdout(), "✔️ Created %d commit(s)\n", committed)
+	return nil
+}
+
+func commitAllStopped(ctx *snap.Context, committed int, err error) error {
+	if committed > 0 {
+		fmt.Fprintf(ctx.Stderr(), "ℹ️ Stopped after %d commit(s); the remaining files are untouched\n", committed)
+	}
+	return err
+}
+
+func listChangedFiles() ([]changedFile, error) {
+	out, err := flowCommand("git", "status", "--porcelain", "-z", "--untracked-files=all").Output()
+	if err != nil {
+		return nil, fmt.Errorf("git status --porcelain: %w", err)
+	}
+	return parseChangedFiles(string(out)), nil
+}
+
+func parseChangedFiles(raw string) []changedFile {
+	fields := strings.Split(raw, "\x00")
+	var files []changedFile
+	for i := 0; i < len(fields); i++ {
+		field := fields[i]
+		if len(field) < 4 {
+			continue
+		}
+		status, path := field[:2], field[3:]
+		file := changedFile{
+			Status:    strings.TrimSpace(status),
+			Paths:     []string{path},
+			Untracked: status == "??",
+		}
+		if (status[0] == 'R' || status[0] == 'C') && i+1 < len(fields) {
+			i++
+			file.Paths = append(file.Paths, fields[i])
+		}
+		files = append(files, file)
+	}
+	return files
+}
+
+// changedFileDiff is what committing file would record, against HEAD.
+func changedFileDiff(file changedFile) (string, error) {
+	if !file.Untracked {
+		args := append([]string{"diff", "HEAD", "--"}, file.Paths...)
+		out, err := flowCommand("git", args...).Output()
+		if err != nil {
+			return "", fmt.Errorf("git diff HEAD -- %s: %w", file.Paths[0], err)
+		}
+		return string(out), nil
+	}
+
+	// --no-index exits 1 when the files differ, which they always do here.
+	out, err := flowCommand("git", "diff", "--no-index", "--", "/dev/null", file.Paths[0]).Output()
+	var exitErr *exec.ExitError
+	if err != nil && !(errors.As(err, &exitErr) && exitErr.ExitCode() == 1) {
+		return "", fmt.Errorf("git diff --no-index %s: %w", file.Paths[0], err)
+	}
+	return string(out), nil
+}
+
+// commitChangedFile stages and commits only file's paths. Naming them after
+// -- makes git commit leave anything else in the index alone.
+func commitChangedFile(ctx *snap.Context, file changedFile, payload *commitPayload, opts commitOptions) error {
+	addArgs := append([]string{"add", "-A", "--"}, file.Paths...)
+	if out, err := flowCommand("git", addArgs...).CombinedOutput(); err != nil {
+		return reportError(ctx, fmt.Errorf("git add %s: %s: %w", file.Paths[0], strings.TrimSpace(string(out)), err))
+	}
+
+	args := []string{"commit", "--quiet"}
+	if opts.sign {
+		args = append(args, "-S")
+	}
+	for _, paragraph := range withCoAuthorTrailers(payload.paragraphs, opts.coAuthors) {
+		args = append(args, "-m", paragraph)
+	}
+	args = append(args, "--")
+	args = append(args, file.Paths...)
+
+	cmd := flowCommand("git", args...)
+	cmd.Stdout = ctx.Stdout()
+	cmd.Stderr = ctx.Stderr()
+	cmd.Stdin = ctx.Stdin()
+	if err := cmd.Run(); err != nil {
+		return reportError(ctx, fmt.Errorf("git commit %s: %w", file.Paths[0], err))
+	}
+	fmt.Fprintf(ctx.Stdout(), "✔️ Committed %s\n", file.Paths[0])
+	return nil
+}
@@ -0,0 +1,19 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseChangedFiles(t *testing.T) {
+	raw := " M main.go\x00R  new.go\x00old.go\x00D  gone.txt\x00?? notes/todo.md\x00"
+	want := []changedFile{
+		{Status: "M", Paths: []string{"main.go"}},
+		{Status: "R", Paths: []string{"new.go", "old.go"}},
+		{Status: "D", Paths: []string{"gone.txt"}},
+		{Status: "??", Paths: []string{"notes/todo.md"}, Untracked: true},
+	}
+	if got := parseChangedFiles(raw); !reflect.DeepEqual(got, want) {
+		t.Fatalf("parseChangedFiles() = %+v, want %+v", got, want)
+	}
+}
@@ -0,0 +1,194 @@
+package main
+
+import (
+	"math"
+	"path"
+	"regexp"
+	"strings"
+)
+
+const redactedPlaceholder = "[REDACTED]"
+
+var (
+	secretTokenPatterns = []*regexp.Regexp{
+		regexp.MustCompile(`\b(?:AKIA|ASIA)[0-9A-Z]{16}\b`),
+		regexp.MustCompile(`\bsk-[A-Za-z0-9_-]{20,}`),
+		regexp.MustCompile(`\bgh[pousr]_[A-Za-z0-9]{30,}`),
+		regexp.MustCompile(`\bgithub_pat_[A-Za-z0-9_]{30,}`),
+		regexp.MustCompile(`\bxox[abprs]-[A-Za-z0-9-]{10,}`),
+	}
+	secretAssignmentPatterns = []*regexp.Regexp{
+		// Environment-style assignments such as OPENAI_API_KEY=sk-...
+		regexp.MustCompile(`\b([A-Z0-9_]*(?:API_?KEY|SECRET|TOKEN|PASSWORD|PASSWD|PRIVATE_?KEY|ACCESS_?KEY)[A-Z0-9_]*\s*=\s*["']?)([^\s"'=][^\s"']*)`),
+		// Quoted literals assigned to secret-looking keys in code or config.
+		regexp.MustCompile(`(?i)((?:api_?key|secret|token|password|passwd)["']?\s*[:=]\s*["'])([^"'\s]{8,})`),
+	}
+	entropyCandidatePattern = regexp.MustCompile(`[A-Za-z0-9+/_=-]{32,}`)
+)
+
+// redactDiffSecrets masks likely credentials in a unified diff before it is
+// sent to the model. It returns the redacted diff and how many values were masked.
+func redactDiffSecrets(diff string) (string, int) {
+	lines := strings.Split(diff, "\n")
+	count := 0
+	inPEM := false
+	envFile := false
+	// inHeader is true between a file's "diff --git" line and its first hunk,
+	// the only place "--- " and "+++ " name files rather than removed or
+	// added lines that happen to start with "-- " or "++ ".
+	inHeader := true
+
+	for i, line := range lines {
+		switch {
+		case strings.HasPrefix(line, "diff --git "):
+			envFile = false
+			inPEM = false
+			inHeader = true
+			continue
+		case strings.HasPrefix(line, "@@"):
+			inHeader = false
+			continue
+		case inHeader && (strings.HasPrefix(line, "+++ ") || strings.HasPrefix(line, "--- ")):
+			name := strings.TrimSpace(line[4:])
+			if name != "/dev/null" {
+				envFile = isDotEnvPath(strings.TrimPrefix(strings.TrimPrefix(name, "a/"), "b/"))
+			}
+			continue
+		}
+
+		if !isDiffContentLine(line) {
+			continue
+		}
+		prefix, body := line[:1], line[1:]
+
+		if inPEM {
+			if strings.Contains(body, "-----END ") {
+				inPEM = false
+			}
+			lines[i] = prefix + redactedPlaceholder
+			continue
+		}
+		if strings.Contains(body, "-----BEGIN ") && strings.Contains(body, "PRIVATE KEY-----") {
+			inPEM = !strings.Contains(body, "-----END ")
+			lines[i] = prefix + redactedPlaceholder
+			count++
+			continue
+		}
+
+		if envFile && prefix == "+" {
+			if key, _, ok := strings.Cut(body, "="); ok && !strings.HasPrefix(strings.TrimSpace(key), "#") {
+				lines[i] = prefix + key + "=" + redactedPlaceholder
+				count++
+				continue
+			}
+		}
+
+		redacted, n := redactSecretLine(body)
+		if n > 0 {
+			lines[i] = prefix + redacted
+			count += n
+		}
+	}
+
+	return strings.Join(lines, "\n"), count
+}
+
+func redactSecretLine(body string) (string, int) {
+	count := 0
+
+	for _, pattern := range secretAssignmentPatterns {
+		body = pattern.ReplaceAllStringFunc(body, func(match string) string {
+			parts := pattern.FindStringSubmatch(match)
+			if len(parts) != 3 || parts[2] == redactedPlaceholder {
+				return match
+			}
+			count++
+			return parts[1] + redactedPlaceholder
+		})
+	}
+
+	for _, pattern := range secretTokenPatterns {
+		body = pattern.ReplaceAllStringFunc(body, func(string) string {
+			count++
+			return redactedPlaceholder
+		})
+	}
+
+	body = entropyCandidatePattern.ReplaceAllStringFunc(body, func(match string) string {
+		if !looksLikeHighEntropyToken(match) {
+			return match
+		}
+		count++
+		return redactedPlaceholder
+	})
+
+	return body, count
+}
+
+func isDiffContentLine(line string) bool {
+	if line == "" {
+		return false
+	}
+	return line[0] == '+' || line[0] == '-' || line[0] == ' '
+}
+
+func isDotEnvPath(name string) bool {
+	base := path.Base(strings.TrimSpace(name))
+	if base == ".env" {
+		return true
+	}
+	if !strings.HasPrefix(base, ".env.") {
+		return false
+	}
+	// Templates are meant to be committed and rarely hold real values.
+	switch strings.TrimPrefix(base, ".env.") {
+	case "example", "sample", "template", "dist":
+		return false
+	}
+	return true
+}
+
+// looksLikeHighEntropyToken flags long random-looking strings while leaving
+// hex digests (commit hashes, checksums) and ordinary identifiers alone.
+func looksLikeHighEntropyToken(token string) bool {
+	if len(token) < 32 {
+		return false
+	}
+
+	var hasUpper, hasLower, hasDigit bool
+	for _, r := range token {
+		switch {
+		case r >= 'A' && r <= 'Z':
+			hasUpper = true
+		case r >= 'a' && r <= 'z':
+			hasLower = true
+		case r >= '0' && r <= '9':
+			hasDigit = true
+		}
+	}
+	if !hasDigit || !(hasUpper && hasLower) {
+		return false
+	}
+
+	return shannonEntropy(token) >= 4.5
+}
+
+func shannonEntropy(value string) float64 {
+	if value == "" {
+		return 0
+	}
+
+	freq := make(map[rune]int)
+	total := 0
+	for _, r := range value {
+		freq[r]++
+		total++
+	}
+
+	entropy := 0.0
+	for _, n := range freq {
+		p := float64(n) / float64(total)
+		entropy -= p * math.Log2(p)
+	}
+	return entropy
+}
@@ -0,0 +1,101 @@
+package main
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"lang/gitutil"
+
+	"github.com/dzonerzy/go-snap/snap"
+)
+
+const commitTicketPatternEnv = "FLOW_COMMIT_TICKET_PATTERN"
+
+// defaultTicketPattern finds a tracker key such as abc-123 in a branch name,
+// or failing that the first run of digits, which branchFromClipboard always
+// leaves in the name.
+const defaultTicketPattern = `[A-Za-z][A-Za-z0-9]*-[0-9]+|[0-9]+`
+
+// applyTicketPrefix prefixes the subject line of payload with the ticket id
+// in the current branch name. A branch without one is left alone.
+func applyTicketPrefix(ctx *snap.Context, payload *commitPayload) error {
+	pattern, err := commitTicketPattern()
+	if err != nil {
+		return reportError(ctx, err)
+	}
+	branch, err := gitutil.CurrentBranch(flowCtx)
+	if err != nil {
+		return reportError(ctx, err)
+	}
+
+	ticket := ticketFromBranch(branch, pattern)
+	if ticket == "" {
+		fmt.Fprintf(ctx.Stderr(), "ℹ️ No ticket id in branch %s; leaving the subject as is\n", branch)
+		return nil
+	}
+
+	subject := withTicketPrefix(payload.paragraphs[0], ticket)
+	if subject == payload.paragraphs[0] {
+		return nil
+	}
+	payload.paragraphs[0] = subject
+	payload.message = strings.Join(payload.paragraphs, "\n\n")
+	if payload.streamed {
+		fmt.Fprintf(ctx.Stdout(), "ℹ️ Subject prefixed with %s from branch %s\n", ticket, branch)
+	}
+	return nil
+}
+
+func commitTicketPattern() (*regexp.Regexp, error) {
+	value, ok := lookupSetting(commitTicketPatternEnv)
+	if !ok {
+		value = defaultTicketPattern
+	}
+	pattern, err := regexp.Compile(value)
+	if err != nil {
+		return nil, fmt.Errorf("invalid %s %q: %w", commitTicketPatternEnv, value, err)
+	}
+	return pattern, nil
+}
+
+// ticketFromBranch returns the first match of pattern in branch, or its first
+// non-empty capture group when it has groups. Letters are upper-cased, so
+// abc-123 becomes ABC-123, and a bare number becomes #123.
+func ticketFromBranch(branch string, pattern *regexp.Regexp) string {
+	match := pattern.FindStringSubmatch(branch)
+	if match == nil {
+		return ""
+	}
+	ticket := match[0]
+	for _, group := range match[1:] {
+		if group != "" {
+			ticket = group
+			break
+		}
+	}
+
+	ticket = strings.ToUpper(strings.TrimSpace(ticket))
+	if ticket != "" && strings.Trim(ticket, "0123456789") == "" {
+		ticket = "#" + ticket
+	}
+	return ticket
+}
+
+// withTicketPrefix returns subject as "TICKET: subject", unless it already
+// starts with the ticket, bracketed or not.
+func withTicketPrefix(subject, ticket string) string {
+	lead := strings.TrimLeft(subject, "[#")
+	bare := strings.TrimPrefix(ticket, "#")
+	if len(lead) >= len(bare) && strings.EqualFold(lead[:len(bare)], bare) {
+		rest := lead[len(bare):]
+		if rest == "" || !isTicketRune(rest[0]) {
+			return subject
+		}
+	}
+	return ticket + ": " + subject
+}
+
+func isTicketRune(c byte) bool {
+	return c == '-' || c == '_' || '0' <= c && c <= '9' || 'a' <= c && c <= 'z' || 'A' <= c && c <= 'Z'
+}
@@ -0,0 +1,48 @@
+package main
+
+import (
+	"regexp"
+	"testing"
+)
+
+func TestTicketFromBranch(t *testing.T) {
+	pattern := regexp.MustCompile(defaultTicketPattern)
+	cases := []struct {
+		branch string
+		want   string
+	}{
+		{"feature/abc-123-fix-login", "ABC-123"},
+		{"PROJ-42", "PROJ-42"},
+		{"nikiv/1234-retry-uploads", "#1234"},
+		{"fix-flaky-tests", ""},
+	}
+	for _, tc := range cases {
+		if got := ticketFromBranch(tc.branch, pattern); got != tc.want {
+			t.Errorf("ticketFromBranch(%q) = %q, want %q", tc.branch, got, tc.want)
+		}
+	}
+
+	grouped := regexp.MustCompile(`^[a-z]+/(\d+)-`)
+	if got := ticketFromBranch("nikiv/77-tidy", grouped); got != "#77" {
+		t.Errorf("expected the capture group to win, got %q", got)
+	}
+}
+
+func TestWithTicketPrefix(t *testing.T) {
+	cases := []struct {
+		subject string
+		ticket  string
+		want    string
+	}{
+		{"Fix login redirect", "ABC-123", "ABC-123: Fix login redirect"},
+		{"abc-123: Fix login redirect", "ABC-123", "abc-123: Fix login redirect"},
+		{"[ABC-123] Fix login redirect", "ABC-123", "[ABC-123] Fix login redirect"},
+		{"#42 Retry uploads", "#42", "#42 Retry uploads"},
+		{"420 errors are retried", "#42", "#42: 420 errors are retried"},
+	}
+	for _, tc := range cases {
+		if got := withTicketPrefix(tc.subject, tc.ticket); got != tc.want {
+			t.Errorf("withTicketPrefix(%q, %q) = %q, want %q", tc.subject, tc.ticket, got, tc.want)
+		}
+	}
+}
@@ -0,0 +1,351 @@
+package main
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"lang/skipdirs"
+
+	"github.com/dzonerzy/go-snap/snap"
+)
+
+const flowConfigEnv = "FLOW_CONFIG"
+
+// flowSetting maps a config.toml key to the environment variable that overrides it.
+type flowSetting struct {
+	Key         string
+	Env         string
+	Description string
+}
+
+var flowSettings = []flowSetting{
+	{Key: "editor", Env: flowEditorEnv, Description: "Editor for opening workspaces: cursor, zed, or a command"},
+	{Key: "browser", Env: flowBrowserEnv, Description: "Browser for frontmost-tab helpers: safari, chrome, arc, brave"},
+	{Key: "commit_model", Env: commitModelEnv, Description: "OpenAI model used for commit messages, reviews, and explanations"},
+	{Key: "commit_staged_only", Env: commitStagedOnlyEnv, Description: "Commit only what is already staged by default (true/false)"},
+	{Key: "commit_ticket_pattern", Env: commitTicketPatternEnv, Description: "Regex that finds the ticket id in a branch name for --ticket-prefix"},
+	{Key: "openai_max_attempts", Env: openAIMaxAttemptsEnv, Description: "Attempts for OpenAI requests before giving up"},
+	{Key: "openai_retry_delay", Env: openAIRetryDelayEnv, Description: "Base delay between OpenAI retries (Go duration)"},
+	{Key: "skip_dirs", Env: skipdirs.Env, Description: "Extra directory names tree walks skip, comma separated (!name un-skips a default)"},
+	{Key: "timeout", Env: flowTimeoutEnv, Description: "Overall limit for a command run, e.g. 2m (Go duration; unset means none; timer is exempt)"},
+	{Key: "window_focus_db", Env: windowFocusDBEnv, Description: "Path to the 1focus window-focus database"},
+	{Key: "workspace_file", Env: workspaceFileEnv, Description: "Path to the workspace paths file"},
+	{Key: "youtube_cookies_browser", Env: youtubeCookiesBrowserEnv, Description: "Browser yt-dlp reads cookies from, or none"},
+}
+
+var (
+	flowConfigOnce   sync.Once
+	flowConfigValues map[string]string
+)
+
+// lookupSetting returns the value of a setting, preferring its environment
+// variable and falling back to ~/.flow/config.toml.
+func lookupSetting(env string) (string, bool) {
+	if value, ok := lookupNonEmptyEnv(env); ok {
+		return value, true
+	}
+
+	setting, ok := findFlowSetting(env)
+	if !ok {
+		return "", false
+	}
+
+	flowConfigOnce.Do(func() {
+		values, err := loadFlowConfig()
+		if err != nil {
+			fmt.Fprintf(stderr, "ℹ️ Ignoring config file: %v\n", err)
+		}
+		flowConfigValues = values
+	})
+
+	value := strings.TrimSpace(flowConfigValues[setting.Key])
+	return value, value != ""
+}
+
+// findFlowSetting matches either the config key or its environment variable name.
+func findFlowSetting(name string) (flowSetting, bool) {
+	for _, setting := range flowSettings {
+		if strings.EqualFold(name, setting.Key) || name == setting.Env {
+			return setting, true
+		}
+	}
+	return flowSetting{}, false
+}
+
+func flowConfigPath() (string, error) {
+	if override, ok := lookupNonEmptyEnv(flowConfigEnv); ok {
+		return filepath.Clean(override), nil
+	}
+
+	homeDir, err := os.UserHomeDir()
+	if err != nil {
+		return "", fmt.Errorf("determine home directory: %w", err)
+	}
+	return filepath.Join(homeDir, ".flow", "config.toml"), nil
+}
+
+func loadFlowConfig() (map[string]string, error) {
+	path, err := flowConfigPath()
+	if err != nil {
+		return nil, err
+	}
+	return loadFlatTOML(path)
+}
+
+// loadFlatTOML reads a file in the format parseFlowConfig understands. A
+// missing file yields an empty map.
+func loadFlatTOML(path string) (map[string]string, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return map[string]string{}, nil
+		}
+		return nil, fmt.Errorf("open %s: %w", path, err)
+	}
+	defer file.Close()
+
+	values, err := parseFlowConfig(bufio.NewScanner(file))
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return values, nil
+}
+
+// parseFlowConfig reads the flat subset of TOML fgo writes: top-level
+// `key = value` pairs with basic, literal, or bare values.
+func parseFlowConfig(scanner *bufio.Scanner) (map[string]string, error) {
+	values := make(map[string]string)
+	lineNumber := 0
+	for scanner.Scan() {
+		lineNumber++
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		if strings.HasPrefix(line, "[") {
+			return nil, fmt.Errorf("line %d: tables are not supported", lineNumber)
+		}
+
+		key, raw, ok := strings.Cut(line, "=")
+		if !ok {
+			return nil, fmt.Errorf("line %d: expected key = value", lineNumber)
+		}
+		key = strings.TrimSpace(key)
+		if key == "" {
+			return nil, fmt.Errorf("line %d: missing key", lineNumber)
+		}
+
+		value, err := parseFlowConfigValue(strings.TrimSpace(raw))
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", lineNumber, err)
+		}
+		values[key] = value
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return values, nil
+}
+
+func parseFlowConfigValue(raw string) (string, error) {
+	switch {
+	case strings.HasPrefix(raw, `"`):
+		end := closingQuote(raw)
+		if end < 0 {
+			return "", fmt.Errorf("unterminated string %s", raw)
+		}
+		value, err := strconv.Unquote(raw[:end+1])
+		if err != nil {
+			return "", fmt.Errorf("invalid string %s: %w", raw[:end+1], err)
+		}
+		return value, nil
+	case strings.HasPrefix(raw, "'"):
+		end := strings.Index(raw[1:], "'")
+		if end < 0 {
+			return "", fmt.Errorf("unterminated string %s", raw)
+		}
+		return raw[1 : end+1], nil
+	default:
+		if hash := strings.Index(raw, "#"); hash >= 0 {
+			raw = raw[:hash]
+		}
+		return strings.TrimSpace(raw), nil
+	}
+}
+
+func closingQuote(raw string) int {
+	for i := 1; i < len(raw); i++ {
+		switch raw[i] {
+		case '\\':
+			i++
+		case '"':
+			return i
+		}
+	}
+	return -1
+}
+
+func writeFlowConfig(values map[string]string) (string, error) {
+	path, err := flowConfigPath()
+	if err != nil {
+		return "", err
+	}
+	header := fmt.Sprintf("%s settings; environment variables take precedence.", commandName)
+	if err := writeFlatTOML(path, header, values); err != nil {
+		return "", err
+	}
+	return path, nil
+}
+
+func writeFlatTOML(path, header string, values map[string]string) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return fmt.Errorf("create %s: %w", filepath.Dir(path), err)
+	}
+
+	keys := make([]string, 0, len(values))
+	for key := range values {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "# %s\n", header)
+	for _, key := range keys {
+		fmt.Fprintf(&b, "%s = %s\n", key, strconv.Quote(values[key]))
+	}
+
+	if err := os.WriteFile(path, []byte(b.String()), 0o644); err != nil {
+		return fmt.Errorf("write %s: %w", path, err)
+	}
+	return nil
+}
+
+func runConfig(ctx *snap.Context) error {
+	usage := func() {
+		fmt.Fprintf(ctx.Stderr(), "Usage: %s config <list|get <key>|set <key> <value>|unset <key>|path>\n", commandName)
+	}
+
+	if ctx.NArgs() == 0 {
+		return configList(ctx)
+	}
+
+	action := strings.TrimSpace(ctx.Arg(0))
+	switch action {
+	case "list":
+		if ctx.NArgs() != 1 {
+			usage()
+			return fmt.Errorf("config list takes no arguments")
+		}
+		return configList(ctx)
+	case "path":
+		path, err := flowConfigPath()
+		if err != nil {
+			return reportError(ctx, err)
+		}
+		fmt.Fprintln(ctx.Stdout(), path)
+		return nil
+	case "get":
+		if ctx.NArgs() != 2 {
+			usage()
+			return fmt.Errorf("config get expects 1 key, got %d arguments", ctx.NArgs()-1)
+		}
+		setting, err := configSettingArg(ctx.Arg(1))
+		if err != nil {
+			return reportError(ctx, err)
+		}
+		value, ok := lookupSetting(setting.Env)
+		if !ok {
+			return reportError(ctx, fmt.Errorf("%s is not set", setting.Key))
+		}
+		fmt.Fprintln(ctx.Stdout(), value)
+		return nil
+	case "set", "unset":
+		want := 3
+		if action == "unset" {
+			want = 2
+		}
+		if ctx.NArgs() != want {
+			usage()
+			return fmt.Errorf("config %s expects %d argument(s), got %d", action, want-1, ctx.NArgs()-1)
+		}
+		setting, err := configSettingArg(ctx.Arg(1))
+		if err != nil {
+			return reportError(ctx, err)
+		}
+
+		values, err := loadFlowConfig()
+		if err != nil {
+			return reportError(ctx, err)
+		}
+		if action == "set" {
+			value := strings.TrimSpace(ctx.Arg(2))
+			if value == "" {
+				return reportError(ctx, fmt.Errorf("value for %s cannot be empty; use config unset", setting.Key))
+			}
+			values[setting.Key] = value
+		} else {
+			delete(values, setting.Key)
+		}
+
+		path, err := writeFlowConfig(values)
+		if err != nil {
+			return reportError(ctx, err)
+		}
+		if action == "set" {
+			fmt.Fprintf(ctx.Stdout(), "✔️ Set %s in %s\n", setting.Key, path)
+		} else {
+			fmt.Fprintf(ctx.Stdout(), "✔️ Unset %s in %s\n", setting.Key, path)
+		}
+		if _, ok := lookupNonEmptyEnv(setting.Env); ok {
+			fmt.Fprintf(ctx.Stdout(), "ℹ️ %s is set in the environment and takes precedence\n", setting.Env)
+		}
+		return nil
+	default:
+		usage()
+		return fmt.Errorf("unknown config action %q", action)
+	}
+}
+
+func configSettingArg(name string) (flowSetting, error) {
+	setting, ok := findFlowSetting(strings.TrimSpace(name))
+	if !ok {
+		keys := make([]string, 0, len(flowSettings))
+		for _, s := range flowSettings {
+			keys = append(keys, s.Key)
+		}
+		return flowSetting{}, fmt.Errorf("unknown setting %q (known: %s)", name, strings.Join(keys, ", "))
+	}
+	return setting, nil
+}
+
+func configList(ctx *snap.Context) error {
+	values, err := loadFlowConfig()
+	if err != nil {
+		return reportError(ctx, err)
+	}
+
+	width := 0
+	for _, setting := range flowSettings {
+		if len(setting.Key) > width {
+			width = len(setting.Key)
+		}
+	}
+
+	for _, setting := range flowSettings {
+		value, source := "", "unset"
+		if env, ok := lookupNonEmptyEnv(setting.Env); ok {
+			value, source = env, "env "+setting.Env
+		} else if configured := strings.TrimSpace(values[setting.Key]); configured != "" {
+			value, source = configured, "config"
+		}
+		fmt.Fprintf(ctx.Stdout(), "%-*s  %-24s  (%s)\n", width, setting.Key, value, source)
+	}
+	return nil
+}
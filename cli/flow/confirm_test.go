@@ -0,0 +1,24 @@
+package main
+
+import "testing"
+
+func TestConfirmReply(t *testing.T) {
+	tests := []struct {
+		reply      string
+		defaultYes bool
+		want       bool
+	}{
+		{"", true, true},
+		{"", false, false},
+		{"  \n", true, true},
+		{"y\n", false, true},
+		{"YES", false, true},
+		{"n", true, false},
+		{"sure", true, false},
+	}
+	for _, tt := range tests {
+		if got := confirmReply(tt.reply, tt.defaultYes); got != tt.want {
+			t.Errorf("confirmReply(%q, %v) = %v, want %v", tt.reply, tt.defaultYes, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,99 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"lang/try/dockerlayers"
+
+	"github.com/dzonerzy/go-snap/snap"
+)
+
+func runDockerLayers(ctx *snap.Context) error {
+	var target string
+	var passthrough []string
+	for i := 0; i < ctx.NArgs(); i++ {
+		arg := strings.TrimSpace(ctx.Arg(i))
+		switch {
+		case arg == "":
+		case arg == "-file" || arg == "--file":
+			i++
+			if i >= ctx.NArgs() {
+				fmt.Fprintf(ctx.Stderr(), "Usage: %s dockerlayers [path] [-context] [-merge-runs N] [-max-layers N]\n", commandName)
+				return fmt.Errorf("%s requires a value", arg)
+			}
+			target = strings.TrimSpace(ctx.Arg(i))
+		case strings.HasPrefix(arg, "-"):
+			passthrough = append(passthrough, arg)
+			// Forward values of flags given as "-flag value".
+			if !strings.Contains(arg, "=") && i+1 < ctx.NArgs() && !strings.HasPrefix(ctx.Arg(i+1), "-") && dockerLayersFlagTakesValue(arg) {
+				i++
+				passthrough = append(passthrough, ctx.Arg(i))
+			}
+		case target == "":
+			target = arg
+		default:
+			fmt.Fprintf(ctx.Stderr(), "Usage: %s dockerlayers [path] [-context] [-merge-runs N] [-max-layers N]\n", commandName)
+			return fmt.Errorf("unexpected argument %q", arg)
+		}
+	}
+
+	path, others, err := resolveDockerfile(target)
+	if err != nil {
+		return reportError(ctx, err)
+	}
+	if path == "" {
+		cwd, _ := os.Getwd()
+		fmt.Fprintf(ctx.Stdout(), "ℹ️ No Dockerfile or Containerfile found in %s; pass a path: %s dockerlayers path/to/Dockerfile\n", cwd, commandName)
+		return nil
+	}
+	if len(others) > 0 {
+		fmt.Fprintf(ctx.Stderr(), "ℹ️ Analyzing %s; pass a path to pick one of: %s\n", path, strings.Join(others, ", "))
+	}
+
+	args := append([]string{"-file", path}, passthrough...)
+	if err := dockerlayers.RunCLI(args, ctx.Stdout(), ctx.Stderr()); err != nil {
+		return reportError(ctx, fmt.Errorf("dockerlayers: %w", err))
+	}
+	return nil
+}
+
+func dockerLayersFlagTakesValue(flag string) bool {
+	switch strings.TrimLeft(flag, "-") {
+	case "merge-runs", "max-layers":
+		return true
+	}
+	return false
+}
+
+// resolveDockerfile accepts a build file path or a directory containing
+// one, where a Dockerfile wins over a Containerfile and then *.dockerfile.
+// others lists the files passed over in that directory. It returns an empty
+// path when nothing was given and the current directory has no build file.
+func resolveDockerfile(target string) (path string, others []string, err error) {
+	dir := target
+	if target == "" {
+		dir = "."
+	} else {
+		info, err := os.Stat(target)
+		if err != nil {
+			return "", nil, fmt.Errorf("stat %s: %w", target, err)
+		}
+		if !info.IsDir() {
+			return target, nil, nil
+		}
+	}
+
+	candidates, err := dockerlayers.FindDockerfiles(dir)
+	if err != nil {
+		return "", nil, err
+	}
+	if len(candidates) == 0 {
+		if target == "" {
+			return "", nil, nil
+		}
+		return "", nil, fmt.Errorf("no Dockerfile or Containerfile found in %s", target)
+	}
+	return candidates[0], candidates[1:], nil
+}
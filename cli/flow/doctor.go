@@ -0,0 +1,219 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"slices"
+	"strings"
+
+	"github.com/dzonerzy/go-snap/snap"
+)
+
+// dependency is an external program or macOS app that some fgo commands
+// shell out to. Alternatives are accepted in place of Name, e.g. wl-paste
+// instead of pbpaste.
+type dependency struct {
+	Name         string
+	Alternatives []string
+	AppPath      string
+	Hint         string
+	Commands     []string
+}
+
+type dependencyStatus struct {
+	Name     string   `json:"name"`
+	Found    bool     `json:"found"`
+	Path     string   `json:"path,omitempty"`
+	Hint     string   `json:"hint,omitempty"`
+	Commands []string `json:"commands"`
+}
+
+var flowDependencies = []dependency{
+	{
+		Name: "git",
+		Hint: "xcode-select --install",
+		Commands: []string{"commit", "commitPush", "commitReviewAndPush", "commitAll", "branchFromClipboard", "clone", "cloneAndOpen", "clonePR",
+			"gitCheckout", "gitCheckoutRemote", "gitFetchUpstream", "gitSyncFork", "gitMirror", "gitUndo", "gitBlameRange", "gitReflame",
+			"gitStashPick", "gitLog", "gitAuthors", "gitDiffSize", "sizeReport", "diffStat", "smartCherryPick", "gitResolve", "gitInteractiveRebase", "gitIgnore", "explainDiff", "privateForkRepo", "privateForkRepoAndOpen",
+			"branchRename", "pushForce", "recentBranches", "gitSwitchLast", "gitAmend", "gitFixup", "gitTag", "gitConfigFix"},
+	},
+	{
+		Name:     "gh",
+		Hint:     "brew install gh && gh auth login",
+		Commands: []string{"clonePR", "gitCheckout <pr-url>", "openBrowserTabs", "prDiff", "prReview", "privateForkRepo", "privateForkRepoAndOpen", "createRepoFromRemote"},
+	},
+	{
+		Name:     "lsof",
+		Hint:     "ships with macOS; on Linux install the lsof package",
+		Commands: []string{"killPort", "checkPort"},
+	},
+	{
+		Name:     "osascript",
+		Hint:     "macOS only",
+		Commands: []string{"cloneAndOpen", "youtubeToSound", "openBrowserTabs", "listWindowsOfApp", "focusCursorWindow", "spotifyPlay", "spotifySearch", "spotifyCurrentPlayingSongCopy", "spotifyCurrentPlayingSongUrlCopy", "timer"},
+	},
+	{
+		Name:         "rg",
+		Alternatives: []string{"grep"},
+		Hint:         "brew install ripgrep (grep works as a slower fallback)",
+		Commands:     []string{"search"},
+	},
+	{
+		Name:     "yt-dlp",
+		Hint:     "brew install yt-dlp",
+		Commands: []string{"youtubeToSound"},
+	},
+	{
+		Name:     "go",
+		Hint:     "brew install go",
+		Commands: []string{"release"},
+	},
+	{
+		Name:     "task",
+		Hint:     "brew install go-task",
+		Commands: []string{"tasks"},
+	},
+	{
+		Name:         "pbpaste",
+		Alternatives: []string{"wl-paste", "xclip"},
+		Hint:         "macOS ships pbpaste; on Linux install wl-clipboard or xclip",
+		Commands:     []string{"branchFromClipboard", "notes --clip"},
+	},
+	{
+		Name:         "pbcopy",
+		Alternatives: []string{"wl-copy", "xclip"},
+		Hint:         "macOS ships pbcopy; on Linux install wl-clipboard or xclip",
+		Commands:     []string{"clipboard --write", "branchFromClipboard --copy", "createRepoFromRemote --copy", "spotifyCurrentPlayingSongCopy", "spotifyCurrentPlayingSongUrlCopy"},
+	},
+	{
+		Name:     "TablePlus",
+		AppPath:  "/Applications/TablePlus.app",
+		Hint:     "https://tableplus.com",
+		Commands: []string{"openSqlite"},
+	},
+	{
+		Name:     "Cursor",
+		AppPath:  "/Applications/Cursor.app",
+		Hint:     "https://cursor.com (or set FLOW_EDITOR)",
+		Commands: []string{"cloneAndOpen", "openDoc", "openLog", "openChanges", "openMetrics", "openLookingBack"},
+	},
+	{
+		Name:     "Zed",
+		AppPath:  "/Applications/Zed.app",
+		Hint:     "https://zed.dev",
+		Commands: []string{"privateForkRepoAndOpen"},
+	},
+}
+
+// editorCommands open files through openInEditor, so the tool they need
+// depends on FLOW_EDITOR rather than being fixed in flowDependencies.
+var editorCommands = []string{"open", "grepOpen", "search", "notes --open", "recentWorkspaces --open"}
+
+// editorDependency is the editor FLOW_EDITOR selects, as a dependency.
+func editorDependency() dependency {
+	editor, _ := lookupSetting(flowEditorEnv)
+	name := strings.ToLower(editor)
+	if name == "" {
+		name = "cursor"
+	}
+	// Cursor and Zed are opened as apps and already have doctor entries.
+	for _, dep := range flowDependencies {
+		if dep.AppPath != "" && strings.EqualFold(dep.Name, name) {
+			return dependency{Name: dep.Name, AppPath: dep.AppPath, Hint: dep.Hint}
+		}
+	}
+	return dependency{Name: strings.Fields(editor)[0], Hint: fmt.Sprintf("install it or change %s", flowEditorEnv)}
+}
+
+// dependenciesWithEditor is flowDependencies with editorCommands added to
+// the editor FLOW_EDITOR selects, as a new entry when it has none.
+func dependenciesWithEditor() []dependency {
+	editor := editorDependency()
+	deps := slices.Clone(flowDependencies)
+	for i := range deps {
+		if deps[i].Name == editor.Name {
+			deps[i].Commands = append(slices.Clone(deps[i].Commands), editorCommands...)
+			return deps
+		}
+	}
+	editor.Commands = editorCommands
+	return append(deps, editor)
+}
+
+func checkDependency(dep dependency) dependencyStatus {
+	status := dependencyStatus{Name: dep.Name, Hint: dep.Hint, Commands: dep.Commands}
+	if dep.AppPath != "" {
+		if _, err := os.Stat(dep.AppPath); err == nil {
+			status.Found = true
+			status.Path = dep.AppPath
+		}
+		return status
+	}
+
+	for _, name := range append([]string{dep.Name}, dep.Alternatives...) {
+		if path, err := exec.LookPath(name); err == nil {
+			status.Found = true
+			status.Path = path
+			return status
+		}
+	}
+	return status
+}
+
+func runDoctor(ctx *snap.Context) error {
+	asJSON := false
+	for i := 0; i < ctx.NArgs(); i++ {
+		arg := strings.TrimSpace(ctx.Arg(i))
+		switch arg {
+		case "":
+		case "--json":
+			asJSON = true
+		default:
+			fmt.Fprintf(ctx.Stderr(), "Usage: %s doctor [--json]\n", commandName)
+			return fmt.Errorf("unexpected argument %q", arg)
+		}
+	}
+
+	deps := dependenciesWithEditor()
+	statuses := make([]dependencyStatus, 0, len(deps))
+	missing := 0
+	for _, dep := range deps {
+		status := checkDependency(dep)
+		if !status.Found {
+			missing++
+		}
+		statuses = append(statuses, status)
+	}
+
+	if asJSON {
+		encoder := json.NewEncoder(ctx.Stdout())
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(statuses)
+	}
+
+	width := 0
+	for _, status := range statuses {
+		if len(status.Name) > width {
+			width = len(status.Name)
+		}
+	}
+
+	for _, status := range statuses {
+		if status.Found {
+			fmt.Fprintf(ctx.Stdout(), "✔️ %-*s  %s\n", width, status.Name, status.Path)
+			continue
+		}
+		fmt.Fprintf(ctx.Stdout(), "   %-*s  missing (%s)\n", width, status.Name, status.Hint)
+		fmt.Fprintf(ctx.Stdout(), "   %-*s  affects: %s\n", width, "", strings.Join(status.Commands, ", "))
+	}
+
+	fmt.Fprintln(ctx.Stdout())
+	if missing == 0 {
+		fmt.Fprintln(ctx.Stdout(), "✔️ All external tools are available")
+	} else {
+		fmt.Fprintf(ctx.Stdout(), "ℹ️ %d of %d tools missing; the commands listed above will fail until they are installed\n", missing, len(statuses))
+	}
+	return nil
+}
@@ -0,0 +1,95 @@
+package main
+
+import (
+	"fmt"
+	"net"
+	"strconv"
+	"strings"
+
+	"github.com/dzonerzy/go-snap/snap"
+)
+
+const defaultEnvPortStart = 3000
+
+func runEnvPort(ctx *snap.Context) error {
+	usage := func() {
+		fmt.Fprintf(ctx.Stderr(), "Usage: %s envPort [start-port] [--count N]\n", commandName)
+	}
+
+	start := defaultEnvPortStart
+	startSet := false
+	count := 1
+	for i := 0; i < ctx.NArgs(); i++ {
+		arg := strings.TrimSpace(ctx.Arg(i))
+		switch {
+		case arg == "":
+		case arg == "--count" || arg == "-n" || strings.HasPrefix(arg, "--count="):
+			value := strings.TrimPrefix(arg, "--count=")
+			if arg == "--count" || arg == "-n" {
+				i++
+				if i >= ctx.NArgs() {
+					usage()
+					return reportError(ctx, fmt.Errorf("%s requires a value", arg))
+				}
+				value = ctx.Arg(i)
+			}
+			n, err := strconv.Atoi(strings.TrimSpace(value))
+			if err != nil || n < 1 {
+				usage()
+				return reportError(ctx, fmt.Errorf("invalid count %q: expected a positive integer", value))
+			}
+			count = n
+		case strings.HasPrefix(arg, "-"):
+			usage()
+			return reportError(ctx, fmt.Errorf("unknown flag %q", arg))
+		case !startSet:
+			port, err := strconv.Atoi(arg)
+			if err != nil || port < 1 || port > 65535 {
+				usage()
+				return reportError(ctx, fmt.Errorf("invalid port %q: expected 1-65535", arg))
+			}
+			start, startSet = port, true
+		default:
+			usage()
+			return reportError(ctx, fmt.Errorf("unexpected argument %q", arg))
+		}
+	}
+
+	ports, err := findFreePorts(start, count)
+	if err != nil {
+		return reportError(ctx, err)
+	}
+	for _, port := range ports {
+		fmt.Fprintln(ctx.Stdout(), port)
+	}
+	return nil
+}
+
+// findFreePorts returns the first count ports at or above start that can be
+// bound right now. Nothing is reserved, so another process may still grab a
+// port before the caller uses it.
+func findFreePorts(start, count int) ([]int, error) {
+	var ports []int
+	for port := start; port <= 65535 && len(ports) < count; port++ {
+		if tcpPortFree(port) {
+			ports = append(ports, port)
+		}
+	}
+	if len(ports) < count {
+		return ports, fmt.Errorf("found only %d free port(s) between %d and 65535", len(ports), start)
+	}
+	return ports, nil
+}
+
+// tcpPortFree checks both the wildcard and loopback addresses, since dev
+// servers commonly bind either one.
+func tcpPortFree(port int) bool {
+	for _, host := range []string{"", "127.0.0.1"} {
+		listener, err := net.Listen("tcp", net.JoinHostPort(host, strconv.Itoa(port)))
+		if err != nil {
+			return false
+		}
+		listener.Close()
+	}
+	return true
+}
@@ -0,0 +1,13 @@
+package main
+
+import "lang/exitcode"
+
+// The errors fgo commands return to pick an exit code; lang/exitcode holds
+// the mapping shared with the other binaries.
+var (
+	// errUserAbort means the user closed a picker or declined a prompt.
+	// Commands print their own "cancelled" note, so it is never shown.
+	errUserAbort = exitcode.ErrUserAbort
+
+	errMissingDependency = exitcode.ErrMissingDependency
+)
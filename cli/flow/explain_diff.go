@@ -0,0 +1,116 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	"lang/gitutil"
+
+	"github.com/dzonerzy/go-snap/snap"
+)
+
+const explainDiffSystemPrompt = "You are an expert software engineer explaining a code change to a teammate. In plain English, describe what changed and the likely reason for it, grouping related edits together. Start with a one-paragraph overview, then use short bullet points for the notable changes. Mention behaviour changes, risks, and follow-ups when relevant. Do not repeat the diff verbatim and never include secrets or credentials even if they appear in it."
+
+func runExplainDiff(ctx *snap.Context) error {
+	usage := func() {
+		fmt.Fprintf(ctx.Stderr(), "Usage: %s explainDiff [--cached | --range <a>..<b>]\n", commandName)
+	}
+
+	cached := false
+	rangeSpec := ""
+	for i := 0; i < ctx.NArgs(); i++ {
+		arg := strings.TrimSpace(ctx.Arg(i))
+		if arg == "" {
+			continue
+		}
+
+		switch {
+		case arg == "--cached" || arg == "--staged":
+			cached = true
+		case arg == "--range":
+			if i+1 >= ctx.NArgs() {
+				usage()
+				return fmt.Errorf("--range requires a value")
+			}
+			i++
+			rangeSpec = strings.TrimSpace(ctx.Arg(i))
+		case strings.HasPrefix(arg, "--range="):
+			rangeSpec = strings.TrimSpace(strings.TrimPrefix(arg, "--range="))
+		default:
+			usage()
+			return fmt.Errorf("unexpected argument %q", arg)
+		}
+	}
+	if cached && rangeSpec != "" {
+		usage()
+		return fmt.Errorf("--cached and --range cannot be combined")
+	}
+	if rangeSpec != "" && !strings.Contains(rangeSpec, "..") {
+		usage()
+		return fmt.Errorf("invalid range %q; expected <a>..<b>", rangeSpec)
+	}
+
+	if err := gitutil.EnsureRepository(flowCtx); err != nil {
+		return err
+	}
+
+	apiKey, err := resolveOpenAIKey(ctx.Context())
+	if err != nil {
+		return reportError(ctx, err)
+	}
+
+	args := []string{"diff"}
+	label := "working tree changes"
+	switch {
+	case rangeSpec != "":
+		args = append(args, rangeSpec)
+		label = "changes in " + rangeSpec
+	case cached:
+		args = append(args, "--cached")
+		label = "staged changes"
+	}
+
+	diffOutput, err := flowCommand("git", args...).CombinedOutput()
+	if err != nil {
+		if msg := strings.TrimSpace(string(diffOutput)); msg != "" {
+			return reportError(ctx, fmt.Errorf("git %s: %s", strings.Join(args, " "), msg))
+		}
+		return reportError(ctx, fmt.Errorf("git %s: %w", strings.Join(args, " "), err))
+	}
+	if strings.TrimSpace(string(diffOutput)) == "" {
+		fmt.Fprintf(ctx.Stdout(), "No %s to explain.\n", label)
+		return nil
+	}
+
+	diff, truncated := truncateDiffForCommit(string(diffOutput))
+	diff, redacted := redactDiffSecrets(diff)
+	if redacted > 0 {
+		fmt.Fprintf(ctx.Stderr(), "ℹ️ Redacted %d likely secret(s) from the diff before sending it to the model\n", redacted)
+	}
+
+	var prompt strings.Builder
+	fmt.Fprintf(&prompt, "Explain these %s.\n\nGit diff:\n", label)
+	prompt.WriteString(diff)
+	if truncated {
+		prompt.WriteString("\n\n[Diff truncated to fit within prompt]")
+	}
+
+	params := newChatParams(explainDiffSystemPrompt, prompt.String())
+
+	explanation, streamed, err := runChatCompletion(ctx.Context(), apiKey, params, func(token string) {
+		fmt.Fprint(ctx.Stdout(), token)
+	})
+	if err != nil {
+		return reportError(ctx, fmt.Errorf("explain diff: %w", err))
+	}
+	explanation = strings.TrimSpace(explanation)
+	if explanation == "" {
+		return reportError(ctx, fmt.Errorf("model returned an empty explanation"))
+	}
+	if streamed {
+		fmt.Fprintln(ctx.Stdout())
+	} else {
+		fmt.Fprintln(ctx.Stdout(), explanation)
+	}
+	return nil
+}
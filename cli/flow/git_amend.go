@@ -0,0 +1,143 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	"lang/gitutil"
+
+	"github.com/dzonerzy/go-snap/snap"
+)
+
+// emptyTreeHash is git's well-known empty tree, used as the parent when the
+// commit being amended is the root commit.
+const emptyTreeHash = "4b825dc642cb6eb9a060e54bf8d69288fbee4904"
+
+const gitAmendUsageLabel = "gitAmend [--ai|--no-edit]"
+
+func runGitAmend(ctx *snap.Context) error {
+	useAI, noEdit := false, false
+	opts, err := parseCommitOptions(ctx, gitAmendUsageLabel, func(arg string) bool {
+		switch arg {
+		case "--ai":
+			useAI = true
+		case "--no-edit":
+			noEdit = true
+		default:
+			return false
+		}
+		return true
+	})
+	if err != nil {
+		return err
+	}
+	if useAI && noEdit {
+		fmt.Fprintln(ctx.Stderr(), commitUsage(gitAmendUsageLabel))
+		return reportError(ctx, fmt.Errorf("--ai and --no-edit cannot be combined"))
+	}
+
+	if err := gitutil.EnsureRepository(flowCtx); err != nil {
+		return err
+	}
+	if exists, _ := gitutil.RefExists(flowCtx, "HEAD"); !exists {
+		return reportError(ctx, fmt.Errorf("there is no commit to amend yet"))
+	}
+
+	var apiKey string
+	if useAI {
+		// Fail before touching the index when the model can't be reached.
+		if apiKey, err = resolveOpenAIKey(ctx.Context()); err != nil {
+			return reportError(ctx, err)
+		}
+	}
+
+	warnIfHeadPublished(ctx)
+
+	if err := stageForCommit(ctx, opts); err != nil {
+		return err
+	}
+
+	args := []string{"commit", "--amend"}
+	if opts.sign {
+		args = append(args, "-S")
+	}
+
+	var payload *commitPayload
+	switch {
+	case useAI:
+		// Describe the commit as it will look after amending: its parent
+		// against everything now staged.
+		parent := "HEAD~1"
+		if exists, _ := gitutil.RefExists(flowCtx, parent); !exists {
+			parent = emptyTreeHash
+		}
+		diffOutput, err := flowCommand("git", "diff", "--cached", parent).CombinedOutput()
+		if err != nil {
+			return reportError(ctx, fmt.Errorf("git diff --cached %s: %w", parent, err))
+		}
+		diff := string(diffOutput)
+		if strings.TrimSpace(diff) == "" {
+			return reportError(ctx, fmt.Errorf("the amended commit would be empty"))
+		}
+
+		if payload, err = proposeCommitMessage(ctx, apiKey, diff); err != nil {
+			return err
+		}
+		if opts.ticketPrefix {
+			if err := applyTicketPrefix(ctx, payload); err != nil {
+				return err
+			}
+		}
+		printProposedMessage(ctx, payload)
+		for _, paragraph := range withCoAuthorTrailers(payload.paragraphs, opts.coAuthors) {
+			args = append(args, "-m", paragraph)
+		}
+	default:
+		if noEdit {
+			args = append(args, "--no-edit")
+		}
+		for _, coAuthor := range opts.coAuthors {
+			args = append(args, "--trailer", "Co-authored-by: "+coAuthor)
+		}
+	}
+
+	if err := runGitCommandStreaming(ctx, args...); err != nil {
+		return reportError(ctx, fmt.Errorf("git commit --amend: %w", err))
+	}
+
+	fmt.Fprintf(ctx.Stdout(), "✔️ Amended %s: %s\n", shortHash(gitHeadHash()), gitCommitSubject("HEAD"))
+	return nil
+}
+
+// warnIfHeadPublished notes when HEAD is already part of the upstream branch,
+// since amending it means the next push has to be forced.
+func warnIfHeadPublished(ctx *snap.Context) {
+	if upstream := publishedUpstream("HEAD"); upstream != "" {
+		fmt.Fprintf(ctx.Stderr(), "ℹ️ The last commit is already pushed to %s; amending it means force-pushing (see %s pushForce)\n", upstream, commandName)
+	}
+}
+
+// publishedUpstream returns the current branch's upstream when rev is
+// already part of it, and "" when rev is unpublished or there is no upstream.
+func publishedUpstream(rev string) string {
+	out, err := flowCommand("git", "rev-parse", "--abbrev-ref", "--symbolic-full-name", "@{upstream}").Output()
+	if err != nil {
+		return ""
+	}
+	upstream := strings.TrimSpace(string(out))
+	if upstream == "" {
+		return ""
+	}
+	if err := flowCommand("git", "merge-base", "--is-ancestor", rev, upstream).Run(); err != nil {
+		return ""
+	}
+	return upstream
+}
+
+func gitHeadHash() string {
+	out, err := flowCommand("git", "rev-parse", "HEAD").Output()
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSpace(string(out))
+}
@@ -0,0 +1,185 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"lang/gitutil"
+
+	"github.com/dzonerzy/go-snap/snap"
+)
+
+// gitAuthor is one line of git shortlog -sne: a contributor and how many
+// commits they made in the range.
+type gitAuthor struct {
+	Name    string
+	Email   string
+	Commits int
+}
+
+func runGitAuthors(ctx *snap.Context) error {
+	usage := func() {
+		fmt.Fprintf(ctx.Stderr(), "Usage: %s gitAuthors [--since <date>] [--until <date>] [--merge-emails] [range]\n", commandName)
+	}
+
+	var since, until, revRange string
+	mergeEmails := false
+	for i := 0; i < ctx.NArgs(); i++ {
+		arg := strings.TrimSpace(ctx.Arg(i))
+		switch {
+		case arg == "":
+		case arg == "--merge-emails":
+			mergeEmails = true
+		case arg == "--since" || arg == "--until":
+			if i+1 >= ctx.NArgs() || strings.TrimSpace(ctx.Arg(i+1)) == "" {
+				usage()
+				return fmt.Errorf("%s requires a date", arg)
+			}
+			i++
+			if arg == "--since" {
+				since = strings.TrimSpace(ctx.Arg(i))
+			} else {
+				until = strings.TrimSpace(ctx.Arg(i))
+			}
+		case strings.HasPrefix(arg, "--since="):
+			since = strings.TrimSpace(strings.TrimPrefix(arg, "--since="))
+		case strings.HasPrefix(arg, "--until="):
+			until = strings.TrimSpace(strings.TrimPrefix(arg, "--until="))
+		case strings.HasPrefix(arg, "-") || revRange != "":
+			usage()
+			return fmt.Errorf("unexpected argument %q", arg)
+		default:
+			revRange = arg
+		}
+	}
+
+	if err := gitutil.EnsureRepository(flowCtx); err != nil {
+		return err
+	}
+
+	// shortlog applies .mailmap itself. Without a revision it would read a
+	// log from stdin, so every ref is the default.
+	args := []string{"shortlog", "-sne"}
+	if since != "" {
+		args = append(args, "--since="+since)
+	}
+	if until != "" {
+		args = append(args, "--until="+until)
+	}
+	if revRange == "" {
+		args = append(args, "--all")
+	} else {
+		args = append(args, revRange, "--")
+	}
+	out, err := flowCommand("git", args...).Output()
+	if err != nil {
+		return reportError(ctx, fmt.Errorf("git shortlog: %w", err))
+	}
+
+	authors, err := parseShortlog(string(out))
+	if err != nil {
+		return reportError(ctx, err)
+	}
+	if mergeEmails {
+		authors = mergeAuthorsByEmail(authors)
+	}
+	if len(authors) == 0 {
+		fmt.Fprintln(ctx.Stdout(), "No commits in that range.")
+		return nil
+	}
+
+	total := 0
+	nameWidth := len("Author")
+	for _, author := range authors {
+		total += author.Commits
+		nameWidth = max(nameWidth, len([]rune(author.Name)))
+	}
+	rankWidth := len(strconv.Itoa(len(authors)))
+	countWidth := max(len("Commits"), len(strconv.Itoa(total)))
+
+	fmt.Fprintf(ctx.Stdout(), "%*s  %*s  %6s  %-*s  %s\n", rankWidth, "#", countWidth, "Commits", "Share", nameWidth, "Author", "Email")
+	for i, author := range authors {
+		share := 100 * float64(author.Commits) / float64(total)
+		fmt.Fprintf(ctx.Stdout(), "%*d  %*d  %5.1f%%  %-*s  %s\n", rankWidth, i+1, countWidth, author.Commits, share, nameWidth, author.Name, author.Email)
+	}
+	noun := "authors"
+	if len(authors) == 1 {
+		noun = "author"
+	}
+	fmt.Fprintf(ctx.Stdout(), "\n%d commits by %d %s\n", total, len(authors), noun)
+	return nil
+}
+
+// parseShortlog reads git shortlog -sne output ("   12\tName <email>"),
+// ranked by commit count, ties broken by name.
+func parseShortlog(out string) ([]gitAuthor, error) {
+	var authors []gitAuthor
+	for _, line := range strings.Split(out, "\n") {
+		if strings.TrimSpace(line) == "" {
+			continue
+		}
+		count, who, ok := strings.Cut(strings.TrimLeft(line, " "), "\t")
+		if !ok {
+			return nil, fmt.Errorf("unexpected shortlog line %q", line)
+		}
+		commits, err := strconv.Atoi(strings.TrimSpace(count))
+		if err != nil {
+			return nil, fmt.Errorf("unexpected shortlog line %q", line)
+		}
+		author := gitAuthor{Name: strings.TrimSpace(who), Commits: commits}
+		if open := strings.LastIndex(who, " <"); open >= 0 && strings.HasSuffix(who, ">") {
+			author.Name = strings.TrimSpace(who[:open])
+			author.Email = who[open+2 : len(who)-1]
+		}
+		authors = append(authors, author)
+	}
+	sortAuthors(authors)
+	return authors, nil
+}
+
+// mergeAuthorsByEmail folds entries that share an email, compared without
+// case, into one under the name with the most commits. It catches what a
+// missing .mailmap leaves split, such as a renamed author.
+func mergeAuthorsByEmail(authors []gitAuthor) []gitAuthor {
+	type merged struct {
+		author   gitAuthor
+		topCount int
+	}
+	byEmail := map[string]*merged{}
+	var order []string
+	var result []gitAuthor
+	for _, author := range authors {
+		if author.Email == "" {
+			result = append(result, author)
+			continue
+		}
+		key := strings.ToLower(author.Email)
+		entry, ok := byEmail[key]
+		if !ok {
+			byEmail[key] = &merged{author: author, topCount: author.Commits}
+			order = append(order, key)
+			continue
+		}
+		entry.author.Commits += author.Commits
+		if author.Commits > entry.topCount {
+			entry.author.Name = author.Name
+			entry.topCount = author.Commits
+		}
+	}
+	for _, key := range order {
+		result = append(result, byEmail[key].author)
+	}
+	sortAuthors(result)
+	return result
+}
+
+func sortAuthors(authors []gitAuthor) {
+	sort.SliceStable(authors, func(i, j int) bool {
+		if authors[i].Commits != authors[j].Commits {
+			return authors[i].Commits > authors[j].Commits
+		}
+		return authors[i].Name < authors[j].Name
+	})
+}
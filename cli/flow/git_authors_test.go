@@ -0,0 +1,54 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseShortlog(t *testing.T) {
+	out := "    12\tAda Lovelace <ada@example.com>\n" +
+		"     3\tbot\n" +
+		"    12\tAlan Turing <alan@example.com>\n" +
+		"   140\tGrace Hopper <grace@navy.mil>\n"
+	got, err := parseShortlog(out)
+	if err != nil {
+		t.Fatalf("parseShortlog: %v", err)
+	}
+	want := []gitAuthor{
+		{Name: "Grace Hopper", Email: "grace@navy.mil", Commits: 140},
+		{Name: "Ada Lovelace", Email: "ada@example.com", Commits: 12},
+		{Name: "Alan Turing", Email: "alan@example.com", Commits: 12},
+		{Name: "bot", Commits: 3},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("parseShortlog = %+v, want %+v", got, want)
+	}
+
+	if got, err := parseShortlog(""); err != nil || len(got) != 0 {
+		t.Errorf("parseShortlog(\"\") = %+v, %v; want nothing", got, err)
+	}
+	for _, bad := range []string{"Ada <ada@example.com>", "  x\tAda <ada@example.com>"} {
+		if _, err := parseShortlog(bad); err == nil {
+			t.Errorf("parseShortlog(%q) should fail", bad)
+		}
+	}
+}
+
+func TestMergeAuthorsByEmail(t *testing.T) {
+	authors := []gitAuthor{
+		{Name: "Ada Lovelace", Email: "ada@example.com", Commits: 12},
+		{Name: "Alan Turing", Email: "alan@example.com", Commits: 10},
+		{Name: "ada", Email: "Ada@Example.com", Commits: 4},
+		{Name: "bot", Commits: 3},
+		{Name: "A. Turing", Email: "alan@example.com", Commits: 30},
+	}
+	got := mergeAuthorsByEmail(authors)
+	want := []gitAuthor{
+		{Name: "A. Turing", Email: "alan@example.com", Commits: 40},
+		{Name: "Ada Lovelace", Email: "ada@example.com", Commits: 16},
+		{Name: "bot", Commits: 3},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("mergeAuthorsByEmail = %+v, want %+v", got, want)
+	}
+}
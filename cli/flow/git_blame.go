@@ -0,0 +1,313 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"lang/gitutil"
+
+	"github.com/dzonerzy/go-snap/snap"
+	"github.com/ktr0731/go-fuzzyfinder"
+)
+
+type blameCommit struct {
+	Hash       string
+	Author     string
+	AuthorMail string
+	AuthorTime time.Time
+	Summary    string
+}
+
+type blameLine struct {
+	Commit *blameCommit
+	Line   int
+}
+
+type blameAuthorSummary struct {
+	Author string
+	Mail   string
+	Lines  int
+}
+
+func runGitBlameRange(ctx *snap.Context) error {
+	usage := func() {
+		fmt.Fprintf(ctx.Stderr(), "Usage: %s gitBlameRange [path] [<start>[,<end>]]\n", commandName)
+	}
+
+	var path, lineRange string
+	for i := 0; i < ctx.NArgs(); i++ {
+		arg := strings.TrimSpace(ctx.Arg(i))
+		switch {
+		case arg == "":
+		case arg == "-L":
+			i++
+			if i >= ctx.NArgs() {
+				usage()
+				return fmt.Errorf("-L requires a value")
+			}
+			lineRange = strings.TrimSpace(ctx.Arg(i))
+		case strings.HasPrefix(arg, "-L"):
+			lineRange = strings.TrimPrefix(arg, "-L")
+		case path == "" && !looksLikeLineRange(arg):
+			path = arg
+		case lineRange == "":
+			lineRange = arg
+		default:
+			usage()
+			return fmt.Errorf("unexpected argument %q", arg)
+		}
+	}
+
+	if err := gitutil.EnsureRepository(flowCtx); err != nil {
+		return err
+	}
+
+	if path == "" {
+		selected, err := pickTrackedFile()
+		if err != nil {
+			if errors.Is(err, fuzzyfinder.ErrAbort) {
+				return errUserAbort
+			}
+			return reportError(ctx, err)
+		}
+		path = selected
+	}
+
+	if lineRange == "" {
+		input, err := promptLine(ctx, fmt.Sprintf("Line range for %s (start,end): ", path))
+		if err != nil {
+			return reportError(ctx, fmt.Errorf("read line range: %w", err))
+		}
+		lineRange = input
+	}
+
+	start, end, err := parseLineRange(lineRange)
+	if err != nil {
+		usage()
+		return err
+	}
+
+	args := []string{"blame", "--porcelain", "-L", fmt.Sprintf("%d,%d", start, end), "--", path}
+	out, err := flowCommand("git", args...).CombinedOutput()
+	if err != nil {
+		if msg := strings.TrimSpace(string(out)); msg != "" {
+			return reportError(ctx, fmt.Errorf("git blame: %s", msg))
+		}
+		return reportError(ctx, fmt.Errorf("git blame: %w", err))
+	}
+
+	lines, err := parseBlamePorcelain(string(out))
+	if err != nil {
+		return reportError(ctx, err)
+	}
+	if len(lines) == 0 {
+		fmt.Fprintln(ctx.Stdout(), "No lines in range.")
+		return nil
+	}
+
+	authors := summarizeBlameAuthors(lines)
+	fmt.Fprintf(ctx.Stdout(), "%s:%d-%d (%d lines)\n\n", path, start, start+len(lines)-1, len(lines))
+
+	width := 0
+	for _, author := range authors {
+		if n := len([]rune(author.Author)); n > width {
+			width = n
+		}
+	}
+	for _, author := range authors {
+		percent := float64(author.Lines) * 100 / float64(len(lines))
+		fmt.Fprintf(ctx.Stdout(), "  %-*s  %4d lines  %3.0f%%  %s\n", width, author.Author, author.Lines, percent, author.Mail)
+	}
+
+	latest := lines[0].Commit
+	for _, line := range lines[1:] {
+		if line.Commit.AuthorTime.After(latest.AuthorTime) {
+			latest = line.Commit
+		}
+	}
+	fmt.Fprintf(ctx.Stdout(), "\nMost recent: %s %s %s  %s\n",
+		shortHash(latest.Hash), latest.AuthorTime.Format("2006-01-02"), latest.Author, latest.Summary)
+	return nil
+}
+
+func looksLikeLineRange(arg string) bool {
+	if arg == "" {
+		return false
+	}
+	for _, r := range arg {
+		if (r < '0' || r > '9') && r != ',' && r != '+' && r != '-' {
+			return false
+		}
+	}
+	return true
+}
+
+// parseLineRange accepts "start", "start,end", "start-end", or "start,+count".
+func parseLineRange(raw string) (int, int, error) {
+	raw = strings.TrimSpace(raw)
+	if raw == "" {
+		return 0, 0, fmt.Errorf("line range cannot be empty")
+	}
+
+	startText, endText, hasEnd := strings.Cut(raw, ",")
+	if !hasEnd {
+		startText, endText, hasEnd = strings.Cut(raw, "-")
+	}
+
+	start, err := strconv.Atoi(strings.TrimSpace(startText))
+	if err != nil || start < 1 {
+		return 0, 0, fmt.Errorf("invalid start line %q", startText)
+	}
+	if !hasEnd {
+		return start, start, nil
+	}
+
+	endText = strings.TrimSpace(endText)
+	if count, ok := strings.CutPrefix(endText, "+"); ok {
+		n, err := strconv.Atoi(count)
+		if err != nil || n < 1 {
+			return 0, 0, fmt.Errorf("invalid line count %q", endText)
+		}
+		return start, start + n - 1, nil
+	}
+
+	end, err := strconv.Atoi(endText)
+	if err != nil || end < start {
+		return 0, 0, fmt.Errorf("invalid end line %q", endText)
+	}
+	return start, end, nil
+}
+
+// parseBlamePorcelain parses `git blame --porcelain`. Commit metadata is only
+// printed the first time a commit appears, so commits are shared by hash.
+func parseBlamePorcelain(raw string) ([]blameLine, error) {
+	commits := make(map[string]*blameCommit)
+	var (
+		lines   []blameLine
+		current *blameCommit
+		lineNo  int
+	)
+
+	for _, line := range strings.Split(raw, "\n") {
+		if line == "" {
+			continue
+		}
+		if strings.HasPrefix(line, "\t") {
+			if current == nil {
+				return nil, fmt.Errorf("malformed blame output: content before header")
+			}
+			lines = append(lines, blameLine{Commit: current, Line: lineNo})
+			continue
+		}
+
+		if current == nil || isBlameHeader(line) {
+			fields := strings.Fields(line)
+			if len(fields) < 3 {
+				return nil, fmt.Errorf("malformed blame header %q", line)
+			}
+			hash := fields[0]
+			n, err := strconv.Atoi(fields[2])
+			if err != nil {
+				return nil, fmt.Errorf("malformed blame header %q", line)
+			}
+			lineNo = n
+			commit, ok := commits[hash]
+			if !ok {
+				commit = &blameCommit{Hash: hash}
+				commits[hash] = commit
+			}
+			current = commit
+			continue
+		}
+
+		key, value, _ := strings.Cut(line, " ")
+		switch key {
+		case "author":
+			current.Author = value
+		case "author-mail":
+			current.AuthorMail = strings.Trim(value, "<>")
+		case "author-time":
+			if ts, err := strconv.ParseInt(value, 10, 64); err == nil {
+				current.AuthorTime = time.Unix(ts, 0)
+			}
+		case "summary":
+			current.Summary = value
+		}
+	}
+
+	return lines, nil
+}
+
+func isBlameHeader(line string) bool {
+	hash, _, ok := strings.Cut(line, " ")
+	if !ok || (len(hash) != 40 && len(hash) != 64) {
+		return false
+	}
+	for _, r := range hash {
+		if (r < '0' || r > '9') && (r < 'a' || r > 'f') {
+			return false
+		}
+	}
+	return true
+}
+
+func summarizeBlameAuthors(lines []blameLine) []blameAuthorSummary {
+	byAuthor := make(map[string]*blameAuthorSummary)
+	for _, line := range lines {
+		key := line.Commit.Author + "\x00" + line.Commit.AuthorMail
+		summary, ok := byAuthor[key]
+		if !ok {
+			summary = &blameAuthorSummary{Author: line.Commit.Author, Mail: line.Commit.AuthorMail}
+			byAuthor[key] = summary
+		}
+		summary.Lines++
+	}
+
+	authors := make([]blameAuthorSummary, 0, len(byAuthor))
+	for _, summary := range byAuthor {
+		authors = append(authors, *summary)
+	}
+	sort.Slice(authors, func(i, j int) bool {
+		if authors[i].Lines != authors[j].Lines {
+			return authors[i].Lines > authors[j].Lines
+		}
+		return authors[i].Author < authors[j].Author
+	})
+	return authors
+}
+
+func pickTrackedFile() (string, error) {
+	out, err := flowCommand("git", "ls-files").Output()
+	if err != nil {
+		return "", fmt.Errorf("git ls-files: %w", err)
+	}
+
+	var files []string
+	for _, line := range strings.Split(string(out), "\n") {
+		if line = strings.TrimSpace(line); line != "" {
+			files = append(files, line)
+		}
+	}
+	if len(files) == 0 {
+		return "", fmt.Errorf("no tracked files found")
+	}
+
+	idx, err := fuzzyfinder.Find(
+		files,
+		func(i int) string {
+			return files[i]
+		},
+		fuzzyfinder.WithPromptString("gitBlameRange> "),
+	)
+	if err != nil {
+		if errors.Is(err, fuzzyfinder.ErrAbort) {
+			return "", err
+		}
+		return "", fmt.Errorf("select file: %w", err)
+	}
+	return files[idx], nil
+}
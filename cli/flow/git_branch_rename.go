@@ -0,0 +1,127 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	"lang/gitutil"
+
+	"github.com/dzonerzy/go-snap/snap"
+)
+
+func runBranchRename(ctx *snap.Context) error {
+	usage := func() {
+		fmt.Fprintf(ctx.Stderr(), "Usage: %s branchRename [new-name] [--yes]\n", commandName)
+	}
+
+	var newName string
+	assumeYes := false
+	for i := 0; i < ctx.NArgs(); i++ {
+		arg := strings.TrimSpace(ctx.Arg(i))
+		switch {
+		case arg == "":
+		case arg == "--yes" || arg == "-y":
+			assumeYes = true
+		case newName == "" && !strings.HasPrefix(arg, "-"):
+			newName = arg
+		default:
+			usage()
+			return fmt.Errorf("unexpected argument %q", arg)
+		}
+	}
+
+	if err := gitutil.EnsureRepository(flowCtx); err != nil {
+		return err
+	}
+
+	oldName, err := gitutil.CurrentBranch(flowCtx)
+	if err != nil {
+		return reportError(ctx, err)
+	}
+	if oldName == "HEAD" {
+		return reportError(ctx, fmt.Errorf("HEAD is detached; check out the branch you want to rename first"))
+	}
+
+	if newName == "" {
+		input, err := promptLine(ctx, fmt.Sprintf("Rename %s to: ", oldName))
+		if err != nil {
+			return reportError(ctx, fmt.Errorf("read branch name: %w", err))
+		}
+		if newName = strings.TrimSpace(input); newName == "" {
+			fmt.Fprintln(ctx.Stdout(), "Rename cancelled.")
+			return errUserAbort
+		}
+	}
+	if newName == oldName {
+		return reportError(ctx, fmt.Errorf("branch is already named %s", oldName))
+	}
+
+	if out, err := flowCommand("git", "check-ref-format", "--branch", newName).CombinedOutput(); err != nil {
+		if msg := strings.TrimSpace(string(out)); msg != "" {
+			return reportError(ctx, fmt.Errorf("invalid branch name %q: %s", newName, msg))
+		}
+		return reportError(ctx, fmt.Errorf("invalid branch name %q", newName))
+	}
+	exists, err := gitutil.RefExists(flowCtx, "refs/heads/"+newName)
+	if err != nil {
+		return reportError(ctx, fmt.Errorf("check local branch %s: %w", newName, err))
+	}
+	if exists {
+		return reportError(ctx, fmt.Errorf("branch %s already exists", newName))
+	}
+
+	// git branch -m carries the tracking config over to the new name, so read
+	// it first to know which remote branch still has the old name.
+	remote, remoteBranch := branchUpstream(oldName)
+
+	if err := runGitCommandStreaming(ctx, "branch", "-m", newName); err != nil {
+		return reportError(ctx, fmt.Errorf("git branch -m %s: %w", newName, err))
+	}
+	fmt.Fprintf(ctx.Stdout(), "✔️ Renamed %s to %s\n", oldName, newName)
+
+	if remote == "" {
+		return nil
+	}
+
+	fmt.Fprintf(ctx.Stdout(), "ℹ️ %s tracked %s/%s\n", oldName, remote, remoteBranch)
+	if !assumeYes {
+		ok, err := confirm(ctx, fmt.Sprintf("Push %s to %s and delete %s/%s?", newName, remote, remote, remoteBranch), false)
+		if err != nil {
+			return reportError(ctx, err)
+		}
+		if !ok {
+			fmt.Fprintf(ctx.Stdout(), "Remote left untouched; %s still tracks %s/%s.\n", newName, remote, remoteBranch)
+			return nil
+		}
+	}
+
+	if err := runGitCommandStreaming(ctx, "push", remote, ":"+remoteBranch, newName+":"+newName); err != nil {
+		return reportError(ctx, fmt.Errorf("git push %s: %w", remote, err))
+	}
+	if err := runGitCommandStreaming(ctx, "branch", "--set-upstream-to="+remote+"/"+newName, newName); err != nil {
+		return reportError(ctx, fmt.Errorf("set upstream to %s/%s: %w", remote, newName, err))
+	}
+
+	fmt.Fprintf(ctx.Stdout(), "✔️ %s now tracks %s/%s; %s/%s was deleted\n", newName, remote, newName, remote, remoteBranch)
+	return nil
+}
+
+// branchUpstream returns the remote and remote branch name a local branch
+// tracks, or empty strings when it tracks nothing or only a local branch.
+func branchUpstream(branch string) (string, string) {
+	remoteOut, err := flowCommand("git", "config", "--get", "branch."+branch+".remote").Output()
+	if err != nil {
+		return "", ""
+	}
+	mergeOut, err := flowCommand("git", "config", "--get", "branch."+branch+".merge").Output()
+	if err != nil {
+		return "", ""
+	}
+
+	remote := strings.TrimSpace(string(remoteOut))
+	merge := strings.TrimPrefix(strings.TrimSpace(string(mergeOut)), "refs/heads/")
+	if remote == "" || remote == "." || merge == "" {
+		return "", ""
+	}
+	return remote, merge
+}
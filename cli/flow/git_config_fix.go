@@ -0,0 +1,132 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"lang/gitutil"
+
+	"github.com/dzonerzy/go-snap/snap"
+)
+
+// defaultGitConfig is applied when ~/.flow/gitconfig.toml is missing or
+// empty.
+var defaultGitConfig = map[string]string{
+	"fetch.prune":          "true",
+	"init.defaultBranch":   "main",
+	"pull.rebase":          "true",
+	"push.autoSetupRemote": "true",
+}
+
+func gitConfigFixPath() (string, error) {
+	homeDir, err := os.UserHomeDir()
+	if err != nil {
+		return "", fmt.Errorf("determine home directory: %w", err)
+	}
+	return filepath.Join(homeDir, ".flow", "gitconfig.toml"), nil
+}
+
+// loadGitConfigSet reads the desired settings as flat `key = value` pairs,
+// e.g. `pull.rebase = true`. A file with entries replaces the defaults.
+func loadGitConfigSet() (map[string]string, error) {
+	path, err := gitConfigFixPath()
+	if err != nil {
+		return nil, err
+	}
+	values, err := loadFlatTOML(path)
+	if err != nil {
+		return nil, err
+	}
+	if len(values) == 0 {
+		return defaultGitConfig, nil
+	}
+	return values, nil
+}
+
+func runGitConfigFix(ctx *snap.Context) error {
+	force := false
+	for i := 0; i < ctx.NArgs(); i++ {
+		arg := strings.TrimSpace(ctx.Arg(i))
+		switch arg {
+		case "":
+		case "--force", "-f":
+			force = true
+		default:
+			fmt.Fprintf(ctx.Stderr(), "Usage: %s gitConfigFix [--force]\n", commandName)
+			return fmt.Errorf("unexpected argument %q", arg)
+		}
+	}
+
+	if err := gitutil.EnsureRepository(flowCtx); err != nil {
+		return err
+	}
+
+	desired, err := loadGitConfigSet()
+	if err != nil {
+		return reportError(ctx, err)
+	}
+	keys := make([]string, 0, len(desired))
+	for key := range desired {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	changed := 0
+	for _, key := range keys {
+		want := desired[key]
+		current, set, err := localGitConfig(key)
+		if err != nil {
+			return reportError(ctx, err)
+		}
+		if set && current == want {
+			fmt.Fprintf(ctx.Stdout(), "  %s = %s (unchanged)\n", key, want)
+			continue
+		}
+		if set && !force {
+			overwrite, err := confirm(ctx, fmt.Sprintf("Overwrite %s = %s with %s?", key, current, want), false)
+			if err != nil {
+				return reportError(ctx, err)
+			}
+			if !overwrite {
+				fmt.Fprintf(ctx.Stdout(), "  %s = %s (kept)\n", key, current)
+				continue
+			}
+		}
+
+		if err := flowCommand("git", "config", "--local", key, want).Run(); err != nil {
+			return reportError(ctx, fmt.Errorf("git config %s: %w", key, err))
+		}
+		if set {
+			fmt.Fprintf(ctx.Stdout(), "✔️ %s = %s (was %s)\n", key, want, current)
+		} else {
+			fmt.Fprintf(ctx.Stdout(), "✔️ %s = %s\n", key, want)
+		}
+		changed++
+	}
+
+	if changed == 0 {
+		fmt.Fprintln(ctx.Stdout(), "ℹ️ Git config already up to date")
+	} else {
+		fmt.Fprintf(ctx.Stdout(), "✔️ Updated %d git config value(s)\n", changed)
+	}
+	return nil
+}
+
+// localGitConfig reads key from the repository's own config. git exits 1
+// when the key is unset, which is not an error here.
+func localGitConfig(key string) (string, bool, error) {
+	out, err := flowCommand("git", "config", "--local", "--get", key).Output()
+	if err != nil {
+		var exitErr *exec.ExitError
+		if errors.As(err, &exitErr) && exitErr.ExitCode() == 1 {
+			return "", false, nil
+		}
+		return "", false, fmt.Errorf("git config --get %s: %w", key, err)
+	}
+	return strings.TrimSpace(string(out)), true, nil
+}
@@ -0,0 +1,103 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	"lang/gitutil"
+
+	"github.com/dzonerzy/go-snap/snap"
+)
+
+func runDiffStat(ctx *snap.Context) error {
+	usage := func() {
+		fmt.Fprintf(ctx.Stderr(), "Usage: %s diffStat [--base <ref>]\n", commandName)
+	}
+
+	base := ""
+	for i := 0; i < ctx.NArgs(); i++ {
+		arg := strings.TrimSpace(ctx.Arg(i))
+		switch {
+		case arg == "":
+		case arg == "--base":
+			if i+1 >= ctx.NArgs() || strings.TrimSpace(ctx.Arg(i+1)) == "" {
+				usage()
+				return fmt.Errorf("--base requires a ref")
+			}
+			i++
+			base = strings.TrimSpace(ctx.Arg(i))
+		case strings.HasPrefix(arg, "--base="):
+			base = strings.TrimSpace(strings.TrimPrefix(arg, "--base="))
+		default:
+			usage()
+			return fmt.Errorf("unexpected argument %q", arg)
+		}
+	}
+
+	if err := gitutil.EnsureRepository(flowCtx); err != nil {
+		return err
+	}
+
+	branch, err := gitutil.CurrentBranch(flowCtx)
+	if err != nil {
+		return reportError(ctx, err)
+	}
+
+	if base == "" {
+		if base, err = diffStatBase(branch); err != nil {
+			return reportError(ctx, err)
+		}
+	} else if exists, err := gitutil.RefExists(flowCtx, base); err != nil {
+		return reportError(ctx, err)
+	} else if !exists {
+		return reportError(ctx, fmt.Errorf("base ref %s not found", base))
+	}
+
+	out, err := flowCommand("git", "merge-base", "HEAD", base).Output()
+	if err != nil {
+		return reportError(ctx, fmt.Errorf("no common ancestor between HEAD and %s: %w", base, err))
+	}
+	mergeBase := strings.TrimSpace(string(out))
+
+	stat, err := flowCommand("git", "diff", "--stat", mergeBase+"..HEAD").Output()
+	if err != nil {
+		return reportError(ctx, fmt.Errorf("git diff --stat: %w", err))
+	}
+
+	fmt.Fprintf(ctx.Stdout(), "%s vs %s (merge-base %s)\n", branch, base, shortHash(mergeBase))
+	if strings.TrimSpace(string(stat)) == "" {
+		fmt.Fprintf(ctx.Stdout(), "ℹ️ No changes since %s\n", base)
+		return nil
+	}
+	fmt.Fprint(ctx.Stdout(), string(stat))
+	return nil
+}
+
+// diffStatBase picks the ref to compare against when --base is absent.
+// gitutil.DefaultBranch answers with the current branch when there is one, so
+// when that's the branch we're on, fall back to the remote's default and
+// then to main/master. On main itself this ends at origin/main, which shows
+// what hasn't been pushed yet.
+func diffStatBase(branch string) (string, error) {
+	if base := gitutil.DefaultBranch(flowCtx); base != branch {
+		return base, nil
+	}
+
+	candidates := []string{"main", "master"}
+	if out, err := flowCommand("git", "symbolic-ref", "--short", "refs/remotes/origin/HEAD").Output(); err == nil {
+		if ref := strings.TrimSpace(string(out)); ref != "" {
+			candidates = append([]string{ref}, candidates...)
+		}
+	}
+	candidates = append(candidates, "origin/main", "origin/master")
+
+	for _, ref := range candidates {
+		if ref == branch {
+			continue
+		}
+		if exists, _ := gitutil.RefExists(flowCtx, ref); exists {
+			return ref, nil
+		}
+	}
+	return "", fmt.Errorf("could not determine a base branch for %s; pass --base <ref>", branch)
+}
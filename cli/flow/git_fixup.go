@@ -0,0 +1,183 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+
+	"lang/gitutil"
+
+	"github.com/dzonerzy/go-snap/snap"
+	"github.com/ktr0731/go-fuzzyfinder"
+)
+
+const defaultGitFixupLimit = 50
+
+func runGitFixup(ctx *snap.Context) error {
+	usage := func() {
+		fmt.Fprintf(ctx.Stderr(), "Usage: %s gitFixup [--autosquash] [--all|--patch|--staged-only] [--sign] [-n <limit>] [commit]\n", commandName)
+	}
+
+	opts := commitOptions{stage: defaultCommitStageMode()}
+	autosquash := false
+	limit := defaultGitFixupLimit
+	target := ""
+	for i := 0; i < ctx.NArgs(); i++ {
+		arg := strings.TrimSpace(ctx.Arg(i))
+		switch {
+		case arg == "":
+		case arg == "--autosquash":
+			autosquash = true
+		case arg == "--all" || arg == "-a":
+			opts.stage = commitStageAll
+		case arg == "--patch" || arg == "-p":
+			opts.stage = commitStagePatch
+		case arg == "--staged-only" || arg == "--staged":
+			opts.stage = commitStageStagedOnly
+		case arg == "--sign" || arg == "-S":
+			opts.sign = true
+		case arg == "-n" || strings.HasPrefix(arg, "-n="):
+			value, ok := strings.CutPrefix(arg, "-n=")
+			if !ok {
+				if i+1 >= ctx.NArgs() {
+					usage()
+					return fmt.Errorf("-n requires a value")
+				}
+				i++
+				value = ctx.Arg(i)
+			}
+			n, err := strconv.Atoi(strings.TrimSpace(value))
+			if err != nil || n <= 0 {
+				usage()
+				return fmt.Errorf("-n expects a positive number, got %q", value)
+			}
+			limit = n
+		case strings.HasPrefix(arg, "-") || target != "":
+			usage()
+			return fmt.Errorf("unexpected argument %q", arg)
+		default:
+			target = arg
+		}
+	}
+
+	if err := gitutil.EnsureRepository(flowCtx); err != nil {
+		return err
+	}
+	if exists, _ := gitutil.RefExists(flowCtx, "HEAD"); !exists {
+		return reportError(ctx, fmt.Errorf("there are no commits to fix up yet"))
+	}
+
+	sha, err := pickFixupTarget(target, limit)
+	if err != nil {
+		if errors.Is(err, fuzzyfinder.ErrAbort) {
+			return errUserAbort
+		}
+		return reportError(ctx, err)
+	}
+	if err := flowCommand("git", "merge-base", "--is-ancestor", sha, "HEAD").Run(); err != nil {
+		return reportError(ctx, fmt.Errorf("%s is not on the current branch; a fixup can only target a commit in HEAD's history", shortHash(sha)))
+	}
+	subject := gitCommitSubject(sha)
+	if autosquash {
+		out, err := flowCommand("git", "rev-list", "--merges", sha+"..HEAD").Output()
+		if err != nil {
+			return reportError(ctx, fmt.Errorf("git rev-list --merges: %w", err))
+		}
+		if strings.TrimSpace(string(out)) != "" {
+			return reportError(ctx, fmt.Errorf("there are merge commits after %s; autosquash would flatten them, so fold the fixup in by hand", shortHash(sha)))
+		}
+	}
+	if upstream := publishedUpstream(sha); upstream != "" {
+		fmt.Fprintf(ctx.Stderr(), "ℹ️ %s is already pushed to %s; folding a fixup into it means force-pushing (see %s pushForce)\n", shortHash(sha), upstream, commandName)
+	}
+
+	if err := stageForCommit(ctx, opts); err != nil {
+		return err
+	}
+	// git diff --quiet exits 1 when something is staged.
+	if err := flowCommand("git", "diff", "--cached", "--quiet").Run(); err == nil {
+		return reportError(ctx, fmt.Errorf("nothing staged for the fixup; change some files or stage them with git add"))
+	}
+
+	args := []string{"commit", "--fixup=" + sha}
+	if opts.sign {
+		args = append(args, "-S")
+	}
+	if err := runGitCommandStreaming(ctx, args...); err != nil {
+		return reportError(ctx, fmt.Errorf("git commit --fixup: %w", err))
+	}
+	fmt.Fprintf(ctx.Stdout(), "✔️ Created fixup for %s %s\n", shortHash(sha), subject)
+	if !autosquash {
+		fmt.Fprintf(ctx.Stdout(), "ℹ️ Fold it in later with git %s\n", strings.Join(fixupRebaseArgs(shortHash(sha), gitHasParent(sha)), " "))
+		return nil
+	}
+
+	// Accepting the todo git generates keeps the rebase non-interactive;
+	// --autostash carries over whatever was left unstaged.
+	cmd := flowCommand("git", fixupRebaseArgs(sha, gitHasParent(sha))...)
+	cmd.Env = append(os.Environ(), "GIT_SEQUENCE_EDITOR=true", "GIT_EDITOR=true")
+	cmd.Stdout = ctx.Stdout()
+	cmd.Stderr = ctx.Stderr()
+	if err := cmd.Run(); err != nil {
+		fmt.Fprintf(ctx.Stderr(), "ℹ️ The rebase stopped. Resolve conflicts (%s gitResolve can help), then git rebase --continue, or git rebase --abort to go back.\n", commandName)
+		return reportError(ctx, fmt.Errorf("git rebase --autosquash: %w", err))
+	}
+	fmt.Fprintf(ctx.Stdout(), "✔️ Folded the fixup into %s\n", subject)
+	return nil
+}
+
+// pickFixupTarget resolves target to a commit SHA, or lets the user pick one
+// of the newest limit commits when target is empty.
+func pickFixupTarget(target string, limit int) (string, error) {
+	if target != "" {
+		out, err := flowCommand("git", "rev-parse", "--verify", "--quiet", target+"^{commit}").Output()
+		if err != nil {
+			return "", fmt.Errorf("%q is not a commit", target)
+		}
+		return strings.TrimSpace(string(out)), nil
+	}
+
+	entries, err := listGitLog(limit, nil)
+	if err != nil {
+		return "", err
+	}
+	previews := make(map[int]string, len(entries))
+	idx, err := fuzzyfinder.Find(
+		entries,
+		func(i int) string {
+			return entries[i].Line
+		},
+		fuzzyfinder.WithPromptString("fixup> "),
+		fuzzyfinder.WithPreviewWindow(func(i, width, height int) string {
+			if i < 0 || i >= len(entries) {
+				return ""
+			}
+			if cached, ok := previews[i]; ok {
+				return cached
+			}
+			preview := commitPreview(entries[i].SHA)
+			previews[i] = preview
+			return preview
+		}),
+	)
+	if err != nil {
+		return "", err
+	}
+	return entries[idx].SHA, nil
+}
+
+func gitHasParent(sha string) bool {
+	return flowCommand("git", "rev-parse", "--verify", "--quiet", sha+"^").Run() == nil
+}
+
+// fixupRebaseArgs rebases from the target's parent so the fixup can be
+// folded into it; the root commit has no parent and needs --root.
+func fixupRebaseArgs(sha string, hasParent bool) []string {
+	args := []string{"rebase", "-i", "--autosquash", "--autostash"}
+	if !hasParent {
+		return append(args, "--root")
+	}
+	return append(args, sha+"^")
+}
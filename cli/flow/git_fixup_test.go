@@ -0,0 +1,20 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestFixupRebaseArgs(t *testing.T) {
+	got := fixupRebaseArgs("abc123", true)
+	want := []string{"rebase", "-i", "--autosquash", "--autostash", "abc123^"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("fixupRebaseArgs = %q, want %q", got, want)
+	}
+
+	got = fixupRebaseArgs("abc123", false)
+	want = []string{"rebase", "-i", "--autosquash", "--autostash", "--root"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("fixupRebaseArgs for the root commit = %q, want %q", got, want)
+	}
+}
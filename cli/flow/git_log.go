@@ -0,0 +1,149 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"lang/gitutil"
+
+	"github.com/dzonerzy/go-snap/snap"
+	"github.com/ktr0731/go-fuzzyfinder"
+)
+
+const defaultGitLogLimit = 200
+
+type logEntry struct {
+	SHA  string
+	Line string
+}
+
+func runGitLog(ctx *snap.Context) error {
+	usage := func() {
+		fmt.Fprintf(ctx.Stderr(), "Usage: %s gitLog [-n <limit>] [--author <pattern>] [--grep <pattern>] [--copy]\n", commandName)
+	}
+
+	limit := defaultGitLogLimit
+	copySHA := false
+	var filters []string
+	for i := 0; i < ctx.NArgs(); i++ {
+		arg := strings.TrimSpace(ctx.Arg(i))
+		name, value, hasValue := strings.Cut(arg, "=")
+		switch name {
+		case "":
+			continue
+		case "--copy":
+			copySHA = true
+			continue
+		case "-n", "--author", "--grep":
+		default:
+			usage()
+			return fmt.Errorf("unexpected argument %q", arg)
+		}
+
+		if !hasValue {
+			if i+1 >= ctx.NArgs() || strings.TrimSpace(ctx.Arg(i+1)) == "" {
+				usage()
+				return fmt.Errorf("%s requires a value", name)
+			}
+			i++
+			value = ctx.Arg(i)
+		}
+		value = strings.TrimSpace(value)
+
+		if name == "-n" {
+			n, err := strconv.Atoi(value)
+			if err != nil || n <= 0 {
+				usage()
+				return fmt.Errorf("-n expects a positive number, got %q", value)
+			}
+			limit = n
+			continue
+		}
+		filters = append(filters, name+"="+value)
+	}
+
+	if err := gitutil.EnsureRepository(flowCtx); err != nil {
+		return err
+	}
+
+	entries, err := listGitLog(limit, filters)
+	if err != nil {
+		return reportError(ctx, err)
+	}
+	if len(entries) == 0 {
+		fmt.Fprintln(ctx.Stdout(), "No commits match.")
+		return nil
+	}
+
+	previews := make(map[int]string, len(entries))
+	idx, err := fuzzyfinder.Find(
+		entries,
+		func(i int) string {
+			return entries[i].Line
+		},
+		fuzzyfinder.WithPromptString("gitLog> "),
+		fuzzyfinder.WithPreviewWindow(func(i, width, height int) string {
+			if i < 0 || i >= len(entries) {
+				return ""
+			}
+			if cached, ok := previews[i]; ok {
+				return cached
+			}
+			preview := commitPreview(entries[i].SHA)
+			previews[i] = preview
+			return preview
+		}),
+	)
+	if err != nil {
+		if errors.Is(err, fuzzyfinder.ErrAbort) {
+			return errUserAbort
+		}
+		return reportError(ctx, fmt.Errorf("select commit: %w", err))
+	}
+
+	sha := entries[idx].SHA
+	if copySHA {
+		copyToClipboard(ctx, sha)
+		return nil
+	}
+	fmt.Fprintln(ctx.Stdout(), sha)
+	return nil
+}
+
+// listGitLog lists the newest commits on HEAD. filters are --author=... and
+// --grep=... flags, passed to git as is.
+func listGitLog(limit int, filters []string) ([]logEntry, error) {
+	args := []string{"log", "-n", strconv.Itoa(limit), "--format=%H%x09%h %s (%an, %ar)"}
+	args = append(args, filters...)
+	out, err := flowCommand("git", args...).Output()
+	if err != nil {
+		return nil, fmt.Errorf("git log: %w", err)
+	}
+	return parseLogEntries(string(out)), nil
+}
+
+func parseLogEntries(raw string) []logEntry {
+	var entries []logEntry
+	for _, line := range strings.Split(raw, "\n") {
+		sha, rest, ok := strings.Cut(strings.TrimSpace(line), "\t")
+		if !ok || sha == "" {
+			continue
+		}
+		entries = append(entries, logEntry{SHA: sha, Line: rest})
+	}
+	return entries
+}
+
+func commitPreview(sha string) string {
+	out, err := flowCommand("git", "show", "--stat", "-p", "--color=never", sha).CombinedOutput()
+	if err != nil {
+		trimmed := strings.TrimSpace(string(out))
+		if trimmed != "" {
+			return trimmed
+		}
+		return err.Error()
+	}
+	return string(out)
+}
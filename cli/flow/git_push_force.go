@@ -0,0 +1,111 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	"lang/gitutil"
+
+	"github.com/dzonerzy/go-snap/snap"
+)
+
+// protectedBranches are never force-pushed without --allow-protected.
+var protectedBranches = map[string]bool{
+	"main":   true,
+	"master": true,
+}
+
+func runPushForce(ctx *snap.Context) error {
+	usage := func() {
+		fmt.Fprintf(ctx.Stderr(), "Usage: %s pushForce [--yes] [--allow-protected]\n", commandName)
+	}
+
+	assumeYes := false
+	allowProtected := false
+	for i := 0; i < ctx.NArgs(); i++ {
+		arg := strings.TrimSpace(ctx.Arg(i))
+		switch arg {
+		case "":
+		case "--yes", "-y":
+			assumeYes = true
+		case "--allow-protected":
+			allowProtected = true
+		default:
+			usage()
+			return fmt.Errorf("unexpected argument %q", arg)
+		}
+	}
+
+	if err := gitutil.EnsureRepository(flowCtx); err != nil {
+		return err
+	}
+
+	branch, err := gitutil.CurrentBranch(flowCtx)
+	if err != nil {
+		return reportError(ctx, err)
+	}
+	if branch == "HEAD" {
+		return reportError(ctx, fmt.Errorf("HEAD is detached; check out a branch to push"))
+	}
+
+	remote, remoteBranch := branchUpstream(branch)
+	setUpstream := false
+	if remote == "" {
+		remotes, err := gitutil.Remotes(flowCtx)
+		if err != nil {
+			return reportError(ctx, err)
+		}
+		if remote, err = selectGitRemote(remotes, ""); err != nil {
+			return reportError(ctx, err)
+		}
+		remoteBranch = branch
+		setUpstream = true
+	}
+
+	if protectedBranches[remoteBranch] && !allowProtected {
+		return reportError(ctx, fmt.Errorf("refusing to force-push to protected branch %s/%s (pass --allow-protected to override)", remote, remoteBranch))
+	}
+
+	target := remote + "/" + remoteBranch
+	fmt.Fprintf(ctx.Stdout(), "Force-push %s to %s (with lease)\n", branch, target)
+
+	// The lease compares against the remote-tracking ref as last fetched, so
+	// don't fetch here: fetching would silently accept whatever is there now.
+	if exists, _ := gitutil.RefExists(flowCtx, "refs/remotes/"+target); exists {
+		out, err := flowCommand("git", "rev-list", "--left-right", "--count", target+"...HEAD").Output()
+		if err == nil {
+			var behind, ahead int
+			if _, err := fmt.Sscanf(strings.TrimSpace(string(out)), "%d %d", &behind, &ahead); err == nil {
+				fmt.Fprintf(ctx.Stdout(), "  %d commit(s) ahead, %d behind %s\n", ahead, behind, target)
+				if behind > 0 {
+					fmt.Fprintf(ctx.Stdout(), "ℹ️ %d commit(s) on %s will be overwritten\n", behind, target)
+				}
+			}
+		}
+	} else {
+		fmt.Fprintf(ctx.Stdout(), "  %s does not exist yet\n", target)
+	}
+
+	if !assumeYes {
+		ok, err := confirm(ctx, "Proceed?", false)
+		if err != nil {
+			return reportError(ctx, err)
+		}
+		if !ok {
+			fmt.Fprintln(ctx.Stdout(), "Push cancelled.")
+			return errUserAbort
+		}
+	}
+
+	args := []string{"push", "--force-with-lease"}
+	if setUpstream {
+		args = append(args, "--set-upstream")
+	}
+	args = append(args, remote, branch+":"+remoteBranch)
+	if err := runGitCommandStreaming(ctx, args...); err != nil {
+		return reportError(ctx, fmt.Errorf("git push --force-with-lease: %w", err))
+	}
+
+	fmt.Fprintf(ctx.Stdout(), "✔️ Force-pushed %s to %s\n", branch, target)
+	return nil
+}
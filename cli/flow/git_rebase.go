@@ -0,0 +1,256 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	"lang/gitutil"
+
+	"github.com/dzonerzy/go-snap/snap"
+	"github.com/ktr0731/go-fuzzyfinder"
+)
+
+type rebaseCommit struct {
+	Hash    string
+	Subject string
+}
+
+func runGitInteractiveRebase(ctx *snap.Context) error {
+	usage := func() {
+		fmt.Fprintf(ctx.Stderr(), "Usage: %s gitInteractiveRebase [--base <ref>] [--ai] [--yes]\n", commandName)
+	}
+
+	base := ""
+	useAI := false
+	assumeYes := false
+	for i := 0; i < ctx.NArgs(); i++ {
+		arg := strings.TrimSpace(ctx.Arg(i))
+		switch {
+		case arg == "":
+		case arg == "--ai":
+			useAI = true
+		case arg == "--yes" || arg == "-y":
+			assumeYes = true
+		case arg == "--base":
+			if i+1 >= ctx.NArgs() || strings.TrimSpace(ctx.Arg(i+1)) == "" {
+				usage()
+				return fmt.Errorf("--base requires a ref")
+			}
+			i++
+			base = strings.TrimSpace(ctx.Arg(i))
+		case strings.HasPrefix(arg, "--base="):
+			base = strings.TrimSpace(strings.TrimPrefix(arg, "--base="))
+		default:
+			usage()
+			return fmt.Errorf("unexpected argument %q", arg)
+		}
+	}
+
+	if err := gitutil.EnsureRepository(flowCtx); err != nil {
+		return err
+	}
+
+	dirty, err := gitWorkingTreeDirty()
+	if err != nil {
+		return reportError(ctx, err)
+	}
+	if len(dirty) > 0 {
+		return reportError(ctx, fmt.Errorf("working tree has %d uncommitted change(s); commit or stash them before rebasing", len(dirty)))
+	}
+
+	branch, err := gitutil.CurrentBranch(flowCtx)
+	if err != nil {
+		return reportError(ctx, err)
+	}
+	if base == "" {
+		if base, err = diffStatBase(branch); err != nil {
+			return reportError(ctx, err)
+		}
+	}
+	out, err := flowCommand("git", "merge-base", "HEAD", base).Output()
+	if err != nil {
+		return reportError(ctx, fmt.Errorf("no common ancestor between HEAD and %s: %w", base, err))
+	}
+	mergeBase := strings.TrimSpace(string(out))
+
+	commits, err := commitsSince(mergeBase)
+	if err != nil {
+		return reportError(ctx, err)
+	}
+	if len(commits) < 2 {
+		fmt.Fprintf(ctx.Stdout(), "ℹ️ %s has %d commit(s) since %s; nothing to squash\n", branch, len(commits), base)
+		return nil
+	}
+
+	// Newest first in the picker, like git log.
+	indices, err := fuzzyfinder.FindMulti(
+		commits,
+		func(i int) string {
+			c := commits[len(commits)-1-i]
+			return fmt.Sprintf("%s %s", shortHash(c.Hash), c.Subject)
+		},
+		fuzzyfinder.WithPromptString("squash (tab to select)> "),
+		fuzzyfinder.WithPreviewWindow(func(i, width, height int) string {
+			if i < 0 || i >= len(commits) {
+				return ""
+			}
+			preview, _ := flowCommand("git", "show", "--stat", "--format=%h %s%n%an, %ar%n%n%b", commits[len(commits)-1-i].Hash).Output()
+			return string(preview)
+		}),
+	)
+	if err != nil {
+		if errors.Is(err, fuzzyfinder.ErrAbort) {
+			return errUserAbort
+		}
+		return reportError(ctx, fmt.Errorf("select commits: %w", err))
+	}
+	var selected []int
+	for _, idx := range indices {
+		selected = append(selected, len(commits)-1-idx)
+	}
+	sort.Ints(selected)
+	if len(selected) < 2 {
+		return reportError(ctx, fmt.Errorf("select at least two commits to squash"))
+	}
+
+	message, err := squashMessage(ctx, commits, selected, useAI)
+	if err != nil {
+		return err
+	}
+
+	messageFile, err := os.CreateTemp("", "fgo-squash-msg-*")
+	if err != nil {
+		return reportError(ctx, fmt.Errorf("create temp file: %w", err))
+	}
+	defer os.Remove(messageFile.Name())
+	if _, err := messageFile.WriteString(message + "\n"); err != nil {
+		messageFile.Close()
+		return reportError(ctx, fmt.Errorf("write commit message: %w", err))
+	}
+	messageFile.Close()
+
+	todo := buildSquashTodo(commits, selected, messageFile.Name())
+	todoFile, err := os.CreateTemp("", "fgo-rebase-todo-*")
+	if err != nil {
+		return reportError(ctx, fmt.Errorf("create temp file: %w", err))
+	}
+	defer os.Remove(todoFile.Name())
+	if _, err := todoFile.WriteString(todo); err != nil {
+		todoFile.Close()
+		return reportError(ctx, fmt.Errorf("write rebase todo: %w", err))
+	}
+	todoFile.Close()
+
+	fmt.Fprintf(ctx.Stdout(), "Rebase plan onto %s:\n%s\n", shortHash(mergeBase), todo)
+	if !assumeYes {
+		ok, err := confirm(ctx, fmt.Sprintf("Squash %d commits into one?", len(selected)), true)
+		if err != nil {
+			return reportError(ctx, err)
+		}
+		if !ok {
+			fmt.Fprintln(ctx.Stdout(), "Rebase cancelled.")
+			return errUserAbort
+		}
+	}
+
+	// git runs the sequence editor with the todo path appended, so copying
+	// the prepared plan over it makes the rebase non-interactive.
+	cmd := flowCommand("git", "rebase", "-i", mergeBase)
+	cmd.Env = append(os.Environ(), "GIT_SEQUENCE_EDITOR=cp "+shellQuote(todoFile.Name()), "GIT_EDITOR=true")
+	cmd.Stdout = ctx.Stdout()
+	cmd.Stderr = ctx.Stderr()
+	if err := cmd.Run(); err != nil {
+		fmt.Fprintf(ctx.Stderr(), "ℹ️ The rebase stopped. Resolve conflicts (%s gitResolve can help), then git rebase --continue, or git rebase --abort to go back.\n", commandName)
+		return reportError(ctx, fmt.Errorf("git rebase: %w", err))
+	}
+
+	fmt.Fprintf(ctx.Stdout(), "✔️ Squashed %d commits: %s\n", len(selected), gitCommitSubject("HEAD"))
+	return nil
+}
+
+// commitsSince lists the commits after base up to HEAD, oldest first.
+func commitsSince(base string) ([]rebaseCommit, error) {
+	out, err := flowCommand("git", "log", "--reverse", "--no-merges", "--format=%H%x00%s", base+"..HEAD").Output()
+	if err != nil {
+		return nil, fmt.Errorf("git log %s..HEAD: %w", base, err)
+	}
+	var commits []rebaseCommit
+	for _, line := range strings.Split(strings.TrimSpace(string(out)), "\n") {
+		hash, subject, ok := strings.Cut(line, "\x00")
+		if ok && hash != "" {
+			commits = append(commits, rebaseCommit{Hash: hash, Subject: subject})
+		}
+	}
+	return commits, nil
+}
+
+// buildSquashTodo keeps every commit in order, except that the selected
+// ones (indices into commits, ascending) move up to the first of them and
+// are folded into it. The exec line then rewrites the combined message.
+func buildSquashTodo(commits []rebaseCommit, selected []int, messageFile string) string {
+	chosen := make(map[int]bool, len(selected))
+	for _, idx := range selected {
+		chosen[idx] = true
+	}
+
+	var todo strings.Builder
+	for i, c := range commits {
+		if chosen[i] && i != selected[0] {
+			continue
+		}
+		fmt.Fprintf(&todo, "pick %s %s\n", c.Hash, c.Subject)
+		if i != selected[0] {
+			continue
+		}
+		for _, idx := range selected[1:] {
+			fmt.Fprintf(&todo, "fixup %s %s\n", commits[idx].Hash, commits[idx].Subject)
+		}
+		fmt.Fprintf(&todo, "exec git commit --amend --no-verify -F %s\n", shellQuote(messageFile))
+	}
+	return todo.String()
+}
+
+// squashMessage is the model's description of the combined change with
+// --ai, or the selected commits' messages one after another like git's own
+// squash.
+func squashMessage(ctx *snap.Context, commits []rebaseCommit, selected []int, useAI bool) (string, error) {
+	if !useAI {
+		var messages []string
+		for _, idx := range selected {
+			body, err := flowCommand("git", "log", "-1", "--format=%B", commits[idx].Hash).Output()
+			if err != nil {
+				return "", reportError(ctx, fmt.Errorf("read message of %s: %w", shortHash(commits[idx].Hash), err))
+			}
+			messages = append(messages, strings.TrimSpace(string(body)))
+		}
+		return strings.Join(messages, "\n\n"), nil
+	}
+
+	apiKey, err := resolveOpenAIKey(ctx.Context())
+	if err != nil {
+		return "", reportError(ctx, err)
+	}
+	var diff strings.Builder
+	for _, idx := range selected {
+		out, err := flowCommand("git", "show", "--format=", commits[idx].Hash).Output()
+		if err != nil {
+			return "", reportError(ctx, fmt.Errorf("git show %s: %w", shortHash(commits[idx].Hash), err))
+		}
+		diff.Write(out)
+	}
+	payload, err := proposeCommitMessage(ctx, apiKey, diff.String())
+	if err != nil {
+		return "", err
+	}
+	if !payload.streamed {
+		fmt.Fprintf(ctx.Stdout(), "Proposed commit message:\n%s\n\n", payload.message)
+	}
+	return strings.Join(payload.paragraphs, "\n\n"), nil
+}
+
+func shellQuote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
@@ -0,0 +1,27 @@
+package main
+
+import "testing"
+
+func TestBuildSquashTodo(t *testing.T) {
+	commits := []rebaseCommit{
+		{Hash: "a1", Subject: "first"},
+		{Hash: "b2", Subject: "second"},
+		{Hash: "c3", Subject: "third"},
+		{Hash: "d4", Subject: "fourth"},
+	}
+	got := buildSquashTodo(commits, []int{1, 3}, "/tmp/msg")
+	want := "pick a1 first\n" +
+		"pick b2 second\n" +
+		"fixup d4 fourth\n" +
+		"exec git commit --amend --no-verify -F '/tmp/msg'\n" +
+		"pick c3 third\n"
+	if got != want {
+		t.Fatalf("unexpected todo:\n%s\nwant:\n%s", got, want)
+	}
+}
+
+func TestShellQuote(t *testing.T) {
+	if got := shellQuote("it's"); got != `'it'\''s'` {
+		t.Fatalf("got %s", got)
+	}
+}
@@ -0,0 +1,119 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"lang/gitutil"
+
+	"github.com/dzonerzy/go-snap/snap"
+	"github.com/ktr0731/go-fuzzyfinder"
+)
+
+const recentBranchesReflogDepth = 500
+
+func runRecentBranches(ctx *snap.Context) error {
+	if ctx.NArgs() != 0 {
+		fmt.Fprintf(ctx.Stderr(), "Usage: %s recentBranches\n", commandName)
+		return fmt.Errorf("expected 0 arguments, got %d", ctx.NArgs())
+	}
+
+	if err := gitutil.EnsureRepository(flowCtx); err != nil {
+		return err
+	}
+
+	current, err := gitutil.CurrentBranch(flowCtx)
+	if err != nil {
+		return reportError(ctx, err)
+	}
+
+	branches, err := recentlyCheckedOutBranches(current)
+	if err != nil {
+		return reportError(ctx, err)
+	}
+	if len(branches) == 0 {
+		// A fresh clone or an expired reflog has no checkout history.
+		if branches, err = branchesByCommitDate(current); err != nil {
+			return reportError(ctx, err)
+		}
+	}
+	if len(branches) == 0 {
+		fmt.Fprintln(ctx.Stdout(), "No other local branches to switch to.")
+		return nil
+	}
+
+	idx, err := fuzzyfinder.Find(
+		branches,
+		func(i int) string {
+			return branches[i]
+		},
+		fuzzyfinder.WithPromptString("recentBranches> "),
+	)
+	if err != nil {
+		if errors.Is(err, fuzzyfinder.ErrAbort) {
+			return errUserAbort
+		}
+		return reportError(ctx, fmt.Errorf("select branch: %w", err))
+	}
+
+	branch := branches[idx]
+	if err := runGitCommandStreaming(ctx, "checkout", branch); err != nil {
+		return reportError(ctx, fmt.Errorf("git checkout %s: %w", branch, err))
+	}
+	fmt.Fprintf(ctx.Stdout(), "✔️ Switched to %s\n", branch)
+	return nil
+}
+
+// recentlyCheckedOutBranches walks the HEAD reflog for "checkout: moving from
+// X to Y" entries and returns the local branches moved to, most recent first.
+func recentlyCheckedOutBranches(current string) ([]string, error) {
+	entries, err := readHeadReflog(recentBranchesReflogDepth)
+	if err != nil {
+		return nil, err
+	}
+
+	seen := map[string]bool{current: true}
+	var branches []string
+	for _, entry := range entries {
+		target, ok := reflogCheckoutTarget(entry.Subject)
+		if !ok || seen[target] {
+			continue
+		}
+		seen[target] = true
+		// Skip detached checkouts and branches deleted since.
+		if exists, _ := gitutil.RefExists(flowCtx, "refs/heads/"+target); !exists {
+			continue
+		}
+		branches = append(branches, target)
+	}
+	return branches, nil
+}
+
+func reflogCheckoutTarget(subject string) (string, bool) {
+	rest, ok := strings.CutPrefix(subject, "checkout: moving from ")
+	if !ok {
+		return "", false
+	}
+	_, target, ok := strings.Cut(rest, " to ")
+	if !ok {
+		return "", false
+	}
+	target = strings.TrimSpace(target)
+	return target, target != ""
+}
+
+func branchesByCommitDate(current string) ([]string, error) {
+	out, err := flowCommand("git", "for-each-ref", "--sort=-committerdate", "--format=%(refname:short)", "refs/heads").Output()
+	if err != nil {
+		return nil, fmt.Errorf("git for-each-ref: %w", err)
+	}
+
+	var branches []string
+	for _, line := range strings.Split(string(out), "\n") {
+		if line = strings.TrimSpace(line); line != "" && line != current {
+			branches = append(branches, line)
+		}
+	}
+	return branches, nil
+}
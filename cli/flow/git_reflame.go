@@ -0,0 +1,140 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	"lang/gitutil"
+
+	"github.com/dzonerzy/go-snap/snap"
+	"github.com/ktr0731/go-fuzzyfinder"
+)
+
+func runGitReflame(ctx *snap.Context) error {
+	usage := func() {
+		fmt.Fprintf(ctx.Stderr(), "Usage: %s gitReflame [--regex] [--copy] <path> <text...>\n", commandName)
+	}
+
+	regex, copySHA := false, false
+	var path string
+	var words []string
+	for i := 0; i < ctx.NArgs(); i++ {
+		arg := ctx.Arg(i)
+		switch {
+		case len(words) > 0 || (path != "" && !strings.HasPrefix(arg, "--")):
+			words = append(words, arg)
+		case arg == "--regex":
+			regex = true
+		case arg == "--copy":
+			copySHA = true
+		case arg == "--":
+			if path == "" && i+1 < ctx.NArgs() {
+				i++
+				path = ctx.Arg(i)
+			}
+			words = append(words, ctx.Args()[i+1:]...)
+			i = ctx.NArgs()
+		case strings.HasPrefix(arg, "-"):
+			usage()
+			return fmt.Errorf("unknown flag %q", arg)
+		default:
+			path = strings.TrimSpace(arg)
+		}
+	}
+	text := strings.Join(words, " ")
+	if path == "" || strings.TrimSpace(text) == "" {
+		usage()
+		return fmt.Errorf("a path and the text to look for are required")
+	}
+
+	if err := gitutil.EnsureRepository(flowCtx); err != nil {
+		return err
+	}
+	if err := checkPathInHistory(path); err != nil {
+		return reportError(ctx, err)
+	}
+
+	out, err := flowCommand("git", pickaxeArgs(path, text, regex)...).CombinedOutput()
+	if err != nil {
+		if msg := strings.TrimSpace(string(out)); msg != "" {
+			return reportError(ctx, fmt.Errorf("git log -S: %s", msg))
+		}
+		return reportError(ctx, fmt.Errorf("git log -S: %w", err))
+	}
+	entries := parseLogEntries(string(out))
+	if len(entries) == 0 {
+		fmt.Fprintf(ctx.Stdout(), "No commit in the history of %s added or removed %q.\n", path, text)
+		return nil
+	}
+
+	idx := 0
+	if len(entries) > 1 {
+		// Newest first, so the last entry is usually where the text appeared.
+		fmt.Fprintf(ctx.Stderr(), "ℹ️ %d commits changed how often %q appears; the oldest is likely where it was added\n", len(entries), text)
+		previews := make(map[int]string, len(entries))
+		idx, err = fuzzyfinder.Find(
+			entries,
+			func(i int) string {
+				return entries[i].Line
+			},
+			fuzzyfinder.WithPromptString("gitReflame> "),
+			fuzzyfinder.WithPreviewWindow(func(i, width, height int) string {
+				if i < 0 || i >= len(entries) {
+					return ""
+				}
+				if cached, ok := previews[i]; ok {
+					return cached
+				}
+				preview := commitPreview(entries[i].SHA)
+				previews[i] = preview
+				return preview
+			}),
+		)
+		if err != nil {
+			if errors.Is(err, fuzzyfinder.ErrAbort) {
+				return errUserAbort
+			}
+			return reportError(ctx, fmt.Errorf("select commit: %w", err))
+		}
+	}
+
+	entry := entries[idx]
+	fmt.Fprintln(ctx.Stdout(), entry.Line)
+	if copySHA {
+		copyToClipboard(ctx, entry.SHA)
+	}
+	return nil
+}
+
+// pickaxeArgs builds the git log call that lists commits changing how many
+// times text occurs in path, following the file across renames.
+func pickaxeArgs(path, text string, regex bool) []string {
+	args := []string{"log", "--follow", "--date=short", "--format=%H%x09%h %ad %s (%an)", "-S" + text}
+	if regex {
+		args = append(args, "--pickaxe-regex")
+	}
+	return append(args, "--", path)
+}
+
+// checkPathInHistory accepts a path that is tracked now or was at some
+// point, so text can be traced in files that have since been deleted.
+func checkPathInHistory(path string) error {
+	if info, err := os.Stat(path); err == nil {
+		if info.IsDir() {
+			return fmt.Errorf("%s is a directory; pass a file", path)
+		}
+		if err := flowCommand("git", "ls-files", "--error-unmatch", "--", path).Run(); err == nil {
+			return nil
+		}
+	}
+	out, err := flowCommand("git", "log", "-1", "--format=%H", "--all", "--", path).Output()
+	if err != nil {
+		return fmt.Errorf("git log -- %s: %w", path, err)
+	}
+	if strings.TrimSpace(string(out)) == "" {
+		return fmt.Errorf("%s is not tracked and has no history in this repository", path)
+	}
+	return nil
+}
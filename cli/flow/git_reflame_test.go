@@ -0,0 +1,20 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestPickaxeArgs(t *testing.T) {
+	got := pickaxeArgs("cli/main.go", "func main", false)
+	want := []string{"log", "--follow", "--date=short", "--format=%H%x09%h %ad %s (%an)", "-Sfunc main", "--", "cli/main.go"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("pickaxeArgs = %q, want %q", got, want)
+	}
+
+	got = pickaxeArgs("-weird.go", "v[0-9]+", true)
+	want = []string{"log", "--follow", "--date=short", "--format=%H%x09%h %ad %s (%an)", "-Sv[0-9]+", "--pickaxe-regex", "--", "-weird.go"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("pickaxeArgs with regex = %q, want %q", got, want)
+	}
+}
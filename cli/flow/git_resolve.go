@@ -0,0 +1,259 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	"lang/gitutil"
+
+	"github.com/dzonerzy/go-snap/snap"
+	claudecode "github.com/severity1/claude-code-sdk-go"
+)
+
+// conflictOperation is the git operation that stopped on conflicts, with
+// the commit it was applying when git records one.
+type conflictOperation struct {
+	Name   string // merge, rebase, cherry-pick, or revert
+	Head   string // the ref holding the incoming commit, e.g. CHERRY_PICK_HEAD
+	Commit string
+}
+
+// conflictOperations maps the state files git leaves in the git dir to the
+// operation in progress. A rebase stopped on a conflict also has REBASE_HEAD.
+var conflictOperations = []struct {
+	name, head, marker string
+}{
+	{"cherry-pick", "CHERRY_PICK_HEAD", "CHERRY_PICK_HEAD"},
+	{"revert", "REVERT_HEAD", "REVERT_HEAD"},
+	{"rebase", "REBASE_HEAD", "rebase-merge"},
+	{"rebase", "REBASE_HEAD", "rebase-apply"},
+	{"merge", "MERGE_HEAD", "MERGE_HEAD"},
+}
+
+func currentConflictOperation() (conflictOperation, bool) {
+	for _, op := range conflictOperations {
+		out, err := flowCommand("git", "rev-parse", "--git-path", op.marker).Output()
+		if err != nil {
+			continue
+		}
+		if _, err := os.Stat(strings.TrimSpace(string(out))); err != nil {
+			continue
+		}
+		current := conflictOperation{Name: op.name, Head: op.head}
+		if sha, err := flowCommand("git", "rev-parse", "--verify", "--quiet", op.head).Output(); err == nil {
+			current.Commit = strings.TrimSpace(string(sha))
+		}
+		return current, true
+	}
+	return conflictOperation{}, false
+}
+
+// conflictContext is what the resolver is told about the change being
+// applied: the operation and, when known, the incoming commit.
+type conflictContext struct {
+	Operation     string
+	CommitMessage string
+	CommitDiff    string
+}
+
+// resolveConflictWithAI asks Claude for the content of file with its
+// conflict markers resolved. The file itself is left untouched.
+func resolveConflictWithAI(cwd, file string, info conflictContext) (string, error) {
+	conflictedContent, err := os.ReadFile(file)
+	if err != nil {
+		return "", fmt.Errorf("failed to read conflicted file %s: %w", file, err)
+	}
+
+	incoming := "The change being applied is not recorded as a single commit."
+	if info.CommitMessage != "" || info.CommitDiff != "" {
+		incoming = fmt.Sprintf(`The commit being applied has this message: %s
+
+The diff from the original commit:
+%s`, info.CommitMessage, info.CommitDiff)
+	}
+
+	prompt := fmt.Sprintf(`You are helping resolve a git merge conflict during a %s operation.
+
+%s
+
+The file "%s" has merge conflicts. Here is the current content with conflict markers:
+%s
+
+Please resolve the conflicts intelligently by:
+1. Understanding the intent of both changes
+2. Merging them in a way that preserves both intentions where possible
+3. If changes conflict directly, prefer the incoming changes (from the commit being applied) but ensure the result is valid code
+
+Output ONLY the resolved file content, without any explanation or markdown code blocks. Just the raw file content that should replace the conflicted file.`,
+		info.Operation,
+		incoming,
+		file,
+		string(conflictedContent))
+
+	iterator, err := claudecode.Query(flowCtx, prompt,
+		claudecode.WithCwd(cwd),
+		claudecode.WithPermissionMode(claudecode.PermissionModeBypassPermissions),
+	)
+	if err != nil {
+		return "", fmt.Errorf("failed to query Claude: %w", err)
+	}
+	defer iterator.Close()
+
+	var resolvedContent strings.Builder
+	for {
+		message, err := iterator.Next(flowCtx)
+		if err != nil {
+			if errors.Is(err, claudecode.ErrNoMoreMessages) {
+				break
+			}
+			return "", fmt.Errorf("failed to get Claude response: %w", err)
+		}
+
+		if message == nil {
+			break
+		}
+
+		switch msg := message.(type) {
+		case *claudecode.AssistantMessage:
+			for _, block := range msg.Content {
+				if textBlock, ok := block.(*claudecode.TextBlock); ok {
+					resolvedContent.WriteString(textBlock.Text)
+				}
+			}
+		case *claudecode.ResultMessage:
+			if msg.IsError {
+				return "", fmt.Errorf("Claude error: %s", msg.Result)
+			}
+		}
+	}
+
+	resolved := resolvedContent.String()
+	if resolved == "" {
+		return "", fmt.Errorf("Claude returned empty resolution for %s", file)
+	}
+	return resolved, nil
+}
+
+func runGitResolve(ctx *snap.Context) error {
+	preview := false
+	var only []string
+	for i := 0; i < ctx.NArgs(); i++ {
+		arg := strings.TrimSpace(ctx.Arg(i))
+		switch {
+		case arg == "":
+		case arg == "--preview":
+			preview = true
+		case strings.HasPrefix(arg, "-"):
+			fmt.Fprintf(ctx.Stderr(), "Usage: %s gitResolve [--preview] [file...]\n", commandName)
+			return fmt.Errorf("unknown flag %q", arg)
+		default:
+			only = append(only, arg)
+		}
+	}
+
+	if err := gitutil.EnsureRepository(flowCtx); err != nil {
+		return err
+	}
+
+	files := getConflictedFiles()
+	if len(only) > 0 {
+		var selected []string
+		for _, file := range only {
+			if !containsString(files, file) {
+				return reportError(ctx, fmt.Errorf("%s has no unresolved conflicts", file))
+			}
+			selected = append(selected, file)
+		}
+		files = selected
+	}
+	if len(files) == 0 {
+		fmt.Fprintln(ctx.Stdout(), "ℹ️ No conflicted files")
+		return nil
+	}
+
+	op, ok := currentConflictOperation()
+	if !ok {
+		op = conflictOperation{Name: "merge"}
+	}
+	info := conflictContext{Operation: op.Name}
+	if op.Commit != "" {
+		message, _ := flowCommand("git", "log", "-1", "--format=%s", op.Commit).Output()
+		diff, _ := flowCommand("git", "show", op.Commit, "--format=").Output()
+		info.CommitMessage = strings.TrimSpace(string(message))
+		info.CommitDiff = string(diff)
+		fmt.Fprintf(ctx.Stdout(), "Resolving %d file(s) in %s of %s: %s\n", len(files), op.Name, shortHash(op.Commit), info.CommitMessage)
+	} else {
+		fmt.Fprintf(ctx.Stdout(), "Resolving %d file(s) in %s\n", len(files), op.Name)
+	}
+
+	cwd, err := os.Getwd()
+	if err != nil {
+		return reportError(ctx, fmt.Errorf("failed to get working directory: %w", err))
+	}
+
+	staged := 0
+	for _, file := range files {
+		fmt.Fprintf(ctx.Stdout(), "  Resolving: %s\n", file)
+		resolved, err := resolveConflictWithAI(cwd, file, info)
+		if err != nil {
+			return reportError(ctx, err)
+		}
+
+		if preview {
+			if err := printResolutionPreview(ctx, file, resolved); err != nil {
+				return reportError(ctx, err)
+			}
+			apply, err := confirm(ctx, fmt.Sprintf("Apply this resolution to %s?", file), true)
+			if err != nil {
+				return reportError(ctx, err)
+			}
+			if !apply {
+				fmt.Fprintf(ctx.Stdout(), "    Skipped %s\n", file)
+				continue
+			}
+		}
+
+		if err := os.WriteFile(file, []byte(resolved), 0o644); err != nil {
+			return reportError(ctx, fmt.Errorf("failed to write resolved file %s: %w", file, err))
+		}
+		if err := flowCommand("git", "add", "--", file).Run(); err != nil {
+			return reportError(ctx, fmt.Errorf("failed to stage resolved file %s: %w", file, err))
+		}
+		fmt.Fprintf(ctx.Stdout(), "    ✓ Resolved and staged\n")
+		staged++
+	}
+
+	if remaining := getConflictedFiles(); len(remaining) > 0 {
+		fmt.Fprintf(ctx.Stdout(), "ℹ️ %d file(s) still conflicted: %s\n", len(remaining), strings.Join(remaining, ", "))
+		return nil
+	}
+	fmt.Fprintf(ctx.Stdout(), "✔️ Resolved %d file(s)\n", staged)
+	if ok {
+		fmt.Fprintf(ctx.Stdout(), "ℹ️ Review with git diff --cached, then run git %s --continue\n", op.Name)
+	}
+	return nil
+}
+
+// printResolutionPreview shows how the resolution differs from the file as
+// it sits with its conflict markers.
+func printResolutionPreview(ctx *snap.Context, file, resolved string) error {
+	tmp, err := os.CreateTemp("", "fgo-resolve-*")
+	if err != nil {
+		return fmt.Errorf("create temp file: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.WriteString(resolved); err != nil {
+		tmp.Close()
+		return fmt.Errorf("write temp file: %w", err)
+	}
+	tmp.Close()
+
+	// --no-index exits 1 when the files differ, which is the expected case.
+	cmd := flowCommand("git", "diff", "--no-index", "--", file, tmp.Name())
+	cmd.Stdout = ctx.Stdout()
+	cmd.Stderr = ctx.Stderr()
+	_ = cmd.Run()
+	return nil
+}
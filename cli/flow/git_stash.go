@@ -0,0 +1,144 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"lang/gitutil"
+
+	"github.com/dzonerzy/go-snap/snap"
+	"github.com/ktr0731/go-fuzzyfinder"
+)
+
+type stashEntry struct {
+	Index   int
+	Ref     string
+	Message string
+}
+
+func runGitStashPick(ctx *snap.Context) error {
+	action := "apply"
+	for i := 0; i < ctx.NArgs(); i++ {
+		arg := strings.TrimSpace(ctx.Arg(i))
+		if arg == "" {
+			continue
+		}
+
+		switch arg {
+		case "--apply":
+			action = "apply"
+		case "--pop":
+			action = "pop"
+		case "--drop":
+			action = "drop"
+		default:
+			fmt.Fprintf(ctx.Stderr(), "Usage: %s gitStashPick [--apply|--pop|--drop]\n", commandName)
+			return fmt.Errorf("unexpected argument %q", arg)
+		}
+	}
+
+	if err := gitutil.EnsureRepository(flowCtx); err != nil {
+		return err
+	}
+
+	stashes, err := listGitStashes()
+	if err != nil {
+		return reportError(ctx, err)
+	}
+	if len(stashes) == 0 {
+		fmt.Fprintln(ctx.Stdout(), "No stashes found.")
+		return nil
+	}
+
+	previews := make(map[int]string, len(stashes))
+	idx, err := fuzzyfinder.Find(
+		stashes,
+		func(i int) string {
+			return fmt.Sprintf("%s  %s", stashes[i].Ref, stashes[i].Message)
+		},
+		fuzzyfinder.WithPromptString(fmt.Sprintf("gitStashPick (%s)> ", action)),
+		fuzzyfinder.WithPreviewWindow(func(i, width, height int) string {
+			if i < 0 || i >= len(stashes) {
+				return ""
+			}
+			if cached, ok := previews[i]; ok {
+				return cached
+			}
+			preview := stashPreview(stashes[i].Ref)
+			previews[i] = preview
+			return preview
+		}),
+	)
+	if err != nil {
+		if errors.Is(err, fuzzyfinder.ErrAbort) {
+			return errUserAbort
+		}
+		return reportError(ctx, fmt.Errorf("select stash: %w", err))
+	}
+
+	selected := stashes[idx]
+	if err := runGitCommandStreaming(ctx, "stash", action, selected.Ref); err != nil {
+		return reportError(ctx, fmt.Errorf("git stash %s %s: %w", action, selected.Ref, err))
+	}
+
+	switch action {
+	case "pop":
+		fmt.Fprintf(ctx.Stdout(), "✔️ Popped %s: %s\n", selected.Ref, selected.Message)
+	case "drop":
+		fmt.Fprintf(ctx.Stdout(), "✔️ Dropped %s: %s\n", selected.Ref, selected.Message)
+	default:
+		fmt.Fprintf(ctx.Stdout(), "✔️ Applied %s: %s\n", selected.Ref, selected.Message)
+	}
+	return nil
+}
+
+func listGitStashes() ([]stashEntry, error) {
+	out, err := flowCommand("git", "stash", "list", "--format=%gd%x09%gs").Output()
+	if err != nil {
+		return nil, fmt.Errorf("git stash list: %w", err)
+	}
+
+	return parseStashList(string(out)), nil
+}
+
+func parseStashList(raw string) []stashEntry {
+	var entries []stashEntry
+	for _, line := range strings.Split(raw, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" {
+			continue
+		}
+
+		ref, message, _ := strings.Cut(line, "\t")
+		ref = strings.TrimSpace(ref)
+		if !strings.HasPrefix(ref, "stash@{") || !strings.HasSuffix(ref, "}") {
+			continue
+		}
+
+		index, err := strconv.Atoi(strings.TrimSuffix(strings.TrimPrefix(ref, "stash@{"), "}"))
+		if err != nil {
+			continue
+		}
+
+		entries = append(entries, stashEntry{
+			Index:   index,
+			Ref:     ref,
+			Message: strings.TrimSpace(message),
+		})
+	}
+	return entries
+}
+
+func stashPreview(ref string) string {
+	out, err := flowCommand("git", "stash", "show", "--stat", "-p", ref).CombinedOutput()
+	if err != nil {
+		trimmed := strings.TrimSpace(string(out))
+		if trimmed != "" {
+			return trimmed
+		}
+		return err.Error()
+	}
+	return string(out)
+}
@@ -0,0 +1,86 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	"lang/gitutil"
+
+	"github.com/dzonerzy/go-snap/snap"
+)
+
+func runGitSwitchLast(ctx *snap.Context) error {
+	assumeYes := false
+	for i := 0; i < ctx.NArgs(); i++ {
+		arg := strings.TrimSpace(ctx.Arg(i))
+		switch arg {
+		case "":
+		case "--yes", "-y":
+			assumeYes = true
+		default:
+			fmt.Fprintf(ctx.Stderr(), "Usage: %s gitSwitchLast [--yes]\n", commandName)
+			return fmt.Errorf("unexpected argument %q", arg)
+		}
+	}
+
+	if err := gitutil.EnsureRepository(flowCtx); err != nil {
+		return err
+	}
+
+	current, err := gitutil.CurrentBranch(flowCtx)
+	if err != nil {
+		return reportError(ctx, err)
+	}
+
+	target := previousBranch(current)
+	if target == "" {
+		fallback, err := diffStatBase(current)
+		if err != nil {
+			return reportError(ctx, fmt.Errorf("no previous branch to switch to"))
+		}
+		// git checkout main creates the tracking branch from origin/main.
+		fallback = strings.TrimPrefix(fallback, "origin/")
+		if fallback == current {
+			return reportError(ctx, fmt.Errorf("no previous branch to switch to"))
+		}
+
+		if !assumeYes {
+			ok, err := confirm(ctx, fmt.Sprintf("No previous branch recorded. Switch to %s instead?", fallback), true)
+			if err != nil {
+				return reportError(ctx, err)
+			}
+			if !ok {
+				fmt.Fprintln(ctx.Stdout(), "Switch cancelled.")
+				return errUserAbort
+			}
+		}
+		target = fallback
+	}
+
+	if err := runGitCommandStreaming(ctx, "checkout", target); err != nil {
+		return reportError(ctx, fmt.Errorf("git checkout %s: %w", target, err))
+	}
+	fmt.Fprintf(ctx.Stdout(), "✔️ Switched from %s to %s\n", current, target)
+	return nil
+}
+
+// previousBranch returns the branch git checkout - would switch to, or the
+// most recent other branch in the reflog when that one is gone or was a
+// detached checkout. It returns "" when there is none.
+func previousBranch(current string) string {
+	out, err := flowCommand("git", "rev-parse", "--abbrev-ref", "@{-1}").Output()
+	if err == nil {
+		name := strings.TrimSpace(string(out))
+		if name != "" && name != current {
+			if exists, _ := gitutil.RefExists(flowCtx, "refs/heads/"+name); exists {
+				return name
+			}
+		}
+	}
+
+	branches, err := recentlyCheckedOutBranches(current)
+	if err != nil || len(branches) == 0 {
+		return ""
+	}
+	return branches[0]
+}
@@ -0,0 +1,222 @@
+package main
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"lang/gitutil"
+
+	"github.com/dzonerzy/go-snap/snap"
+)
+
+// firstReleaseTag is proposed when the repository has no semver tags yet.
+const firstReleaseTag = "v0.1.0"
+
+type semver struct {
+	Prefix     string // "v" or ""
+	Major      int
+	Minor      int
+	Patch      int
+	Prerelease string
+}
+
+// parseSemver accepts MAJOR.MINOR.PATCH with an optional leading "v", an
+// optional -prerelease, and optional +build metadata, which is dropped.
+func parseSemver(tag string) (semver, bool) {
+	var v semver
+	rest := strings.TrimSpace(tag)
+	if after, ok := strings.CutPrefix(rest, "v"); ok {
+		v.Prefix = "v"
+		rest = after
+	}
+	rest, _, _ = strings.Cut(rest, "+")
+	rest, v.Prerelease, _ = strings.Cut(rest, "-")
+
+	parts := strings.Split(rest, ".")
+	if len(parts) != 3 {
+		return semver{}, false
+	}
+	numbers := make([]int, 3)
+	for i, part := range parts {
+		n, err := strconv.Atoi(part)
+		if err != nil || n < 0 || (len(part) > 1 && part[0] == '0') {
+			return semver{}, false
+		}
+		numbers[i] = n
+	}
+	v.Major, v.Minor, v.Patch = numbers[0], numbers[1], numbers[2]
+	return v, true
+}
+
+func (v semver) String() string {
+	s := fmt.Sprintf("%s%d.%d.%d", v.Prefix, v.Major, v.Minor, v.Patch)
+	if v.Prerelease != "" {
+		s += "-" + v.Prerelease
+	}
+	return s
+}
+
+// less orders versions by precedence: a prerelease comes before its release,
+// and prereleases of the same version compare as strings.
+func (v semver) less(other semver) bool {
+	if v.Major != other.Major {
+		return v.Major < other.Major
+	}
+	if v.Minor != other.Minor {
+		return v.Minor < other.Minor
+	}
+	if v.Patch != other.Patch {
+		return v.Patch < other.Patch
+	}
+	if (v.Prerelease == "") != (other.Prerelease == "") {
+		return v.Prerelease != ""
+	}
+	return v.Prerelease < other.Prerelease
+}
+
+// bump returns the next release for part (major, minor, or patch). A
+// prerelease is released as is when it already sits at that level, so
+// v1.2.0-rc.1 bumps to v1.2.0 for minor or patch and v2.0.0-rc.1 to v2.0.0.
+func (v semver) bump(part string) semver {
+	next := semver{Prefix: v.Prefix, Major: v.Major, Minor: v.Minor, Patch: v.Patch}
+	pre := v.Prerelease != ""
+	switch part {
+	case "major":
+		if !pre || v.Minor != 0 || v.Patch != 0 {
+			next.Major, next.Minor, next.Patch = v.Major+1, 0, 0
+		}
+	case "minor":
+		if !pre || v.Patch != 0 {
+			next.Minor, next.Patch = v.Minor+1, 0
+		}
+	default:
+		if !pre {
+			next.Patch = v.Patch + 1
+		}
+	}
+	return next
+}
+
+func runGitTag(ctx *snap.Context) error {
+	usage := func() {
+		fmt.Fprintf(ctx.Stderr(), "Usage: %s gitTag [--patch|--minor|--major] [-m <message>] [--push] [--yes]\n", commandName)
+	}
+
+	part := "patch"
+	message := ""
+	push := false
+	assumeYes := false
+	for i := 0; i < ctx.NArgs(); i++ {
+		arg := strings.TrimSpace(ctx.Arg(i))
+		switch arg {
+		case "":
+		case "--patch", "--minor", "--major":
+			part = strings.TrimPrefix(arg, "--")
+		case "--push":
+			push = true
+		case "--yes", "-y":
+			assumeYes = true
+		case "-m", "--message":
+			if i+1 >= ctx.NArgs() || strings.TrimSpace(ctx.Arg(i+1)) == "" {
+				usage()
+				return fmt.Errorf("%s requires a message", arg)
+			}
+			i++
+			message = strings.TrimSpace(ctx.Arg(i))
+		default:
+			usage()
+			return fmt.Errorf("unexpected argument %q", arg)
+		}
+	}
+
+	if err := gitutil.EnsureRepository(flowCtx); err != nil {
+		return err
+	}
+
+	dirty, err := gitWorkingTreeDirty()
+	if err != nil {
+		return reportError(ctx, err)
+	}
+	if len(dirty) > 0 {
+		return reportError(ctx, fmt.Errorf("working tree has %d uncommitted change(s); commit or stash them before tagging", len(dirty)))
+	}
+
+	latest, found, err := latestSemverTag()
+	if err != nil {
+		return reportError(ctx, err)
+	}
+
+	var next semver
+	if found {
+		next = latest.bump(part)
+		fmt.Fprintf(ctx.Stdout(), "Latest tag: %s\n", latest)
+	} else {
+		next, _ = parseSemver(firstReleaseTag)
+		fmt.Fprintln(ctx.Stdout(), "No semver tags yet.")
+	}
+	tag := next.String()
+	if exists, _ := gitutil.RefExists(flowCtx, "refs/tags/"+tag); exists {
+		return reportError(ctx, fmt.Errorf("tag %s already exists", tag))
+	}
+	if message == "" {
+		message = "Release " + tag
+	}
+
+	head, err := flowCommand("git", "rev-parse", "--short", "HEAD").Output()
+	if err != nil {
+		return reportError(ctx, fmt.Errorf("git rev-parse HEAD: %w", err))
+	}
+
+	if !assumeYes {
+		ok, err := confirm(ctx, fmt.Sprintf("Create tag %s at %s?", tag, strings.TrimSpace(string(head))), true)
+		if err != nil {
+			return reportError(ctx, err)
+		}
+		if !ok {
+			fmt.Fprintln(ctx.Stdout(), "Tag cancelled.")
+			return errUserAbort
+		}
+	}
+
+	if err := runGitCommandStreaming(ctx, "tag", "-a", tag, "-m", message); err != nil {
+		return reportError(ctx, fmt.Errorf("git tag %s: %w", tag, err))
+	}
+	fmt.Fprintf(ctx.Stdout(), "✔️ Tagged %s\n", tag)
+
+	if !push {
+		return nil
+	}
+	remotes, err := gitutil.Remotes(flowCtx)
+	if err != nil {
+		return reportError(ctx, err)
+	}
+	remote, err := selectGitRemote(remotes, "")
+	if err != nil {
+		return reportError(ctx, err)
+	}
+	if err := runGitCommandStreaming(ctx, "push", remote, tag); err != nil {
+		return reportError(ctx, fmt.Errorf("git push %s %s: %w", remote, tag, err))
+	}
+	fmt.Fprintf(ctx.Stdout(), "✔️ Pushed %s to %s\n", tag, remote)
+	return nil
+}
+
+// latestSemverTag returns the highest tag that parses as semver. git's
+// version sort puts v1.2.0-rc.1 after v1.2.0 unless versionsort.suffix is
+// set, so the order is only a starting point and semver.less decides.
+func latestSemverTag() (semver, bool, error) {
+	out, err := flowCommand("git", "tag", "--list", "--sort=-v:refname").Output()
+	if err != nil {
+		return semver{}, false, fmt.Errorf("git tag --list: %w", err)
+	}
+
+	var latest semver
+	found := false
+	for _, line := range strings.Split(string(out), "\n") {
+		if v, ok := parseSemver(line); ok && (!found || latest.less(v)) {
+			latest, found = v, true
+		}
+	}
+	return latest, found, nil
+}
@@ -0,0 +1,58 @@
+package main
+
+import "testing"
+
+func TestParseSemver(t *testing.T) {
+	cases := []struct {
+		tag  string
+		want semver
+	}{
+		{"v1.2.3", semver{Prefix: "v", Major: 1, Minor: 2, Patch: 3}},
+		{"0.10.0", semver{Major: 0, Minor: 10, Patch: 0}},
+		{"v2.0.0-rc.1+build.5", semver{Prefix: "v", Major: 2, Prerelease: "rc.1"}},
+	}
+	for _, tc := range cases {
+		got, ok := parseSemver(tc.tag)
+		if !ok || got != tc.want {
+			t.Errorf("parseSemver(%q) = %+v, %v; want %+v", tc.tag, got, ok, tc.want)
+		}
+	}
+
+	for _, tag := range []string{"", "release-1", "v1.2", "v1.2.3.4", "v01.2.3", "v1.x.3"} {
+		if got, ok := parseSemver(tag); ok {
+			t.Errorf("parseSemver(%q) = %+v, want rejection", tag, got)
+		}
+	}
+}
+
+func TestSemverBump(t *testing.T) {
+	cases := []struct {
+		from, part, want string
+	}{
+		{"v1.2.3", "patch", "v1.2.4"},
+		{"v1.2.3", "minor", "v1.3.0"},
+		{"v1.2.3", "major", "v2.0.0"},
+		{"1.2.3", "patch", "1.2.4"},
+		{"v1.3.0-rc.1", "patch", "v1.3.0"},
+		{"v1.3.0-rc.1", "minor", "v1.3.0"},
+		{"v1.3.0-rc.1", "major", "v2.0.0"},
+		{"v2.0.0-beta", "major", "v2.0.0"},
+	}
+	for _, tc := range cases {
+		v, _ := parseSemver(tc.from)
+		if got := v.bump(tc.part).String(); got != tc.want {
+			t.Errorf("%s bump %s = %s, want %s", tc.from, tc.part, got, tc.want)
+		}
+	}
+}
+
+func TestSemverLess(t *testing.T) {
+	ordered := []string{"v0.9.9", "v1.0.0-rc.1", "v1.0.0-rc.2", "v1.0.0", "v1.0.1", "v1.10.0"}
+	for i := 1; i < len(ordered); i++ {
+		a, _ := parseSemver(ordered[i-1])
+		b, _ := parseSemver(ordered[i])
+		if !a.less(b) || b.less(a) {
+			t.Errorf("expected %s < %s", ordered[i-1], ordered[i])
+		}
+	}
+}
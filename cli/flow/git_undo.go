@@ -0,0 +1,170 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	"lang/gitutil"
+
+	"github.com/dzonerzy/go-snap/snap"
+)
+
+type reflogEntry struct {
+	Hash     string
+	Selector string
+	Subject  string
+}
+
+func runGitUndo(ctx *snap.Context) error {
+	usage := func() {
+		fmt.Fprintf(ctx.Stderr(), "Usage: %s gitUndo [--soft|--mixed|--hard] [--yes]\n", commandName)
+	}
+
+	mode := "mixed"
+	assumeYes := false
+	for i := 0; i < ctx.NArgs(); i++ {
+		arg := strings.TrimSpace(ctx.Arg(i))
+		if arg == "" {
+			continue
+		}
+
+		switch arg {
+		case "--soft", "--mixed", "--hard":
+			mode = strings.TrimPrefix(arg, "--")
+		case "--yes", "-y":
+			assumeYes = true
+		default:
+			usage()
+			return fmt.Errorf("unexpected argument %q", arg)
+		}
+	}
+
+	if err := gitutil.EnsureRepository(flowCtx); err != nil {
+		return err
+	}
+
+	entries, err := readHeadReflog(50)
+	if err != nil {
+		return reportError(ctx, err)
+	}
+	if len(entries) < 2 {
+		return reportError(ctx, fmt.Errorf("reflog has no earlier entry to return to"))
+	}
+
+	last := entries[0]
+	target, err := undoTarget(entries)
+	if err != nil {
+		return reportError(ctx, err)
+	}
+
+	targetSubject := gitCommitSubject(target.Hash)
+	fmt.Fprintf(ctx.Stdout(), "Last operation: %s\n", last.Subject)
+	fmt.Fprintf(ctx.Stdout(), "Reset (%s) to %s %s  %s\n", mode, target.Selector, shortHash(target.Hash), targetSubject)
+
+	if out, err := flowCommand("git", "log", "--oneline", target.Hash+"..HEAD").Output(); err == nil {
+		if dropped := strings.TrimSpace(string(out)); dropped != "" {
+			fmt.Fprintln(ctx.Stdout())
+			fmt.Fprintln(ctx.Stdout(), "Commits leaving the branch:")
+			for _, line := range strings.Split(dropped, "\n") {
+				fmt.Fprintf(ctx.Stdout(), "  %s\n", line)
+			}
+		}
+	}
+	if out, err := flowCommand("git", "diff", "--stat", target.Hash, "HEAD").Output(); err == nil {
+		if stat := strings.TrimRight(string(out), "\n"); stat != "" {
+			fmt.Fprintln(ctx.Stdout())
+			fmt.Fprintln(ctx.Stdout(), "Changes being undone:")
+			fmt.Fprintln(ctx.Stdout(), stat)
+		}
+	}
+
+	if mode == "hard" {
+		dirty, err := gitWorkingTreeDirty()
+		if err != nil {
+			return reportError(ctx, err)
+		}
+		if len(dirty) > 0 {
+			fmt.Fprintln(ctx.Stdout())
+			fmt.Fprintf(ctx.Stdout(), "ℹ️ --hard also discards %d uncommitted change(s) in the working tree\n", len(dirty))
+		}
+	}
+
+	// --hard is destructive, so it always asks even with --yes.
+	if !assumeYes || mode == "hard" {
+		fmt.Fprintln(ctx.Stdout())
+		ok, err := confirm(ctx, fmt.Sprintf("Proceed with git reset --%s %s?", mode, shortHash(target.Hash)), false)
+		if err != nil {
+			return reportError(ctx, err)
+		}
+		if !ok {
+			fmt.Fprintln(ctx.Stdout(), "Undo cancelled.")
+			return errUserAbort
+		}
+	}
+
+	if err := runGitCommandStreaming(ctx, "reset", "--"+mode, target.Hash); err != nil {
+		return reportError(ctx, fmt.Errorf("git reset --%s %s: %w", mode, target.Hash, err))
+	}
+
+	fmt.Fprintf(ctx.Stdout(), "✔️ Undid %q; HEAD is now %s %s\n", last.Subject, shortHash(target.Hash), targetSubject)
+	fmt.Fprintf(ctx.Stdout(), "ℹ️ Changed your mind? Run: git reset --%s %s\n", mode, shortHash(last.Hash))
+	return nil
+}
+
+func readHeadReflog(limit int) ([]reflogEntry, error) {
+	out, err := flowCommand("git", "reflog", "show", fmt.Sprintf("-n%d", limit), "--format=%H%x09%gd%x09%gs", "HEAD").Output()
+	if err != nil {
+		return nil, fmt.Errorf("git reflog: %w", err)
+	}
+
+	var entries []reflogEntry
+	for _, line := range strings.Split(strings.TrimSpace(string(out)), "\n") {
+		parts := strings.SplitN(line, "\t", 3)
+		if len(parts) != 3 {
+			continue
+		}
+		entries = append(entries, reflogEntry{Hash: parts[0], Selector: parts[1], Subject: parts[2]})
+	}
+	return entries, nil
+}
+
+// undoTarget picks the reflog entry from before the most recent operation.
+// A rebase writes one entry per replayed commit, so it walks back to the
+// entry preceding "rebase (start)".
+func undoTarget(entries []reflogEntry) (reflogEntry, error) {
+	last := entries[0]
+	action, _, _ := strings.Cut(last.Subject, ":")
+
+	switch {
+	case strings.HasPrefix(action, "rebase"):
+		for i, entry := range entries {
+			entryAction, _, _ := strings.Cut(entry.Subject, ":")
+			if strings.HasPrefix(entryAction, "rebase") && strings.Contains(entryAction, "(start)") {
+				if i+1 < len(entries) {
+					return entries[i+1], nil
+				}
+				break
+			}
+		}
+		return reflogEntry{}, fmt.Errorf("could not find where the last rebase started in the reflog")
+	case strings.HasPrefix(action, "checkout"):
+		return reflogEntry{}, fmt.Errorf("last operation was a checkout (%s); switch back with git checkout - instead", last.Subject)
+	}
+
+	return entries[1], nil
+}
+
+func gitCommitSubject(hash string) string {
+	out, err := flowCommand("git", "log", "-1", "--format=%s", hash).Output()
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSpace(string(out))
+}
+
+func shortHash(hash string) string {
+	if len(hash) > 7 {
+		return hash[:7]
+	}
+	return hash
+}
@@ -46,6 +46,7 @@ require (
 	github.com/tidwall/pretty v1.2.1 // indirect
 	github.com/tidwall/sjson v1.2.5 // indirect
 	gopkg.in/yaml.v3 v3.0.1
+	lang v0.0.0
 )
 
 replace golang.org/x/sys => github.com/golang/sys v0.30.0
@@ -67,3 +68,5 @@ replace golang.org/x/crypto => github.com/golang/crypto v0.23.0
 replace golang.org/x/mod => github.com/golang/mod v0.15.0
 
 replace github.com/severity1/claude-code-sdk-go => /Users/nikiv/fork-i/severity1/claude-code-sdk-go
+
+replace lang => ../..
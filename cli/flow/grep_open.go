@@ -0,0 +1,185 @@
+package main
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/dzonerzy/go-snap/snap"
+	"github.com/ktr0731/go-fuzzyfinder"
+)
+
+func runGrepOpen(ctx *snap.Context) error {
+	usage := func() {
+		fmt.Fprintf(ctx.Stderr(), "Usage: %s grepOpen <query> [doc-type]\n", commandName)
+		fmt.Fprintf(ctx.Stderr(), "Available doc types: %s\n", strings.Join(availableDocKeys(), ", "))
+	}
+
+	var query, docType string
+	for i := 0; i < ctx.NArgs(); i++ {
+		arg := ctx.Arg(i)
+		switch {
+		case strings.TrimSpace(arg) == "":
+		case query == "":
+			query = arg
+		case docType == "":
+			docType = strings.TrimSpace(arg)
+		default:
+			usage()
+			return reportError(ctx, fmt.Errorf("unexpected argument %q", arg))
+		}
+	}
+	if query == "" {
+		usage()
+		return reportError(ctx, fmt.Errorf("search query is required"))
+	}
+
+	keys := availableDocKeys()
+	if docType != "" {
+		if _, ok := resolveDocSpec(docType); !ok {
+			usage()
+			return reportError(ctx, fmt.Errorf("unknown doc type %q", docType))
+		}
+		keys = []string{docType}
+	}
+
+	var dirs []string
+	seen := make(map[string]bool)
+	for _, key := range keys {
+		spec, _ := resolveDocSpec(key)
+		dir, err := docDir(spec)
+		if err != nil {
+			return reportError(ctx, err)
+		}
+		if !seen[dir] {
+			seen[dir] = true
+			dirs = append(dirs, dir)
+		}
+	}
+
+	matches, err := grepDocs(dirs, query)
+	if err != nil {
+		return reportError(ctx, err)
+	}
+	if len(matches) == 0 {
+		fmt.Fprintf(ctx.Stdout(), "No docs mention %q\n", query)
+		return nil
+	}
+
+	selected := matches[0]
+	if len(matches) > 1 {
+		fileCache := make(map[string][]string)
+		idx, err := fuzzyfinder.Find(
+			matches,
+			func(i int) string {
+				m := matches[i]
+				return fmt.Sprintf("%s:%d: %s", docLabel(m.Path), m.Line, strings.TrimSpace(m.Text))
+			},
+			fuzzyfinder.WithPromptString("grepOpen> "),
+			fuzzyfinder.WithPreviewWindow(func(i, width, height int) string {
+				if i < 0 || i >= len(matches) {
+					return ""
+				}
+				return searchMatchPreview(matches[i], fileCache, height)
+			}),
+		)
+		if err != nil {
+			if errors.Is(err, fuzzyfinder.ErrAbort) {
+				return errUserAbort
+			}
+			return reportError(ctx, fmt.Errorf("select match: %w", err))
+		}
+		selected = matches[idx]
+	}
+
+	if err := openInEditorAtLine(ctx, selected.Path, selected.Line); err != nil {
+		return reportError(ctx, err)
+	}
+	fmt.Fprintf(ctx.Stdout(), "✔️ Opened %s:%d in %s\n", selected.Path, selected.Line, editorDisplayName())
+	return nil
+}
+
+// grepDocs scans the .md and .mdx files under dirs for lines containing
+// query, ignoring case. Newer docs come first; missing dirs are skipped.
+func grepDocs(dirs []string, query string) ([]searchMatch, error) {
+	type docFile struct {
+		path    string
+		modTime int64
+	}
+	var files []docFile
+	for _, dir := range dirs {
+		err := filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+			if err != nil {
+				if path == dir && errors.Is(err, fs.ErrNotExist) {
+					return filepath.SkipDir
+				}
+				return err
+			}
+			if d.IsDir() {
+				if path != dir && shouldSkip(d.Name()) {
+					return filepath.SkipDir
+				}
+				return nil
+			}
+			if ext := strings.ToLower(filepath.Ext(path)); ext != ".md" && ext != ".mdx" {
+				return nil
+			}
+			info, err := d.Info()
+			if err != nil {
+				return err
+			}
+			files = append(files, docFile{path: path, modTime: info.ModTime().UnixNano()})
+			return nil
+		})
+		if err != nil {
+			return nil, fmt.Errorf("walk %s: %w", dir, err)
+		}
+	}
+	sort.SliceStable(files, func(i, j int) bool { return files[i].modTime > files[j].modTime })
+
+	needle := strings.ToLower(query)
+	var matches []searchMatch
+	for _, file := range files {
+		found, err := grepFile(file.path, needle)
+		if err != nil {
+			return nil, err
+		}
+		matches = append(matches, found...)
+		if len(matches) >= searchMatchLimit {
+			return matches[:searchMatchLimit], nil
+		}
+	}
+	return matches, nil
+}
+
+func grepFile(path, needle string) ([]searchMatch, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var matches []searchMatch
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 0, 64*1024), 4*1024*1024)
+	for line := 1; scanner.Scan(); line++ {
+		text := scanner.Text()
+		if strings.Contains(strings.ToLower(text), needle) {
+			matches = append(matches, searchMatch{Path: path, Line: line, Text: text})
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("read %s: %w", path, err)
+	}
+	return matches, nil
+}
+
+// docLabel shortens a doc path to its type and file name, e.g. log/25-oct.mdx.
+func docLabel(path string) string {
+	return filepath.Join(filepath.Base(filepath.Dir(path)), filepath.Base(path))
+}
@@ -11,17 +11,30 @@ import (
 	"io"
 	"io/fs"
 	"math/rand"
+	"net"
+	"net/http"
 	"net/url"
 	"os"
 	"os/exec"
 	"path/filepath"
+	"regexp"
+	"runtime"
 	"sort"
 	"strconv"
 	"strings"
+	"sync"
 	"syscall"
 	"time"
 	"unicode"
 
+	"lang/buildinfo"
+	"lang/cmdlog"
+	"lang/exitcode"
+	"lang/ghref"
+	"lang/gitutil"
+	"lang/ports"
+	"lang/skipdirs"
+
 	"github.com/dzonerzy/go-snap/snap"
 	fzf "github.com/junegunn/fzf/src"
 	fzfutil "github.com/junegunn/fzf/src/util"
@@ -29,7 +42,6 @@ import (
 	openai "github.com/openai/openai-go"
 	"github.com/openai/openai-go/option"
 	"github.com/openai/openai-go/shared"
-	claudecode "github.com/severity1/claude-code-sdk-go"
 	_ "modernc.org/sqlite"
 )
 
@@ -43,7 +55,19 @@ const (
 	commitModelName          = "gpt-5-nano"
 	maxCommitDiffRunes       = 12000
 	openAIAPIKeyEnv          = "OPENAI_API_KEY"
+	commitModelEnv           = "FLOW_COMMIT_MODEL"
+	commitStagedOnlyEnv      = "FLOW_COMMIT_STAGED_ONLY"
+	workspaceFileEnv         = "FLOW_WORKSPACE_FILE"
+	youtubeCookiesBrowserEnv = "FLOW_YOUTUBE_COOKIES_BROWSER"
+	openAIMaxAttemptsEnv     = "FLOW_OPENAI_MAX_ATTEMPTS"
+	openAIRetryDelayEnv      = "FLOW_OPENAI_RETRY_DELAY"
+	defaultOpenAIMaxAttempts = 3
+	maxOpenAIMaxAttempts     = 10
+	defaultOpenAIRetryDelay  = time.Second
+	maxOpenAIRetryDelay      = 30 * time.Second
+	openAIRequestTimeout     = 45 * time.Second
 	windowFocusDBEnv         = "FLOW_WINDOW_FOCUS_DB"
+	flowEditorEnv            = "FLOW_EDITOR"
 	defaultWindowFocusDBPath = "/Users/nikiv/Library/Application Support/1focus/window-focus.db"
 )
 
@@ -255,6 +279,18 @@ type windowFocusEntry struct {
 	FocusedAt     int64
 }
 
+// FocusedAtTime converts the stored epoch into local time. Values too large to
+// be seconds are treated as milliseconds.
+func (e *windowFocusEntry) FocusedAtTime() time.Time {
+	if e == nil || e.FocusedAt <= 0 {
+		return time.Time{}
+	}
+	if e.FocusedAt >= 1e12 {
+		return time.UnixMilli(e.FocusedAt).Local()
+	}
+	return time.Unix(e.FocusedAt, 0).Local()
+}
+
 func (e *windowFocusEntry) cursorOpenPath() string {
 	if e == nil {
 		return ""
@@ -298,6 +334,10 @@ func main() {
 		return runDeploy(ctx)
 	})
 
+	registerCommand(app, "release", "Build a Go CLI with version ldflags and install it into ~/bin", func(ctx *snap.Context) error {
+		return runRelease(ctx)
+	})
+
 	registerCommand(app, "commit", "Generate a commit message with GPT-5 nano and create the commit", func(ctx *snap.Context) error {
 		return runCommit(ctx)
 	})
@@ -310,10 +350,30 @@ func main() {
 		return runCommitReviewAndPush(ctx)
 	})
 
+	registerCommand(app, "commitAll", "Commit each changed file separately with its own generated message", func(ctx *snap.Context) error {
+		return runCommitAll(ctx)
+	})
+
+	registerCommand(app, "gitAmend", "Amend the last commit, optionally regenerating its message with AI", func(ctx *snap.Context) error {
+		return runGitAmend(ctx)
+	})
+
+	registerCommand(app, "gitFixup", "Commit the current changes as a fixup of a picked commit and optionally autosquash it", func(ctx *snap.Context) error {
+		return runGitFixup(ctx)
+	})
+
 	registerCommand(app, "branchFromClipboard", "Create a git branch from the clipboard name", func(ctx *snap.Context) error {
 		return runBranchFromClipboard(ctx)
 	})
 
+	registerCommand(app, "branchRename", "Rename the current branch and move its remote branch too", func(ctx *snap.Context) error {
+		return runBranchRename(ctx)
+	})
+
+	registerCommand(app, "clipboard", "Print the clipboard with optional transforms, or set it with --write", func(ctx *snap.Context) error {
+		return runClipboard(ctx)
+	})
+
 	registerCommand(app, "clone", "Clone a GitHub repository into ~/gh/<owner>/<repo>", func(ctx *snap.Context) error {
 		return runClone(ctx)
 	})
@@ -334,10 +394,22 @@ func main() {
 		return runGitCheckoutRemote(ctx)
 	})
 
+	registerCommand(app, "recentBranches", "Fuzzy-pick a branch you recently had checked out and switch to it", func(ctx *snap.Context) error {
+		return runRecentBranches(ctx)
+	})
+
+	registerCommand(app, "gitSwitchLast", "Switch back to the previously checked-out branch", func(ctx *snap.Context) error {
+		return runGitSwitchLast(ctx)
+	})
+
 	registerCommand(app, "killPort", "Kill a process by the port it listens on, optionally with fuzzy finder", func(ctx *snap.Context) error {
 		return runKillPort(ctx)
 	})
 
+	registerCommand(app, "envPort", "Print the next free TCP port (or several) starting from a port", func(ctx *snap.Context) error {
+		return runEnvPort(ctx)
+	})
+
 	registerCommand(app, "checkPort", "Show what process is running on a given port", func(ctx *snap.Context) error {
 		return runCheckPort(ctx)
 	})
@@ -406,14 +478,74 @@ func main() {
 		return runGitMirror(ctx)
 	})
 
+	registerCommand(app, "gitUndo", "Undo the last commit, merge, or rebase by resetting to the prior reflog entry", func(ctx *snap.Context) error {
+		return runGitUndo(ctx)
+	})
+
+	registerCommand(app, "gitTag", "Create the next semver tag (patch, minor, or major) and optionally push it", func(ctx *snap.Context) error {
+		return runGitTag(ctx)
+	})
+
+	registerCommand(app, "gitConfigFix", "Apply recommended local git config to the current repo", func(ctx *snap.Context) error {
+		return runGitConfigFix(ctx)
+	})
+
+	registerCommand(app, "pushForce", "Force-push the current branch with --force-with-lease after a confirmation", func(ctx *snap.Context) error {
+		return runPushForce(ctx)
+	})
+
+	registerCommand(app, "gitBlameRange", "Summarize who wrote a range of lines in a file", func(ctx *snap.Context) error {
+		return runGitBlameRange(ctx)
+	})
+
+	registerCommand(app, "gitReflame", "Find the commits that added or removed a piece of text in a file", func(ctx *snap.Context) error {
+		return runGitReflame(ctx)
+	})
+
+	registerCommand(app, "gitStashPick", "Fuzzy-pick a stash with a diff preview and apply, pop, or drop it", func(ctx *snap.Context) error {
+		return runGitStashPick(ctx)
+	})
+
+	registerCommand(app, "gitLog", "Fuzzy-pick a commit from git log with a full preview and print or copy its SHA", func(ctx *snap.Context) error {
+		return runGitLog(ctx)
+	})
+
+	registerCommand(app, "gitAuthors", "Rank contributors by commit count from git shortlog", func(ctx *snap.Context) error {
+		return runGitAuthors(ctx)
+	})
+
+	registerCommand(app, "sizeReport", "Show the largest blobs in git history and files in the working tree", func(ctx *snap.Context) error {
+		return runSizeReport(ctx)
+	})
+
+	registerCommand(app, "gitResolve", "Resolve merge/rebase/cherry-pick conflicts with AI and stage them", func(ctx *snap.Context) error {
+		return runGitResolve(ctx)
+	})
+
+	registerCommand(app, "gitInteractiveRebase", "Select commits since the base branch and squash them with an optional AI message", func(ctx *snap.Context) error {
+		return runGitInteractiveRebase(ctx)
+	})
+
+	registerCommand(app, "diffStat", "Show files changed and line totals on the current branch since its base", func(ctx *snap.Context) error {
+		return runDiffStat(ctx)
+	})
+
 	registerCommand(app, "youtubeToSound", "Download audio into ~/.flow/youtube-sound using yt-dlp", func(ctx *snap.Context) error {
 		return runYoutubeToSound(ctx)
 	})
 
+	registerCommand(app, "youtubeList", "List audio downloaded by youtubeToSound", func(ctx *snap.Context) error {
+		return runYoutubeList(ctx)
+	})
+
 	registerCommand(app, "spotifyPlay", "Start playing a Spotify track from a URL or ID", func(ctx *snap.Context) error {
 		return runSpotifyPlay(ctx)
 	})
 
+	registerCommand(app, "spotifySearch", "Search Spotify by name and play the selection", func(ctx *snap.Context) error {
+		return runSpotifySearch(ctx)
+	})
+
 	registerCommand(app, "openDoc", "Open a doc type by key (metrics, changes, log, looking-back)", func(ctx *snap.Context) error {
 		return runOpenDoc(ctx)
 	})
@@ -434,26 +566,93 @@ func main() {
 		return runOpenLookingBack(ctx)
 	})
 
+	registerCommand(app, "grepOpen", "Search the monthly docs for text and open the matching doc at that line", func(ctx *snap.Context) error {
+		return runGrepOpen(ctx)
+	})
+
+	registerCommand(app, "notes", "Append a timestamped line to today's note, or open it with --open", func(ctx *snap.Context) error {
+		return runNotes(ctx)
+	})
+
+	registerCommand(app, "timer", "Count down a duration and notify when it is done (pomodoro)", func(ctx *snap.Context) error {
+		return runTimer(ctx)
+	})
+
 	registerCommand(app, "openSqlite", "Select a .sqlite file in the current tree and open it in TablePlus", func(ctx *snap.Context) error {
 		return runOpenSqlite(ctx)
 	})
 
+	registerCommand(app, "open", "Fuzzy-find a file in the current tree and open it in your editor", func(ctx *snap.Context) error {
+		return runOpen(ctx)
+	})
+
+	registerCommand(app, "search", "Search code with ripgrep, fuzzy-pick a match, and open it at that line", func(ctx *snap.Context) error {
+		return runSearch(ctx)
+	})
+
 	registerCommand(app, "focusCursorWindow", "Focus the latest Cursor window recorded in window_focus", func(ctx *snap.Context) error {
 		return runFocusCursorWindow(ctx)
 	})
 
+	registerCommand(app, "recentWorkspaces", "List the most recently focused workspaces recorded in window_focus", func(ctx *snap.Context) error {
+		return runRecentWorkspaces(ctx)
+	})
+
+	registerCommand(app, "dockerlayers", "Explain the layers, cache behaviour, and easy wins in a Dockerfile", func(ctx *snap.Context) error {
+		return runDockerLayers(ctx)
+	})
+
+	registerCommand(app, "openBrowserTabs", "Pick GitHub repo/PR tabs from the front browser window and clone them", func(ctx *snap.Context) error {
+		return runOpenBrowserTabs(ctx)
+	})
+
 	registerCommand(app, "prDiff", "Fetch a GitHub PR diff and details for AI context", func(ctx *snap.Context) error {
 		return runPRDiff(ctx)
 	})
 
+	registerCommand(app, "explainDiff", "Explain the current git diff or a commit range in plain English", func(ctx *snap.Context) error {
+		return runExplainDiff(ctx)
+	})
+
+	registerCommand(app, "prReview", "Generate a first-pass AI review of a GitHub PR and optionally post it", func(ctx *snap.Context) error {
+		return runPRReview(ctx)
+	})
+
+	registerCommand(app, "config", "View and set fgo settings stored in ~/.flow/config.toml", func(ctx *snap.Context) error {
+		return runConfig(ctx)
+	})
+
+	registerCommand(app, "doctor", "Check which external tools fgo commands depend on are installed", func(ctx *snap.Context) error {
+		return runDoctor(ctx)
+	})
+
+	registerCommand(app, "which", "Show the external tools a command uses and where they resolve", func(ctx *snap.Context) error {
+		return runWhich(ctx)
+	})
+
+	registerCommand(app, "alias", "Define shortcuts for fgo commands in ~/.flow/aliases.toml", func(ctx *snap.Context) error {
+		return runAlias(ctx)
+	})
+
 	registerCommand(app, "version", "Reports the current version of fgo", func(ctx *snap.Context) error {
-		fmt.Fprintln(ctx.Stdout(), flowVersion)
-		return nil
+		return runVersion(ctx)
 	})
 
+	globalArgs, plain := plainOutputFromArgs(os.Args[1:])
+	os.Args = append(os.Args[:1], cmdlog.EnableFromArgs(globalArgs)...)
+	var stdout io.Writer = os.Stdout
+	if plain {
+		stdout = plainWriter{os.Stdout}
+		stderr = plainWriter{os.Stderr}
+		app.IO().WithOut(stdout).WithErr(stderr)
+		cmdlog.Log = plainWriter{cmdlog.Log}
+	}
+
+	registerAliases(stderr)
+
 	if len(os.Args) == 1 {
 		if newArgs, exitCode, err := selectCommandArgs(); err != nil {
-			fmt.Fprintf(os.Stderr, "%s: %v\n", commandName, err)
+			fmt.Fprintf(stderr, "%s: %v\n", commandName, err)
 		} else if exitCode == -1 {
 			// Fall through to help output
 		} else if len(newArgs) == 0 {
@@ -466,18 +665,32 @@ func main() {
 		}
 	}
 
+	os.Args = append(os.Args[:1], expandCommandAlias(os.Args[1:])...)
+
 	args := os.Args[1:]
-	if handled := handleTopLevel(args, os.Stdout); handled {
+	if handled := handleTopLevel(args, stdout); handled {
 		return
 	}
 
-	app.RunAndExit()
+	runCtx, stop := newRunContext(runTimeout(args))
+	flowCtx = runCtx
+	code := app.RunAndGetExitCode()
+	stop()
+	os.Exit(code)
 }
 
 func registerCommand(app *snap.App, name, description string, action snap.ActionFunc) {
 	commandCatalog = append(commandCatalog, commandInfo{name: name, description: description})
+	// Commands parse their own flags, so forward everything as positional args.
 	app.Command(name, description).
-		Action(action)
+		RestArgs().
+		Action(func(ctx *snap.Context) error {
+			if err := action(ctx.WithContext(flowCtx)); err != nil {
+				return exitcode.Wrap(withRunCause(err))
+			}
+			recordCommandUsage(name)
+			return nil
+		})
 }
 
 func selectCommandArgs() ([]string, int, error) {
@@ -512,8 +725,13 @@ func selectCommandArgs() ([]string, int, error) {
 		}
 	}
 
+	// Ordering is a convenience, so an unreadable usage file just means
+	// registration order.
+	usage, _ := loadCommandUsage()
+	entries := commandsByUsage(commandCatalog, usage, time.Now())
+
 	go func() {
-		for _, entry := range commandCatalog {
+		for _, entry := range entries {
 			line := fmt.Sprintf("%s\t%s", entry.name, entry.description)
 			input <- line
 		}
@@ -556,6 +774,14 @@ func handleTopLevel(args []string, out io.Writer) bool {
 	case "--version":
 		fmt.Fprintln(out, flowVersion)
 		return true
+	case "--reset-usage":
+		path, err := resetCommandUsage()
+		if err != nil {
+			fmt.Fprintln(stderr, err.Error())
+			os.Exit(1)
+		}
+		fmt.Fprintf(out, "✔️ Cleared command palette usage history (%s)\n", path)
+		return true
 	case "help":
 		if len(args) == 1 {
 			printRootHelp(out)
@@ -583,6 +809,16 @@ func handleTopLevel(args []string, out io.Writer) bool {
 }
 
 func printCommandHelp(name string, out io.Writer) bool {
+	if alias, ok := findCommandAlias(name); ok {
+		fmt.Fprintf(out, "%s is an alias for `%s %s`.\n\n", alias.Name, commandName, alias.Expansion())
+		name = alias.Command
+	}
+
+	if spec, ok := docSpecForCommand(name); ok {
+		printDocCommandHelp(out, spec.description, name)
+		return true
+	}
+
 	switch name {
 	case "updateGoVersion":
 		fmt.Fprintln(out, "Upgrade Go using the workspace script")
@@ -595,30 +831,102 @@ func printCommandHelp(name string, out io.Writer) bool {
 		fmt.Fprintln(out)
 		fmt.Fprintln(out, "Usage:")
 		fmt.Fprintf(out, "  %s deploy\n", commandName)
+		fmt.Fprintln(out)
+		fmt.Fprintln(out, "Passes LDFLAGS, BUILD_TIME, and GIT_COMMIT to the task as variables and environment")
+		fmt.Fprintln(out, "variables, and warns when the deploy task never uses them.")
+		return true
+	case "release":
+		fmt.Fprintln(out, "Build a Go CLI with version ldflags and install it into ~/bin")
+		fmt.Fprintln(out)
+		fmt.Fprintln(out, "Usage:")
+		fmt.Fprintf(out, "  %s release [--name <binary>] [--pkg <path>] [--dir <install-dir>]\n", commandName)
+		fmt.Fprintln(out)
+		fmt.Fprintf(out, "Runs go build on --pkg (default .) into --dir (default %s) as --name (default the\n", flowInstallDir)
+		fmt.Fprintln(out, "package directory's name), setting main.buildTime and main.gitCommit, then prints")
+		fmt.Fprintln(out, "the installed path and the binary's version output.")
 		return true
 	case "commit":
 		fmt.Fprintln(out, "Generate a commit message with GPT-5 nano and create the commit")
 		fmt.Fprintln(out)
 		fmt.Fprintln(out, "Usage:")
-		fmt.Fprintf(out, "  %s commit\n", commandName)
+		fmt.Fprintf(out, "  %s commit %s\n", commandName, commitFlagsUsage)
+		printCommitFlagsHelp(out)
 		return true
 	case "commitPush":
 		fmt.Fprintln(out, "Generate a commit message, commit, and push to the default remote")
 		fmt.Fprintln(out)
 		fmt.Fprintln(out, "Usage:")
-		fmt.Fprintf(out, "  %s commitPush\n", commandName)
+		fmt.Fprintf(out, "  %s commitPush %s\n", commandName, commitFlagsUsage)
+		printCommitFlagsHelp(out)
 		return true
 	case "commitReviewAndPush":
 		fmt.Fprintln(out, "Generate a commit message, review it interactively, commit, and push")
 		fmt.Fprintln(out)
 		fmt.Fprintln(out, "Usage:")
-		fmt.Fprintf(out, "  %s commitReviewAndPush\n", commandName)
+		fmt.Fprintf(out, "  %s commitReviewAndPush [--yes] %s\n", commandName, commitFlagsUsage)
+		fmt.Fprintln(out)
+		fmt.Fprintln(out, "The review reads a single key from the terminal. When stdin is not a terminal, as in scripts")
+		fmt.Fprintln(out, "and CI, the command stops before generating a message unless --yes (-y) is given, which")
+		fmt.Fprintln(out, "commits and pushes the proposed message without review.")
+		printCommitFlagsHelp(out)
+		return true
+	case "commitAll":
+		fmt.Fprintln(out, "Commit each changed file on its own, with a message generated from that file's diff")
+		fmt.Fprintln(out)
+		fmt.Fprintln(out, "Usage:")
+		fmt.Fprintf(out, "  %s commitAll %s\n", commandName, commitAllUsage)
+		fmt.Fprintln(out)
+		fmt.Fprintln(out, "Walks `git status`, including untracked files, and for every file stages just that file,")
+		fmt.Fprintln(out, "asks the model for a message, and commits it. Anything you had staged before stays staged.")
+		fmt.Fprintln(out, "--dry-run still generates the messages but stages and commits nothing. --sign, --co-author,")
+		fmt.Fprintln(out, "and --ticket-prefix work as they do for commit.")
+		return true
+	case "gitAmend":
+		fmt.Fprintln(out, "Amend the last commit, optionally regenerating its message with AI")
+		fmt.Fprintln(out)
+		fmt.Fprintln(out, "Usage:")
+		fmt.Fprintf(out, "  %s gitAmend [--ai|--no-edit] %s\n", commandName, commitFlagsUsage)
+		fmt.Fprintln(out)
+		fmt.Fprintln(out, "  --ai       Write a new message from the amended commit's full diff")
+		fmt.Fprintln(out, "  --no-edit  Keep the existing message")
+		fmt.Fprintln(out, "Without either, git opens your editor on the current message.")
+		printCommitFlagsHelp(out)
+		return true
+	case "gitFixup":
+		fmt.Fprintln(out, "Commit the current changes as a fixup of a picked commit and optionally autosquash it")
+		fmt.Fprintln(out)
+		fmt.Fprintln(out, "Usage:")
+		fmt.Fprintf(out, "  %s gitFixup [--autosquash] [--all|--patch|--staged-only] [--sign] [-n <limit>] [commit]\n", commandName)
+		fmt.Fprintln(out)
+		fmt.Fprintln(out, "Without a commit, pick one of the newest 50 (or -n) in a fuzzy finder with a preview. The")
+		fmt.Fprintln(out, "changes are staged like fgo commit does, then committed with git commit --fixup. With")
+		fmt.Fprintln(out, "--autosquash, git rebase -i --autosquash folds the fixup into its target without opening an")
+		fmt.Fprintln(out, "editor; unstaged leftovers are stashed around it. Targets already pushed get a warning.")
 		return true
 	case "branchFromClipboard":
 		fmt.Fprintln(out, "Create a git branch from the clipboard name")
 		fmt.Fprintln(out)
 		fmt.Fprintln(out, "Usage:")
-		fmt.Fprintf(out, "  %s branchFromClipboard\n", commandName)
+		fmt.Fprintf(out, "  %s branchFromClipboard [--copy]\n", commandName)
+		fmt.Fprintln(out)
+		fmt.Fprintln(out, "--copy puts the branch name back on the clipboard after switching.")
+		return true
+	case "branchRename":
+		fmt.Fprintln(out, "Rename the current branch and move its remote branch too")
+		fmt.Fprintln(out)
+		fmt.Fprintln(out, "Usage:")
+		fmt.Fprintf(out, "  %s branchRename [new-name] [--yes]\n", commandName)
+		fmt.Fprintln(out)
+		fmt.Fprintln(out, "Runs git branch -m. If the branch tracked a remote branch, it asks before pushing")
+		fmt.Fprintln(out, "the new name, deleting the old remote branch, and pointing upstream at the new one.")
+		return true
+	case "createRepoFromRemote":
+		fmt.Fprintln(out, "Create a GitHub repo based on the current git remote origin")
+		fmt.Fprintln(out)
+		fmt.Fprintln(out, "Usage:")
+		fmt.Fprintf(out, "  %s createRepoFromRemote [--copy]\n", commandName)
+		fmt.Fprintln(out)
+		fmt.Fprintln(out, "--copy puts the repository URL on the clipboard.")
 		return true
 	case "clone":
 		fmt.Fprintln(out, "Clone a GitHub repository into ~/gh/<owner>/<repo>")
@@ -632,13 +940,25 @@ func printCommandHelp(name string, out io.Writer) bool {
 		fmt.Fprintln(out, "Usage:")
 		fmt.Fprintf(out, "  %s cloneAndOpen [github-url]\n", commandName)
 		fmt.Fprintln(out)
-		fmt.Fprintln(out, "Without an argument the command uses the frontmost Safari tab URL.")
+		fmt.Fprintf(out, "Without an argument the command uses the frontmost browser tab URL (%s: safari, chrome, arc, brave; default safari).\n", flowBrowserEnv)
 		return true
 	case "clonePR":
 		fmt.Fprintln(out, "Clone a GitHub pull request into ~/pr/<repo>-pr<num> and check it out")
 		fmt.Fprintln(out)
 		fmt.Fprintln(out, "Usage:")
-		fmt.Fprintf(out, "  %s clonePR <github-pr-url-or-owner/repo#num>\n", commandName)
+		fmt.Fprintf(out, "  %s clonePR <github-pr-url-or-owner/repo#num> [--checkout-base]\n", commandName)
+		fmt.Fprintln(out)
+		fmt.Fprintln(out, "  --checkout-base  Also fetch the PR's base branch so git diff origin/<base>...HEAD works")
+		return true
+	case "openBrowserTabs":
+		fmt.Fprintln(out, "Pick GitHub repo/PR tabs from the front browser window and clone them")
+		fmt.Fprintln(out)
+		fmt.Fprintln(out, "Usage:")
+		fmt.Fprintf(out, "  %s openBrowserTabs\n", commandName)
+		fmt.Fprintln(out)
+		fmt.Fprintf(out, "Reads every tab of the front window of the browser set by %s (default safari),\n", flowBrowserEnv)
+		fmt.Fprintln(out, "lets you multi-select GitHub repositories and pull requests with tab, and clones")
+		fmt.Fprintln(out, "repos into ~/gh/<owner>/<repo> and PRs into ~/pr/<repo>-pr<num>.")
 		return true
 	case "prDiff":
 		fmt.Fprintln(out, "Fetch a GitHub PR diff and details for AI context")
@@ -649,11 +969,32 @@ func printCommandHelp(name string, out io.Writer) bool {
 		fmt.Fprintln(out, "Outputs PR title, description, comments, reviews, and diff as text.")
 		fmt.Fprintln(out, "Use --no-comments to exclude comments and reviews.")
 		return true
+	case "explainDiff":
+		fmt.Fprintln(out, "Explain the current git diff or a commit range in plain English")
+		fmt.Fprintln(out)
+		fmt.Fprintln(out, "Usage:")
+		fmt.Fprintf(out, "  %s explainDiff [--cached | --range <a>..<b>]\n", commandName)
+		fmt.Fprintln(out)
+		fmt.Fprintln(out, "Defaults to unstaged working tree changes. Use --cached for the index or --range")
+		fmt.Fprintln(out, "to explain `git diff a..b`. Handy as a starting point for PR descriptions.")
+		return true
+	case "prReview":
+		fmt.Fprintln(out, "Generate a first-pass AI review of a GitHub PR and optionally post it")
+		fmt.Fprintln(out)
+		fmt.Fprintln(out, "Usage:")
+		fmt.Fprintf(out, "  %s prReview <github-pr-url-or-owner/repo#num> [--post]\n", commandName)
+		fmt.Fprintln(out)
+		fmt.Fprintln(out, "Fetches the diff with gh, asks the model for a summary, concerns, and suggestions,")
+		fmt.Fprintln(out, "and prints the review. Use --post to add it as a PR comment via `gh pr comment`.")
+		return true
 	case "gitCheckout":
 		fmt.Fprintln(out, "Check out a branch from the remote, creating a local tracking branch if needed")
 		fmt.Fprintln(out)
 		fmt.Fprintln(out, "Usage:")
 		fmt.Fprintf(out, "  %s gitCheckout [branch-or-url]\n", commandName)
+		fmt.Fprintln(out)
+		fmt.Fprintln(out, "Accepts a branch, remote/branch, a GitHub /tree/ URL, or a pull request URL, which")
+		fmt.Fprintln(out, "is checked out with gh pr checkout into a local PR branch.")
 		return true
 	case "gitCheckoutRemote":
 		fmt.Fprintln(out, "Fuzzy-search remote branches and switch to one locally")
@@ -661,11 +1002,50 @@ func printCommandHelp(name string, out io.Writer) bool {
 		fmt.Fprintln(out, "Usage:")
 		fmt.Fprintf(out, "  %s gitCheckoutRemote\n", commandName)
 		return true
+	case "recentBranches":
+		fmt.Fprintln(out, "Fuzzy-pick a branch you recently had checked out and switch to it")
+		fmt.Fprintln(out)
+		fmt.Fprintln(out, "Usage:")
+		fmt.Fprintf(out, "  %s recentBranches\n", commandName)
+		fmt.Fprintln(out)
+		fmt.Fprintln(out, "Branches are ordered by the reflog's checkout history, falling back to the")
+		fmt.Fprintln(out, "latest commit date when the reflog has none.")
+		return true
+	case "gitSwitchLast":
+		fmt.Fprintln(out, "Switch back to the previously checked-out branch, like git checkout -")
+		fmt.Fprintln(out)
+		fmt.Fprintln(out, "Usage:")
+		fmt.Fprintf(out, "  %s gitSwitchLast [--yes]\n", commandName)
+		fmt.Fprintln(out)
+		fmt.Fprintln(out, "With no previous branch (e.g. a fresh clone) it offers the default branch;")
+		fmt.Fprintln(out, "--yes switches to it without asking.")
+		return true
 	case "killPort":
 		fmt.Fprintln(out, "Kill a process by the port it listens on, optionally with fuzzy finder")
 		fmt.Fprintln(out)
 		fmt.Fprintln(out, "Usage:")
-		fmt.Fprintf(out, "  %s killPort [port]\n", commandName)
+		fmt.Fprintf(out, "  %s killPort [port] [--name <substr>] [--wait-free [--timeout <duration>]]\n", commandName)
+		fmt.Fprintln(out)
+		fmt.Fprintln(out, "--name keeps processes whose command contains substr (case-insensitive) and can be")
+		fmt.Fprintln(out, "combined with a port. A single match is killed directly; several open the picker.")
+		fmt.Fprintf(out, "--wait-free blocks until the port is released (default timeout %s).\n", defaultKillPortWaitTimeout)
+		return true
+	case "clipboard":
+		fmt.Fprintln(out, "Print the clipboard with optional transforms, or set it with --write")
+		fmt.Fprintln(out)
+		fmt.Fprintln(out, "Usage:")
+		fmt.Fprintf(out, "  %s clipboard [--trim] [--slug] [--json-pretty]\n", commandName)
+		fmt.Fprintf(out, "  %s clipboard --write <text>\n", commandName)
+		fmt.Fprintln(out)
+		fmt.Fprintln(out, "--slug turns the text into a lowercase-hyphenated name; --json-pretty indents valid JSON.")
+		return true
+	case "envPort":
+		fmt.Fprintln(out, "Print the next free TCP port (or several) starting from a port")
+		fmt.Fprintln(out)
+		fmt.Fprintln(out, "Usage:")
+		fmt.Fprintf(out, "  %s envPort [start-port] [--count N]\n", commandName)
+		fmt.Fprintln(out)
+		fmt.Fprintf(out, "Tries to bind each port from start-port (default %d) upward and prints the first free ones.\n", defaultEnvPortStart)
 		return true
 	case "tasks":
 		fmt.Fprintln(out, "List Taskfile tasks with descriptions")
@@ -683,13 +1063,19 @@ func printCommandHelp(name string, out io.Writer) bool {
 		fmt.Fprintln(out, "Clone a public repo into ~/fork-i and create a private fork under your account")
 		fmt.Fprintln(out)
 		fmt.Fprintln(out, "Usage:")
-		fmt.Fprintf(out, "  %s privateForkRepo [github-repo-url]\n", commandName)
+		fmt.Fprintf(out, "  %s privateForkRepo [--cleanup-on-failure] [github-repo-url]\n", commandName)
+		fmt.Fprintln(out)
+		fmt.Fprintln(out, "If a step fails, reports which one and what was left behind; --cleanup-on-failure")
+		fmt.Fprintln(out, "removes the partially set up clone instead.")
 		return true
 	case "privateForkRepoAndOpen":
 		fmt.Fprintln(out, "Clone a public repo into ~/fork-i, create a private fork under your account, and open it in Cursor")
 		fmt.Fprintln(out)
 		fmt.Fprintln(out, "Usage:")
-		fmt.Fprintf(out, "  %s privateForkRepoAndOpen [github-repo-url]\n", commandName)
+		fmt.Fprintf(out, "  %s privateForkRepoAndOpen [--cleanup-on-failure] [github-repo-url]\n", commandName)
+		fmt.Fprintln(out)
+		fmt.Fprintln(out, "If a step fails, reports which one and what was left behind; --cleanup-on-failure")
+		fmt.Fprintln(out, "removes the partially set up clone instead.")
 		return true
 	case "listWindowsOfApp":
 		fmt.Fprintln(out, "Fuzzy-select a running macOS app and print its visible window titles")
@@ -701,23 +1087,30 @@ func printCommandHelp(name string, out io.Writer) bool {
 		fmt.Fprintln(out, "Fuzzy-search executable scripts in ~/config/sh and run them")
 		fmt.Fprintln(out)
 		fmt.Fprintln(out, "Usage:")
-		fmt.Fprintf(out, "  %s shExec\n", commandName)
+		fmt.Fprintf(out, "  %s shExec [--gitignore]\n", commandName)
+		fmt.Fprintln(out)
+		fmt.Fprintln(out, "With --gitignore and ~/config/sh inside a git repository, only scripts git tracks or would")
+		fmt.Fprintln(out, "track are listed, so ignored files stay out of the picker.")
 		return true
 	case "gitFetchUpstream":
 		fmt.Fprintln(out, "Fetch upstream (or all remotes) and prune deleted refs")
 		fmt.Fprintln(out)
 		fmt.Fprintln(out, "Usage:")
-		fmt.Fprintf(out, "  %s gitFetchUpstream [--all] [--no-prune] [remote]\n", commandName)
+		fmt.Fprintf(out, "  %s gitFetchUpstream [--all] [--no-prune] [--tags] [--depth <n>|--unshallow] [remote]\n", commandName)
 		fmt.Fprintln(out)
 		fmt.Fprintln(out, "Defaults to fetching from the upstream remote with pruning.")
+		fmt.Fprintln(out, "Use --tags to fetch all tags, --depth to deepen a shallow clone to n commits,")
+		fmt.Fprintln(out, "or --unshallow to fetch its full history.")
 		return true
 	case "gitSyncFork":
 		fmt.Fprintln(out, "Rebase or merge your local branch with upstream/<branch>")
 		fmt.Fprintln(out)
 		fmt.Fprintln(out, "Usage:")
-		fmt.Fprintf(out, "  %s gitSyncFork [--branch <name>] [--strategy rebase|merge] [--remote <remote>]\n", commandName)
+		fmt.Fprintf(out, "  %s gitSyncFork [--branch <name>] [--strategy rebase|merge] [--remote <remote>] [--autostash]\n", commandName)
 		fmt.Fprintln(out)
 		fmt.Fprintln(out, "Defaults: branch=current (or origin/HEAD), strategy=rebase, remote=upstream.")
+		fmt.Fprintln(out, "Refuses to run on a dirty working tree unless --autostash is passed, and even then when the")
+		fmt.Fprintln(out, "branch to sync is not the one checked out, since switching would carry the changes over.")
 		return true
 	case "gitMirror":
 		fmt.Fprintln(out, "Manage a contributor mirror remote without changing Flow core behavior")
@@ -731,58 +1124,235 @@ func printCommandHelp(name string, out io.Writer) bool {
 		fmt.Fprintln(out, "setup stores the default mirror remote in local git config key fgo.collabRemote.")
 		fmt.Fprintln(out, "take performs `git merge --squash --no-commit <remote>/<branch>` so you can commit as yourself.")
 		return true
+	case "gitUndo":
+		fmt.Fprintln(out, "Undo the last commit, merge, or rebase by resetting to the prior reflog entry")
+		fmt.Fprintln(out)
+		fmt.Fprintln(out, "Usage:")
+		fmt.Fprintf(out, "  %s gitUndo [--soft|--mixed|--hard] [--yes]\n", commandName)
+		fmt.Fprintln(out)
+		fmt.Fprintln(out, "Shows the target reflog entry, the commits leaving the branch, and a diffstat before")
+		fmt.Fprintln(out, "resetting. Defaults to --mixed. --yes skips the prompt except for --hard, which always asks.")
+		return true
+	case "gitTag":
+		fmt.Fprintln(out, "Create the next semver tag (patch, minor, or major) and optionally push it")
+		fmt.Fprintln(out)
+		fmt.Fprintln(out, "Usage:")
+		fmt.Fprintf(out, "  %s gitTag [--patch|--minor|--major] [-m <message>] [--push] [--yes]\n", commandName)
+		fmt.Fprintln(out)
+		fmt.Fprintf(out, "Bumps the highest vX.Y.Z tag (--patch by default), or starts at %s. The tree must be\n", firstReleaseTag)
+		fmt.Fprintln(out, "clean. Creates an annotated tag after a confirmation; --push sends it to origin.")
+		return true
+	case "gitConfigFix":
+		fmt.Fprintln(out, "Apply recommended local git config (pull.rebase, fetch.prune, ...) to this repo")
+		fmt.Fprintln(out)
+		fmt.Fprintln(out, "Usage:")
+		fmt.Fprintf(out, "  %s gitConfigFix [--force]\n", commandName)
+		fmt.Fprintln(out)
+		fmt.Fprintln(out, "Sets pull.rebase=true, fetch.prune=true, push.autoSetupRemote=true, and")
+		fmt.Fprintln(out, "init.defaultBranch=main, or the `key = value` pairs in ~/.flow/gitconfig.toml.")
+		fmt.Fprintln(out, "Asks before overwriting a different existing value unless --force is given.")
+		return true
+	case "pushForce":
+		fmt.Fprintln(out, "Force-push the current branch with --force-with-lease after a confirmation")
+		fmt.Fprintln(out)
+		fmt.Fprintln(out, "Usage:")
+		fmt.Fprintf(out, "  %s pushForce [--yes] [--allow-protected]\n", commandName)
+		fmt.Fprintln(out)
+		fmt.Fprintln(out, "Shows the target remote and how far ahead/behind it you are before pushing.")
+		fmt.Fprintln(out, "main and master are refused unless --allow-protected is passed.")
+		return true
+	case "gitBlameRange":
+		fmt.Fprintln(out, "Summarize who wrote a range of lines in a file")
+		fmt.Fprintln(out)
+		fmt.Fprintln(out, "Usage:")
+		fmt.Fprintf(out, "  %s gitBlameRange [path] [<start>[,<end>]]\n", commandName)
+		fmt.Fprintln(out)
+		fmt.Fprintln(out, "Aggregates `git blame -L` by author and shows the most recent commit touching the range.")
+		fmt.Fprintln(out, "Ranges accept start,end, start-end, or start,+count. Without a path, pick a tracked file.")
+		return true
+	case "gitReflame":
+		fmt.Fprintln(out, "Find the commits that added or removed a piece of text in a file")
+		fmt.Fprintln(out)
+		fmt.Fprintln(out, "Usage:")
+		fmt.Fprintf(out, "  %s gitReflame [--regex] [--copy] <path> <text...>\n", commandName)
+		fmt.Fprintln(out)
+		fmt.Fprintln(out, "Runs git log -S (the pickaxe) on path, following renames, and prints the commit with its")
+		fmt.Fprintln(out, "date. With several matches, pick one in a fuzzy finder with a git show preview; the oldest")
+		fmt.Fprintln(out, "is usually where the text first appeared. --regex treats the text as a regular expression;")
+		fmt.Fprintln(out, "--copy copies the full SHA. The path may be a file that has since been deleted.")
+		return true
+	case "gitStashPick":
+		fmt.Fprintln(out, "Fuzzy-pick a stash with a diff preview and apply, pop, or drop it")
+		fmt.Fprintln(out)
+		fmt.Fprintln(out, "Usage:")
+		fmt.Fprintf(out, "  %s gitStashPick [--apply|--pop|--drop]\n", commandName)
+		fmt.Fprintln(out)
+		fmt.Fprintln(out, "Defaults to --apply, which keeps the stash after applying it.")
+		return true
+	case "gitLog":
+		fmt.Fprintln(out, "Fuzzy-pick a commit from git log with a full preview and print or copy its SHA")
+		fmt.Fprintln(out)
+		fmt.Fprintln(out, "Usage:")
+		fmt.Fprintf(out, "  %s gitLog [-n <limit>] [--author <pattern>] [--grep <pattern>] [--copy]\n", commandName)
+		fmt.Fprintln(out)
+		fmt.Fprintln(out, "Lists the newest 200 commits on HEAD unless -n says otherwise; --author and --grep go to")
+		fmt.Fprintln(out, "git log as is. The picked SHA is printed, ready for smartCherryPick, or copied with --copy.")
+		return true
+	case "gitAuthors":
+		fmt.Fprintln(out, "Rank contributors by commit count from git shortlog")
+		fmt.Fprintln(out)
+		fmt.Fprintln(out, "Usage:")
+		fmt.Fprintf(out, "  %s gitAuthors [--since <date>] [--until <date>] [--merge-emails] [range]\n", commandName)
+		fmt.Fprintln(out)
+		fmt.Fprintln(out, "Counts commits on every ref unless a range such as main or v1.0..HEAD is given; --since and")
+		fmt.Fprintln(out, "--until take any date git log accepts. Names and emails go through .mailmap as git shortlog")
+		fmt.Fprintln(out, "applies it. --merge-emails also folds entries sharing an email into the most-used name.")
+		return true
+	case "sizeReport":
+		fmt.Fprintln(out, "Show the largest blobs in git history and the largest files in the working tree")
+		fmt.Fprintln(out)
+		fmt.Fprintln(out, "Usage:")
+		fmt.Fprintf(out, "  %s sizeReport [-n <limit>]\n", commandName)
+		fmt.Fprintln(out)
+		fmt.Fprintln(out, "Lists the 20 largest of each unless -n says otherwise. History covers every blob reachable")
+		fmt.Fprintln(out, "from any ref; the working tree covers tracked files and untracked ones not ignored. Files")
+		fmt.Fprintln(out, "over 1MB are flagged: untracked ones as .gitignore candidates, tracked binaries as git lfs")
+		fmt.Fprintln(out, "candidates, and history blobs whose path is gone as needing a history rewrite.")
+		return true
+	case "gitResolve":
+		fmt.Fprintln(out, "Resolve the conflicts of an in-progress merge, rebase, cherry-pick, or revert with AI")
+		fmt.Fprintln(out)
+		fmt.Fprintln(out, "Usage:")
+		fmt.Fprintf(out, "  %s gitResolve [--preview] [file...]\n", commandName)
+		fmt.Fprintln(out)
+		fmt.Fprintln(out, "Resolves each conflicted file (or just the ones named) with the same resolver as")
+		fmt.Fprintln(out, "smartCherryPick and stages it. --preview shows the diff and asks before applying.")
+		fmt.Fprintln(out, "Finish with git <operation> --continue once you have reviewed the result.")
+		return true
+	case "gitInteractiveRebase":
+		fmt.Fprintln(out, "Pick commits since the base branch and squash them into one with a non-interactive rebase")
+		fmt.Fprintln(out)
+		fmt.Fprintln(out, "Usage:")
+		fmt.Fprintf(out, "  %s gitInteractiveRebase [--base <ref>] [--ai] [--yes]\n", commandName)
+		fmt.Fprintln(out)
+		fmt.Fprintln(out, "Requires a clean tree. The selected commits move up to the oldest of them and are")
+		fmt.Fprintln(out, "folded into it; their messages are joined, or --ai writes one from the combined diff.")
+		fmt.Fprintln(out, "The plan is shown and confirmed before git rebase runs.")
+		return true
+	case "diffStat":
+		fmt.Fprintln(out, "Summarize how much the current branch changed since it left its base")
+		fmt.Fprintln(out)
+		fmt.Fprintln(out, "Usage:")
+		fmt.Fprintf(out, "  %s diffStat [--base <ref>]\n", commandName)
+		fmt.Fprintln(out)
+		fmt.Fprintln(out, "Prints git diff --stat from the merge-base with the base branch to HEAD.")
+		fmt.Fprintln(out, "Without --base, compares with the default branch (origin/<branch> when you are on it).")
+		return true
 	case "youtubeToSound":
 		fmt.Fprintln(out, "Download audio from a YouTube URL into ~/.flow/youtube-sound using yt-dlp")
 		fmt.Fprintln(out)
 		fmt.Fprintln(out, "Usage:")
-		fmt.Fprintf(out, "  %s youtubeToSound [youtube-url] [yt-dlp-args...]\n", commandName)
+		fmt.Fprintf(out, "  %s youtubeToSound [-o <dir>] [--embed-metadata] [--embed-thumbnail] [--sponsorblock-remove [categories]] [youtube-url] [yt-dlp-args...]\n", commandName)
 		fmt.Fprintln(out)
-		fmt.Fprintln(out, "When no URL is provided, the command uses the frontmost Safari tab.")
+		fmt.Fprintf(out, "When no URL is provided, the command uses the frontmost browser tab (%s: safari, chrome, arc, brave; default safari).\n", flowBrowserEnv)
 		fmt.Fprintln(out, "Any additional arguments are forwarded directly to yt-dlp.")
+		fmt.Fprintln(out)
+		fmt.Fprintln(out, "Flags (before the URL):")
+		fmt.Fprintln(out, "  -o, --output-dir <dir> Save the audio there instead (created if missing)")
+		fmt.Fprintln(out, "  --embed-metadata       Write title, artist, and other tags into the file")
+		fmt.Fprintln(out, "  --embed-thumbnail      Embed the video thumbnail as cover art (mp3, m4a, flac, opus, ogg)")
+		fmt.Fprintf(out, "  --sponsorblock-remove  Cut SponsorBlock segments (default category %s)\n", defaultSponsorBlockCategory)
+		fmt.Fprintln(out)
+		fmt.Fprintf(out, "Each download is recorded in ~/.flow/youtube-sound/%s; see youtubeList.\n", youtubeManifestName)
+		return true
+	case "youtubeList":
+		fmt.Fprintln(out, "List the audio youtubeToSound has downloaded, newest first")
+		fmt.Fprintln(out)
+		fmt.Fprintln(out, "Usage:")
+		fmt.Fprintf(out, "  %s youtubeList [--json]\n", commandName)
+		fmt.Fprintln(out)
+		fmt.Fprintf(out, "Reads ~/.flow/youtube-sound/%s, which youtubeToSound appends to after each download.\n", youtubeManifestName)
 		return true
 	case "spotifyPlay":
 		fmt.Fprintln(out, "Start playing a Spotify track or playlist by URL or ID")
 		fmt.Fprintln(out)
 		fmt.Fprintln(out, "Usage:")
 		fmt.Fprintf(out, "  %s spotifyPlay <spotify-url-or-id>\n", commandName)
+		fmt.Fprintln(out)
+		fmt.Fprintln(out, "Accepts open.spotify.com URLs, spotify: URIs, bare track IDs, and spotify.link")
+		fmt.Fprintln(out, "short links, which are resolved by following their redirect.")
 		return true
-	case "openDoc":
-		fmt.Fprintln(out, "Open a doc by type key (e.g., metrics, changes, log, looking-back)")
+	case "spotifySearch":
+		fmt.Fprintln(out, "Search Spotify by name and play the track, album, or playlist you pick")
 		fmt.Fprintln(out)
 		fmt.Fprintln(out, "Usage:")
-		fmt.Fprintf(out, "  %s openDoc <doc-type>\n", commandName)
+		fmt.Fprintf(out, "  %s spotifySearch [--type track|album|playlist] <query>\n", commandName)
 		fmt.Fprintln(out)
+		fmt.Fprintf(out, "Uses the Spotify Web API with the access token in %s, then plays the\n", spotifyTokenEnv)
+		fmt.Fprintln(out, "selection in the Spotify app the same way spotifyPlay does.")
+		return true
+	case "openDoc":
+		printDocCommandHelp(out, "Open a doc by type key (e.g., metrics, changes, log, looking-back)", "openDoc <doc-type>")
 		fmt.Fprintf(out, "Available doc types: %s\n", strings.Join(availableDocKeys(), ", "))
 		return true
-	case "openLog":
-		fmt.Fprintln(out, "Open the current month log doc in Cursor")
+	case "grepOpen":
+		fmt.Fprintln(out, "Search the log, changes, metrics, and looking-back docs and open a match at its line")
 		fmt.Fprintln(out)
 		fmt.Fprintln(out, "Usage:")
-		fmt.Fprintf(out, "  %s openLog\n", commandName)
-		return true
-	case "openChanges":
-		fmt.Fprintln(out, "Open the current month changes doc in Cursor")
+		fmt.Fprintf(out, "  %s grepOpen <query> [doc-type]\n", commandName)
 		fmt.Fprintln(out)
-		fmt.Fprintln(out, "Usage:")
-		fmt.Fprintf(out, "  %s openChanges\n", commandName)
+		fmt.Fprintln(out, "Matches ignore case and list newer docs first. A single match opens directly; several open a")
+		fmt.Fprintf(out, "picker. Pass a doc type (%s) to search only that folder.\n", strings.Join(availableDocKeys(), ", "))
 		return true
-	case "openMetrics":
-		fmt.Fprintln(out, "Open the current month metrics doc in Cursor")
+	case "notes":
+		fmt.Fprintln(out, "Append a timestamped line to today's note in ~/.flow/notes/YYYY-MM-DD.md")
 		fmt.Fprintln(out)
 		fmt.Fprintln(out, "Usage:")
-		fmt.Fprintf(out, "  %s openMetrics\n", commandName)
+		fmt.Fprintf(out, "  %s notes <text...>\n", commandName)
+		fmt.Fprintf(out, "  echo text | %s notes\n", commandName)
+		fmt.Fprintf(out, "  %s notes --clip\n", commandName)
+		fmt.Fprintf(out, "  %s notes --open\n", commandName)
+		fmt.Fprintln(out)
+		fmt.Fprintln(out, "The note text comes from the arguments, the clipboard with --clip, or piped stdin. The file and")
+		fmt.Fprintln(out, "its directory are created on first use. --open opens the day's note in your editor afterwards,")
+		fmt.Fprintln(out, "and on its own just opens it.")
 		return true
-	case "openLookingBack":
-		fmt.Fprintln(out, "Open the current year-month looking-back doc in Cursor")
+	case "timer":
+		fmt.Fprintln(out, "Count down and post a macOS notification when the time is up")
 		fmt.Fprintln(out)
 		fmt.Fprintln(out, "Usage:")
-		fmt.Fprintf(out, "  %s openLookingBack\n", commandName)
+		fmt.Fprintf(out, "  %s timer [duration] [--label <text>] [--sound [name]]\n", commandName)
+		fmt.Fprintln(out)
+		fmt.Fprintln(out, "The duration is a number of minutes (25) or a Go duration (90s, 1h15m); it defaults to a 25 minute")
+		fmt.Fprintf(out, "pomodoro. --label names the timer in the countdown and the notification, and --sound plays a sound\n")
+		fmt.Fprintf(out, "from /System/Library/Sounds with it (%s by default). Ctrl-C cancels the timer; %s does not apply to it.\n", defaultTimerSound, flowTimeoutEnv)
 		return true
 	case "openSqlite":
 		fmt.Fprintln(out, "Scan the current directory for .sqlite files and open one in TablePlus")
 		fmt.Fprintln(out)
 		fmt.Fprintln(out, "Usage:")
-		fmt.Fprintf(out, "  %s openSqlite\n", commandName)
+		fmt.Fprintf(out, "  %s openSqlite [--gitignore]\n", commandName)
+		fmt.Fprintln(out)
+		fmt.Fprintln(out, "With --gitignore inside a git repository, only databases git tracks or that .gitignore does")
+		fmt.Fprintln(out, "not exclude are listed; outside a repository the whole tree is scanned as usual.")
+		return true
+	case "open":
+		fmt.Fprintln(out, "Fuzzy-find a file in the current tree and open it in your editor")
+		fmt.Fprintln(out)
+		fmt.Fprintln(out, "Usage:")
+		fmt.Fprintf(out, "  %s open [dir]\n", commandName)
+		fmt.Fprintln(out)
+		fmt.Fprintln(out, "Inside a git repository the list comes from git ls-files, so ignored files are left")
+		fmt.Fprintln(out, "out. The file opens in FLOW_EDITOR (Cursor by default).")
+		return true
+	case "search":
+		fmt.Fprintln(out, "Search code with ripgrep, fuzzy-pick a match, and open it at that line")
+		fmt.Fprintln(out)
+		fmt.Fprintln(out, "Usage:")
+		fmt.Fprintf(out, "  %s search <query> [path]\n", commandName)
+		fmt.Fprintln(out)
+		fmt.Fprintln(out, "Falls back to grep -rn when rg is not installed. The match opens in FLOW_EDITOR;")
+		fmt.Fprintln(out, "Cursor and Zed jump to the line when their command-line tools are on PATH.")
 		return true
 	case "focusCursorWindow":
 		fmt.Fprintln(out, "Focus the most recent Cursor window logged without a trailing '.' workspace name, falling back to opening its folder")
@@ -790,38 +1360,124 @@ func printCommandHelp(name string, out io.Writer) bool {
 		fmt.Fprintln(out, "Usage:")
 		fmt.Fprintf(out, "  %s focusCursorWindow\n", commandName)
 		return true
-	case "version":
-		fmt.Fprintln(out, "Reports the current version of fgo")
+	case "recentWorkspaces":
+		fmt.Fprintln(out, "List the most recently focused workspaces recorded in window_focus")
 		fmt.Fprintln(out)
 		fmt.Fprintln(out, "Usage:")
-		fmt.Fprintf(out, "  %s version\n", commandName)
+		fmt.Fprintf(out, "  %s recentWorkspaces [-n <count>] [--app <name>] [--all] [--open]\n", commandName)
+		fmt.Fprintln(out)
+		fmt.Fprintln(out, "Shows the latest focus per workspace (default 10) in local time.")
+		fmt.Fprintln(out, "Use --app to filter by application and --all to include workspace names ending in '.'.")
+		fmt.Fprintf(out, "With --open, fuzzy-pick a workspace and open it in the editor set by %s (cursor, zed, or a command).\n", flowEditorEnv)
+		fmt.Fprintf(out, "Reads %s (override with %s).\n", defaultWindowFocusDBPath, windowFocusDBEnv)
 		return true
-	}
-
-	return false
-}
-
-func printRootHelp(out io.Writer) {
-	fmt.Fprintln(out, commandSummary)
-	fmt.Fprintln(out)
-	fmt.Fprintln(out, "Usage:")
-	fmt.Fprintf(out, "  %s [command]\n", commandName)
-	fmt.Fprintln(out)
-	fmt.Fprintf(out, "Run `%s` without arguments to open the interactive command palette.\n", commandName)
-	fmt.Fprintln(out)
-	fmt.Fprintln(out, "Available Commands:")
-	fmt.Fprintln(out, "  help             Help about any command")
-	fmt.Fprintf(out, "  deploy           Install %s into %s and optionally add it to PATH\n", commandName, flowInstallDir)
-	fmt.Fprintln(out, "  commit           Generate a commit message with GPT-5 nano and create the commit")
-	fmt.Fprintln(out, "  commitPush       Generate a commit message, commit, and push to the default remote")
-	fmt.Fprintln(out, "  commitReviewAndPush Generate a commit message, review it interactively, commit, and push")
-	fmt.Fprintln(out, "  branchFromClipboard Create a git branch from the clipboard name")
+	case "dockerlayers":
+		fmt.Fprintln(out, "Explain the layers, cache behaviour, and easy wins in a Dockerfile")
+		fmt.Fprintln(out)
+		fmt.Fprintln(out, "Usage:")
+		fmt.Fprintf(out, "  %s dockerlayers [path] [-context] [-merge-runs N] [-max-layers N]\n", commandName)
+		fmt.Fprintln(out)
+		fmt.Fprintln(out, "path may be a Dockerfile, a Containerfile, or a directory holding one (Dockerfile first, then")
+		fmt.Fprintln(out, "Containerfile, then *.dockerfile); it defaults to the current directory.")
+		fmt.Fprintln(out, "-context checks COPY/ADD sources against .dockerignore. -merge-runs sets how many")
+		fmt.Fprintln(out, "adjacent RUN instructions are flagged as mergeable (0 disables). -max-layers sets how")
+		fmt.Fprintln(out, "many filesystem layers the final image may add before a warning (default 10, 0 disables).")
+		fmt.Fprintln(out, "ENV and ARG lines that look like they hold credentials are always reported under Secrets.")
+		return true
+	case "doctor":
+		fmt.Fprintln(out, "Check which external tools fgo commands depend on are installed")
+		fmt.Fprintln(out)
+		fmt.Fprintln(out, "Usage:")
+		fmt.Fprintf(out, "  %s doctor [--json]\n", commandName)
+		fmt.Fprintln(out)
+		fmt.Fprintln(out, "Looks up each binary in PATH (and macOS apps in /Applications) and lists the commands")
+		fmt.Fprintln(out, "that fail without it, with an install hint.")
+		return true
+	case "which":
+		fmt.Fprintln(out, "List the external tools and apps a command runs, and where they are on this machine")
+		fmt.Fprintln(out)
+		fmt.Fprintln(out, "Usage:")
+		fmt.Fprintf(out, "  %s which [--json] <command>\n", commandName)
+		fmt.Fprintln(out)
+		fmt.Fprintln(out, "The editor and browser reflect FLOW_EDITOR and FLOW_BROWSER. Aliases resolve to their command.")
+		fmt.Fprintf(out, "Use %s doctor for the same check across every command.\n", commandName)
+		return true
+	case "alias":
+		fmt.Fprintln(out, "Define shortcuts for fgo commands in ~/.flow/aliases.toml")
+		fmt.Fprintln(out)
+		fmt.Fprintln(out, "Usage:")
+		fmt.Fprintf(out, "  %s alias list\n", commandName)
+		fmt.Fprintf(out, "  %s alias set <name> <command> [args...]\n", commandName)
+		fmt.Fprintf(out, "  %s alias unset <name>\n", commandName)
+		fmt.Fprintf(out, "  %s alias path\n", commandName)
+		fmt.Fprintln(out)
+		fmt.Fprintln(out, "Aliases expand to an existing command plus fixed arguments; extra arguments are appended.")
+		fmt.Fprintln(out, "They appear in help and the palette, and cannot shadow built-in commands.")
+		return true
+	case "config":
+		fmt.Fprintln(out, "View and set fgo settings stored in ~/.flow/config.toml")
+		fmt.Fprintln(out)
+		fmt.Fprintln(out, "Usage:")
+		fmt.Fprintf(out, "  %s config list\n", commandName)
+		fmt.Fprintf(out, "  %s config get <key>\n", commandName)
+		fmt.Fprintf(out, "  %s config set <key> <value>\n", commandName)
+		fmt.Fprintf(out, "  %s config unset <key>\n", commandName)
+		fmt.Fprintf(out, "  %s config path\n", commandName)
+		fmt.Fprintln(out)
+		fmt.Fprintln(out, "Environment variables override the file. Keys (or their env var names):")
+		for _, setting := range flowSettings {
+			fmt.Fprintf(out, "  %-24s %-30s %s\n", setting.Key, setting.Env, setting.Description)
+		}
+		fmt.Fprintf(out, "Set %s to use a different config file.\n", flowConfigEnv)
+		return true
+	case "version":
+		fmt.Fprintln(out, "Reports the current version of fgo")
+		fmt.Fprintln(out)
+		fmt.Fprintln(out, "Usage:")
+		fmt.Fprintf(out, "  %s version [--json]\n", commandName)
+		fmt.Fprintln(out)
+		fmt.Fprintln(out, "Use --json to print the version, build time, Go version, and git commit.")
+		return true
+	}
+
+	return false
+}
+
+func printRootHelp(out io.Writer) {
+	fmt.Fprintln(out, commandSummary)
+	fmt.Fprintln(out)
+	fmt.Fprintln(out, "Usage:")
+	fmt.Fprintf(out, "  %s [--verbose] [--ascii] [command]\n", commandName)
+	fmt.Fprintln(out)
+	fmt.Fprintf(out, "Run `%s` without arguments to open the interactive command palette.\n", commandName)
+	fmt.Fprintf(out, "--verbose (or %s=1) logs each external command and its exit status to stderr.\n", cmdlog.DebugEnv)
+	fmt.Fprintf(out, "--ascii (or --no-color, or %s=1) prints [OK]/[i] instead of emoji and strips ANSI colors.\n", noColorEnv)
+	fmt.Fprintln(out)
+	fmt.Fprintln(out, "Available Commands:")
+	fmt.Fprintln(out, "  help             Help about any command")
+	fmt.Fprintf(out, "  deploy           Install %s into %s and optionally add it to PATH\n", commandName, flowInstallDir)
+	fmt.Fprintf(out, "  release          Build a Go CLI with version ldflags and install it into %s\n", flowInstallDir)
+	fmt.Fprintln(out, "  commit           Generate a commit message with GPT-5 nano and create the commit")
+	fmt.Fprintln(out, "  commitPush       Generate a commit message, commit, and push to the default remote")
+	fmt.Fprintln(out, "  commitReviewAndPush Generate a commit message, review it interactively, commit, and push")
+	fmt.Fprintln(out, "  commitAll        Commit each changed file separately with its own generated message")
+	fmt.Fprintln(out, "  gitAmend         Amend the last commit, optionally regenerating its message with AI")
+	fmt.Fprintln(out, "  gitFixup         Commit the current changes as a fixup of a picked commit and optionally autosquash it")
+	fmt.Fprintln(out, "  branchFromClipboard Create a git branch from the clipboard name")
+	fmt.Fprintln(out, "  branchRename     Rename the current branch and move its remote branch too")
+	fmt.Fprintln(out, "  clipboard        Print the clipboard with optional transforms, or set it with --write")
 	fmt.Fprintln(out, "  clone            Clone a GitHub repository into ~/gh/<owner>/<repo>")
-	fmt.Fprintln(out, "  cloneAndOpen     Clone a GitHub repository and open it in Cursor (Safari tab optional)")
+	fmt.Fprintln(out, "  cloneAndOpen     Clone a GitHub repository and open it in Cursor (browser tab optional)")
 	fmt.Fprintln(out, "  clonePR          Clone a GitHub pull request into ~/pr/<repo>-pr<num> and check it out")
+	fmt.Fprintln(out, "  openBrowserTabs  Pick GitHub repo/PR tabs from the front browser window and clone them")
+	fmt.Fprintln(out, "  explainDiff      Explain the current git diff or a commit range in plain English")
+	fmt.Fprintln(out, "  prReview         Generate a first-pass AI review of a GitHub PR and optionally post it")
 	fmt.Fprintln(out, "  gitCheckout      Check out a branch from the remote, creating a local tracking branch if needed")
 	fmt.Fprintln(out, "  gitCheckoutRemote Fuzzy-search remote branches and switch to one locally")
+	fmt.Fprintln(out, "  recentBranches   Fuzzy-pick a branch you recently had checked out and switch to it")
+	fmt.Fprintln(out, "  gitSwitchLast    Switch back to the previously checked-out branch")
 	fmt.Fprintln(out, "  killPort         Kill a process by the port it listens on, optionally with fuzzy finder")
+	fmt.Fprintln(out, "  envPort          Print the next free TCP port (or several) starting from a port")
 	fmt.Fprintln(out, "  tasks            List Taskfile tasks with descriptions")
 	fmt.Fprintln(out, "  try              Create a numbered scratch directory in ~/t and open a shell there")
 	fmt.Fprintln(out, "  privateForkRepo  Clone a repo and create a private fork with upstream remotes")
@@ -831,32 +1487,116 @@ func printRootHelp(out io.Writer) {
 	fmt.Fprintln(out, "  gitFetchUpstream Fetch from upstream (or all remotes) with pruning")
 	fmt.Fprintln(out, "  gitSyncFork      Update a local branch from upstream using rebase or merge")
 	fmt.Fprintln(out, "  gitMirror        Mirror-remote workflow (setup/push/pull/take) for contributor repos")
+	fmt.Fprintln(out, "  gitUndo          Undo the last commit, merge, or rebase by resetting to the prior reflog entry")
+	fmt.Fprintln(out, "  gitTag           Create the next semver tag (patch, minor, or major) and optionally push it")
+	fmt.Fprintln(out, "  gitConfigFix     Apply recommended local git config to the current repo")
+	fmt.Fprintln(out, "  pushForce        Force-push the current branch with --force-with-lease after a confirmation")
+	fmt.Fprintln(out, "  gitBlameRange    Summarize who wrote a range of lines in a file")
+	fmt.Fprintln(out, "  gitReflame       Find the commits that added or removed a piece of text in a file")
+	fmt.Fprintln(out, "  gitStashPick     Fuzzy-pick a stash with a diff preview and apply, pop, or drop it")
+	fmt.Fprintln(out, "  gitLog           Fuzzy-pick a commit from git log with a full preview and print or copy its SHA")
+	fmt.Fprintln(out, "  gitAuthors       Rank contributors by commit count from git shortlog")
+	fmt.Fprintln(out, "  sizeReport       Show the largest blobs in git history and files in the working tree")
+	fmt.Fprintln(out, "  gitResolve       Resolve merge/rebase/cherry-pick conflicts with AI and stage them")
+	fmt.Fprintln(out, "  gitInteractiveRebase Select commits since the base branch and squash them with an optional AI message")
+	fmt.Fprintln(out, "  diffStat         Show files changed and line totals on the current branch since its base")
 	fmt.Fprintln(out, "  updateGoVersion  Upgrade Go using the workspace script")
 	fmt.Fprintln(out, "  youtubeToSound   Download audio from a YouTube URL into ~/.flow/youtube-sound using yt-dlp")
+	fmt.Fprintln(out, "  youtubeList      List audio downloaded by youtubeToSound")
 	fmt.Fprintln(out, "  spotifyPlay      Start playing a Spotify track from a URL or ID")
+	fmt.Fprintln(out, "  spotifySearch    Search Spotify by name and play the selection")
 	fmt.Fprintln(out, "  openDoc          Open a doc by type key (metrics, changes, log, looking-back)")
 	fmt.Fprintln(out, "  openLog          Open the current monthly log doc in Cursor")
 	fmt.Fprintln(out, "  openChanges      Open the current monthly changes doc in Cursor")
 	fmt.Fprintln(out, "  openMetrics      Open the current monthly metrics doc in Cursor")
 	fmt.Fprintln(out, "  openLookingBack  Open the current looking-back doc in Cursor")
+	fmt.Fprintln(out, "  grepOpen         Search the monthly docs for text and open the matching doc at that line")
+	fmt.Fprintln(out, "  notes            Append a timestamped line to today's note, or open it with --open")
+	fmt.Fprintln(out, "  timer            Count down a duration and notify when it is done (pomodoro)")
 	fmt.Fprintln(out, "  openSqlite       Select a .sqlite file in the current tree and open it in TablePlus")
+	fmt.Fprintln(out, "  open             Fuzzy-find a file in the current tree and open it in your editor")
+	fmt.Fprintln(out, "  search           Search code with ripgrep, fuzzy-pick a match, and open it at that line")
 	fmt.Fprintln(out, "  focusCursorWindow Focus the latest Cursor window logged without a trailing '.' workspace name")
+	fmt.Fprintln(out, "  recentWorkspaces List the most recently focused workspaces recorded in window_focus")
+	fmt.Fprintln(out, "  dockerlayers     Explain the layers, cache behaviour, and easy wins in a Dockerfile")
+	fmt.Fprintln(out, "  config           View and set fgo settings stored in ~/.flow/config.toml")
+	fmt.Fprintln(out, "  doctor           Check which external tools fgo commands depend on are installed")
+	fmt.Fprintln(out, "  which            Show the external tools a command uses and where they resolve")
+	fmt.Fprintln(out, "  alias            Define shortcuts for fgo commands in ~/.flow/aliases.toml")
 	fmt.Fprintln(out, "  version          Reports the current version of fgo")
 	fmt.Fprintln(out)
+	if len(commandAliases) > 0 {
+		fmt.Fprintln(out, "Aliases:")
+		for _, alias := range commandAliases {
+			fmt.Fprintf(out, "  %-16s %s\n", alias.Name, alias.Expansion())
+		}
+		fmt.Fprintln(out)
+	}
 	fmt.Fprintln(out, "Flags:")
 	fmt.Fprintf(out, "  -h, --help   help for %s\n", commandName)
+	fmt.Fprintln(out, "  --reset-usage  clear the command palette's recently-used ordering")
 	fmt.Fprintln(out)
 	fmt.Fprintf(out, "Use \"%s [command] --help\" for more information about a command.\n", commandName)
 }
 
+func runVersion(ctx *snap.Context) error {
+	asJSON := false
+	for i := 0; i < ctx.NArgs(); i++ {
+		arg := strings.TrimSpace(ctx.Arg(i))
+		switch arg {
+		case "":
+		case "--json":
+			asJSON = true
+		default:
+			fmt.Fprintf(ctx.Stderr(), "Usage: %s version [--json]\n", commandName)
+			return fmt.Errorf("unexpected argument %q", arg)
+		}
+	}
+
+	if asJSON {
+		return buildinfo.New(commandName, flowVersion, buildTime, gitCommit).WriteJSON(ctx.Stdout())
+	}
+	fmt.Fprintln(ctx.Stdout(), flowVersion)
+	return nil
+}
+
+// Set at build time with -ldflags "-X main.buildTime=<RFC3339> -X main.gitCommit=<sha>".
+var (
+	buildTime = buildinfo.Unknown
+	gitCommit = buildinfo.Unknown
+)
+
 func windowFocusDatabasePath() (string, error) {
-	if override, ok := lookupNonEmptyEnv(windowFocusDBEnv); ok {
+	if override, ok := lookupSetting(windowFocusDBEnv); ok {
 		return filepath.Clean(override), nil
 	}
 	return defaultWindowFocusDBPath, nil
 }
 
+// windowFocusQuery narrows which window_focus rows are returned.
+type windowFocusQuery struct {
+	// App filters by application name (case-insensitive); empty matches every app.
+	App string
+	// Limit caps the number of rows; values below 1 mean 1.
+	Limit int
+	// IncludeTrailingDot keeps workspaces whose name ends in '.', which are skipped by default.
+	IncludeTrailingDot bool
+	// DistinctWorkspaces collapses rows to the latest entry per workspace.
+	DistinctWorkspaces bool
+}
+
 func fetchLatestWindowFocusEntry() (*windowFocusEntry, error) {
+	entries, err := fetchWindowFocusEntries(windowFocusQuery{Limit: 1})
+	if err != nil {
+		return nil, err
+	}
+	if len(entries) == 0 {
+		return nil, nil
+	}
+	return &entries[0], nil
+}
+
+func fetchWindowFocusEntries(q windowFocusQuery) ([]windowFocusEntry, error) {
 	dbPath, err := windowFocusDatabasePath()
 	if err != nil {
 		return nil, fmt.Errorf("determine window focus database path: %w", err)
@@ -877,68 +1617,149 @@ func fetchLatestWindowFocusEntry() (*windowFocusEntry, error) {
 	db.SetMaxOpenConns(1)
 	db.SetMaxIdleConns(1)
 
-	const query = `
+	conditions := []string{"workspace_name IS NOT NULL"}
+	var args []any
+	if !q.IncludeTrailingDot {
+		conditions = append(conditions, "workspace_name = rtrim(workspace_name, '.')")
+	}
+	if app := strings.TrimSpace(q.App); app != "" {
+		column, err := windowFocusAppColumn(db)
+		if err != nil {
+			return nil, err
+		}
+		conditions = append(conditions, fmt.Sprintf("lower(%s) = lower(?)", column))
+		args = append(args, app)
+	}
+
+	limit := q.Limit
+	if limit < 1 {
+		limit = 1
+	}
+	args = append(args, limit)
+
+	// SQLite returns the bare columns from the row holding MAX(focused_at) in each group.
+	focusedAt := "focused_at"
+	groupBy := ""
+	if q.DistinctWorkspaces {
+		focusedAt = "MAX(focused_at) AS focused_at"
+		groupBy = "GROUP BY COALESCE(NULLIF(workspace_path, ''), workspace_name)"
+	}
+
+	query := fmt.Sprintf(`
 SELECT
 	id,
 	window_title,
 	workspace_name,
 	workspace_path,
 	active_file,
-	focused_at
+	%s
 FROM window_focus
 WHERE
-	workspace_name IS NOT NULL
-	AND workspace_name = rtrim(workspace_name, '.')
+	%s
+%s
 ORDER BY focused_at DESC
-LIMIT 1;
-`
+LIMIT ?;
+`, focusedAt, strings.Join(conditions, "\n\tAND "), groupBy)
 
-	var (
-		entry         windowFocusEntry
-		windowTitle   sql.NullString
-		workspaceName sql.NullString
-		workspacePath sql.NullString
-		activeFile    sql.NullString
-	)
-
-	err = db.QueryRow(query).Scan(
-		&entry.ID,
-		&windowTitle,
-		&workspaceName,
-		&workspacePath,
-		&activeFile,
-		&entry.FocusedAt,
-	)
+	rows, err := db.Query(query, args...)
 	if err != nil {
-		if errors.Is(err, sql.ErrNoRows) {
-			return nil, nil
-		}
 		return nil, fmt.Errorf("query window_focus: %w", err)
 	}
+	defer rows.Close()
+
+	var entries []windowFocusEntry
+	for rows.Next() {
+		var (
+			entry         windowFocusEntry
+			windowTitle   sql.NullString
+			workspaceName sql.NullString
+			workspacePath sql.NullString
+			activeFile    sql.NullString
+		)
+
+		if err := rows.Scan(
+			&entry.ID,
+			&windowTitle,
+			&workspaceName,
+			&workspacePath,
+			&activeFile,
+			&entry.FocusedAt,
+		); err != nil {
+			return nil, fmt.Errorf("scan window_focus row: %w", err)
+		}
+
+		if windowTitle.Valid {
+			entry.WindowTitle = strings.TrimSpace(windowTitle.String)
+		}
+		if workspaceName.Valid {
+			entry.WorkspaceName = strings.TrimSpace(workspaceName.String)
+		}
+		if workspacePath.Valid {
+			entry.WorkspacePath = strings.TrimSpace(workspacePath.String)
+		}
+		if activeFile.Valid {
+			entry.ActiveFile = strings.TrimSpace(activeFile.String)
+		}
 
-	if windowTitle.Valid {
-		entry.WindowTitle = strings.TrimSpace(windowTitle.String)
+		entries = append(entries, entry)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("read window_focus rows: %w", err)
 	}
-	if workspaceName.Valid {
-		entry.WorkspaceName = strings.TrimSpace(workspaceName.String)
+
+	return entries, nil
+}
+
+// windowFocusAppColumn finds the column holding the application name; older
+// databases only tracked Cursor and have no such column.
+func windowFocusAppColumn(db *sql.DB) (string, error) {
+	rows, err := db.Query("PRAGMA table_info(window_focus)")
+	if err != nil {
+		return "", fmt.Errorf("inspect window_focus schema: %w", err)
 	}
-	if workspacePath.Valid {
-		entry.WorkspacePath = strings.TrimSpace(workspacePath.String)
+	defer rows.Close()
+
+	columns := make(map[string]bool)
+	for rows.Next() {
+		var (
+			cid        int
+			name       string
+			columnType string
+			notNull    int
+			defaultVal sql.NullString
+			primaryKey int
+		)
+		if err := rows.Scan(&cid, &name, &columnType, &notNull, &defaultVal, &primaryKey); err != nil {
+			return "", fmt.Errorf("inspect window_focus schema: %w", err)
+		}
+		columns[strings.ToLower(name)] = true
 	}
-	if activeFile.Valid {
-		entry.ActiveFile = strings.TrimSpace(activeFile.String)
+	if err := rows.Err(); err != nil {
+		return "", fmt.Errorf("inspect window_focus schema: %w", err)
 	}
 
-	return &entry, nil
+	for _, candidate := range []string{"app_name", "app", "application"} {
+		if columns[candidate] {
+			return candidate, nil
+		}
+	}
+	return "", fmt.Errorf("window_focus has no app column; cannot filter by app")
 }
 
 func runBranchFromClipboard(ctx *snap.Context) error {
-	if ctx.NArgs() != 0 {
-		fmt.Fprintf(ctx.Stderr(), "Usage: %s branchFromClipboard\n", commandName)
-		return fmt.Errorf("expected 0 arguments, got %d", ctx.NArgs())
+	copyBranch := false
+	for i := 0; i < ctx.NArgs(); i++ {
+		switch arg := strings.TrimSpace(ctx.Arg(i)); arg {
+		case "":
+		case "--copy":
+			copyBranch = true
+		default:
+			fmt.Fprintf(ctx.Stderr(), "Usage: %s branchFromClipboard [--copy]\n", commandName)
+			return fmt.Errorf("unexpected argument %q", arg)
+		}
 	}
 
-	if err := ensureGitRepository(); err != nil {
+	if err := gitutil.EnsureRepository(flowCtx); err != nil {
 		return err
 	}
 
@@ -968,7 +1789,7 @@ func runBranchFromClipboard(ctx *snap.Context) error {
 		return fmt.Errorf("clipboard branch %q contains whitespace", branchName)
 	}
 
-	exists, err := gitRefExists(branchName)
+	exists, err := gitutil.RefExists(flowCtx, branchName)
 	if err != nil {
 		return fmt.Errorf("check local branch %s: %w", branchName, err)
 	}
@@ -978,17 +1799,29 @@ func runBranchFromClipboard(ctx *snap.Context) error {
 			return fmt.Errorf("git checkout %s: %w", branchName, err)
 		}
 		fmt.Fprintf(ctx.Stdout(), "✔️ Switched to %s\n", branchName)
-		return nil
+	} else {
+		if err := runGitCommandStreaming(ctx, "checkout", "-b", branchName); err != nil {
+			return fmt.Errorf("git checkout -b %s: %w", branchName, err)
+		}
+		fmt.Fprintf(ctx.Stdout(), "✔️ Created and switched to %s\n", branchName)
 	}
 
-	if err := runGitCommandStreaming(ctx, "checkout", "-b", branchName); err != nil {
-		return fmt.Errorf("git checkout -b %s: %w", branchName, err)
+	if copyBranch {
+		copyToClipboard(ctx, branchName)
 	}
-
-	fmt.Fprintf(ctx.Stdout(), "✔️ Created and switched to %s\n", branchName)
 	return nil
 }
 
+// copyToClipboard is the shared tail of --copy flags: the main action already
+// succeeded, so a clipboard failure is only reported, not returned.
+func copyToClipboard(ctx *snap.Context, text string) {
+	if err := writeClipboardText(text); err != nil {
+		fmt.Fprintf(ctx.Stderr(), "ℹ️ Could not copy to clipboard: %v\n", err)
+		return
+	}
+	fmt.Fprintf(ctx.Stdout(), "✔️ Copied %s to the clipboard\n", text)
+}
+
 func extractBranchName(raw string) string {
 	scanner := bufio.NewScanner(strings.NewReader(raw))
 	for scanner.Scan() {
@@ -1002,16 +1835,7 @@ func extractBranchName(raw string) string {
 }
 
 func readClipboardText() (string, error) {
-	type clipCommand struct {
-		name string
-		args []string
-	}
-
-	candidates := []clipCommand{
-		{name: "pbpaste"},
-		{name: "wl-paste"},
-		{name: "xclip", args: []string{"-selection", "clipboard", "-o"}},
-	}
+	candidates := clipboardReadCandidates(runtime.GOOS)
 
 	sawCommand := false
 	var lastErr error
@@ -1020,7 +1844,7 @@ func readClipboardText() (string, error) {
 			continue
 		}
 		sawCommand = true
-		cmd := exec.Command(candidate.name, candidate.args...)
+		cmd := flowCommand(candidate.name, candidate.args...)
 		output, err := cmd.Output()
 		if err != nil {
 			lastErr = fmt.Errorf("%s: %w", candidate.name, err)
@@ -1030,7 +1854,7 @@ func readClipboardText() (string, error) {
 	}
 
 	if !sawCommand {
-		return "", fmt.Errorf("no clipboard utility found (tried pbpaste, wl-paste, xclip)")
+		return "", fmt.Errorf("no clipboard utility found (tried %s)", clipCommandNames(candidates))
 	}
 	if lastErr != nil {
 		return "", lastErr
@@ -1083,13 +1907,13 @@ func runCloneAndOpen(ctx *snap.Context) error {
 			return fmt.Errorf("github url cannot be empty")
 		}
 	} else {
-		safariURL, err := activeSafariURL()
+		browserURL, browser, err := frontmostBrowserURL()
 		if err != nil {
 			fmt.Fprintf(ctx.Stderr(), "Usage: %s cloneAndOpen [github-url]\n", commandName)
-			return fmt.Errorf("determine Safari URL: %w", err)
+			return fmt.Errorf("determine browser URL: %w", err)
 		}
-		input = safariURL
-		fmt.Fprintf(ctx.Stdout(), "ℹ️ Using Safari URL %s\n", input)
+		input = browserURL
+		fmt.Fprintf(ctx.Stdout(), "ℹ️ Using %s URL %s\n", browser, input)
 	}
 
 	targetDir, err := cloneRepository(ctx, input)
@@ -1108,65 +1932,146 @@ func runCloneAndOpen(ctx *snap.Context) error {
 }
 
 func runClonePR(ctx *snap.Context) error {
-	if ctx.NArgs() != 1 {
-		fmt.Fprintf(ctx.Stderr(), "Usage: %s clonePR <github-pr-url-or-owner/repo#num>\n", commandName)
-		return fmt.Errorf("expected 1 argument, got %d", ctx.NArgs())
+	usage := func() {
+		fmt.Fprintf(ctx.Stderr(), "Usage: %s clonePR <github-pr-url-or-owner/repo#num> [--checkout-base]\n", commandName)
 	}
 
-	ref := strings.TrimSpace(ctx.Arg(0))
+	ref := ""
+	checkoutBase := false
+	for i := 0; i < ctx.NArgs(); i++ {
+		arg := strings.TrimSpace(ctx.Arg(i))
+		switch {
+		case arg == "":
+		case arg == "--checkout-base":
+			checkoutBase = true
+		case strings.HasPrefix(arg, "-"):
+			usage()
+			return fmt.Errorf("unknown flag %q", arg)
+		case ref == "":
+			ref = arg
+		default:
+			usage()
+			return fmt.Errorf("unexpected argument %q", arg)
+		}
+	}
 	if ref == "" {
-		fmt.Fprintf(ctx.Stderr(), "Usage: %s clonePR <github-pr-url-or-owner/repo#num>\n", commandName)
+		usage()
 		return fmt.Errorf("pull request reference cannot be empty")
 	}
 
-	owner, repo, prNumber, err := parsePullRequestRef(ref)
+	dest, err := clonePullRequest(ctx, ref)
 	if err != nil {
 		return err
 	}
 
+	if checkoutBase {
+		base, err := fetchPullRequestBase(ctx, dest, ref)
+		if err != nil {
+			return reportError(ctx, err)
+		}
+		fmt.Fprintf(ctx.Stdout(), "✔️ Base branch: %s (compare with git diff origin/%s...HEAD)\n", base, base)
+	}
+
+	fmt.Fprintf(ctx.Stdout(), "✔️ Ready at %s\n", dest)
+	return nil
+}
+
+// fetchPullRequestBase looks up the PR's base branch and fetches it into
+// origin/<base> in dest, so base...HEAD diffs work offline. It returns the
+// branch name as gh reports it.
+func fetchPullRequestBase(ctx *snap.Context, dest, ref string) (string, error) {
+	owner, repo, prNumber, err := parsePullRequestRef(ref)
+	if err != nil {
+		return "", err
+	}
+	repoFull := fmt.Sprintf("%s/%s", owner, repo)
+
+	out, err := flowCommand("gh", "pr", "view", strconv.Itoa(prNumber), "--repo", repoFull, "--json", "baseRefName", "--jq", ".baseRefName").Output()
+	if err != nil {
+		return "", fmt.Errorf("gh pr view %d: %w", prNumber, err)
+	}
+	base := strings.TrimSpace(string(out))
+	if base == "" {
+		return "", fmt.Errorf("gh pr view %d returned no base branch", prNumber)
+	}
+
+	// gh repo clone names the PR's repository origin, even when the head
+	// lives in a fork. A remote-tracking ref can't clash with the checked-out
+	// PR branch, which may itself be called main.
+	fetchCmd := flowCommand("git", "fetch", "origin", fmt.Sprintf("+refs/heads/%s:refs/remotes/origin/%s", base, base))
+	fetchCmd.Dir = dest
+	fetchCmd.Stdout = ctx.Stdout()
+	fetchCmd.Stderr = ctx.Stderr()
+	if err := fetchCmd.Run(); err != nil {
+		return "", fmt.Errorf("git fetch origin %s: %w", base, err)
+	}
+
+	return base, nil
+}
+
+// clonePullRequest clones the PR's repository into ~/pr/<repo>-pr<num> and
+// checks out the PR branch, returning the destination directory.
+func clonePullRequest(ctx *snap.Context, ref string) (string, error) {
+	owner, repo, prNumber, err := parsePullRequestRef(ref)
+	if err != nil {
+		return "", err
+	}
+
 	if _, err := exec.LookPath("gh"); err != nil {
-		return fmt.Errorf("gh CLI not found in PATH: %w", err)
+		return "", fmt.Errorf("gh CLI not found in PATH: %w", err)
 	}
 
 	repoFull := fmt.Sprintf("%s/%s", owner, repo)
 	dest, err := pullRequestCloneDestination(repo, prNumber)
 	if err != nil {
-		return err
+		return "", err
 	}
 
 	if err := os.MkdirAll(filepath.Dir(dest), 0o755); err != nil {
-		return fmt.Errorf("create destination parent: %w", err)
+		return "", fmt.Errorf("create destination parent: %w", err)
 	}
 
 	if info, err := os.Stat(dest); err == nil {
 		if info.IsDir() {
-			return fmt.Errorf("destination %s already exists", dest)
+			return "", fmt.Errorf("destination %s already exists", dest)
 		}
-		return fmt.Errorf("destination %s exists and is not a directory", dest)
+		return "", fmt.Errorf("destination %s exists and is not a directory", dest)
 	} else if !errors.Is(err, os.ErrNotExist) {
-		return fmt.Errorf("check destination %s: %w", dest, err)
+		return "", fmt.Errorf("check destination %s: %w", dest, err)
 	}
 
 	fmt.Fprintf(ctx.Stdout(), "Cloning %s PR #%d into %s\n", repoFull, prNumber, dest)
 
-	cloneCmd := exec.Command("gh", "repo", "clone", repoFull, dest)
+	cloneCmd := flowCommand("gh", "repo", "clone", repoFull, dest)
 	cloneCmd.Stdout = ctx.Stdout()
 	cloneCmd.Stderr = ctx.Stderr()
 	cloneCmd.Stdin = ctx.Stdin()
 	if err := cloneCmd.Run(); err != nil {
-		return fmt.Errorf("gh repo clone %s: %w", repoFull, err)
+		return "", fmt.Errorf("gh repo clone %s: %w", repoFull, err)
 	}
 
-	checkoutCmd := exec.Command("gh", "pr", "checkout", strconv.Itoa(prNumber))
-	checkoutCmd.Dir = dest
+	if err := checkoutPullRequest(ctx, dest, "", prNumber); err != nil {
+		return "", err
+	}
+
+	return dest, nil
+}
+
+// checkoutPullRequest runs gh pr checkout in dir, which creates or updates
+// the local PR branch. repoFull may be empty to use dir's own repository.
+func checkoutPullRequest(ctx *snap.Context, dir, repoFull string, prNumber int) error {
+	args := []string{"pr", "checkout", strconv.Itoa(prNumber)}
+	if repoFull != "" {
+		args = append(args, "--repo", repoFull)
+	}
+	checkoutCmd := flowCommand("gh", args...)
+	checkoutCmd.Dir = dir
 	checkoutCmd.Stdout = ctx.Stdout()
 	checkoutCmd.Stderr = ctx.Stderr()
 	checkoutCmd.Stdin = ctx.Stdin()
 	if err := checkoutCmd.Run(); err != nil {
 		return fmt.Errorf("gh pr checkout %d: %w", prNumber, err)
 	}
-
-	fmt.Fprintf(ctx.Stdout(), "✔️ Ready at %s\n", dest)
 	return nil
 }
 
@@ -1205,7 +2110,7 @@ func runPRDiff(ctx *snap.Context) error {
 
 	out.WriteString(fmt.Sprintf("# Pull Request: %s#%d\n\n", repoFull, prNumber))
 
-	viewCmd := exec.Command("gh", "pr", "view", prRef, "--repo", repoFull, "--json", "title,body,author,state,baseRefName,headRefName,additions,deletions,changedFiles")
+	viewCmd := flowCommand("gh", "pr", "view", prRef, "--repo", repoFull, "--json", "title,body,author,state,baseRefName,headRefName,additions,deletions,changedFiles")
 	viewOutput, err := viewCmd.Output()
 	if err != nil {
 		return fmt.Errorf("gh pr view: %w", err)
@@ -1240,7 +2145,7 @@ func runPRDiff(ctx *snap.Context) error {
 	}
 
 	if includeComments {
-		commentsCmd := exec.Command("gh", "pr", "view", prRef, "--repo", repoFull, "--json", "comments")
+		commentsCmd := flowCommand("gh", "pr", "view", prRef, "--repo", repoFull, "--json", "comments")
 		commentsOutput, err := commentsCmd.Output()
 		if err == nil {
 			var commentsInfo struct {
@@ -1259,7 +2164,7 @@ func runPRDiff(ctx *snap.Context) error {
 			}
 		}
 
-		reviewsCmd := exec.Command("gh", "pr", "view", prRef, "--repo", repoFull, "--json", "reviews")
+		reviewsCmd := flowCommand("gh", "pr", "view", prRef, "--repo", repoFull, "--json", "reviews")
 		reviewsOutput, err := reviewsCmd.Output()
 		if err == nil {
 			var reviewsInfo struct {
@@ -1286,7 +2191,7 @@ func runPRDiff(ctx *snap.Context) error {
 	out.WriteString("## Diff\n\n")
 	out.WriteString("```diff\n")
 
-	diffCmd := exec.Command("gh", "pr", "diff", prRef, "--repo", repoFull)
+	diffCmd := flowCommand("gh", "pr", "diff", prRef, "--repo", repoFull)
 	diffOutput, err := diffCmd.Output()
 	if err != nil {
 		return fmt.Errorf("gh pr diff: %w", err)
@@ -1359,7 +2264,7 @@ func cloneRepository(ctx *snap.Context, input string) (string, error) {
 		return "", fmt.Errorf("checking %s: %w", targetDir, err)
 	}
 
-	cmd := exec.Command("git", "clone", cloneURL, targetDir)
+	cmd := flowCommand("git", "clone", cloneURL, targetDir)
 	output, err := cmd.CombinedOutput()
 	if err != nil {
 		trimmed := strings.TrimSpace(string(output))
@@ -1373,74 +2278,30 @@ func cloneRepository(ctx *snap.Context, input string) (string, error) {
 }
 
 func parsePullRequestRef(input string) (string, string, int, error) {
-	candidate := strings.TrimSpace(strings.TrimSuffix(input, "/"))
-	if candidate == "" {
-		return "", "", 0, fmt.Errorf("pull request reference cannot be empty")
+	ref, err := ghref.Parse(input)
+	if err != nil {
+		return "", "", 0, err
 	}
-
-	if strings.HasPrefix(candidate, "http://") || strings.HasPrefix(candidate, "https://") {
-		u, err := url.Parse(candidate)
-		if err != nil {
-			return "", "", 0, fmt.Errorf("parse url %q: %w", input, err)
-		}
-		if !strings.EqualFold(u.Host, "github.com") {
-			return "", "", 0, fmt.Errorf("expected github.com host, got %s", u.Host)
-		}
-		segments := strings.Split(strings.Trim(u.Path, "/"), "/")
-		if len(segments) < 4 {
-			return "", "", 0, fmt.Errorf("expected GitHub pull request URL, got %q", input)
-		}
-		owner := segments[0]
-		repo := strings.TrimSuffix(segments[1], ".git")
-		number := 0
-		for i := 2; i < len(segments); i++ {
-			if segments[i] == "pull" || segments[i] == "pulls" {
-				if i+1 < len(segments) {
-					if n, err := strconv.Atoi(strings.TrimSpace(segments[i+1])); err == nil && n > 0 {
-						number = n
-						break
-					}
-				}
-			}
-		}
-		if owner == "" || repo == "" || number == 0 {
-			return "", "", 0, fmt.Errorf("unable to parse pull request from %q", input)
-		}
-		return owner, repo, number, nil
+	if !ref.HasRepo() {
+		return "", "", 0, fmt.Errorf("pull request reference %q must include owner/repo", input)
 	}
-
-	if hash := strings.Index(candidate, "#"); hash > 0 {
-		repoPart := strings.TrimSpace(candidate[:hash])
-		numberPart := strings.TrimSpace(candidate[hash+1:])
-		owner, repo, err := splitOwnerRepo(repoPart)
-		if err != nil {
-			return "", "", 0, err
-		}
-		number, err := strconv.Atoi(numberPart)
-		if err != nil || number <= 0 {
-			return "", "", 0, fmt.Errorf("invalid pull request number %q", numberPart)
-		}
-		return owner, repo, number, nil
+	if ref.Kind == ghref.Issue {
+		return "", "", 0, fmt.Errorf("%q is an issue, not a pull request", input)
 	}
+	return ref.Owner, ref.Repo, ref.Number, nil
+}
 
-	if strings.Contains(candidate, "/pull/") || strings.Contains(candidate, "/pulls/") {
-		parts := strings.Split(candidate, "/")
-		if len(parts) >= 4 {
-			owner := parts[0]
-			repo := strings.TrimSuffix(parts[1], ".git")
-			for i := 2; i < len(parts); i++ {
-				if parts[i] == "pull" || parts[i] == "pulls" {
-					if i+1 < len(parts) {
-						if number, err := strconv.Atoi(strings.TrimSpace(parts[i+1])); err == nil && number > 0 {
-							return owner, repo, number, nil
-						}
-					}
-				}
-			}
-		}
+// pullRequestURL reports whether input is a GitHub pull request URL, as
+// opposed to a branch name or /tree/ URL that happens to parse as a ref.
+func pullRequestURL(input string) (string, string, int, bool) {
+	if !strings.HasPrefix(input, "http://") && !strings.HasPrefix(input, "https://") {
+		return "", "", 0, false
 	}
-
-	return "", "", 0, fmt.Errorf("expected GitHub PR URL or owner/repo#num, got %q", input)
+	ref, err := ghref.Parse(input)
+	if err != nil || ref.Kind != ghref.Pull || !ref.HasRepo() {
+		return "", "", 0, false
+	}
+	return ref.Owner, ref.Repo, ref.Number, true
 }
 
 func pullRequestCloneDestination(repo string, prNumber int) (string, error) {
@@ -1464,7 +2325,7 @@ func pullRequestCloneDestination(repo string, prNumber int) (string, error) {
 func openInCursor(ctx *snap.Context, path string) error {
 	cursorApp := "/Applications/Cursor.app"
 	if _, err := os.Stat(cursorApp); err != nil {
-		return fmt.Errorf("Cursor.app not found at %s: %w", cursorApp, err)
+		return fmt.Errorf("%w: Cursor.app not found at %s: %w", errMissingDependency, cursorApp, err)
 	}
 
 	cmd := exec.Command("open", "-a", cursorApp, path)
@@ -1481,7 +2342,7 @@ func openInCursor(ctx *snap.Context, path string) error {
 func openInZed(ctx *snap.Context, path string) error {
 	zedApp := "/Applications/Zed.app"
 	if _, err := os.Stat(zedApp); err != nil {
-		return fmt.Errorf("Zed.app not found at %s: %w", zedApp, err)
+		return fmt.Errorf("%w: Zed.app not found at %s: %w", errMissingDependency, zedApp, err)
 	}
 
 	cmd := exec.Command("open", "-a", zedApp, path)
@@ -1495,6 +2356,84 @@ func openInZed(ctx *snap.Context, path string) error {
 	return nil
 }
 
+// openInEditor opens path in the editor named by FLOW_EDITOR: "cursor"
+// (default), "zed", or any command that accepts a path argument.
+func openInEditor(ctx *snap.Context, path string) error {
+	editor, _ := lookupSetting(flowEditorEnv)
+	switch strings.ToLower(editor) {
+	case "", "cursor":
+		return openInCursor(ctx, path)
+	case "zed":
+		return openInZed(ctx, path)
+	}
+
+	fields := strings.Fields(editor)
+	cmd := exec.Command(fields[0], append(fields[1:], path)...)
+	cmd.Stdout = ctx.Stdout()
+	cmd.Stderr = ctx.Stderr()
+	cmd.Stdin = ctx.Stdin()
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("open with %s: %w", fields[0], err)
+	}
+
+	return nil
+}
+
+// openInEditorAtLine is openInEditor for a specific line. Editors whose
+// line syntax is known get it; anything else just opens the file.
+func openInEditorAtLine(ctx *snap.Context, path string, line int) error {
+	editor, _ := lookupSetting(flowEditorEnv)
+	fields := strings.Fields(editor)
+	name := "cursor"
+	if len(fields) > 0 {
+		name = strings.ToLower(filepath.Base(fields[0]))
+	}
+
+	var args []string
+	switch name {
+	case "cursor", "code", "code-insiders":
+		// The .app bundles can't take a line, but their CLIs can.
+		if _, err := exec.LookPath(name); err != nil {
+			return openInEditor(ctx, path)
+		}
+		fields = []string{name}
+		args = []string{"--goto", fmt.Sprintf("%s:%d", path, line)}
+	case "zed":
+		if _, err := exec.LookPath("zed"); err != nil {
+			return openInEditor(ctx, path)
+		}
+		fields = []string{"zed"}
+		args = []string{fmt.Sprintf("%s:%d", path, line)}
+	case "subl", "hx":
+		args = []string{fmt.Sprintf("%s:%d", path, line)}
+	case "vi", "vim", "nvim", "nano", "emacs", "micro":
+		args = []string{fmt.Sprintf("+%d", line), path}
+	default:
+		return openInEditor(ctx, path)
+	}
+
+	cmd := exec.Command(fields[0], append(fields[1:], args...)...)
+	cmd.Stdout = ctx.Stdout()
+	cmd.Stderr = ctx.Stderr()
+	cmd.Stdin = ctx.Stdin()
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("open with %s: %w", fields[0], err)
+	}
+
+	return nil
+}
+
+func editorDisplayName() string {
+	editor, _ := lookupSetting(flowEditorEnv)
+	switch strings.ToLower(editor) {
+	case "", "cursor":
+		return "Cursor"
+	case "zed":
+		return "Zed"
+	}
+	return strings.Fields(editor)[0]
+}
+
 func tryBaseDir() (string, error) {
 	homeDir, err := os.UserHomeDir()
 	if err != nil {
@@ -1534,6 +2473,7 @@ func detectShell() string {
 }
 
 type docSpec struct {
+	command     string
 	description string
 	dirSegments []string
 	fileName    func(time.Time) string
@@ -1554,21 +2494,25 @@ func lookingBackDocName(now time.Time) string {
 
 var docSpecs = map[string]docSpec{
 	"changes": {
+		command:     "openChanges",
 		description: "Open the current monthly changes doc in Cursor",
 		dirSegments: []string{"nikiv-old", "content", "docs", "changes"},
 		fileName:    monthlyDocName(25),
 	},
 	"metrics": {
+		command:     "openMetrics",
 		description: "Open the current monthly metrics doc in Cursor",
 		dirSegments: []string{"nikiv-old", "content", "docs", "metrics"},
 		fileName:    monthlyDocName(25),
 	},
 	"log": {
+		command:     "openLog",
 		description: "Open the current monthly log doc in Cursor",
 		dirSegments: []string{"nikiv-old", "content", "docs", "log"},
 		fileName:    monthlyDocName(25),
 	},
 	"looking-back": {
+		command:     "openLookingBack",
 		description: "Open the current looking-back doc in Cursor",
 		dirSegments: []string{"nikiv-old", "content", "docs", "looking-back"},
 		fileName:    lookingBackDocName,
@@ -1588,6 +2532,27 @@ func availableDocKeys() []string {
 	return keys
 }
 
+// docSpecForCommand finds the doc a shortcut command such as openLog opens.
+func docSpecForCommand(name string) (docSpec, bool) {
+	for _, spec := range docSpecs {
+		if spec.command == name {
+			return spec, true
+		}
+	}
+	return docSpec{}, false
+}
+
+// printDocCommandHelp is the help shared by openDoc and the shortcuts for
+// each doc type.
+func printDocCommandHelp(out io.Writer, summary, usage string) {
+	fmt.Fprintln(out, summary)
+	fmt.Fprintln(out)
+	fmt.Fprintln(out, "Usage:")
+	fmt.Fprintf(out, "  %s %s [--no-create]\n", commandName, usage)
+	fmt.Fprintln(out)
+	fmt.Fprintln(out, "A missing doc is created empty first; --no-create reports it instead.")
+}
+
 func resolveDocSpec(key string) (docSpec, bool) {
 	normalized := strings.TrimSpace(strings.ToLower(key))
 	normalized = strings.ReplaceAll(normalized, "_", "-")
@@ -1599,7 +2564,18 @@ func resolveDocSpec(key string) (docSpec, bool) {
 	return spec, ok
 }
 
-func openDoc(ctx *snap.Context, spec docSpec) error {
+// docDir is the directory under the home directory that holds spec's docs.
+func docDir(spec docSpec) (string, error) {
+	homeDir, err := os.UserHomeDir()
+	if err != nil {
+		return "", fmt.Errorf("determine home directory: %w", err)
+	}
+	return filepath.Join(append([]string{homeDir}, spec.dirSegments...)...), nil
+}
+
+// openDoc opens the current file for spec, creating it and its directory
+// first unless create is false, in which case a missing doc is an error.
+func openDoc(ctx *snap.Context, spec docSpec, create bool) error {
 	now := time.Now()
 	if spec.fileName == nil {
 		return reportError(ctx, fmt.Errorf("missing file name generator for doc"))
@@ -1609,28 +2585,27 @@ func openDoc(ctx *snap.Context, spec docSpec) error {
 		return reportError(ctx, fmt.Errorf("empty file name for doc"))
 	}
 
-	homeDir, err := os.UserHomeDir()
+	baseDir, err := docDir(spec)
 	if err != nil {
-		return reportError(ctx, fmt.Errorf("determine home directory: %w", err))
-	}
-
-	baseDir := filepath.Join(append([]string{homeDir}, spec.dirSegments...)...)
-	if err := os.MkdirAll(baseDir, 0o755); err != nil {
-		return reportError(ctx, fmt.Errorf("create directory %s: %w", baseDir, err))
+		return reportError(ctx, err)
 	}
-
 	targetFile := filepath.Join(baseDir, fileName)
 
 	created := false
 	if _, err := os.Stat(targetFile); err != nil {
-		if errors.Is(err, os.ErrNotExist) {
-			if err := os.WriteFile(targetFile, []byte{}, 0o644); err != nil {
-				return reportError(ctx, fmt.Errorf("create file %s: %w", targetFile, err))
-			}
-			created = true
-		} else {
+		if !errors.Is(err, os.ErrNotExist) {
 			return reportError(ctx, fmt.Errorf("stat %s: %w", targetFile, err))
 		}
+		if !create {
+			return reportError(ctx, fmt.Errorf("%s does not exist yet (run without --no-create to create it)", targetFile))
+		}
+		if err := os.MkdirAll(baseDir, 0o755); err != nil {
+			return reportError(ctx, fmt.Errorf("create directory %s: %w", baseDir, err))
+		}
+		if err := os.WriteFile(targetFile, []byte{}, 0o644); err != nil {
+			return reportError(ctx, fmt.Errorf("create file %s: %w", targetFile, err))
+		}
+		created = true
 	}
 
 	if err := openInCursor(ctx, targetFile); err != nil {
@@ -1644,64 +2619,85 @@ func openDoc(ctx *snap.Context, spec docSpec) error {
 	return nil
 }
 
-func runOpenDoc(ctx *snap.Context) error {
-	if ctx.NArgs() != 1 {
-		fmt.Fprintf(ctx.Stderr(), "Usage: %s openDoc <doc-type>\n", commandName)
+// docCommandArgs separates --no-create from the other arguments of the doc
+// commands and reports whether a missing doc may be created.
+func docCommandArgs(ctx *snap.Context) ([]string, bool) {
+	create := true
+	var args []string
+	for i := 0; i < ctx.NArgs(); i++ {
+		arg := strings.TrimSpace(ctx.Arg(i))
+		if arg == "--no-create" {
+			create = false
+			continue
+		}
+		args = append(args, arg)
+	}
+	return args, create
+}
+
+func runOpenDoc(ctx *snap.Context) error {
+	args, create := docCommandArgs(ctx)
+	if len(args) != 1 {
+		fmt.Fprintf(ctx.Stderr(), "Usage: %s openDoc <doc-type> [--no-create]\n", commandName)
 		fmt.Fprintf(ctx.Stderr(), "Available doc types: %s\n", strings.Join(availableDocKeys(), ", "))
-		return fmt.Errorf("expected 1 argument, got %d", ctx.NArgs())
+		return fmt.Errorf("expected 1 argument, got %d", len(args))
 	}
 
-	docType := ctx.Arg(0)
-	docType = strings.TrimSpace(docType)
+	docType := args[0]
 	spec, ok := resolveDocSpec(docType)
 	if !ok {
 		fmt.Fprintf(ctx.Stderr(), "Unknown doc type %q. Available: %s\n", docType, strings.Join(availableDocKeys(), ", "))
 		return fmt.Errorf("unknown doc type %q", docType)
 	}
 
-	return openDoc(ctx, spec)
+	return openDoc(ctx, spec, create)
 }
 
 func runOpenChanges(ctx *snap.Context) error {
-	if ctx.NArgs() != 0 {
-		fmt.Fprintf(ctx.Stderr(), "Usage: %s openChanges\n", commandName)
-		return fmt.Errorf("expected 0 arguments, got %d", ctx.NArgs())
+	args, create := docCommandArgs(ctx)
+	if len(args) != 0 {
+		fmt.Fprintf(ctx.Stderr(), "Usage: %s openChanges [--no-create]\n", commandName)
+		return fmt.Errorf("expected 0 arguments, got %d", len(args))
 	}
 
-	return openDoc(ctx, docSpecs["changes"])
+	return openDoc(ctx, docSpecs["changes"], create)
 }
 
 func runOpenMetrics(ctx *snap.Context) error {
-	if ctx.NArgs() != 0 {
-		fmt.Fprintf(ctx.Stderr(), "Usage: %s openMetrics\n", commandName)
-		return fmt.Errorf("expected 0 arguments, got %d", ctx.NArgs())
+	args, create := docCommandArgs(ctx)
+	if len(args) != 0 {
+		fmt.Fprintf(ctx.Stderr(), "Usage: %s openMetrics [--no-create]\n", commandName)
+		return fmt.Errorf("expected 0 arguments, got %d", len(args))
 	}
 
-	return openDoc(ctx, docSpecs["metrics"])
+	return openDoc(ctx, docSpecs["metrics"], create)
 }
 
 func runOpenLog(ctx *snap.Context) error {
-	if ctx.NArgs() != 0 {
-		fmt.Fprintf(ctx.Stderr(), "Usage: %s openLog\n", commandName)
-		return fmt.Errorf("expected 0 arguments, got %d", ctx.NArgs())
+	args, create := docCommandArgs(ctx)
+	if len(args) != 0 {
+		fmt.Fprintf(ctx.Stderr(), "Usage: %s openLog [--no-create]\n", commandName)
+		return fmt.Errorf("expected 0 arguments, got %d", len(args))
 	}
 
-	return openDoc(ctx, docSpecs["log"])
+	return openDoc(ctx, docSpecs["log"], create)
 }
 
 func runOpenLookingBack(ctx *snap.Context) error {
-	if ctx.NArgs() != 0 {
-		fmt.Fprintf(ctx.Stderr(), "Usage: %s openLookingBack\n", commandName)
-		return fmt.Errorf("expected 0 arguments, got %d", ctx.NArgs())
+	args, create := docCommandArgs(ctx)
+	if len(args) != 0 {
+		fmt.Fprintf(ctx.Stderr(), "Usage: %s openLookingBack [--no-create]\n", commandName)
+		return fmt.Errorf("expected 0 arguments, got %d", len(args))
 	}
 
-	return openDoc(ctx, docSpecs["looking-back"])
+	return openDoc(ctx, docSpecs["looking-back"], create)
 }
 
 func runOpenSqlite(ctx *snap.Context) error {
-	if ctx.NArgs() != 0 {
-		fmt.Fprintf(ctx.Stderr(), "Usage: %s openSqlite\n", commandName)
-		return fmt.Errorf("expected 0 arguments, got %d", ctx.NArgs())
+	respectGitignore, err := gitignoreFlag(ctx)
+	if err != nil {
+		fmt.Fprintf(ctx.Stderr(), "Usage: %s openSqlite [--gitignore]\n", commandName)
+		return err
 	}
 
 	workingDir, err := os.Getwd()
@@ -1709,7 +2705,7 @@ func runOpenSqlite(ctx *snap.Context) error {
 		return reportError(ctx, fmt.Errorf("determine working directory: %w", err))
 	}
 
-	files, err := findSqliteFiles(workingDir)
+	files, err := findSqliteFiles(workingDir, respectGitignore)
 	if err != nil {
 		return reportError(ctx, fmt.Errorf("scan for .sqlite files: %w", err))
 	}
@@ -1719,16 +2715,28 @@ func runOpenSqlite(ctx *snap.Context) error {
 		return nil
 	}
 
+	previews := make(map[int]string, len(files))
 	idx, err := fuzzyfinder.Find(
 		files,
 		func(i int) string {
 			return files[i].Relative
 		},
 		fuzzyfinder.WithPromptString("openSqlite> "),
+		fuzzyfinder.WithPreviewWindow(func(i, width, height int) string {
+			if i < 0 || i >= len(files) {
+				return ""
+			}
+			if cached, ok := previews[i]; ok {
+				return cached
+			}
+			preview := sqliteTablesPreview(files[i].Absolute)
+			previews[i] = preview
+			return preview
+		}),
 	)
 	if err != nil {
 		if errors.Is(err, fuzzyfinder.ErrAbort) {
-			return nil
+			return errUserAbort
 		}
 		return reportError(ctx, fmt.Errorf("select sqlite file: %w", err))
 	}
@@ -1742,6 +2750,48 @@ func runOpenSqlite(ctx *snap.Context) error {
 	return nil
 }
 
+// previewTimeout bounds the work a picker preview may do so that moving the
+// cursor never stalls on a slow command or a locked database.
+const previewTimeout = 500 * time.Millisecond
+
+// sqliteTablesPreview lists the tables in a database, opened read-only.
+// Errors become the preview text instead of failing the picker.
+func sqliteTablesPreview(path string) string {
+	db, err := sql.Open("sqlite", "file:"+path+"?mode=ro")
+	if err != nil {
+		return fmt.Sprintf("(cannot open: %v)", err)
+	}
+	defer db.Close()
+	db.SetMaxOpenConns(1)
+
+	queryCtx, cancel := context.WithTimeout(flowCtx, previewTimeout)
+	defer cancel()
+
+	rows, err := db.QueryContext(queryCtx, "SELECT name FROM sqlite_master WHERE type = 'table' AND name NOT LIKE 'sqlite_%' ORDER BY name")
+	if err != nil {
+		return fmt.Sprintf("(cannot read tables: %v)", err)
+	}
+	defer rows.Close()
+
+	var b strings.Builder
+	count := 0
+	for rows.Next() {
+		var name string
+		if err := rows.Scan(&name); err != nil {
+			return fmt.Sprintf("(cannot read tables: %v)", err)
+		}
+		count++
+		fmt.Fprintf(&b, "  %s\n", name)
+	}
+	if err := rows.Err(); err != nil {
+		return fmt.Sprintf("(cannot read tables: %v)", err)
+	}
+	if count == 0 {
+		return "(no tables)"
+	}
+	return fmt.Sprintf("%d table(s):\n%s", count, b.String())
+}
+
 func runFocusCursorWindow(ctx *snap.Context) error {
 	if ctx.NArgs() != 0 {
 		fmt.Fprintf(ctx.Stderr(), "Usage: %s focusCursorWindow\n", commandName)
@@ -1810,14 +2860,75 @@ type sqliteCandidate struct {
 	Relative string
 }
 
-func findSqliteFiles(root string) ([]sqliteCandidate, error) {
+// shouldSkip reports whether tree walks should stay out of a directory
+// called name: the shared defaults plus skip_dirs / FLOW_SKIP_DIRS.
+func shouldSkip(name string) bool {
+	return walkSkipDirs().Skip(name)
+}
+
+var walkSkipDirs = sync.OnceValue(func() skipdirs.Set {
+	extra, _ := lookupSetting(skipdirs.Env)
+	return skipdirs.New(extra)
+})
+
+// gitignoreFlag parses the optional --gitignore argument of the pickers
+// that walk a tree.
+func gitignoreFlag(ctx *snap.Context) (bool, error) {
+	respectGitignore := false
+	for i := 0; i < ctx.NArgs(); i++ {
+		switch arg := strings.TrimSpace(ctx.Arg(i)); arg {
+		case "":
+		case "--gitignore":
+			respectGitignore = true
+		default:
+			return false, fmt.Errorf("unexpected argument %q", arg)
+		}
+	}
+	return respectGitignore, nil
+}
+
+// gitVisibleFiles lists the files under root that git tracks plus the
+// untracked ones .gitignore does not exclude, relative to root. ok is false
+// when root is not inside a repository.
+func gitVisibleFiles(root string) (files []string, ok bool) {
+	cmd := flowCommand("git", "ls-files", "-z", "--cached", "--others", "--exclude-standard")
+	cmd.Dir = root
+	out, err := cmd.Output()
+	if err != nil {
+		return nil, false
+	}
+	for _, name := range strings.Split(string(out), "\x00") {
+		if name != "" {
+			files = append(files, filepath.FromSlash(name))
+		}
+	}
+	return files, true
+}
+
+// findSqliteFiles lists the .sqlite files under root. With respectGitignore
+// inside a repository, only files git lists are considered; otherwise the
+// whole tree is walked minus the skipped directories.
+func findSqliteFiles(root string, respectGitignore bool) ([]sqliteCandidate, error) {
 	var files []sqliteCandidate
-	skipDirs := map[string]struct{}{
-		".git":         {},
-		".idea":        {},
-		".vscode":      {},
-		"node_modules": {},
-		"vendor":       {},
+
+	if respectGitignore {
+		if names, ok := gitVisibleFiles(root); ok {
+			for _, rel := range names {
+				if !strings.EqualFold(filepath.Ext(rel), ".sqlite") {
+					continue
+				}
+				path := filepath.Join(root, rel)
+				// Tracked files deleted from the work tree are still listed.
+				if _, err := os.Stat(path); err != nil {
+					continue
+				}
+				files = append(files, sqliteCandidate{Absolute: path, Relative: rel})
+			}
+			sort.Slice(files, func(i, j int) bool {
+				return files[i].Relative < files[j].Relative
+			})
+			return files, nil
+		}
 	}
 
 	err := filepath.WalkDir(root, func(path string, d fs.DirEntry, walkErr error) error {
@@ -1832,7 +2943,7 @@ func findSqliteFiles(root string) ([]sqliteCandidate, error) {
 			if path == root {
 				return nil
 			}
-			if _, skip := skipDirs[d.Name()]; skip {
+			if shouldSkip(d.Name()) {
 				return filepath.SkipDir
 			}
 			return nil
@@ -1867,7 +2978,7 @@ func findSqliteFiles(root string) ([]sqliteCandidate, error) {
 func openInTablePlus(ctx *snap.Context, databasePath string) error {
 	tablePlusApp := "/Applications/TablePlus.app"
 	if _, err := os.Stat(tablePlusApp); err != nil {
-		return fmt.Errorf("TablePlus.app not found at %s: %w", tablePlusApp, err)
+		return fmt.Errorf("%w: TablePlus.app not found at %s: %w", errMissingDependency, tablePlusApp, err)
 	}
 
 	cmd := exec.Command("open", "-a", tablePlusApp, databasePath)
@@ -1905,7 +3016,7 @@ func runListWindowsOfApp(ctx *snap.Context) error {
 	)
 	if err != nil {
 		if errors.Is(err, fuzzyfinder.ErrAbort) {
-			return nil
+			return errUserAbort
 		}
 		return reportError(ctx, fmt.Errorf("select application: %w", err))
 	}
@@ -1944,7 +3055,7 @@ end tell
 set AppleScript's text item delimiters to "\n"
 return appNames as text`
 
-	cmd := exec.Command("osascript", "-")
+	cmd := flowCommand("osascript", "-")
 	cmd.Stdin = strings.NewReader(script)
 	output, err := cmd.CombinedOutput()
 	if err != nil {
@@ -2004,7 +3115,7 @@ func listApplicationWindows(appName string) ([]string, error) {
 	return filteredNames as text
 end run`
 
-	cmd := exec.Command("osascript", "-", appName)
+	cmd := flowCommand("osascript", "-", appName)
 	cmd.Stdin = strings.NewReader(script)
 	output, err := cmd.CombinedOutput()
 	if err != nil {
@@ -2032,6 +3143,134 @@ end run`
 	return titles, nil
 }
 
+func runRecentWorkspaces(ctx *snap.Context) error {
+	usage := func() {
+		fmt.Fprintf(ctx.Stderr(), "Usage: %s recentWorkspaces [-n <count>] [--app <name>] [--all] [--open]\n", commandName)
+	}
+
+	query := windowFocusQuery{Limit: 10, DistinctWorkspaces: true}
+	open := false
+	for i := 0; i < ctx.NArgs(); i++ {
+		arg := strings.TrimSpace(ctx.Arg(i))
+		if arg == "" {
+			continue
+		}
+
+		name, value, hasValue := strings.Cut(arg, "=")
+		switch name {
+		case "-n", "--limit", "--app":
+			if !hasValue {
+				if i+1 >= ctx.NArgs() {
+					usage()
+					return fmt.Errorf("%s requires a value", name)
+				}
+				i++
+				value = ctx.Arg(i)
+			}
+			value = strings.TrimSpace(value)
+			if name == "--app" {
+				query.App = value
+				continue
+			}
+			limit, err := strconv.Atoi(value)
+			if err != nil || limit < 1 {
+				usage()
+				return fmt.Errorf("invalid count %q", value)
+			}
+			query.Limit = limit
+		case "--all":
+			query.IncludeTrailingDot = true
+		case "--open":
+			open = true
+		default:
+			usage()
+			return fmt.Errorf("unexpected argument %q", arg)
+		}
+	}
+
+	entries, err := fetchWindowFocusEntries(query)
+	if err != nil {
+		return reportError(ctx, fmt.Errorf("load window_focus entries: %w", err))
+	}
+	if len(entries) == 0 {
+		fmt.Fprintln(ctx.Stdout(), "No focused workspaces found.")
+		return nil
+	}
+
+	if open {
+		return openRecentWorkspace(ctx, entries)
+	}
+
+	nameWidth := 0
+	for _, entry := range entries {
+		if n := len([]rune(entry.WorkspaceName)); n > nameWidth {
+			nameWidth = n
+		}
+	}
+
+	for _, entry := range entries {
+		when := "unknown time"
+		if focusedAt := entry.FocusedAtTime(); !focusedAt.IsZero() {
+			when = focusedAt.Format("2006-01-02 15:04 MST")
+		}
+		fmt.Fprintf(ctx.Stdout(), "%s  %-*s  %s\n", when, nameWidth, entry.WorkspaceName, entry.WorkspacePath)
+	}
+	return nil
+}
+
+func openRecentWorkspace(ctx *snap.Context, entries []windowFocusEntry) error {
+	var candidates []windowFocusEntry
+	for _, entry := range entries {
+		if workspaceOpenPath(&entry) != "" {
+			candidates = append(candidates, entry)
+		}
+	}
+	if len(candidates) == 0 {
+		fmt.Fprintln(ctx.Stdout(), "No recent workspaces have a path to open.")
+		return nil
+	}
+
+	idx, err := fuzzyfinder.Find(
+		candidates,
+		func(i int) string {
+			entry := candidates[i]
+			when := ""
+			if focusedAt := entry.FocusedAtTime(); !focusedAt.IsZero() {
+				when = focusedAt.Format("2006-01-02 15:04")
+			}
+			return fmt.Sprintf("%s  %s  %s", entry.WorkspaceName, workspaceOpenPath(&entry), when)
+		},
+		fuzzyfinder.WithPromptString("recentWorkspaces> "),
+	)
+	if err != nil {
+		if errors.Is(err, fuzzyfinder.ErrAbort) {
+			return errUserAbort
+		}
+		return reportError(ctx, fmt.Errorf("select workspace: %w", err))
+	}
+
+	openPath := workspaceOpenPath(&candidates[idx])
+	if _, err := os.Stat(openPath); err != nil {
+		return reportError(ctx, fmt.Errorf("workspace %s is no longer available: %w", openPath, err))
+	}
+
+	if err := openInEditor(ctx, openPath); err != nil {
+		return reportError(ctx, fmt.Errorf("open %s: %w", openPath, err))
+	}
+
+	fmt.Fprintf(ctx.Stdout(), "✔️ Opened %s in %s\n", openPath, editorDisplayName())
+	return nil
+}
+
+// workspaceOpenPath prefers the workspace folder so the whole project reopens,
+// falling back to the entry's active file when no folder was recorded.
+func workspaceOpenPath(entry *windowFocusEntry) string {
+	if path := strings.TrimSpace(entry.WorkspacePath); path != "" {
+		return path
+	}
+	return entry.cursorOpenPath()
+}
+
 func focusCursorWindowByTitle(title string) (bool, string, error) {
 	trimmed := strings.TrimSpace(title)
 	if trimmed == "" {
@@ -2081,7 +3320,7 @@ end if
 
 return "NOT_FOUND"`, escapeAppleScriptString(trimmed))
 
-	cmd := exec.Command("osascript", "-e", script)
+	cmd := flowCommand("osascript", "-e", script)
 	output, err := cmd.CombinedOutput()
 	if err != nil {
 		trimmedErr := strings.TrimSpace(string(output))
@@ -2149,7 +3388,7 @@ end tell
 
 return ""`
 
-	cmd := exec.Command("osascript", "-e", script)
+	cmd := flowCommand("osascript", "-e", script)
 	output, err := cmd.CombinedOutput()
 	if err != nil {
 		trimmed := strings.TrimSpace(string(output))
@@ -2163,9 +3402,10 @@ return ""`
 }
 
 func runShExec(ctx *snap.Context) error {
-	if ctx.NArgs() != 0 {
-		fmt.Fprintf(ctx.Stderr(), "Usage: %s shExec\n", commandName)
-		return fmt.Errorf("expected 0 arguments, got %d", ctx.NArgs())
+	respectGitignore, err := gitignoreFlag(ctx)
+	if err != nil {
+		fmt.Fprintf(ctx.Stderr(), "Usage: %s shExec [--gitignore]\n", commandName)
+		return err
 	}
 
 	homeDir, err := os.UserHomeDir()
@@ -2174,7 +3414,7 @@ func runShExec(ctx *snap.Context) error {
 	}
 
 	scriptsDir := filepath.Join(homeDir, "config", "sh")
-	scripts, err := collectShellScripts(scriptsDir)
+	scripts, err := collectShellScripts(scriptsDir, respectGitignore)
 	if err != nil {
 		return reportError(ctx, err)
 	}
@@ -2193,7 +3433,7 @@ func runShExec(ctx *snap.Context) error {
 	)
 	if err != nil {
 		if errors.Is(err, fuzzyfinder.ErrAbort) {
-			return nil
+			return errUserAbort
 		}
 		return reportError(ctx, fmt.Errorf("select script: %w", err))
 	}
@@ -2219,7 +3459,9 @@ type scriptCandidate struct {
 	Relative string
 }
 
-func collectShellScripts(root string) ([]scriptCandidate, error) {
+// collectShellScripts lists the scripts under root, narrowed to the files
+// git lists when respectGitignore is set and root is inside a repository.
+func collectShellScripts(root string, respectGitignore bool) ([]scriptCandidate, error) {
 	info, err := os.Stat(root)
 	if err != nil {
 		if errors.Is(err, os.ErrNotExist) {
@@ -2232,6 +3474,25 @@ func collectShellScripts(root string) ([]scriptCandidate, error) {
 	}
 
 	var scripts []scriptCandidate
+	if respectGitignore {
+		if names, ok := gitVisibleFiles(root); ok {
+			for _, rel := range names {
+				path := filepath.Join(root, rel)
+				entryInfo, err := os.Lstat(path)
+				if err != nil || !entryInfo.Mode().IsRegular() {
+					continue
+				}
+				if isShellScriptFile(entryInfo.Name(), entryInfo.Mode()) {
+					scripts = append(scripts, scriptCandidate{Absolute: path, Relative: rel})
+				}
+			}
+			sort.Slice(scripts, func(i, j int) bool {
+				return scripts[i].Relative < scripts[j].Relative
+			})
+			return scripts, nil
+		}
+	}
+
 	err = filepath.WalkDir(root, func(path string, d fs.DirEntry, walkErr error) error {
 		if walkErr != nil {
 			if errors.Is(walkErr, fs.ErrPermission) {
@@ -2241,6 +3502,9 @@ func collectShellScripts(root string) ([]scriptCandidate, error) {
 		}
 
 		if d.IsDir() {
+			if path != root && shouldSkip(d.Name()) {
+				return filepath.SkipDir
+			}
 			return nil
 		}
 
@@ -2286,32 +3550,6 @@ func isShellScriptFile(name string, mode fs.FileMode) bool {
 	return mode&0o111 != 0
 }
 
-func activeSafariURL() (string, error) {
-	if _, err := exec.LookPath("osascript"); err != nil {
-		return "", fmt.Errorf("osascript not found in PATH: %w", err)
-	}
-
-	script := `tell application "Safari"
-	if it is running then
-		if exists front document then
-			return URL of front document
-		end if
-	end if
-end tell`
-	cmd := exec.Command("osascript", "-e", script)
-	output, err := cmd.Output()
-	if err != nil {
-		return "", fmt.Errorf("osascript Safari URL: %w", err)
-	}
-
-	url := strings.TrimSpace(string(output))
-	if url == "" {
-		return "", fmt.Errorf("Safari has no active tab URL")
-	}
-
-	return url, nil
-}
-
 func runDeploy(ctx *snap.Context) error {
 	if ctx.NArgs() != 0 {
 		fmt.Fprintf(ctx.Stderr(), "Usage: %s deploy\n", commandName)
@@ -2338,95 +3576,30 @@ func runDeploy(ctx *snap.Context) error {
 		return fmt.Errorf("task command not found in PATH: %w", err)
 	}
 
-	cmd := exec.Command("task", "deploy")
-	cmd.Stdin = ctx.Stdin()
-	cmd.Stdout = ctx.Stdout()
-	cmd.Stderr = ctx.Stderr()
-	if err := cmd.Run(); err != nil {
-		return fmt.Errorf("task deploy failed: %w", err)
-	}
-	return nil
-}
-
-func runYoutubeToSound(ctx *snap.Context) error {
-	var (
-		videoURL string
-		err      error
-	)
-
-	if ctx.NArgs() > 0 {
-		videoURL = strings.TrimSpace(ctx.Arg(0))
-	} else {
-		videoURL, err = safariFrontmostURL()
-		if err != nil {
-			fmt.Fprintf(ctx.Stderr(), "Usage: %s youtubeToSound [youtube-url] [yt-dlp-args...]\n", commandName)
-			return reportError(ctx, fmt.Errorf("determine Safari tab URL: %w", err))
-		}
-	}
-
-	if videoURL == "" {
-		fmt.Fprintf(ctx.Stderr(), "Usage: %s youtubeToSound [youtube-url] [yt-dlp-args...]\n", commandName)
-		return reportError(ctx, fmt.Errorf("youtube url cannot be empty"))
-	}
-
-	if _, err := url.ParseRequestURI(videoURL); err != nil {
-		return reportError(ctx, fmt.Errorf("validate url %q: %w", videoURL, err))
-	}
-
-	downloader := "yt-dlp"
-	if _, err := exec.LookPath(downloader); err != nil {
-		return reportError(ctx, fmt.Errorf("%s not found in PATH: %w", downloader, err))
-	}
-
-	homeDir, err := os.UserHomeDir()
-	if err != nil {
-		return reportError(ctx, fmt.Errorf("determine home directory: %w", err))
-	}
-
-	targetDir := filepath.Join(homeDir, ".flow", "youtube-sound")
-	if err := os.MkdirAll(targetDir, 0o755); err != nil {
-		return reportError(ctx, fmt.Errorf("create directory %s: %w", targetDir, err))
+	if !deployStampsBuildInfo(string(contents)) {
+		fmt.Fprintf(ctx.Stderr(), "ℹ️ %s does not pass -ldflags to go build, so version will report buildTime unknown.\n", taskfilePath)
+		fmt.Fprintln(ctx.Stderr(), "   Add -ldflags \"{{.LDFLAGS}}\" to the deploy task, or use release instead.")
 	}
 
-	outputTemplate := filepath.Join(targetDir, "%(title)s.%(ext)s")
-	args := []string{"--extract-audio", "--audio-format", "mp3", "--audio-quality", "0", "--no-playlist", "-o", outputTemplate}
-	if ctx.NArgs() > 1 {
-		extra := ctx.Args()[1:]
-		for _, raw := range extra {
-			trimmed := strings.TrimSpace(raw)
-			if trimmed != "" {
-				args = append(args, trimmed)
-			}
-		}
-	}
-
-	defaultBrowser := strings.TrimSpace(os.Getenv("FLOW_YOUTUBE_COOKIES_BROWSER"))
-	if defaultBrowser == "" {
-		defaultBrowser = "safari"
-	}
-	if !strings.EqualFold(defaultBrowser, "none") && !containsCookiesArgument(args) {
-		args = append(args, "--cookies-from-browser", defaultBrowser)
-	}
-	args = append(args, videoURL)
-	cmd := exec.Command(downloader, args...)
+	// The metadata goes in both as task variables ({{.LDFLAGS}}) and as
+	// environment variables ($LDFLAGS) so either style of Taskfile can use it.
+	build := buildMetadata(".")
+	ldflags := build.ldflags()
+	cmd := flowCommand("task", "deploy", "LDFLAGS="+ldflags, "BUILD_TIME="+build.Time, "GIT_COMMIT="+build.Commit)
+	cmd.Env = append(os.Environ(), "LDFLAGS="+ldflags, "BUILD_TIME="+build.Time, "GIT_COMMIT="+build.Commit)
+	cmd.Stdin = ctx.Stdin()
 	cmd.Stdout = ctx.Stdout()
 	cmd.Stderr = ctx.Stderr()
-	cmd.Stdin = ctx.Stdin()
 	if err := cmd.Run(); err != nil {
-		return reportError(ctx, fmt.Errorf("%s failed: %w", downloader, err))
+		return fmt.Errorf("task deploy failed: %w", err)
 	}
-
-	fmt.Fprintf(ctx.Stdout(), "✔️ Audio saved to %s\n", targetDir)
 	return nil
 }
 
-func containsCookiesArgument(args []string) bool {
-	for _, arg := range args {
-		if strings.HasPrefix(arg, "--cookies-from-browser") || strings.HasPrefix(arg, "--cookies") {
-			return true
-		}
-	}
-	return false
+// deployStampsBuildInfo reports whether a Taskfile sets buildTime itself or
+// uses the LDFLAGS that runDeploy provides.
+func deployStampsBuildInfo(taskfile string) bool {
+	return strings.Contains(taskfile, "main.buildTime") || strings.Contains(taskfile, "LDFLAGS")
 }
 
 func runSpotifyPlay(ctx *snap.Context) error {
@@ -2441,13 +3614,31 @@ func runSpotifyPlay(ctx *snap.Context) error {
 		return fmt.Errorf("spotify identifier cannot be empty")
 	}
 
+	if isSpotifyShortLink(input) {
+		resolved, err := resolveSpotifyShortLink(flowCtx, input)
+		if err != nil {
+			return reportError(ctx, err)
+		}
+		input = resolved
+	}
+
 	uri, err := normalizeSpotifyURI(input)
 	if err != nil {
 		return reportError(ctx, err)
 	}
 
+	if err := playSpotifyURI(ctx, uri); err != nil {
+		return reportError(ctx, err)
+	}
+
+	fmt.Fprintf(ctx.Stdout(), "✔️ Playing %s\n", uri)
+	return nil
+}
+
+// playSpotifyURI asks the Spotify app to play a spotify: URI.
+func playSpotifyURI(ctx *snap.Context, uri string) error {
 	if _, err := exec.LookPath("osascript"); err != nil {
-		return reportError(ctx, fmt.Errorf("osascript not found in PATH: %w", err))
+		return fmt.Errorf("osascript not found in PATH: %w", err)
 	}
 
 	script := fmt.Sprintf(`tell application "Spotify"
@@ -2455,15 +3646,13 @@ func runSpotifyPlay(ctx *snap.Context) error {
 	play track "%s"
 end tell`, escapeAppleScriptString(uri))
 
-	cmd := exec.Command("osascript", "-e", script)
+	cmd := flowCommand("osascript", "-e", script)
 	cmd.Stdout = ctx.Stdout()
 	cmd.Stderr = ctx.Stderr()
 	cmd.Stdin = ctx.Stdin()
 	if err := cmd.Run(); err != nil {
-		return reportError(ctx, fmt.Errorf("control Spotify via osascript: %w", err))
+		return fmt.Errorf("control Spotify via osascript: %w", err)
 	}
-
-	fmt.Fprintf(ctx.Stdout(), "✔️ Playing %s\n", uri)
 	return nil
 }
 
@@ -2483,7 +3672,10 @@ func normalizeSpotifyURI(input string) (string, error) {
 			return "", fmt.Errorf("parse Spotify URL: %w", err)
 		}
 		host := strings.ToLower(u.Host)
-		if !strings.HasSuffix(host, "spotify.com") && host != "spotify.link" {
+		if host == "spotify.link" {
+			return "", fmt.Errorf("spotify.link short URLs must be resolved to open.spotify.com first")
+		}
+		if !strings.HasSuffix(host, "spotify.com") {
 			return "", fmt.Errorf("expected a spotify.com URL, got %s", u.Host)
 		}
 
@@ -2493,89 +3685,255 @@ func normalizeSpotifyURI(input string) (string, error) {
 			return "", fmt.Errorf("unable to determine Spotify resource from URL")
 		}
 
-		resourceID := parts[len(parts)-1]
-		if resourceID == "" {
-			return "", fmt.Errorf("spotify URL missing resource identifier")
+		resourceID := parts[len(parts)-1]
+		if resourceID == "" {
+			return "", fmt.Errorf("spotify URL missing resource identifier")
+		}
+		if idx := strings.Index(resourceID, "?"); idx >= 0 {
+			resourceID = resourceID[:idx]
+		}
+
+		resourceType := ""
+		for i := len(parts) - 2; i >= 0; i-- {
+			candidate := strings.ToLower(parts[i])
+			if candidate == "" || candidate == "user" || candidate == "embed" || strings.HasPrefix(candidate, "intl-") {
+				continue
+			}
+			resourceType = candidate
+			break
+		}
+
+		if resourceType == "" {
+			resourceType = "track"
+		}
+
+		return fmt.Sprintf("spotify:%s:%s", resourceType, resourceID), nil
+	}
+
+	if strings.Contains(trimmed, "/") {
+		return "", fmt.Errorf("unrecognized Spotify identifier %q", trimmed)
+	}
+
+	return fmt.Sprintf("spotify:track:%s", trimmed), nil
+}
+
+const spotifyShortLinkTimeout = 10 * time.Second
+
+// spotifyOpenURLPattern finds the canonical link in the page spotify.link
+// serves when its redirect stops at an interstitial instead of open.spotify.com.
+var spotifyOpenURLPattern = regexp.MustCompile(`https://open\.spotify\.com/[A-Za-z0-9_\-/]+`)
+
+func isSpotifyShortLink(input string) bool {
+	u, err := url.Parse(strings.TrimSpace(input))
+	return err == nil && strings.EqualFold(u.Host, "spotify.link")
+}
+
+// resolveSpotifyShortLink follows a spotify.link redirect to the
+// open.spotify.com URL it was shared from. Short links carry only an opaque
+// code, so the resource type and id only exist in the target.
+func resolveSpotifyShortLink(ctx context.Context, link string) (string, error) {
+	ctx, cancel := context.WithTimeout(ctx, spotifyShortLinkTimeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, strings.TrimSpace(link), nil)
+	if err != nil {
+		return "", fmt.Errorf("resolve %s: %w", link, err)
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("resolve %s: %w", link, err)
+	}
+	defer resp.Body.Close()
+
+	if final := resp.Request.URL; strings.EqualFold(final.Host, "open.spotify.com") {
+		return final.String(), nil
+	}
+	if resp.StatusCode >= http.StatusBadRequest {
+		return "", fmt.Errorf("resolve %s: %s", link, resp.Status)
+	}
+	body, err := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	if err != nil {
+		return "", fmt.Errorf("resolve %s: %w", link, err)
+	}
+	if found := spotifyOpenURLPattern.Find(body); found != nil {
+		return string(found), nil
+	}
+	return "", fmt.Errorf("resolve %s: redirect ended at %s, not open.spotify.com", link, resp.Request.URL.Host)
+}
+
+func escapeAppleScriptString(value string) string {
+	value = strings.ReplaceAll(value, "\\", "\\\\")
+	value = strings.ReplaceAll(value, "\"", "\\\"")
+	return value
+}
+
+type commitPayload struct {
+	message    string
+	paragraphs []string
+	// streamed is set when the message was already printed while generating.
+	streamed bool
+}
+
+type commitStageMode string
+
+const (
+	commitStageAll        commitStageMode = "all"
+	commitStagePatch      commitStageMode = "patch"
+	commitStageStagedOnly commitStageMode = "staged-only"
+)
+
+const commitFlagsUsage = "[--all|--patch|--staged-only] [--sign] [--co-author \"Name <email>\"]... [--ticket-prefix]"
+
+var coAuthorPattern = regexp.MustCompile(`^[^<>\s][^<>]*\s<[^<>\s@]+@[^<>\s@]+>$`)
+
+type commitOptions struct {
+	stage        commitStageMode
+	sign         bool
+	coAuthors    []string
+	ticketPrefix bool
+}
+
+func commitUsage(label string) string {
+	return fmt.Sprintf("Usage: %s %s %s", commandName, label, commitFlagsUsage)
+}
+
+func printCommitFlagsHelp(out io.Writer) {
+	fmt.Fprintln(out)
+	fmt.Fprintln(out, "Staging:")
+	fmt.Fprintln(out, "  --all          Run `git add .` before generating the message (default)")
+	fmt.Fprintln(out, "  --patch        Run `git add -p` so you can stage individual hunks")
+	fmt.Fprintln(out, "  --staged-only  Leave the index alone and commit only what is already staged")
+	fmt.Fprintln(out)
+	fmt.Fprintf(out, "Set %s=1 to make --staged-only the default; --all restores `git add .`.\n", commitStagedOnlyEnv)
+	fmt.Fprintln(out)
+	fmt.Fprintln(out, "Attribution:")
+	fmt.Fprintln(out, "  --sign, -S                    Sign the commit (`git commit -S`)")
+	fmt.Fprintln(out, "  --co-author \"Name <email>\"    Add a Co-authored-by trailer (repeatable)")
+	fmt.Fprintln(out, "  --ticket-prefix               Prefix the subject with the ticket id in the branch name")
+	fmt.Fprintln(out)
+	fmt.Fprintf(out, "The ticket id is the first match of %s (default %s)\n", commitTicketPatternEnv, defaultTicketPattern)
+	fmt.Fprintln(out, "in the branch name, or its first capture group; abc-123 becomes \"ABC-123: \" and 42 \"#42: \".")
+}
+
+func defaultCommitStageMode() commitStageMode {
+	if envFlagEnabled(commitStagedOnlyEnv) {
+		return commitStageStagedOnly
+	}
+	return commitStageAll
+}
+
+func envFlagEnabled(key string) bool {
+	value, ok := lookupSetting(key)
+	if !ok {
+		return false
+	}
+	enabled, err := strconv.ParseBool(value)
+	return err == nil && enabled
+}
+
+// parseCommitOptions parses the staging and attribution flags shared by the
+// commit commands. extra, when non-nil, gets first look at each argument so
+// a command can accept flags of its own; it reports whether it consumed arg.
+func parseCommitOptions(ctx *snap.Context, label string, extra func(arg string) bool) (commitOptions, error) {
+	opts := commitOptions{stage: defaultCommitStageMode()}
+	for i := 0; i < ctx.NArgs(); i++ {
+		arg := strings.TrimSpace(ctx.Arg(i))
+		if arg == "" {
+			continue
 		}
-		if idx := strings.Index(resourceID, "?"); idx >= 0 {
-			resourceID = resourceID[:idx]
+		if extra != nil && extra(arg) {
+			continue
 		}
 
-		resourceType := ""
-		for i := len(parts) - 2; i >= 0; i-- {
-			candidate := strings.ToLower(parts[i])
-			if candidate == "" || candidate == "user" || candidate == "embed" || strings.HasPrefix(candidate, "intl-") {
+		switch arg {
+		case "--all", "-a":
+			opts.stage = commitStageAll
+		case "--patch", "-p":
+			opts.stage = commitStagePatch
+		case "--staged-only", "--staged":
+			opts.stage = commitStageStagedOnly
+		case "--sign", "-S":
+			opts.sign = true
+		case "--ticket-prefix":
+			opts.ticketPrefix = true
+		case "--co-author":
+			if i+1 >= ctx.NArgs() {
+				fmt.Fprintln(ctx.Stderr(), commitUsage(label))
+				return opts, reportError(ctx, fmt.Errorf("--co-author requires a value"))
+			}
+			i++
+			coAuthor, err := parseCoAuthor(ctx.Arg(i))
+			if err != nil {
+				return opts, reportError(ctx, err)
+			}
+			opts.coAuthors = append(opts.coAuthors, coAuthor)
+		default:
+			if value, ok := strings.CutPrefix(arg, "--co-author="); ok {
+				coAuthor, err := parseCoAuthor(value)
+				if err != nil {
+					return opts, reportError(ctx, err)
+				}
+				opts.coAuthors = append(opts.coAuthors, coAuthor)
 				continue
 			}
-			resourceType = candidate
-			break
-		}
-
-		if resourceType == "" {
-			resourceType = "track"
+			fmt.Fprintln(ctx.Stderr(), commitUsage(label))
+			return opts, reportError(ctx, fmt.Errorf("unexpected argument %q", arg))
 		}
-
-		return fmt.Sprintf("spotify:%s:%s", resourceType, resourceID), nil
 	}
-
-	if strings.Contains(trimmed, "/") {
-		return "", fmt.Errorf("unrecognized Spotify identifier %q", trimmed)
-	}
-
-	return fmt.Sprintf("spotify:track:%s", trimmed), nil
+	return opts, nil
 }
 
-func escapeAppleScriptString(value string) string {
-	value = strings.ReplaceAll(value, "\\", "\\\\")
-	value = strings.ReplaceAll(value, "\"", "\\\"")
-	return value
+func parseCoAuthor(value string) (string, error) {
+	trimmed := strings.Join(strings.Fields(value), " ")
+	if !coAuthorPattern.MatchString(trimmed) {
+		return "", fmt.Errorf("invalid co-author %q; expected \"Name <email>\"", value)
+	}
+	return trimmed, nil
 }
 
-func safariFrontmostURL() (string, error) {
-	script := `tell application "System Events"
-	set safariRunning to (name of processes) contains "Safari"
-end tell
-if not safariRunning then error "Safari is not running"
-tell application "Safari"
-	if not (exists front document) then error "Safari has no front document"
-	return URL of front document
-end tell`
-
-	cmd := exec.Command("osascript", "-e", script)
-	output, err := cmd.CombinedOutput()
-	if err != nil {
-		trimmed := strings.TrimSpace(string(output))
-		if trimmed != "" {
-			return "", fmt.Errorf("osascript: %s", trimmed)
-		}
-		return "", fmt.Errorf("osascript failed: %w", err)
+// withCoAuthorTrailers appends Co-authored-by trailers to the last paragraph,
+// starting a new trailer paragraph unless the message already ends with one.
+func withCoAuthorTrailers(paragraphs []string, coAuthors []string) []string {
+	if len(coAuthors) == 0 {
+		return paragraphs
 	}
 
-	url := strings.TrimSpace(string(output))
-	if url == "" {
-		return "", fmt.Errorf("front Safari tab URL is empty")
+	trailers := make([]string, 0, len(coAuthors))
+	for _, coAuthor := range coAuthors {
+		trailers = append(trailers, "Co-authored-by: "+coAuthor)
 	}
 
-	return url, nil
+	result := append([]string(nil), paragraphs...)
+	if n := len(result); n > 1 && isTrailerParagraph(result[n-1]) {
+		result[n-1] = result[n-1] + "\n" + strings.Join(trailers, "\n")
+		return result
+	}
+	return append(result, strings.Join(trailers, "\n"))
 }
 
-type commitPayload struct {
-	message    string
-	paragraphs []string
+func isTrailerParagraph(paragraph string) bool {
+	for _, line := range strings.Split(paragraph, "\n") {
+		key, value, ok := strings.Cut(line, ": ")
+		if !ok || key == "" || strings.ContainsAny(key, " \t") || strings.TrimSpace(value) == "" {
+			return false
+		}
+	}
+	return true
 }
 
 func runCommit(ctx *snap.Context) error {
-	if ctx.NArgs() != 0 {
-		return reportError(ctx, fmt.Errorf("Usage: %s commit", commandName))
+	opts, err := parseCommitOptions(ctx, "commit", nil)
+	if err != nil {
+		return err
 	}
 
-	payload, err := prepareCommit(ctx)
+	payload, err := prepareCommit(ctx, opts)
 	if err != nil {
 		return err
 	}
 
-	printProposedMessage(ctx, payload.message)
-	if err := commitWithPayload(ctx, payload); err != nil {
+	printProposedMessage(ctx, payload)
+	if err := commitWithPayload(ctx, payload, opts); err != nil {
 		return err
 	}
 
@@ -2584,17 +3942,18 @@ func runCommit(ctx *snap.Context) error {
 }
 
 func runCommitPush(ctx *snap.Context) error {
-	if ctx.NArgs() != 0 {
-		return reportError(ctx, fmt.Errorf("Usage: %s commitPush", commandName))
+	opts, err := parseCommitOptions(ctx, "commitPush", nil)
+	if err != nil {
+		return err
 	}
 
-	payload, err := prepareCommit(ctx)
+	payload, err := prepareCommit(ctx, opts)
 	if err != nil {
 		return err
 	}
 
-	printProposedMessage(ctx, payload.message)
-	if err := commitWithPayload(ctx, payload); err != nil {
+	printProposedMessage(ctx, payload)
+	if err := commitWithPayload(ctx, payload, opts); err != nil {
 		return err
 	}
 	printCommitSuccess(ctx, payload)
@@ -2607,24 +3966,47 @@ func runCommitPush(ctx *snap.Context) error {
 	return nil
 }
 
+const commitReviewUsageLabel = "commitReviewAndPush [--yes]"
+
 func runCommitReviewAndPush(ctx *snap.Context) error {
-	if ctx.NArgs() != 0 {
-		return reportError(ctx, fmt.Errorf("Usage: %s commitReviewAndPush", commandName))
+	yes := false
+	opts, err := parseCommitOptions(ctx, commitReviewUsageLabel, func(arg string) bool {
+		if arg == "--yes" || arg == "-y" {
+			yes = true
+			return true
+		}
+		return false
+	})
+	if err != nil {
+		return err
 	}
 
-	payload, err := prepareCommit(ctx)
+	// The review needs a keyboard. From a script or CI job, fail before
+	// spending a model call instead of hanging on stdin, unless --yes says
+	// to take the proposed message as is.
+	if !yes && !stdinIsTerminal(ctx) {
+		fmt.Fprintln(ctx.Stderr(), commitUsage(commitReviewUsageLabel))
+		return reportError(ctx, fmt.Errorf("stdin is not a terminal, so the message cannot be reviewed; pass --yes to commit and push it without review"))
+	}
+
+	payload, err := prepareCommit(ctx, opts)
 	if err != nil {
 		return err
 	}
 
-	updatedMessage, confirmed, err := promptCommitConfirmation(ctx, payload.message)
-	if err != nil {
-		return reportError(ctx, err)
+	updatedMessage, confirmed := payload.message, true
+	if yes {
+		fmt.Fprintln(ctx.Stdout(), "ℹ️ --yes given; committing the proposed message without review")
+	} else {
+		updatedMessage, confirmed, err = promptCommitConfirmation(ctx, payload.message)
+		if err != nil {
+			return reportError(ctx, err)
+		}
 	}
 
 	if !confirmed {
 		fmt.Fprintln(ctx.Stdout(), "Commit cancelled.")
-		return nil
+		return errUserAbort
 	}
 
 	if updatedMessage != payload.message {
@@ -2638,10 +4020,11 @@ func runCommitReviewAndPush(ctx *snap.Context) error {
 		}
 		payload.message = trimmed
 		payload.paragraphs = paragraphs
+		payload.streamed = false
 	}
 
-	printProposedMessage(ctx, payload.message)
-	if err := commitWithPayload(ctx, payload); err != nil {
+	printProposedMessage(ctx, payload)
+	if err := commitWithPayload(ctx, payload, opts); err != nil {
 		return err
 	}
 	printCommitSuccess(ctx, payload)
@@ -2654,8 +4037,8 @@ func runCommitReviewAndPush(ctx *snap.Context) error {
 	return nil
 }
 
-func prepareCommit(ctx *snap.Context) (*commitPayload, error) {
-	if err := ensureGitRepository(); err != nil {
+func prepareCommit(ctx *snap.Context, opts commitOptions) (*commitPayload, error) {
+	if err := gitutil.EnsureRepository(flowCtx); err != nil {
 		return nil, err
 	}
 
@@ -2664,11 +4047,12 @@ func prepareCommit(ctx *snap.Context) (*commitPayload, error) {
 		return nil, reportError(ctx, err)
 	}
 
-	if err := runGitCommandStreaming(ctx, "add", "."); err != nil {
-		return nil, reportError(ctx, fmt.Errorf("git add .: %w", err))
+	// The message is always generated from whatever ends up staged.
+	if err := stageForCommit(ctx, opts); err != nil {
+		return nil, err
 	}
 
-	diffOutput, err := exec.Command("git", "diff", "--cached").CombinedOutput()
+	diffOutput, err := flowCommand("git", "diff", "--cached").CombinedOutput()
 	if err != nil {
 		return nil, reportError(ctx, fmt.Errorf("git diff --cached: %w", err))
 	}
@@ -2678,15 +4062,59 @@ func prepareCommit(ctx *snap.Context) (*commitPayload, error) {
 		return nil, reportError(ctx, fmt.Errorf("no staged changes to commit; stage files with git add"))
 	}
 
+	payload, err := proposeCommitMessage(ctx, apiKey, diff)
+	if err != nil {
+		return nil, err
+	}
+	if opts.ticketPrefix {
+		if err := applyTicketPrefix(ctx, payload); err != nil {
+			return nil, err
+		}
+	}
+	return payload, nil
+}
+
+func stageForCommit(ctx *snap.Context, opts commitOptions) error {
+	switch opts.stage {
+	case commitStagePatch:
+		if err := runGitCommandStreaming(ctx, "add", "-p"); err != nil {
+			return reportError(ctx, fmt.Errorf("git add -p: %w", err))
+		}
+	case commitStageStagedOnly:
+	default:
+		if err := runGitCommandStreaming(ctx, "add", "."); err != nil {
+			return reportError(ctx, fmt.Errorf("git add .: %w", err))
+		}
+	}
+	return nil
+}
+
+// proposeCommitMessage asks the model for a message describing diff,
+// streaming it to stdout as it arrives.
+func proposeCommitMessage(ctx *snap.Context, apiKey string, diff string) (*commitPayload, error) {
 	trimmedDiff, truncated := truncateDiffForCommit(diff)
+	trimmedDiff, redacted := redactDiffSecrets(trimmedDiff)
+	if redacted > 0 {
+		fmt.Fprintf(ctx.Stderr(), "ℹ️ Redacted %d likely secret(s) from the diff before sending it to the model\n", redacted)
+	}
 
-	statusOutput, statusErr := exec.Command("git", "status", "--short").CombinedOutput()
+	statusOutput, statusErr := flowCommand("git", "status", "--short").CombinedOutput()
 	status := ""
 	if statusErr == nil {
 		status = string(statusOutput)
 	}
 
-	message, err := generateCommitMessage(ctx.Context(), apiKey, trimmedDiff, status, truncated)
+	streamedHeader := false
+	message, streamed, err := generateCommitMessage(ctx.Context(), apiKey, trimmedDiff, status, truncated, func(token string) {
+		if !streamedHeader {
+			fmt.Fprintln(ctx.Stdout(), "Proposed commit message:")
+			streamedHeader = true
+		}
+		fmt.Fprint(ctx.Stdout(), token)
+	})
+	if streamedHeader {
+		fmt.Fprint(ctx.Stdout(), "\n\n")
+	}
 	if err != nil {
 		return nil, reportError(ctx, err)
 	}
@@ -2700,16 +4128,19 @@ func prepareCommit(ctx *snap.Context) (*commitPayload, error) {
 		return nil, reportError(ctx, fmt.Errorf("commit message is empty after formatting"))
 	}
 
-	return &commitPayload{message: message, paragraphs: paragraphs}, nil
+	return &commitPayload{message: message, paragraphs: paragraphs, streamed: streamed}, nil
 }
 
-func commitWithPayload(ctx *snap.Context, payload *commitPayload) error {
+func commitWithPayload(ctx *snap.Context, payload *commitPayload, opts commitOptions) error {
 	args := []string{"commit"}
-	for _, paragraph := range payload.paragraphs {
+	if opts.sign {
+		args = append(args, "-S")
+	}
+	for _, paragraph := range withCoAuthorTrailers(payload.paragraphs, opts.coAuthors) {
 		args = append(args, "-m", paragraph)
 	}
 
-	cmd := exec.Command("git", args...)
+	cmd := flowCommand("git", args...)
 	cmd.Stdout = ctx.Stdout()
 	cmd.Stderr = ctx.Stderr()
 	cmd.Stdin = ctx.Stdin()
@@ -2720,8 +4151,11 @@ func commitWithPayload(ctx *snap.Context, payload *commitPayload) error {
 	return nil
 }
 
-func printProposedMessage(ctx *snap.Context, message string) {
-	fmt.Fprintf(ctx.Stdout(), "Proposed commit message:\n%s\n\n", message)
+func printProposedMessage(ctx *snap.Context, payload *commitPayload) {
+	if payload.streamed {
+		return
+	}
+	fmt.Fprintf(ctx.Stdout(), "Proposed commit message:\n%s\n\n", payload.message)
 }
 
 func printCommitSuccess(ctx *snap.Context, payload *commitPayload) {
@@ -2811,8 +4245,16 @@ func findEditor() string {
 	return "vi"
 }
 
+// stdinIsTerminal reports whether the command's stdin is a terminal that
+// can be put in raw mode for a single-key answer.
+func stdinIsTerminal(ctx *snap.Context) bool {
+	file, ok := ctx.Stdin().(*os.File)
+	return ok && fzfutil.IsTty(file)
+}
+
 func readConfirmationChoice(ctx *snap.Context) (byte, error) {
-	if file, ok := ctx.Stdin().(*os.File); ok {
+	// stty only works on a terminal; piped input is read byte by byte below.
+	if file, ok := ctx.Stdin().(*os.File); ok && fzfutil.IsTty(file) {
 		stateCmd := exec.Command("stty", "-g")
 		stateCmd.Stdin = file
 		stateCmd.Stdout = nil
@@ -2883,19 +4325,18 @@ func resolveOpenAIKey(context.Context) (string, error) {
 }
 
 func reportError(ctx *snap.Context, err error) error {
-	if err == nil {
-		return nil
+	err = withRunCause(err)
+	if err == nil || errors.Is(err, errUserAbort) {
+		return err
 	}
 	fmt.Fprintln(ctx.Stderr(), err.Error())
 	return err
 }
 
-func generateCommitMessage(parent context.Context, apiKey string, diff string, status string, truncated bool) (string, error) {
-	client := openai.NewClient(option.WithAPIKey(apiKey))
-
-	requestCtx, cancel := context.WithTimeout(parent, 45*time.Second)
-	defer cancel()
-
+// generateCommitMessage asks the model for a commit message. When onToken is
+// non-nil the response is streamed through it; the returned bool reports
+// whether the full message was streamed.
+func generateCommitMessage(parent context.Context, apiKey string, diff string, status string, truncated bool, onToken func(string)) (string, bool, error) {
 	systemPrompt := "You are an expert software engineer who writes clear, concise git commit messages. Use imperative mood, keep the subject line under 72 characters, and include an optional body with bullet points if helpful. Never wrap the message in quotes. Never include secrets, credentials, or file contents from .env files, environment variables, keys, or other sensitive data—even if they appear in the diff."
 
 	var userPromptBuilder strings.Builder
@@ -2910,8 +4351,30 @@ func generateCommitMessage(parent context.Context, apiKey string, diff string, s
 		userPromptBuilder.WriteString(s)
 	}
 
-	resp, err := client.Chat.Completions.New(requestCtx, openai.ChatCompletionNewParams{
-		Model: shared.ChatModel(commitModelName),
+	params := newChatParams(systemPrompt, userPromptBuilder.String())
+	message, streamed, err := runChatCompletion(parent, apiKey, params, onToken)
+	if err != nil {
+		return "", false, fmt.Errorf("generate commit message: %w", err)
+	}
+
+	message = strings.TrimSpace(message)
+	if message == "" {
+		return "", false, fmt.Errorf("model returned an empty commit message")
+	}
+
+	return message, streamed, nil
+}
+
+func commitModel() string {
+	if model, ok := lookupSetting(commitModelEnv); ok {
+		return model
+	}
+	return commitModelName
+}
+
+func newChatParams(systemPrompt, userPrompt string) openai.ChatCompletionNewParams {
+	return openai.ChatCompletionNewParams{
+		Model: shared.ChatModel(commitModel()),
 		Messages: []openai.ChatCompletionMessageParamUnion{
 			{
 				OfSystem: &openai.ChatCompletionSystemMessageParam{
@@ -2920,25 +4383,199 @@ func generateCommitMessage(parent context.Context, apiKey string, diff string, s
 			},
 			{
 				OfUser: &openai.ChatCompletionUserMessageParam{
-					Content: openai.ChatCompletionUserMessageParamContentUnion{OfString: openai.String(userPromptBuilder.String())},
+					Content: openai.ChatCompletionUserMessageParamContentUnion{OfString: openai.String(userPrompt)},
 				},
 			},
 		},
+	}
+}
+
+type cachedOpenAIClient struct {
+	once   sync.Once
+	client openai.Client
+}
+
+var (
+	openAIClientsMu sync.Mutex
+	openAIClients   = map[string]*cachedOpenAIClient{}
+)
+
+// sharedOpenAIClient returns a client reused across calls for the same API key.
+func sharedOpenAIClient(apiKey string) *openai.Client {
+	openAIClientsMu.Lock()
+	entry, ok := openAIClients[apiKey]
+	if !ok {
+		entry = &cachedOpenAIClient{}
+		openAIClients[apiKey] = entry
+	}
+	openAIClientsMu.Unlock()
+
+	entry.once.Do(func() {
+		// Retries are handled by withOpenAIRetry so attempts and delays stay configurable.
+		entry.client = openai.NewClient(option.WithAPIKey(apiKey), option.WithMaxRetries(0))
+	})
+	return &entry.client
+}
+
+// runChatCompletion streams the completion through onToken when it is set,
+// falling back to a regular request if streaming fails.
+func runChatCompletion(parent context.Context, apiKey string, params openai.ChatCompletionNewParams, onToken func(string)) (string, bool, error) {
+	client := sharedOpenAIClient(apiKey)
+
+	if onToken != nil {
+		content, received, err := streamChatCompletion(parent, client, params, onToken)
+		if err == nil {
+			return content, true, nil
+		}
+		if parent.Err() != nil {
+			return "", false, err
+		}
+		if received {
+			fmt.Fprintln(stderr)
+		}
+		fmt.Fprintf(stderr, "ℹ️ Streaming failed (%v); retrying without streaming\n", err)
+	}
+
+	var resp *openai.ChatCompletion
+	err := withOpenAIRetry(parent, func(requestCtx context.Context) error {
+		var callErr error
+		resp, callErr = client.Chat.Completions.New(requestCtx, params)
+		return callErr
 	})
 	if err != nil {
-		return "", fmt.Errorf("generate commit message: %w", err)
+		return "", false, err
 	}
 
 	if resp == nil || len(resp.Choices) == 0 {
-		return "", fmt.Errorf("model returned no commit message choices")
+		return "", false, fmt.Errorf("model returned no choices")
 	}
 
-	message := strings.TrimSpace(resp.Choices[0].Message.Content)
-	if message == "" {
-		return "", fmt.Errorf("model returned an empty commit message")
+	return resp.Choices[0].Message.Content, false, nil
+}
+
+func streamChatCompletion(parent context.Context, client *openai.Client, params openai.ChatCompletionNewParams, onToken func(string)) (string, bool, error) {
+	requestCtx, cancel := context.WithTimeout(parent, openAIRequestTimeout)
+	defer cancel()
+
+	stream := client.Chat.Completions.NewStreaming(requestCtx, params)
+	defer stream.Close()
+
+	var content strings.Builder
+	for stream.Next() {
+		chunk := stream.Current()
+		if len(chunk.Choices) == 0 {
+			continue
+		}
+		delta := chunk.Choices[0].Delta.Content
+		if delta == "" {
+			continue
+		}
+		content.WriteString(delta)
+		onToken(delta)
+	}
+	if err := stream.Err(); err != nil {
+		return "", content.Len() > 0, err
+	}
+	if strings.TrimSpace(content.String()) == "" {
+		return "", false, fmt.Errorf("stream returned no content")
+	}
+
+	return content.String(), true, nil
+}
+
+// withOpenAIRetry runs call with a fresh per-attempt timeout, retrying rate
+// limits, server errors, and network failures with exponential backoff and jitter.
+func withOpenAIRetry(parent context.Context, call func(context.Context) error) error {
+	attempts := openAIMaxAttempts()
+	baseDelay := openAIRetryDelay()
+
+	var err error
+	for attempt := 1; attempt <= attempts; attempt++ {
+		requestCtx, cancel := context.WithTimeout(parent, openAIRequestTimeout)
+		err = call(requestCtx)
+		cancel()
+		if err == nil {
+			return nil
+		}
+		if parent.Err() != nil || !isRetryableOpenAIError(err) {
+			return err
+		}
+		if attempt == attempts {
+			break
+		}
+
+		delay := openAIRetryBackoff(baseDelay, attempt)
+		fmt.Fprintf(stderr, "ℹ️ OpenAI request failed (attempt %d/%d): %v; retrying in %s\n", attempt, attempts, err, delay.Round(time.Millisecond))
+
+		select {
+		case <-parent.Done():
+			return parent.Err()
+		case <-time.After(delay):
+		}
+	}
+
+	if attempts > 1 {
+		return fmt.Errorf("giving up after %d attempts: %w", attempts, err)
+	}
+	return err
+}
+
+// openAIRetryBackoff doubles base for each failed attempt and adds up to 50%
+// jitter, capped at maxOpenAIRetryDelay. The cap is checked before shifting so
+// a large base or attempt count cannot overflow into a negative delay.
+func openAIRetryBackoff(base time.Duration, attempt int) time.Duration {
+	delay := maxOpenAIRetryDelay
+	if shift := attempt - 1; shift < 32 && base <= maxOpenAIRetryDelay>>shift {
+		delay = base << shift
+	}
+	delay += time.Duration(rand.Int63n(int64(delay)/2 + 1))
+	return min(delay, maxOpenAIRetryDelay)
+}
+
+func isRetryableOpenAIError(err error) bool {
+	var apiErr *openai.Error
+	if errors.As(err, &apiErr) {
+		switch {
+		case apiErr.StatusCode == http.StatusRequestTimeout,
+			apiErr.StatusCode == http.StatusConflict,
+			apiErr.StatusCode == http.StatusTooManyRequests,
+			apiErr.StatusCode >= http.StatusInternalServerError:
+			return true
+		}
+		return false
+	}
+
+	if errors.Is(err, context.DeadlineExceeded) || errors.Is(err, io.ErrUnexpectedEOF) {
+		return true
 	}
+	var netErr net.Error
+	return errors.As(err, &netErr)
+}
+
+func openAIMaxAttempts() int {
+	value, ok := lookupSetting(openAIMaxAttemptsEnv)
+	if !ok {
+		return defaultOpenAIMaxAttempts
+	}
+	attempts, err := strconv.Atoi(value)
+	if err != nil || attempts < 1 || attempts > maxOpenAIMaxAttempts {
+		fmt.Fprintf(stderr, "ℹ️ Ignoring invalid %s=%q (want 1-%d); using %d\n", openAIMaxAttemptsEnv, value, maxOpenAIMaxAttempts, defaultOpenAIMaxAttempts)
+		return defaultOpenAIMaxAttempts
+	}
+	return attempts
+}
 
-	return message, nil
+func openAIRetryDelay() time.Duration {
+	value, ok := lookupSetting(openAIRetryDelayEnv)
+	if !ok {
+		return defaultOpenAIRetryDelay
+	}
+	delay, err := time.ParseDuration(value)
+	if err != nil || delay <= 0 {
+		fmt.Fprintf(stderr, "ℹ️ Ignoring invalid %s=%q; using %s\n", openAIRetryDelayEnv, value, defaultOpenAIRetryDelay)
+		return defaultOpenAIRetryDelay
+	}
+	return delay
 }
 
 func truncateDiffForCommit(diff string) (string, bool) {
@@ -3050,17 +4687,31 @@ func runPrivateForkRepoAndOpen(ctx *snap.Context) error {
 	return privateForkRepoFlow(ctx, "privateForkRepoAndOpen", true)
 }
 
-func privateForkRepoFlow(ctx *snap.Context, commandLabel string, openAfter bool) error {
-	if ctx.NArgs() > 1 {
-		fmt.Fprintf(ctx.Stderr(), "Usage: %s %s [github-repo-url]\n", commandName, commandLabel)
-		return fmt.Errorf("expected at most 1 argument, got %d", ctx.NArgs())
+func privateForkRepoFlow(ctx *snap.Context, commandLabel string, openAfter bool) (err error) {
+	usage := func() {
+		fmt.Fprintf(ctx.Stderr(), "Usage: %s %s [--cleanup-on-failure] [github-repo-url]\n", commandName, commandLabel)
 	}
 
 	var input string
-	if ctx.NArgs() == 1 {
-		input = strings.TrimSpace(ctx.Arg(0))
-	} else {
-		var err error
+	cleanupOnFailure := false
+	for i := 0; i < ctx.NArgs(); i++ {
+		arg := strings.TrimSpace(ctx.Arg(i))
+		switch {
+		case arg == "":
+		case arg == "--cleanup-on-failure":
+			cleanupOnFailure = true
+		case strings.HasPrefix(arg, "-"):
+			usage()
+			return fmt.Errorf("unknown flag %q", arg)
+		case input == "":
+			input = arg
+		default:
+			usage()
+			return fmt.Errorf("unexpected argument %q", arg)
+		}
+	}
+
+	if input == "" {
 		input, err = promptLine(ctx, "GitHub repository URL: ")
 		if err != nil {
 			return reportError(ctx, fmt.Errorf("read repository URL: %w", err))
@@ -3068,7 +4719,7 @@ func privateForkRepoFlow(ctx *snap.Context, commandLabel string, openAfter bool)
 	}
 
 	if input == "" {
-		fmt.Fprintf(ctx.Stderr(), "Usage: %s %s [github-repo-url]\n", commandName, commandLabel)
+		usage()
 		return fmt.Errorf("github repository url cannot be empty")
 	}
 
@@ -3110,11 +4761,46 @@ func privateForkRepoFlow(ctx *snap.Context, commandLabel string, openAfter bool)
 		return reportError(ctx, fmt.Errorf("check %s: %w", targetDir, err))
 	}
 
+	// From here on targetDir is ours. If a step fails, say which one and
+	// what it left behind, and remove the directory when asked to.
+	var completed []string
+	step := ""
+	setupDone := false
+	defer func() {
+		if setupDone || err == nil {
+			return
+		}
+		fmt.Fprintf(ctx.Stderr(), "ℹ️ %s stopped at step: %s\n", commandLabel, step)
+		if len(completed) > 0 {
+			fmt.Fprintf(ctx.Stderr(), "   Completed: %s\n", strings.Join(completed, ", "))
+		}
+		if _, statErr := os.Stat(targetDir); statErr != nil {
+			return
+		}
+		if !cleanupOnFailure {
+			fmt.Fprintf(ctx.Stderr(), "   Left behind: %s (remove it, or rerun with --cleanup-on-failure)\n", targetDir)
+			return
+		}
+		if removeErr := os.RemoveAll(targetDir); removeErr != nil {
+			fmt.Fprintf(ctx.Stderr(), "   Could not remove %s: %v\n", targetDir, removeErr)
+			return
+		}
+		fmt.Fprintf(ctx.Stderr(), "   Removed %s\n", targetDir)
+	}()
+	beginStep := func(name string) {
+		if step != "" {
+			completed = append(completed, step)
+		}
+		step = name
+	}
+
+	beginStep("clone " + cloneURL)
 	fmt.Fprintf(ctx.Stdout(), "ℹ️ Cloning %s into %s\n", cloneURL, targetDir)
 	if err := gitCloneTo(ctx, cloneURL, targetDir); err != nil {
 		return reportError(ctx, err)
 	}
 
+	beginStep("rename origin to upstream")
 	if err := runGitCommandInDir(ctx, targetDir, "remote", "rename", "origin", "upstream"); err != nil {
 		return reportError(ctx, fmt.Errorf("git remote rename origin upstream: %w", err))
 	}
@@ -3125,14 +4811,17 @@ func privateForkRepoFlow(ctx *snap.Context, commandLabel string, openAfter bool)
 	}
 
 	privateSSH := fmt.Sprintf("git@github.com:%s/%s.git", login, privateRepoName)
+	beginStep("add origin " + privateSSH)
 	if err := runGitCommandInDir(ctx, targetDir, "remote", "add", "origin", privateSSH); err != nil {
 		return reportError(ctx, fmt.Errorf("git remote add origin %s: %w", privateSSH, err))
 	}
 
+	beginStep("write flow.toml")
 	flowTomlCreated, err := ensureFlowToml(targetDir, owner, repo, login, privateRepoName)
 	if err != nil {
 		return reportError(ctx, fmt.Errorf("prepare flow.toml: %w", err))
 	}
+	setupDone = true
 
 	fmt.Fprintf(ctx.Stdout(), "✔️ Local copy: %s\n", targetDir)
 	fmt.Fprintf(ctx.Stdout(), "✔️ origin -> %s\n", privateSSH)
@@ -3178,18 +4867,56 @@ func ensureFlowToml(targetDir, owner, repo, login, privateRepoName string) (bool
 	return true, nil
 }
 
+// promptLine prints prompt and reads one line. It reads a byte at a time so
+// nothing past the newline is consumed, leaving piped answers to later
+// prompts in place.
 func promptLine(ctx *snap.Context, prompt string) (string, error) {
 	fmt.Fprint(ctx.Stdout(), prompt)
 
-	reader := bufio.NewReader(ctx.Stdin())
-	line, err := reader.ReadString('\n')
-	if errors.Is(err, io.EOF) {
-		return strings.TrimSpace(line), nil
+	var line []byte
+	buf := make([]byte, 1)
+	for {
+		n, err := ctx.Stdin().Read(buf)
+		if n > 0 {
+			if buf[0] == '\n' {
+				break
+			}
+			line = append(line, buf[0])
+		}
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return "", err
+		}
+	}
+	return strings.TrimSpace(string(line)), nil
+}
+
+// confirm asks a yes/no question, appending [Y/n] or [y/N] to prompt. An
+// empty reply takes the default only when stdin is a terminal, so piped or
+// closed input never says yes on the user's behalf.
+func confirm(ctx *snap.Context, prompt string, defaultYes bool) (bool, error) {
+	hint := " [y/N]: "
+	if defaultYes {
+		hint = " [Y/n]: "
 	}
+	reply, err := promptLine(ctx, prompt+hint)
 	if err != nil {
-		return "", err
+		return false, err
+	}
+	return confirmReply(reply, defaultYes && stdinIsTerminal(ctx)), nil
+}
+
+func confirmReply(reply string, defaultYes bool) bool {
+	switch strings.ToLower(strings.TrimSpace(reply)) {
+	case "":
+		return defaultYes
+	case "y", "yes":
+		return true
+	default:
+		return false
 	}
-	return strings.TrimSpace(line), nil
 }
 
 func currentGitHubLogin() (string, error) {
@@ -3197,7 +4924,7 @@ func currentGitHubLogin() (string, error) {
 		return "", fmt.Errorf("gh CLI not found in PATH: %w", err)
 	}
 
-	cmd := exec.Command("gh", "api", "user", "--jq", ".login")
+	cmd := flowCommand("gh", "api", "user", "--jq", ".login")
 	output, err := cmd.CombinedOutput()
 	if err != nil {
 		trimmed := strings.TrimSpace(string(output))
@@ -3220,7 +4947,7 @@ func githubRepoExists(owner, repo string) (bool, error) {
 	}
 
 	fullName := fmt.Sprintf("%s/%s", owner, repo)
-	cmd := exec.Command("gh", "repo", "view", fullName, "--json", "name")
+	cmd := flowCommand("gh", "repo", "view", fullName, "--json", "name")
 	output, err := cmd.CombinedOutput()
 	if err != nil {
 		var exitErr *exec.ExitError
@@ -3240,7 +4967,7 @@ func githubRepoExists(owner, repo string) (bool, error) {
 func createPrivateRepository(ctx *snap.Context, owner, repo string) error {
 	repoFull := fmt.Sprintf("%s/%s", owner, repo)
 
-	cmd := exec.Command("gh", "repo", "create", repoFull, "--private", "--confirm")
+	cmd := flowCommand("gh", "repo", "create", repoFull, "--private", "--confirm")
 	cmd.Stdout = ctx.Stdout()
 	cmd.Stderr = ctx.Stderr()
 	cmd.Stdin = ctx.Stdin()
@@ -3251,11 +4978,23 @@ func createPrivateRepository(ctx *snap.Context, owner, repo string) error {
 }
 
 func runCreateRepoFromRemote(ctx *snap.Context) error {
-	if err := ensureGitRepository(); err != nil {
+	copyURL := false
+	for i := 0; i < ctx.NArgs(); i++ {
+		switch arg := strings.TrimSpace(ctx.Arg(i)); arg {
+		case "":
+		case "--copy":
+			copyURL = true
+		default:
+			fmt.Fprintf(ctx.Stderr(), "Usage: %s createRepoFromRemote [--copy]\n", commandName)
+			return fmt.Errorf("unexpected argument %q", arg)
+		}
+	}
+
+	if err := gitutil.EnsureRepository(flowCtx); err != nil {
 		return err
 	}
 
-	cmd := exec.Command("git", "remote", "get-url", "origin")
+	cmd := flowCommand("git", "remote", "get-url", "origin")
 	output, err := cmd.Output()
 	if err != nil {
 		return fmt.Errorf("get git remote origin: %w", err)
@@ -3278,24 +5017,26 @@ func runCreateRepoFromRemote(ctx *snap.Context) error {
 
 	if exists {
 		fmt.Fprintf(ctx.Stdout(), "Repository %s/%s already exists\n", owner, repo)
-		return nil
+	} else {
+		if err := createPrivateRepository(ctx, owner, repo); err != nil {
+			return err
+		}
+		fmt.Fprintf(ctx.Stdout(), "Created repository %s/%s\n", owner, repo)
 	}
 
-	if err := createPrivateRepository(ctx, owner, repo); err != nil {
-		return err
+	if copyURL {
+		copyToClipboard(ctx, fmt.Sprintf("https://github.com/%s/%s", owner, repo))
 	}
-
-	fmt.Fprintf(ctx.Stdout(), "Created repository %s/%s\n", owner, repo)
 	return nil
 }
 
 func runGitIgnore(ctx *snap.Context) error {
-	if err := ensureGitRepository(); err != nil {
+	if err := gitutil.EnsureRepository(flowCtx); err != nil {
 		return err
 	}
 
 	// Get all changed and untracked files
-	cmd := exec.Command("git", "status", "--porcelain")
+	cmd := flowCommand("git", "status", "--porcelain")
 	output, err := cmd.Output()
 	if err != nil {
 		return fmt.Errorf("git status: %w", err)
@@ -3364,7 +5105,7 @@ func runGitIgnore(ctx *snap.Context) error {
 	)
 	if err != nil {
 		if errors.Is(err, fuzzyfinder.ErrAbort) {
-			return nil
+			return errUserAbort
 		}
 		return fmt.Errorf("select files: %w", err)
 	}
@@ -3425,12 +5166,12 @@ func runGitIgnore(ctx *snap.Context) error {
 }
 
 func runGitDiffSize(ctx *snap.Context) error {
-	if err := ensureGitRepository(); err != nil {
+	if err := gitutil.EnsureRepository(flowCtx); err != nil {
 		return err
 	}
 
 	// Get all changed and untracked files
-	cmd := exec.Command("git", "status", "--porcelain")
+	cmd := flowCommand("git", "status", "--porcelain")
 	output, err := cmd.Output()
 	if err != nil {
 		return fmt.Errorf("git status: %w", err)
@@ -3495,15 +5236,8 @@ func runGitDiffSize(ctx *snap.Context) error {
 
 	var tooBigFiles []string
 
-	for _, f := range files {
-		var sizeStr string
-		if f.bytes >= 1000000 {
-			sizeStr = fmt.Sprintf("%.1fMB", float64(f.bytes)/1000000)
-		} else if f.bytes >= 1000 {
-			sizeStr = fmt.Sprintf("%.1fKB", float64(f.bytes)/1000)
-		} else {
-			sizeStr = fmt.Sprintf("%dB", f.bytes)
-		}
+	for _, f := range files {
+		sizeStr := formatFileSize(f.bytes)
 
 		var marker string
 		if f.bytes >= bigThreshold {
@@ -3564,8 +5298,22 @@ func runGitDiffSize(ctx *snap.Context) error {
 	return nil
 }
 
+// formatFileSize renders bytes in decimal units: 512B, 12.3KB, 4.5MB, 1.2GB.
+func formatFileSize(bytes int64) string {
+	switch {
+	case bytes >= 1000000000:
+		return fmt.Sprintf("%.1fGB", float64(bytes)/1000000000)
+	case bytes >= 1000000:
+		return fmt.Sprintf("%.1fMB", float64(bytes)/1000000)
+	case bytes >= 1000:
+		return fmt.Sprintf("%.1fKB", float64(bytes)/1000)
+	default:
+		return fmt.Sprintf("%dB", bytes)
+	}
+}
+
 func runSmartCherryPick(ctx *snap.Context) error {
-	if err := ensureGitRepository(); err != nil {
+	if err := gitutil.EnsureRepository(flowCtx); err != nil {
 		return err
 	}
 
@@ -3590,7 +5338,7 @@ func runSmartCherryPick(ctx *snap.Context) error {
 		commits = []string{startHash}
 	} else {
 		// Range of commits (from startHash to endHash, inclusive)
-		cmd := exec.Command("git", "rev-list", "--reverse", startHash+"^.."+endHash)
+		cmd := flowCommand("git", "rev-list", "--reverse", startHash+"^.."+endHash)
 		output, err := cmd.Output()
 		if err != nil {
 			return fmt.Errorf("failed to get commit range: %w", err)
@@ -3619,26 +5367,26 @@ func runSmartCherryPick(ctx *snap.Context) error {
 		fmt.Fprintf(ctx.Stdout(), "\n[%d/%d] Processing commit %s\n", i+1, len(commits), commit)
 
 		// Get commit info for context
-		commitMsgCmd := exec.Command("git", "log", "-1", "--format=%s", commit)
+		commitMsgCmd := flowCommand("git", "log", "-1", "--format=%s", commit)
 		commitMsgOut, _ := commitMsgCmd.Output()
 		commitMsg := strings.TrimSpace(string(commitMsgOut))
 		fmt.Fprintf(ctx.Stdout(), "  Message: %s\n", commitMsg)
 
 		// Try normal cherry-pick first
-		cherryPickCmd := exec.Command("git", "cherry-pick", commit)
+		cherryPickCmd := flowCommand("git", "cherry-pick", commit)
 		cherryPickCmd.Stdout = ctx.Stdout()
 		cherryPickCmd.Stderr = ctx.Stderr()
 
 		if err := cherryPickCmd.Run(); err != nil {
 			// Check if there are conflicts
-			statusCmd := exec.Command("git", "status", "--porcelain")
+			statusCmd := flowCommand("git", "status", "--porcelain")
 			statusOut, _ := statusCmd.Output()
 
 			if strings.Contains(string(statusOut), "UU") || strings.Contains(string(statusOut), "AA") || strings.Contains(string(statusOut), "DD") {
 				fmt.Fprintf(ctx.Stdout(), "\n  Conflicts detected, using AI to resolve...\n")
 
 				// Get the diff of the commit being cherry-picked
-				diffCmd := exec.Command("git", "show", commit, "--format=")
+				diffCmd := flowCommand("git", "show", commit, "--format=")
 				diffOut, _ := diffCmd.Output()
 
 				// Get conflicted files
@@ -3646,7 +5394,7 @@ func runSmartCherryPick(ctx *snap.Context) error {
 
 				if len(conflictedFiles) == 0 {
 					// Abort and continue to next commit
-					exec.Command("git", "cherry-pick", "--abort").Run()
+					flowCommand("git", "cherry-pick", "--abort").Run()
 					return fmt.Errorf("cherry-pick failed but no conflicts detected")
 				}
 
@@ -3654,95 +5402,25 @@ func runSmartCherryPick(ctx *snap.Context) error {
 				for _, conflictedFile := range conflictedFiles {
 					fmt.Fprintf(ctx.Stdout(), "  Resolving: %s\n", conflictedFile)
 
-					// Read the conflicted file content
-					conflictedContent, err := os.ReadFile(conflictedFile)
-					if err != nil {
-						exec.Command("git", "cherry-pick", "--abort").Run()
-						return fmt.Errorf("failed to read conflicted file %s: %w", conflictedFile, err)
-					}
-
-					// Build prompt for Claude
-					prompt := fmt.Sprintf(`You are helping resolve a git merge conflict during a cherry-pick operation.
-
-The commit being cherry-picked has this message: %s
-
-The diff from the original commit:
-%s
-
-The file "%s" has merge conflicts. Here is the current content with conflict markers:
-%s
-
-Please resolve the conflicts intelligently by:
-1. Understanding the intent of both changes
-2. Merging them in a way that preserves both intentions where possible
-3. If changes conflict directly, prefer the incoming changes (from the cherry-picked commit) but ensure the result is valid code
-
-Output ONLY the resolved file content, without any explanation or markdown code blocks. Just the raw file content that should replace the conflicted file.`,
-						commitMsg,
-						string(diffOut),
-						conflictedFile,
-						string(conflictedContent))
-
-					// Call Claude Code SDK
-					bgCtx := context.Background()
-					iterator, err := claudecode.Query(bgCtx, prompt,
-						claudecode.WithCwd(cwd),
-						claudecode.WithPermissionMode(claudecode.PermissionModeBypassPermissions),
-					)
+					resolved, err := resolveConflictWithAI(cwd, conflictedFile, conflictContext{
+						Operation:     "cherry-pick",
+						CommitMessage: commitMsg,
+						CommitDiff:    string(diffOut),
+					})
 					if err != nil {
-						exec.Command("git", "cherry-pick", "--abort").Run()
-						return fmt.Errorf("failed to query Claude: %w", err)
-					}
-
-					var resolvedContent strings.Builder
-					for {
-						message, err := iterator.Next(bgCtx)
-						if err != nil {
-							if errors.Is(err, claudecode.ErrNoMoreMessages) {
-								break
-							}
-							iterator.Close()
-							exec.Command("git", "cherry-pick", "--abort").Run()
-							return fmt.Errorf("failed to get Claude response: %w", err)
-						}
-
-						if message == nil {
-							break
-						}
-
-						switch msg := message.(type) {
-						case *claudecode.AssistantMessage:
-							for _, block := range msg.Content {
-								if textBlock, ok := block.(*claudecode.TextBlock); ok {
-									resolvedContent.WriteString(textBlock.Text)
-								}
-							}
-						case *claudecode.ResultMessage:
-							if msg.IsError {
-								iterator.Close()
-								exec.Command("git", "cherry-pick", "--abort").Run()
-								return fmt.Errorf("Claude error: %s", msg.Result)
-							}
-						}
-					}
-					iterator.Close()
-
-					// Write the resolved content
-					resolved := resolvedContent.String()
-					if resolved == "" {
-						exec.Command("git", "cherry-pick", "--abort").Run()
-						return fmt.Errorf("Claude returned empty resolution for %s", conflictedFile)
+						flowCommand("git", "cherry-pick", "--abort").Run()
+						return err
 					}
 
 					if err := os.WriteFile(conflictedFile, []byte(resolved), 0644); err != nil {
-						exec.Command("git", "cherry-pick", "--abort").Run()
+						flowCommand("git", "cherry-pick", "--abort").Run()
 						return fmt.Errorf("failed to write resolved file %s: %w", conflictedFile, err)
 					}
 
 					// Stage the resolved file
-					addCmd := exec.Command("git", "add", conflictedFile)
+					addCmd := flowCommand("git", "add", conflictedFile)
 					if err := addCmd.Run(); err != nil {
-						exec.Command("git", "cherry-pick", "--abort").Run()
+						flowCommand("git", "cherry-pick", "--abort").Run()
 						return fmt.Errorf("failed to stage resolved file %s: %w", conflictedFile, err)
 					}
 
@@ -3750,20 +5428,20 @@ Output ONLY the resolved file content, without any explanation or markdown code
 				}
 
 				// Continue the cherry-pick
-				continueCmd := exec.Command("git", "cherry-pick", "--continue")
+				continueCmd := flowCommand("git", "cherry-pick", "--continue")
 				continueCmd.Env = append(os.Environ(), "GIT_EDITOR=true") // Skip commit message edit
 				continueCmd.Stdout = ctx.Stdout()
 				continueCmd.Stderr = ctx.Stderr()
 
 				if err := continueCmd.Run(); err != nil {
-					exec.Command("git", "cherry-pick", "--abort").Run()
+					flowCommand("git", "cherry-pick", "--abort").Run()
 					return fmt.Errorf("failed to continue cherry-pick after resolution: %w", err)
 				}
 
 				fmt.Fprintf(ctx.Stdout(), "  ✓ Cherry-pick completed with AI resolution\n")
 			} else {
 				// Some other error, abort
-				exec.Command("git", "cherry-pick", "--abort").Run()
+				flowCommand("git", "cherry-pick", "--abort").Run()
 				return fmt.Errorf("cherry-pick failed: %w", err)
 			}
 		} else {
@@ -3776,7 +5454,7 @@ Output ONLY the resolved file content, without any explanation or markdown code
 }
 
 func getConflictedFiles() []string {
-	cmd := exec.Command("git", "diff", "--name-only", "--diff-filter=U")
+	cmd := flowCommand("git", "diff", "--name-only", "--diff-filter=U")
 	output, err := cmd.Output()
 	if err != nil {
 		return nil
@@ -3793,7 +5471,7 @@ func getConflictedFiles() []string {
 }
 
 func gitCloneTo(ctx *snap.Context, cloneURL, targetDir string) error {
-	cmd := exec.Command("git", "clone", cloneURL, targetDir)
+	cmd := flowCommand("git", "clone", cloneURL, targetDir)
 	cmd.Stdout = ctx.Stdout()
 	cmd.Stderr = ctx.Stderr()
 	cmd.Stdin = ctx.Stdin()
@@ -3804,14 +5482,21 @@ func gitCloneTo(ctx *snap.Context, cloneURL, targetDir string) error {
 }
 
 func runGitFetchUpstream(ctx *snap.Context) error {
-	if err := ensureGitRepository(); err != nil {
+	if err := gitutil.EnsureRepository(flowCtx); err != nil {
 		return err
 	}
 
+	usage := func() {
+		fmt.Fprintf(ctx.Stderr(), "Usage: %s gitFetchUpstream [--all] [--no-prune] [--tags] [--depth <n>|--unshallow] [remote]\n", commandName)
+	}
+
 	remote := "upstream"
 	remoteSpecified := false
 	fetchAll := false
 	prune := true
+	tags := false
+	unshallow := false
+	depth := 0
 
 	for i := 0; i < ctx.NArgs(); i++ {
 		arg := strings.TrimSpace(ctx.Arg(i))
@@ -3824,8 +5509,28 @@ func runGitFetchUpstream(ctx *snap.Context) error {
 			fetchAll = true
 		case arg == "--no-prune":
 			prune = false
+		case arg == "--tags":
+			tags = true
+		case arg == "--unshallow":
+			unshallow = true
+		case arg == "--depth" || strings.HasPrefix(arg, "--depth="):
+			value := strings.TrimPrefix(arg, "--depth=")
+			if arg == "--depth" {
+				i++
+				if i >= ctx.NArgs() {
+					usage()
+					return fmt.Errorf("--depth requires a value")
+				}
+				value = ctx.Arg(i)
+			}
+			n, err := strconv.Atoi(strings.TrimSpace(value))
+			if err != nil || n < 1 {
+				usage()
+				return fmt.Errorf("invalid depth %q: expected a positive integer", value)
+			}
+			depth = n
 		case strings.HasPrefix(arg, "--"):
-			fmt.Fprintf(ctx.Stderr(), "Usage: %s gitFetchUpstream [--all] [--no-prune] [remote]\n", commandName)
+			usage()
 			return fmt.Errorf("unknown flag %q", arg)
 		default:
 			remoteSpecified = true
@@ -3834,9 +5539,19 @@ func runGitFetchUpstream(ctx *snap.Context) error {
 	}
 
 	if fetchAll && remoteSpecified {
-		fmt.Fprintf(ctx.Stderr(), "Usage: %s gitFetchUpstream [--all] [--no-prune] [remote]\n", commandName)
+		usage()
 		return fmt.Errorf("cannot specify a remote when using --all")
 	}
+	if unshallow && depth > 0 {
+		usage()
+		return fmt.Errorf("--depth and --unshallow cannot be combined")
+	}
+	if unshallow {
+		out, err := flowCommand("git", "rev-parse", "--is-shallow-repository").Output()
+		if err == nil && strings.TrimSpace(string(out)) != "true" {
+			return fmt.Errorf("--unshallow only applies to shallow clones; this repository is already complete")
+		}
+	}
 
 	args := []string{"fetch"}
 	var summary string
@@ -3851,29 +5566,50 @@ func runGitFetchUpstream(ctx *snap.Context) error {
 		if !exists {
 			return fmt.Errorf("git remote %q not found", remote)
 		}
-		args = append(args, remote)
 		summary = remote
 	}
+
+	var details []string
 	if prune {
 		args = append(args, "--prune")
+		details = append(details, "pruned")
+	}
+	if tags {
+		args = append(args, "--tags")
+		details = append(details, "with tags")
+	}
+	switch {
+	case unshallow:
+		args = append(args, "--unshallow")
+		details = append(details, "unshallowed")
+	case depth > 0:
+		args = append(args, "--depth", strconv.Itoa(depth))
+		details = append(details, fmt.Sprintf("depth %d", depth))
+	}
+	if !fetchAll {
+		args = append(args, remote)
 	}
 
 	if err := runGitCommandStreaming(ctx, args...); err != nil {
 		return fmt.Errorf("git %s: %w", strings.Join(args, " "), err)
 	}
 
+	if len(details) > 0 {
+		summary += " (" + strings.Join(details, ", ") + ")"
+	}
 	fmt.Fprintf(ctx.Stdout(), "✔️ Fetched %s\n", summary)
 	return nil
 }
 
 func runGitSyncFork(ctx *snap.Context) error {
-	if err := ensureGitRepository(); err != nil {
+	if err := gitutil.EnsureRepository(flowCtx); err != nil {
 		return err
 	}
 
 	branch := ""
 	strategy := "rebase"
 	remote := "upstream"
+	autostash := false
 
 	for i := 0; i < ctx.NArgs(); i++ {
 		arg := strings.TrimSpace(ctx.Arg(i))
@@ -3885,7 +5621,7 @@ func runGitSyncFork(ctx *snap.Context) error {
 		case arg == "--branch":
 			i++
 			if i >= ctx.NArgs() {
-				fmt.Fprintf(ctx.Stderr(), "Usage: %s gitSyncFork [--branch <name>] [--strategy rebase|merge] [--remote <remote>]\n", commandName)
+				fmt.Fprintf(ctx.Stderr(), "Usage: %s gitSyncFork [--branch <name>] [--strategy rebase|merge] [--remote <remote>] [--autostash]\n", commandName)
 				return fmt.Errorf("--branch requires a value")
 			}
 			branch = strings.TrimSpace(ctx.Arg(i))
@@ -3894,7 +5630,7 @@ func runGitSyncFork(ctx *snap.Context) error {
 		case arg == "--strategy":
 			i++
 			if i >= ctx.NArgs() {
-				fmt.Fprintf(ctx.Stderr(), "Usage: %s gitSyncFork [--branch <name>] [--strategy rebase|merge] [--remote <remote>]\n", commandName)
+				fmt.Fprintf(ctx.Stderr(), "Usage: %s gitSyncFork [--branch <name>] [--strategy rebase|merge] [--remote <remote>] [--autostash]\n", commandName)
 				return fmt.Errorf("--strategy requires a value")
 			}
 			strategy = strings.TrimSpace(ctx.Arg(i))
@@ -3903,14 +5639,16 @@ func runGitSyncFork(ctx *snap.Context) error {
 		case arg == "--remote":
 			i++
 			if i >= ctx.NArgs() {
-				fmt.Fprintf(ctx.Stderr(), "Usage: %s gitSyncFork [--branch <name>] [--strategy rebase|merge] [--remote <remote>]\n", commandName)
+				fmt.Fprintf(ctx.Stderr(), "Usage: %s gitSyncFork [--branch <name>] [--strategy rebase|merge] [--remote <remote>] [--autostash]\n", commandName)
 				return fmt.Errorf("--remote requires a value")
 			}
 			remote = strings.TrimSpace(ctx.Arg(i))
 		case strings.HasPrefix(arg, "--remote="):
 			remote = strings.TrimSpace(strings.TrimPrefix(arg, "--remote="))
+		case arg == "--autostash":
+			autostash = true
 		default:
-			fmt.Fprintf(ctx.Stderr(), "Usage: %s gitSyncFork [--branch <name>] [--strategy rebase|merge] [--remote <remote>]\n", commandName)
+			fmt.Fprintf(ctx.Stderr(), "Usage: %s gitSyncFork [--branch <name>] [--strategy rebase|merge] [--remote <remote>] [--autostash]\n", commandName)
 			return fmt.Errorf("unexpected argument %q", arg)
 		}
 	}
@@ -3928,18 +5666,46 @@ func runGitSyncFork(ctx *snap.Context) error {
 	}
 
 	if branch == "" {
-		branch = detectDefaultBranch()
+		branch = gitutil.DefaultBranch(flowCtx)
 	}
 	if strings.TrimSpace(branch) == "" || branch == "HEAD" {
 		return fmt.Errorf("could not determine branch to sync; provide one with --branch")
 	}
 
+	localExists, err := gitutil.RefExists(flowCtx, branch)
+	if err != nil {
+		return fmt.Errorf("check local branch %s: %w", branch, err)
+	}
+	current, err := gitutil.CurrentBranch(flowCtx)
+	if err != nil {
+		return err
+	}
+	switchesBranch := !localExists || current != branch
+
+	// --autostash is handed to the rebase or merge, which only protects the
+	// branch being synced. Checking out another branch first would refuse
+	// or carry the changes over to it, so that case always needs a clean tree.
+	dirtyFiles, err := gitWorkingTreeDirty()
+	if err != nil {
+		return err
+	}
+	if len(dirtyFiles) > 0 && (!autostash || switchesBranch) {
+		fmt.Fprintln(ctx.Stderr(), "Uncommitted changes:")
+		for _, line := range dirtyFiles {
+			fmt.Fprintf(ctx.Stderr(), "  %s\n", line)
+		}
+		if autostash {
+			return fmt.Errorf("working tree is dirty and syncing %s means switching away from %s; --autostash only covers the current branch, so commit or stash your changes first", branch, current)
+		}
+		return fmt.Errorf("working tree is dirty; commit or stash your changes, or rerun with --autostash")
+	}
+
 	if err := runGitCommandStreaming(ctx, "fetch", remote, "--prune"); err != nil {
 		return fmt.Errorf("git fetch %s --prune: %w", remote, err)
 	}
 
 	remoteRef := fmt.Sprintf("%s/%s", remote, branch)
-	hasRemoteBranch, err := gitRefExists(remoteRef)
+	hasRemoteBranch, err := gitutil.RefExists(flowCtx, remoteRef)
 	if err != nil {
 		return fmt.Errorf("check remote branch %s: %w", remoteRef, err)
 	}
@@ -3947,40 +5713,39 @@ func runGitSyncFork(ctx *snap.Context) error {
 		return fmt.Errorf("remote branch %s not found", remoteRef)
 	}
 
-	localExists, err := gitRefExists(branch)
-	if err != nil {
-		return fmt.Errorf("check local branch %s: %w", branch, err)
-	}
-
 	createdBranch := false
 	if !localExists {
 		if err := runGitCommandStreaming(ctx, "checkout", "-b", branch, remoteRef); err != nil {
 			return fmt.Errorf("git checkout -b %s %s: %w", branch, remoteRef, err)
 		}
 		createdBranch = true
-	} else {
-		current, err := currentGitBranch()
-		if err != nil {
-			return err
-		}
-		if current != branch {
-			if err := runGitCommandStreaming(ctx, "checkout", branch); err != nil {
-				return fmt.Errorf("git checkout %s: %w", branch, err)
-			}
+	} else if current != branch {
+		if err := runGitCommandStreaming(ctx, "checkout", branch); err != nil {
+			return fmt.Errorf("git checkout %s: %w", branch, err)
 		}
 	}
 
 	switch strings.ToLower(strategy) {
 	case "rebase", "":
-		if err := runGitCommandStreaming(ctx, "rebase", remoteRef); err != nil {
-			return fmt.Errorf("git rebase %s: %w", remoteRef, err)
+		args := []string{"rebase"}
+		if autostash {
+			args = append(args, "--autostash")
+		}
+		args = append(args, remoteRef)
+		if err := runGitCommandStreaming(ctx, args...); err != nil {
+			return fmt.Errorf("git %s: %w", strings.Join(args, " "), err)
 		}
 	case "merge":
-		if err := runGitCommandStreaming(ctx, "merge", "--no-ff", remoteRef); err != nil {
-			return fmt.Errorf("git merge --no-ff %s: %w", remoteRef, err)
+		args := []string{"merge", "--no-ff"}
+		if autostash {
+			args = append(args, "--autostash")
+		}
+		args = append(args, remoteRef)
+		if err := runGitCommandStreaming(ctx, args...); err != nil {
+			return fmt.Errorf("git %s: %w", strings.Join(args, " "), err)
 		}
 	default:
-		fmt.Fprintf(ctx.Stderr(), "Usage: %s gitSyncFork [--branch <name>] [--strategy rebase|merge] [--remote <remote>]\n", commandName)
+		fmt.Fprintf(ctx.Stderr(), "Usage: %s gitSyncFork [--branch <name>] [--strategy rebase|merge] [--remote <remote>] [--autostash]\n", commandName)
 		return fmt.Errorf("unsupported strategy %q", strategy)
 	}
 
@@ -4001,7 +5766,7 @@ type gitMirrorOptions struct {
 }
 
 func runGitMirror(ctx *snap.Context) error {
-	if err := ensureGitRepository(); err != nil {
+	if err := gitutil.EnsureRepository(flowCtx); err != nil {
 		return err
 	}
 	if ctx.NArgs() < 1 {
@@ -4166,7 +5931,7 @@ func runGitMirrorPull(ctx *snap.Context, opts gitMirrorOptions) error {
 	}
 
 	localRef := fmt.Sprintf("refs/heads/%s", branch)
-	localExists, err := gitRefExists(localRef)
+	localExists, err := gitutil.RefExists(flowCtx, localRef)
 	if err != nil {
 		return fmt.Errorf("check local branch %s: %w", branch, err)
 	}
@@ -4178,7 +5943,7 @@ func runGitMirrorPull(ctx *snap.Context, opts gitMirrorOptions) error {
 		return nil
 	}
 
-	current, err := currentGitBranch()
+	current, err := gitutil.CurrentBranch(flowCtx)
 	if err != nil {
 		return err
 	}
@@ -4215,7 +5980,7 @@ func runGitMirrorTake(ctx *snap.Context, opts gitMirrorOptions) error {
 	if err != nil {
 		return err
 	}
-	if dirty {
+	if len(dirty) > 0 {
 		return fmt.Errorf("working tree is not clean; commit/stash changes before gitMirror take")
 	}
 
@@ -4224,7 +5989,7 @@ func runGitMirrorTake(ctx *snap.Context, opts gitMirrorOptions) error {
 		return err
 	}
 
-	currentBranch, err := currentGitBranch()
+	currentBranch, err := gitutil.CurrentBranch(flowCtx)
 	if err != nil {
 		return err
 	}
@@ -4285,7 +6050,7 @@ func resolveGitMirrorBranch(override string) (string, error) {
 		return branch, nil
 	}
 
-	current, err := currentGitBranch()
+	current, err := gitutil.CurrentBranch(flowCtx)
 	if err != nil {
 		return "", err
 	}
@@ -4302,7 +6067,7 @@ func fetchMirrorBranch(ctx *snap.Context, remote, branch string) (string, error)
 	}
 
 	remoteRefName := fmt.Sprintf("refs/remotes/%s/%s", remote, branch)
-	exists, err := gitRefExists(remoteRefName)
+	exists, err := gitutil.RefExists(flowCtx, remoteRefName)
 	if err != nil {
 		return "", fmt.Errorf("check remote branch %s: %w", remoteRefName, err)
 	}
@@ -4314,7 +6079,7 @@ func fetchMirrorBranch(ctx *snap.Context, remote, branch string) (string, error)
 }
 
 func getGitLocalConfig(key string) (string, error) {
-	cmd := exec.Command("git", "config", "--local", "--get", key)
+	cmd := flowCommand("git", "config", "--local", "--get", key)
 	out, err := cmd.CombinedOutput()
 	if err != nil {
 		var exitErr *exec.ExitError
@@ -4331,7 +6096,7 @@ func getGitLocalConfig(key string) (string, error) {
 }
 
 func setGitLocalConfig(key, value string) error {
-	cmd := exec.Command("git", "config", "--local", key, value)
+	cmd := flowCommand("git", "config", "--local", key, value)
 	out, err := cmd.CombinedOutput()
 	if err != nil {
 		trimmed := strings.TrimSpace(string(out))
@@ -4343,12 +6108,21 @@ func setGitLocalConfig(key, value string) error {
 	return nil
 }
 
-func gitWorkingTreeDirty() (bool, error) {
-	out, err := exec.Command("git", "status", "--porcelain").Output()
+// gitWorkingTreeDirty returns the `git status --porcelain` lines for
+// uncommitted changes; none means the working tree is clean.
+func gitWorkingTreeDirty() ([]string, error) {
+	out, err := flowCommand("git", "status", "--porcelain").Output()
 	if err != nil {
-		return false, fmt.Errorf("git status --porcelain: %w", err)
+		return nil, fmt.Errorf("git status --porcelain: %w", err)
 	}
-	return strings.TrimSpace(string(out)) != "", nil
+
+	var files []string
+	for _, line := range strings.Split(string(out), "\n") {
+		if strings.TrimSpace(line) != "" {
+			files = append(files, line)
+		}
+	}
+	return files, nil
 }
 
 func runGitCheckout(ctx *snap.Context) error {
@@ -4365,7 +6139,7 @@ func runGitCheckout(ctx *snap.Context) error {
 	if ctx.NArgs() == 1 {
 		branchInput = strings.TrimSpace(ctx.Arg(0))
 	} else {
-		branchInput, err = promptLine(ctx, "Branch name or GitHub tree URL: ")
+		branchInput, err = promptLine(ctx, "Branch name, GitHub tree URL, or PR URL: ")
 		if err != nil {
 			return fmt.Errorf("read branch input: %w", err)
 		}
@@ -4376,11 +6150,23 @@ func runGitCheckout(ctx *snap.Context) error {
 		return fmt.Errorf("branch reference cannot be empty")
 	}
 
-	if err := ensureGitRepository(); err != nil {
+	if err := gitutil.EnsureRepository(flowCtx); err != nil {
 		return err
 	}
 
-	remotes, err := listGitRemotes()
+	if owner, repo, prNumber, ok := pullRequestURL(branchInput); ok {
+		if _, err := exec.LookPath("gh"); err != nil {
+			return fmt.Errorf("gh CLI not found in PATH: %w", err)
+		}
+		repoFull := fmt.Sprintf("%s/%s", owner, repo)
+		if err := checkoutPullRequest(ctx, "", repoFull, prNumber); err != nil {
+			return err
+		}
+		fmt.Fprintf(ctx.Stdout(), "✔️ Checked out %s PR #%d\n", repoFull, prNumber)
+		return nil
+	}
+
+	remotes, err := gitutil.Remotes(flowCtx)
 	if err != nil {
 		return err
 	}
@@ -4442,7 +6228,7 @@ func runGitCheckout(ctx *snap.Context) error {
 		return fmt.Errorf("git fetch %s %s: %w", remote, branchName, err)
 	}
 
-	exists, err := gitRefExists(branchName)
+	exists, err := gitutil.RefExists(flowCtx, branchName)
 	if err != nil {
 		return fmt.Errorf("check local branch %s: %w", branchName, err)
 	}
@@ -4451,7 +6237,7 @@ func runGitCheckout(ctx *snap.Context) error {
 	}
 
 	remoteRef := fmt.Sprintf("%s/%s", remote, branchName)
-	remoteExists, err := gitRefExists(remoteRef)
+	remoteExists, err := gitutil.RefExists(flowCtx, remoteRef)
 	if err != nil {
 		return fmt.Errorf("check remote branch %s: %w", remoteRef, err)
 	}
@@ -4468,7 +6254,7 @@ func runGitCheckoutRemote(ctx *snap.Context) error {
 		return fmt.Errorf("expected 0 arguments, got %d", ctx.NArgs())
 	}
 
-	if err := ensureGitRepository(); err != nil {
+	if err := gitutil.EnsureRepository(flowCtx); err != nil {
 		return err
 	}
 
@@ -4481,16 +6267,28 @@ func runGitCheckoutRemote(ctx *snap.Context) error {
 		return err
 	}
 
+	previews := make(map[int]string, len(branches))
 	idx, err := fuzzyfinder.Find(
 		branches,
 		func(i int) string {
 			return branches[i].fullRef()
 		},
 		fuzzyfinder.WithPromptString("gitCheckoutRemote> "),
+		fuzzyfinder.WithPreviewWindow(func(i, width, height int) string {
+			if i < 0 || i >= len(branches) {
+				return ""
+			}
+			if cached, ok := previews[i]; ok {
+				return cached
+			}
+			preview := remoteBranchPreview(branches[i].fullRef())
+			previews[i] = preview
+			return preview
+		}),
 	)
 	if err != nil {
 		if errors.Is(err, fuzzyfinder.ErrAbort) {
-			return nil
+			return errUserAbort
 		}
 		return fmt.Errorf("select remote branch: %w", err)
 	}
@@ -4498,7 +6296,7 @@ func runGitCheckoutRemote(ctx *snap.Context) error {
 	selected := branches[idx]
 	remoteRef := selected.fullRef()
 
-	remoteExists, err := gitRefExists(remoteRef)
+	remoteExists, err := gitutil.RefExists(flowCtx, remoteRef)
 	if err != nil {
 		return fmt.Errorf("check remote branch %s: %w", remoteRef, err)
 	}
@@ -4506,7 +6304,7 @@ func runGitCheckoutRemote(ctx *snap.Context) error {
 		return fmt.Errorf("remote branch %s not found", remoteRef)
 	}
 
-	localExists, err := gitRefExists(selected.Name)
+	localExists, err := gitutil.RefExists(flowCtx, selected.Name)
 	if err != nil {
 		return fmt.Errorf("check local branch %s: %w", selected.Name, err)
 	}
@@ -4527,13 +6325,72 @@ func runGitCheckoutRemote(ctx *snap.Context) error {
 	return nil
 }
 
+// remoteBranchPreview shows the latest commits on a branch for the picker.
+// A failing git log only costs the preview, not the selection.
+func remoteBranchPreview(ref string) string {
+	out, err := flowCommand("git", "log", "-n", "5", "--oneline", "--no-color", ref).Output()
+	if err != nil {
+		return fmt.Sprintf("(no log for %s)", ref)
+	}
+	return string(out)
+}
+
 func runKillPort(ctx *snap.Context) error {
-	if ctx.NArgs() > 1 {
-		fmt.Fprintf(ctx.Stderr(), "Usage: %s killPort [port]\n", commandName)
-		return reportError(ctx, fmt.Errorf("expected at most 1 argument, got %d", ctx.NArgs()))
+	usage := func() {
+		fmt.Fprintf(ctx.Stderr(), "Usage: %s killPort [port] [--name <substr>] [--wait-free [--timeout <duration>]]\n", commandName)
+	}
+
+	var rawPort, name string
+	nameSet := false
+	waitFree := false
+	timeout := defaultKillPortWaitTimeout
+	for i := 0; i < ctx.NArgs(); i++ {
+		arg := strings.TrimSpace(ctx.Arg(i))
+		switch {
+		case arg == "":
+		case arg == "--name":
+			i++
+			if i >= ctx.NArgs() {
+				usage()
+				return reportError(ctx, fmt.Errorf("--name requires a value"))
+			}
+			name, nameSet = strings.TrimSpace(ctx.Arg(i)), true
+		case strings.HasPrefix(arg, "--name="):
+			name, nameSet = strings.TrimSpace(strings.TrimPrefix(arg, "--name=")), true
+		case arg == "--wait-free":
+			waitFree = true
+		case arg == "--timeout" || strings.HasPrefix(arg, "--timeout="):
+			value := strings.TrimPrefix(arg, "--timeout=")
+			if arg == "--timeout" {
+				i++
+				if i >= ctx.NArgs() {
+					usage()
+					return reportError(ctx, fmt.Errorf("--timeout requires a value"))
+				}
+				value = ctx.Arg(i)
+			}
+			parsed, err := time.ParseDuration(strings.TrimSpace(value))
+			if err != nil || parsed <= 0 {
+				usage()
+				return reportError(ctx, fmt.Errorf("invalid --timeout %q: expected a positive duration like 10s", value))
+			}
+			timeout = parsed
+		case strings.HasPrefix(arg, "-"):
+			usage()
+			return reportError(ctx, fmt.Errorf("unknown flag %q", arg))
+		case rawPort == "":
+			rawPort = arg
+		default:
+			usage()
+			return reportError(ctx, fmt.Errorf("expected at most 1 port, got %q and %q", rawPort, arg))
+		}
+	}
+	if nameSet && name == "" {
+		usage()
+		return reportError(ctx, fmt.Errorf("--name cannot be empty"))
 	}
 
-	processes, err := listListeningProcesses()
+	processes, err := ports.Listening(flowCtx)
 	if err != nil {
 		return reportError(ctx, err)
 	}
@@ -4544,29 +6401,25 @@ func runKillPort(ctx *snap.Context) error {
 	}
 
 	targets := processes
-	if ctx.NArgs() == 1 {
-		rawPort := strings.TrimSpace(ctx.Arg(0))
-		if rawPort == "" {
-			fmt.Fprintf(ctx.Stderr(), "Usage: %s killPort [port]\n", commandName)
-			return reportError(ctx, fmt.Errorf("port cannot be empty"))
+	if rawPort != "" || name != "" {
+		if rawPort != "" {
+			targets = filterListeningProcessesByPort(targets, rawPort)
 		}
-
-		targets = uniqueListeningByPID(filterListeningProcessesByPort(processes, rawPort))
+		if name != "" {
+			targets = filterListeningProcessesByName(targets, name)
+		}
+		targets = uniqueListeningByPID(targets)
 		if len(targets) == 0 {
-			fmt.Fprintf(ctx.Stdout(), "No listening process found on port %s.\n", rawPort)
+			fmt.Fprintf(ctx.Stdout(), "No listening process found %s.\n", describeKillPortFilter(rawPort, name))
 			return nil
 		}
 
 		if len(targets) == 1 {
-			selected := targets[0]
-			if err := killListeningProcess(selected.PID); err != nil {
-				return reportError(ctx, fmt.Errorf("kill pid %d: %w", selected.PID, err))
-			}
-			fmt.Fprintf(ctx.Stdout(), "Killed %s (pid %d) listening on %s\n", selected.Command, selected.PID, selected.Address)
-			return nil
+			return killListeningTarget(ctx, targets[0], waitFree, timeout)
 		}
 	}
 
+	previews := make(map[int]string, len(targets))
 	idx, err := fuzzyfinder.Find(
 		targets,
 		func(i int) string {
@@ -4574,23 +6427,124 @@ func runKillPort(ctx *snap.Context) error {
 			return fmt.Sprintf("%s (%d) %s", p.Command, p.PID, p.Address)
 		},
 		fuzzyfinder.WithPromptString("killPort> "),
+		fuzzyfinder.WithPreviewWindow(func(i, width, height int) string {
+			if i < 0 || i >= len(targets) {
+				return ""
+			}
+			if cached, ok := previews[i]; ok {
+				return cached
+			}
+			preview := listeningProcessPreview(targets[i])
+			previews[i] = preview
+			return preview
+		}),
 	)
 	if err != nil {
 		if errors.Is(err, fuzzyfinder.ErrAbort) {
-			return nil
+			return errUserAbort
 		}
 		return reportError(ctx, fmt.Errorf("select port: %w", err))
 	}
 
-	selected := targets[idx]
+	return killListeningTarget(ctx, targets[idx], waitFree, timeout)
+}
+
+// listeningProcessPreview shows the raw lsof line followed by the process's
+// ancestry, so it is clear what else goes away with it.
+func listeningProcessPreview(p ports.Process) string {
+	var b strings.Builder
+	b.WriteString(p.Raw)
+	b.WriteString("\n")
+
+	tree := processAncestry(p.PID)
+	if len(tree) == 0 {
+		return b.String()
+	}
+	b.WriteString("\nProcess tree:\n")
+	for depth := len(tree) - 1; depth >= 0; depth-- {
+		indent := strings.Repeat("  ", len(tree)-1-depth)
+		fmt.Fprintf(&b, "%s%s\n", indent, tree[depth])
+	}
+	return b.String()
+}
+
+// processAncestry returns "pid command" lines from pid up towards init,
+// stopping quietly at the first ps failure.
+func processAncestry(pid int) []string {
+	queryCtx, cancel := context.WithTimeout(flowCtx, previewTimeout)
+	defer cancel()
+
+	var lines []string
+	for depth := 0; pid > 1 && depth < 10; depth++ {
+		out, err := exec.CommandContext(queryCtx, "ps", "-o", "ppid=,command=", "-p", strconv.Itoa(pid)).Output()
+		if err != nil {
+			break
+		}
+		fields := strings.Fields(strings.TrimSpace(string(out)))
+		if len(fields) < 2 {
+			break
+		}
+		lines = append(lines, fmt.Sprintf("%d %s", pid, strings.Join(fields[1:], " ")))
+		parent, err := strconv.Atoi(fields[0])
+		if err != nil {
+			break
+		}
+		pid = parent
+	}
+	return lines
+}
+
+func killListeningTarget(ctx *snap.Context, selected ports.Process, waitFree bool, timeout time.Duration) error {
 	if err := killListeningProcess(selected.PID); err != nil {
 		return reportError(ctx, fmt.Errorf("kill pid %d: %w", selected.PID, err))
 	}
-
 	fmt.Fprintf(ctx.Stdout(), "Killed %s (pid %d) listening on %s\n", selected.Command, selected.PID, selected.Address)
+
+	if !waitFree {
+		return nil
+	}
+	if err := waitForPortFree(ctx, selected.Port, timeout); err != nil {
+		return reportError(ctx, err)
+	}
+	fmt.Fprintf(ctx.Stdout(), "✔️ Port %s is free\n", selected.Port)
 	return nil
 }
 
+// waitForPortFree polls until nothing listens on port, printing a dot per
+// poll, so a server can be restarted without racing the socket release.
+func waitForPortFree(ctx *snap.Context, port string, timeout time.Duration) error {
+	deadline := time.Now().Add(timeout)
+	fmt.Fprintf(ctx.Stdout(), "Waiting for port %s to be released", port)
+	for {
+		processes, err := ports.Listening(flowCtx)
+		if err != nil {
+			fmt.Fprintln(ctx.Stdout())
+			return err
+		}
+		if len(filterListeningProcessesByPort(processes, port)) == 0 {
+			fmt.Fprintln(ctx.Stdout())
+			return nil
+		}
+		if time.Now().After(deadline) {
+			fmt.Fprintln(ctx.Stdout())
+			return fmt.Errorf("port %s is still in use after %s", port, timeout)
+		}
+		fmt.Fprint(ctx.Stdout(), ".")
+		time.Sleep(killPortPollInterval)
+	}
+}
+
+func describeKillPortFilter(rawPort, name string) string {
+	switch {
+	case rawPort != "" && name != "":
+		return fmt.Sprintf("on port %s matching %q", rawPort, name)
+	case rawPort != "":
+		return fmt.Sprintf("on port %s", rawPort)
+	default:
+		return fmt.Sprintf("matching %q", name)
+	}
+}
+
 func runCheckPort(ctx *snap.Context) error {
 	if ctx.NArgs() != 1 {
 		fmt.Fprintf(ctx.Stderr(), "Usage: %s checkPort <port>\n", commandName)
@@ -4603,7 +6557,7 @@ func runCheckPort(ctx *snap.Context) error {
 		return reportError(ctx, fmt.Errorf("port cannot be empty"))
 	}
 
-	processes, err := listListeningProcesses()
+	processes, err := ports.Listening(flowCtx)
 	if err != nil {
 		return reportError(ctx, err)
 	}
@@ -4639,7 +6593,7 @@ func runSpotifyCurrentPlayingSongCopy(ctx *snap.Context) error {
   end if
 end tell`
 
-	cmd := exec.Command("osascript", "-e", script)
+	cmd := flowCommand("osascript", "-e", script)
 	output, err := cmd.Output()
 	if err != nil {
 		return fmt.Errorf("failed to get Spotify info: %w", err)
@@ -4651,10 +6605,7 @@ end tell`
 		return nil
 	}
 
-	// Copy to clipboard
-	pbcopy := exec.Command("pbcopy")
-	pbcopy.Stdin = strings.NewReader(song)
-	if err := pbcopy.Run(); err != nil {
+	if err := writeClipboardText(song); err != nil {
 		return fmt.Errorf("failed to copy to clipboard: %w", err)
 	}
 
@@ -4671,7 +6622,7 @@ func runSpotifyCurrentPlayingSongUrlCopy(ctx *snap.Context) error {
   end if
 end tell`
 
-	cmd := exec.Command("osascript", "-e", script)
+	cmd := flowCommand("osascript", "-e", script)
 	output, err := cmd.Output()
 	if err != nil {
 		return fmt.Errorf("failed to get Spotify track ID: %w", err)
@@ -4688,9 +6639,7 @@ end tell`
 	trackID = strings.TrimPrefix(trackID, "spotify:track:")
 	trackURL := "https://open.spotify.com/track/" + trackID
 
-	pbcopy := exec.Command("pbcopy")
-	pbcopy.Stdin = strings.NewReader(trackURL)
-	if err := pbcopy.Run(); err != nil {
+	if err := writeClipboardText(trackURL); err != nil {
 		return fmt.Errorf("failed to copy to clipboard: %w", err)
 	}
 
@@ -4698,76 +6647,10 @@ end tell`
 	return nil
 }
 
-type listeningProcess struct {
-	Command string
-	User    string
-	PID     int
-	Address string
-	Port    string
-	Raw     string
-}
-
-func listListeningProcesses() ([]listeningProcess, error) {
-	if _, err := exec.LookPath("lsof"); err != nil {
-		return nil, fmt.Errorf("lsof not found in PATH: %w", err)
-	}
-
-	var stdout bytes.Buffer
-	var stderr bytes.Buffer
-
-	cmd := exec.Command("lsof", "-nP", "-iTCP", "-sTCP:LISTEN")
-	cmd.Stdout = &stdout
-	cmd.Stderr = &stderr
-
-	if err := cmd.Run(); err != nil {
-		msg := strings.TrimSpace(stderr.String())
-		if msg != "" {
-			return nil, fmt.Errorf("list listening ports: %s: %w", msg, err)
-		}
-		return nil, fmt.Errorf("list listening ports: %w", err)
-	}
-
-	scanner := bufio.NewScanner(&stdout)
-	var processes []listeningProcess
-	firstLine := true
-	for scanner.Scan() {
-		line := scanner.Text()
-		if firstLine {
-			firstLine = false
-			continue
-		}
-		fields := strings.Fields(line)
-		if len(fields) < 9 {
-			continue
-		}
-
-		pid, err := strconv.Atoi(fields[1])
-		if err != nil {
-			continue
-		}
-
-		address := fields[len(fields)-2]
-		port := address
-		if idx := strings.LastIndex(address, ":"); idx >= 0 && idx+1 < len(address) {
-			port = address[idx+1:]
-		}
-
-		processes = append(processes, listeningProcess{
-			Command: fields[0],
-			User:    fields[2],
-			PID:     pid,
-			Address: address,
-			Port:    port,
-			Raw:     line,
-		})
-	}
-
-	if err := scanner.Err(); err != nil {
-		return nil, fmt.Errorf("scan lsof output: %w", err)
-	}
-
-	return processes, nil
-}
+const (
+	defaultKillPortWaitTimeout = 10 * time.Second
+	killPortPollInterval       = 250 * time.Millisecond
+)
 
 func killListeningProcess(pid int) error {
 	if err := syscall.Kill(pid, syscall.SIGTERM); err != nil {
@@ -4779,8 +6662,8 @@ func killListeningProcess(pid int) error {
 	return nil
 }
 
-func filterListeningProcessesByPort(processes []listeningProcess, targetPort string) []listeningProcess {
-	var filtered []listeningProcess
+func filterListeningProcessesByPort(processes []ports.Process, targetPort string) []ports.Process {
+	var filtered []ports.Process
 	for _, p := range processes {
 		if p.Port == targetPort {
 			filtered = append(filtered, p)
@@ -4789,9 +6672,20 @@ func filterListeningProcessesByPort(processes []listeningProcess, targetPort str
 	return filtered
 }
 
-func uniqueListeningByPID(processes []listeningProcess) []listeningProcess {
+func filterListeningProcessesByName(processes []ports.Process, substr string) []ports.Process {
+	needle := strings.ToLower(substr)
+	var filtered []ports.Process
+	for _, p := range processes {
+		if strings.Contains(strings.ToLower(p.Command), needle) {
+			filtered = append(filtered, p)
+		}
+	}
+	return filtered
+}
+
+func uniqueListeningByPID(processes []ports.Process) []ports.Process {
 	seen := make(map[int]struct{})
-	var unique []listeningProcess
+	var unique []ports.Process
 	for _, p := range processes {
 		if _, ok := seen[p.PID]; ok {
 			continue
@@ -4879,7 +6773,7 @@ func pickBranchCandidateForRemote(remote string, candidates []string) (string, e
 }
 
 func gitRemoteHasBranch(remote, branch string) (bool, error) {
-	cmd := exec.Command("git", "ls-remote", "--heads", remote, branch)
+	cmd := flowCommand("git", "ls-remote", "--heads", remote, branch)
 	out, err := cmd.Output()
 	if err != nil {
 		return false, fmt.Errorf("git ls-remote %s %s: %w", remote, branch, err)
@@ -4898,7 +6792,7 @@ func (r remoteBranch) fullRef() string {
 }
 
 func listRemoteBranches() ([]remoteBranch, error) {
-	cmd := exec.Command("git", "for-each-ref", "--format=%(refname:short)", "refs/remotes")
+	cmd := flowCommand("git", "for-each-ref", "--format=%(refname:short)", "refs/remotes")
 	out, err := cmd.Output()
 	if err != nil {
 		return nil, fmt.Errorf("git for-each-ref refs/remotes: %w", err)
@@ -4947,7 +6841,7 @@ func listRemoteBranches() ([]remoteBranch, error) {
 }
 
 func gitRemoteState(name string) (bool, string, error) {
-	cmd := exec.Command("git", "remote", "get-url", name)
+	cmd := flowCommand("git", "remote", "get-url", name)
 	out, err := cmd.CombinedOutput()
 	if err != nil {
 		trimmed := strings.TrimSpace(string(out))
@@ -4964,43 +6858,6 @@ func gitRemoteState(name string) (bool, string, error) {
 	return true, strings.TrimSpace(string(out)), nil
 }
 
-func detectDefaultBranch() string {
-	out, err := exec.Command("git", "rev-parse", "--abbrev-ref", "HEAD").Output()
-	if err == nil {
-		current := strings.TrimSpace(string(out))
-		if current != "" && current != "HEAD" {
-			return current
-		}
-	}
-
-	out, err = exec.Command("git", "symbolic-ref", "refs/remotes/origin/HEAD").Output()
-	if err == nil {
-		trimmed := strings.TrimSpace(string(out))
-		if trimmed != "" {
-			parts := strings.Split(trimmed, "/")
-			if len(parts) > 0 {
-				return parts[len(parts)-1]
-			}
-		}
-	}
-
-	return "main"
-}
-
-func currentGitBranch() (string, error) {
-	out, err := exec.Command("git", "rev-parse", "--abbrev-ref", "HEAD").Output()
-	if err != nil {
-		trimmed := strings.TrimSpace(string(out))
-		if trimmed != "" {
-			return "", fmt.Errorf("%s", trimmed)
-		}
-		return "", fmt.Errorf("git rev-parse --abbrev-ref HEAD: %w", err)
-	}
-
-	branch := strings.TrimSpace(string(out))
-	return branch, nil
-}
-
 func urlsEquivalent(a, b string) bool {
 	na := normalizeRemoteURL(a)
 	nb := normalizeRemoteURL(b)
@@ -5068,51 +6925,6 @@ func extractRemoteHostPath(raw string) (string, string, bool) {
 	return "", trimmed, false
 }
 
-func ensureGitRepository() error {
-	cmd := exec.Command("git", "rev-parse", "--is-inside-work-tree")
-	out, err := cmd.CombinedOutput()
-	if err != nil {
-		trimmed := strings.TrimSpace(string(out))
-		if trimmed != "" {
-			return fmt.Errorf("%s", trimmed)
-		}
-		return fmt.Errorf("git rev-parse --is-inside-work-tree: %w", err)
-	}
-
-	if strings.TrimSpace(string(out)) != "true" {
-		return fmt.Errorf("not inside a git repository")
-	}
-
-	return nil
-}
-
-func listGitRemotes() ([]string, error) {
-	out, err := exec.Command("git", "remote").Output()
-	if err != nil {
-		return nil, fmt.Errorf("git remote: %w", err)
-	}
-
-	trimmed := strings.TrimSpace(string(out))
-	if trimmed == "" {
-		return nil, fmt.Errorf("no git remotes configured")
-	}
-
-	lines := strings.Split(trimmed, "\n")
-	remotes := make([]string, 0, len(lines))
-	for _, line := range lines {
-		name := strings.TrimSpace(line)
-		if name != "" {
-			remotes = append(remotes, name)
-		}
-	}
-
-	if len(remotes) == 0 {
-		return nil, fmt.Errorf("no git remotes configured")
-	}
-
-	return remotes, nil
-}
-
 func selectGitRemote(remotes []string, preferred string) (string, error) {
 	if len(remotes) == 0 {
 		return "", fmt.Errorf("no git remotes configured")
@@ -5136,21 +6948,8 @@ func selectGitRemote(remotes []string, preferred string) (string, error) {
 	return remotes[0], nil
 }
 
-func gitRefExists(ref string) (bool, error) {
-	cmd := exec.Command("git", "rev-parse", "--verify", "--quiet", ref)
-	if err := cmd.Run(); err != nil {
-		var exitErr *exec.ExitError
-		if errors.As(err, &exitErr) {
-			return false, nil
-		}
-		return false, err
-	}
-
-	return true, nil
-}
-
 func runGitCommandInDir(ctx *snap.Context, dir string, args ...string) error {
-	cmd := exec.Command("git", args...)
+	cmd := flowCommand("git", args...)
 	cmd.Dir = dir
 	cmd.Stdout = ctx.Stdout()
 	cmd.Stderr = ctx.Stderr()
@@ -5159,7 +6958,7 @@ func runGitCommandInDir(ctx *snap.Context, dir string, args ...string) error {
 }
 
 func runGitCommandStreaming(ctx *snap.Context, args ...string) error {
-	cmd := exec.Command("git", args...)
+	cmd := flowCommand("git", args...)
 	cmd.Stdout = ctx.Stdout()
 	cmd.Stderr = ctx.Stderr()
 	cmd.Stdin = ctx.Stdin()
@@ -0,0 +1,146 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/dzonerzy/go-snap/snap"
+)
+
+// notesDir holds one markdown file per day, named YYYY-MM-DD.md.
+func notesDir() (string, error) {
+	homeDir, err := os.UserHomeDir()
+	if err != nil {
+		return "", fmt.Errorf("determine home directory: %w", err)
+	}
+	return filepath.Join(homeDir, ".flow", "notes"), nil
+}
+
+func runNotes(ctx *snap.Context) error {
+	usage := func() {
+		fmt.Fprintf(ctx.Stderr(), "Usage: %s notes [--clip] [--open] [text...]\n", commandName)
+	}
+
+	fromClipboard := false
+	open := false
+	var words []string
+	for i := 0; i < ctx.NArgs(); i++ {
+		arg := ctx.Arg(i)
+		switch {
+		case len(words) > 0:
+			words = append(words, arg)
+		case arg == "--clip":
+			fromClipboard = true
+		case arg == "--open":
+			open = true
+		case arg == "--":
+			words = append(words, ctx.Args()[i+1:]...)
+			i = ctx.NArgs()
+		case strings.HasPrefix(arg, "-"):
+			usage()
+			return fmt.Errorf("unknown flag %q", arg)
+		default:
+			words = append(words, arg)
+		}
+	}
+	if fromClipboard && len(words) > 0 {
+		usage()
+		return fmt.Errorf("--clip cannot be combined with note text")
+	}
+
+	text := strings.Join(words, " ")
+	switch {
+	case fromClipboard:
+		clip, err := readClipboardText()
+		if err != nil {
+			return reportError(ctx, err)
+		}
+		text = clip
+	case text == "" && !isTerminal(ctx.Stdin()):
+		data, err := io.ReadAll(ctx.Stdin())
+		if err != nil {
+			return reportError(ctx, fmt.Errorf("read stdin: %w", err))
+		}
+		text = string(data)
+	}
+	text = strings.TrimSpace(text)
+	if text == "" && !open {
+		usage()
+		return fmt.Errorf("nothing to note: pass text, pipe it in, or use --clip")
+	}
+
+	dir, err := notesDir()
+	if err != nil {
+		return reportError(ctx, err)
+	}
+	now := time.Now()
+	path := filepath.Join(dir, now.Format("2006-01-02")+".md")
+
+	if text != "" || open {
+		if err := appendNote(path, text, now); err != nil {
+			return reportError(ctx, err)
+		}
+	}
+	if text != "" {
+		fmt.Fprintf(ctx.Stdout(), "✔️ Noted in %s\n", path)
+	}
+
+	if open {
+		if err := openInEditor(ctx, path); err != nil {
+			return reportError(ctx, err)
+		}
+		fmt.Fprintf(ctx.Stdout(), "✔️ Opened %s in %s\n", path, editorDisplayName())
+	}
+	return nil
+}
+
+// appendNote adds text to the day's note at path, starting the file with a
+// date heading when it does not exist yet. Empty text only creates it.
+func appendNote(path, text string, now time.Time) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return fmt.Errorf("create directory %s: %w", filepath.Dir(path), err)
+	}
+
+	var entry string
+	if _, err := os.Stat(path); errors.Is(err, os.ErrNotExist) {
+		entry = fmt.Sprintf("# %s\n\n", now.Format("2006-01-02"))
+	}
+	if text != "" {
+		entry += formatNoteEntry(text, now)
+	}
+	if entry == "" {
+		return nil
+	}
+
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o644)
+	if err != nil {
+		return fmt.Errorf("open %s: %w", path, err)
+	}
+	if _, err := f.WriteString(entry); err != nil {
+		f.Close()
+		return fmt.Errorf("write %s: %w", path, err)
+	}
+	return f.Close()
+}
+
+// formatNoteEntry turns text into a markdown list item stamped with the
+// time of day. Further lines are indented so they stay part of the item.
+func formatNoteEntry(text string, now time.Time) string {
+	lines := strings.Split(strings.TrimSpace(strings.ReplaceAll(text, "\r\n", "\n")), "\n")
+	var b strings.Builder
+	fmt.Fprintf(&b, "- %s %s\n", now.Format("15:04"), strings.TrimRight(lines[0], " \t"))
+	for _, line := range lines[1:] {
+		line = strings.TrimRight(line, " \t")
+		if line == "" {
+			b.WriteString("\n")
+			continue
+		}
+		fmt.Fprintf(&b, "  %s\n", line)
+	}
+	return b.String()
+}
@@ -0,0 +1,48 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestFormatNoteEntry(t *testing.T) {
+	now := time.Date(2026, 10, 16, 9, 5, 0, 0, time.UTC)
+	cases := []struct {
+		text, want string
+	}{
+		{"buy milk", "- 09:05 buy milk\n"},
+		{"  trimmed  \n", "- 09:05 trimmed\n"},
+		{"first\r\nsecond\n\nthird", "- 09:05 first\n  second\n\n  third\n"},
+	}
+	for _, tc := range cases {
+		if got := formatNoteEntry(tc.text, now); got != tc.want {
+			t.Errorf("formatNoteEntry(%q) = %q, want %q", tc.text, got, tc.want)
+		}
+	}
+}
+
+func TestAppendNote(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "notes", "2026-10-16.md")
+	now := time.Date(2026, 10, 16, 9, 5, 0, 0, time.UTC)
+
+	if err := appendNote(path, "one", now); err != nil {
+		t.Fatal(err)
+	}
+	if err := appendNote(path, "two", now.Add(time.Hour)); err != nil {
+		t.Fatal(err)
+	}
+	if err := appendNote(path, "", now); err != nil {
+		t.Fatal(err)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := "# 2026-10-16\n\n- 09:05 one\n- 10:05 two\n"
+	if string(data) != want {
+		t.Errorf("note file = %q, want %q", data, want)
+	}
+}
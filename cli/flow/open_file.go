@@ -0,0 +1,168 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/dzonerzy/go-snap/snap"
+	"github.com/ktr0731/go-fuzzyfinder"
+)
+
+const (
+	// openCandidateLimit caps how many files the picker is offered.
+	openCandidateLimit = 20000
+	// openPreviewBytes is how much of a file the preview reads.
+	openPreviewBytes = 64 * 1024
+)
+
+var errOpenCandidateLimit = errors.New("open candidate limit reached")
+
+func runOpen(ctx *snap.Context) error {
+	if ctx.NArgs() > 1 {
+		fmt.Fprintf(ctx.Stderr(), "Usage: %s open [dir]\n", commandName)
+		return fmt.Errorf("expected at most 1 argument, got %d", ctx.NArgs())
+	}
+
+	root := "."
+	if ctx.NArgs() == 1 && strings.TrimSpace(ctx.Arg(0)) != "" {
+		root = strings.TrimSpace(ctx.Arg(0))
+	}
+	if info, err := os.Stat(root); err != nil {
+		return reportError(ctx, fmt.Errorf("open %s: %w", root, err))
+	} else if !info.IsDir() {
+		return reportError(ctx, fmt.Errorf("%s is not a directory", root))
+	}
+
+	files, truncated, err := listOpenCandidates(root)
+	if err != nil {
+		return reportError(ctx, err)
+	}
+	if len(files) == 0 {
+		fmt.Fprintf(ctx.Stdout(), "No files found under %s\n", root)
+		return nil
+	}
+	if truncated {
+		fmt.Fprintf(ctx.Stderr(), "ℹ️ Showing the first %d files; pass a subdirectory to narrow the search\n", openCandidateLimit)
+	}
+
+	previews := make(map[int]string)
+	idx, err := fuzzyfinder.Find(
+		files,
+		func(i int) string {
+			return files[i]
+		},
+		fuzzyfinder.WithPromptString("open> "),
+		fuzzyfinder.WithPreviewWindow(func(i, width, height int) string {
+			if i < 0 || i >= len(files) {
+				return ""
+			}
+			if cached, ok := previews[i]; ok {
+				return cached
+			}
+			preview := filePreview(filepath.Join(root, files[i]))
+			previews[i] = preview
+			return preview
+		}),
+	)
+	if err != nil {
+		if errors.Is(err, fuzzyfinder.ErrAbort) {
+			return errUserAbort
+		}
+		return reportError(ctx, fmt.Errorf("select file: %w", err))
+	}
+
+	selected, err := filepath.Abs(filepath.Join(root, files[idx]))
+	if err != nil {
+		return reportError(ctx, fmt.Errorf("resolve %s: %w", files[idx], err))
+	}
+	if err := openInEditor(ctx, selected); err != nil {
+		return reportError(ctx, err)
+	}
+	fmt.Fprintf(ctx.Stdout(), "✔️ Opened %s in %s\n", files[idx], editorDisplayName())
+	return nil
+}
+
+// listOpenCandidates returns files under root relative to it. Inside a git
+// repository git ls-files supplies them so .gitignore is respected; elsewhere
+// a bounded walk skips the usual noise directories.
+func listOpenCandidates(root string) ([]string, bool, error) {
+	if files, ok := gitVisibleFiles(root); ok {
+		if len(files) > openCandidateLimit {
+			return files[:openCandidateLimit], true, nil
+		}
+		return files, false, nil
+	}
+
+	var files []string
+	truncated := false
+	err := filepath.WalkDir(root, func(path string, d fs.DirEntry, walkErr error) error {
+		if walkErr != nil {
+			if errors.Is(walkErr, fs.ErrPermission) {
+				return nil
+			}
+			return walkErr
+		}
+
+		if d.IsDir() {
+			if path == root {
+				return nil
+			}
+			if shouldSkip(d.Name()) {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if !d.Type().IsRegular() {
+			return nil
+		}
+
+		if len(files) == openCandidateLimit {
+			truncated = true
+			return errOpenCandidateLimit
+		}
+		rel, err := filepath.Rel(root, path)
+		if err != nil {
+			rel = path
+		}
+		files = append(files, rel)
+		return nil
+	})
+	if err != nil && !errors.Is(err, errOpenCandidateLimit) {
+		return nil, false, fmt.Errorf("scan %s: %w", root, err)
+	}
+
+	sort.Strings(files)
+	return files, truncated, nil
+}
+
+// filePreview shows the start of a text file, or a one-line note for
+// binary files and anything that can't be read.
+func filePreview(path string) string {
+	f, err := os.Open(path)
+	if err != nil {
+		return fmt.Sprintf("(cannot read: %v)", err)
+	}
+	defer f.Close()
+
+	buf := make([]byte, openPreviewBytes)
+	n, err := io.ReadFull(f, buf)
+	if err != nil && !errors.Is(err, io.ErrUnexpectedEOF) && !errors.Is(err, io.EOF) {
+		return fmt.Sprintf("(cannot read: %v)", err)
+	}
+	buf = buf[:n]
+
+	if bytes.IndexByte(buf, 0) >= 0 {
+		if info, err := f.Stat(); err == nil {
+			return fmt.Sprintf("(binary file, %d bytes)", info.Size())
+		}
+		return "(binary file)"
+	}
+	return string(buf)
+}
@@ -0,0 +1,49 @@
+package main
+
+import (
+	"io"
+	"testing"
+	"time"
+)
+
+func TestOpenAIRetryBackoffIsCapped(t *testing.T) {
+	for _, tc := range []struct {
+		base    time.Duration
+		attempt int
+	}{
+		{time.Second, 1},
+		{time.Second, 5},
+		{time.Second, 40},
+		{time.Second, 70},
+		{time.Hour, 1},
+		{time.Duration(1 << 62), 3},
+	} {
+		got := openAIRetryBackoff(tc.base, tc.attempt)
+		if got <= 0 || got > maxOpenAIRetryDelay {
+			t.Errorf("openAIRetryBackoff(%s, %d) = %s, want within (0, %s]", tc.base, tc.attempt, got, maxOpenAIRetryDelay)
+		}
+	}
+	if got := openAIRetryBackoff(time.Second, 2); got < 2*time.Second || got > 3*time.Second {
+		t.Errorf("second attempt waited %s, want 2s plus up to 50%% jitter", got)
+	}
+}
+
+func TestOpenAIMaxAttemptsRejectsOutOfRange(t *testing.T) {
+	prev := stderr
+	stderr = io.Discard
+	t.Cleanup(func() { stderr = prev })
+
+	for value, want := range map[string]int{
+		"5":     5,
+		"10":    10,
+		"0":     defaultOpenAIMaxAttempts,
+		"11":    defaultOpenAIMaxAttempts,
+		"99999": defaultOpenAIMaxAttempts,
+		"many":  defaultOpenAIMaxAttempts,
+	} {
+		t.Setenv(openAIMaxAttemptsEnv, value)
+		if got := openAIMaxAttempts(); got != want {
+			t.Errorf("%s=%q: got %d, want %d", openAIMaxAttemptsEnv, value, got, want)
+		}
+	}
+}
@@ -0,0 +1,90 @@
+package main
+
+import (
+	"io"
+	"os"
+	"regexp"
+	"strings"
+
+	"lang/cmdlog"
+)
+
+const (
+	// noColorEnv follows https://no-color.org: any non-empty value asks for
+	// plain output.
+	noColorEnv  = "NO_COLOR"
+	asciiFlag   = "--ascii"
+	noColorFlag = "--no-color"
+)
+
+// plainReplacer swaps the symbols fgo prints for ASCII. Emoji with a
+// variation selector come first so the bare symbol never leaves it behind.
+var plainReplacer = strings.NewReplacer(
+	"✔️", "[OK]",
+	"ℹ️", "[i]",
+	"▶️", ">",
+	"✔", "[OK]",
+	"✓", "[OK]",
+	"ℹ", "[i]",
+	"▶", ">",
+	"→", "->",
+	"←", "<-",
+	"—", "-",
+	"─", "-",
+	"·", "-",
+)
+
+var ansiEscapePattern = regexp.MustCompile(`\x1b\[[0-9;?]*[A-Za-z]`)
+
+// stderr is where messages outside a command's snap context go, such as
+// warnings about settings. main swaps in a plainWriter for plain output.
+var stderr io.Writer = os.Stderr
+
+// plainWriter rewrites everything written through it to ASCII symbols
+// without ANSI escapes. fgo writes whole messages per call, so a symbol is
+// never split across writes.
+type plainWriter struct {
+	w io.Writer
+}
+
+func (p plainWriter) Write(b []byte) (int, error) {
+	text := plainReplacer.Replace(ansiEscapePattern.ReplaceAllString(string(b), ""))
+	if _, err := io.WriteString(p.w, text); err != nil {
+		return 0, err
+	}
+	return len(b), nil
+}
+
+// plainOutputFromArgs strips --ascii and --no-color from the leading global
+// flags, leaving --verbose for cmdlog, and reports whether plain output was
+// requested there or through NO_COLOR.
+func plainOutputFromArgs(args []string) ([]string, bool) {
+	plain := os.Getenv(noColorEnv) != ""
+	kept := make([]string, 0, len(args))
+	for i, arg := range args {
+		switch arg {
+		case asciiFlag, noColorFlag:
+			plain = true
+		case cmdlog.VerboseFlag:
+			kept = append(kept, arg)
+		default:
+			return append(kept, args[i:]...), plain
+		}
+	}
+	return kept, plain
+}
+
+// isTerminal reports whether stream, typically ctx.Stdin() or ctx.Stdout(),
+// is an interactive terminal rather than a pipe or file. Streams that are
+// not files count as non-interactive.
+func isTerminal(stream any) bool {
+	f, ok := stream.(*os.File)
+	if !ok {
+		return false
+	}
+	info, err := f.Stat()
+	if err != nil {
+		return false
+	}
+	return info.Mode()&os.ModeCharDevice != 0
+}
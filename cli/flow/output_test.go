@@ -0,0 +1,34 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func TestPlainWriter(t *testing.T) {
+	var buf bytes.Buffer
+	w := plainWriter{&buf}
+	fmt.Fprintf(w, "✔️ Tagged %s\n", "v1.0.0")
+	fmt.Fprintln(w, "ℹ️ \x1b[1;32mclean\x1b[0m → done")
+	if got, want := buf.String(), "[OK] Tagged v1.0.0\n[i] clean -> done\n"; got != want {
+		t.Fatalf("got %q, want %q", got, want)
+	}
+}
+
+func TestPlainOutputFromArgs(t *testing.T) {
+	t.Setenv(noColorEnv, "")
+	args, plain := plainOutputFromArgs([]string{"--verbose", "--ascii", "gitLog", "--no-color"})
+	if !plain || strings.Join(args, " ") != "--verbose gitLog --no-color" {
+		t.Fatalf("got %q plain=%v", args, plain)
+	}
+	if _, plain := plainOutputFromArgs([]string{"gitLog"}); plain {
+		t.Fatal("expected plain output to stay off")
+	}
+
+	t.Setenv(noColorEnv, "1")
+	if _, plain := plainOutputFromArgs([]string{"gitLog"}); !plain {
+		t.Fatal("expected NO_COLOR to enable plain output")
+	}
+}
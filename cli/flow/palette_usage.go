@@ -0,0 +1,127 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+)
+
+// usageHalfLife controls how quickly old picks stop pulling a command to the
+// top of the palette: a use this long ago counts half as much as one today.
+const usageHalfLife = 7 * 24 * time.Hour
+
+type commandUsage struct {
+	Count    int       `json:"count"`
+	LastUsed time.Time `json:"lastUsed"`
+}
+
+type usageFile struct {
+	Commands map[string]commandUsage `json:"commands"`
+}
+
+func usageFilePath() (string, error) {
+	homeDir, err := os.UserHomeDir()
+	if err != nil {
+		return "", fmt.Errorf("determine home directory: %w", err)
+	}
+	return filepath.Join(homeDir, ".flow", "usage.json"), nil
+}
+
+func loadCommandUsage() (usageFile, error) {
+	usage := usageFile{Commands: map[string]commandUsage{}}
+	path, err := usageFilePath()
+	if err != nil {
+		return usage, err
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return usage, nil
+		}
+		return usage, fmt.Errorf("read %s: %w", path, err)
+	}
+	if err := json.Unmarshal(data, &usage); err != nil {
+		return usageFile{Commands: map[string]commandUsage{}}, fmt.Errorf("parse %s: %w", path, err)
+	}
+	if usage.Commands == nil {
+		usage.Commands = map[string]commandUsage{}
+	}
+	return usage, nil
+}
+
+func saveCommandUsage(usage usageFile) error {
+	path, err := usageFilePath()
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return fmt.Errorf("create %s: %w", filepath.Dir(path), err)
+	}
+
+	data, err := json.MarshalIndent(usage, "", "  ")
+	if err != nil {
+		return err
+	}
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, append(data, '\n'), 0o644); err != nil {
+		return fmt.Errorf("write %s: %w", tmp, err)
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		return fmt.Errorf("replace %s: %w", path, err)
+	}
+	return nil
+}
+
+// recordCommandUsage bumps a command after it ran successfully. Usage tracking
+// is best effort, so failures never affect the command itself.
+func recordCommandUsage(name string) {
+	usage, err := loadCommandUsage()
+	if err != nil {
+		return
+	}
+	entry := usage.Commands[name]
+	entry.Count++
+	entry.LastUsed = time.Now()
+	usage.Commands[name] = entry
+	_ = saveCommandUsage(usage)
+}
+
+func resetCommandUsage() (string, error) {
+	path, err := usageFilePath()
+	if err != nil {
+		return "", err
+	}
+	if err := os.Remove(path); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return "", fmt.Errorf("remove %s: %w", path, err)
+	}
+	return path, nil
+}
+
+// usageScore weighs the use count by how recently the command was last used.
+func usageScore(entry commandUsage, now time.Time) float64 {
+	if entry.Count == 0 {
+		return 0
+	}
+	age := now.Sub(entry.LastUsed)
+	if age < 0 {
+		age = 0
+	}
+	return float64(entry.Count) * math.Pow(0.5, float64(age)/float64(usageHalfLife))
+}
+
+// commandsByUsage returns the catalog with frequently and recently used
+// commands first; unused commands keep their registration order.
+func commandsByUsage(catalog []commandInfo, usage usageFile, now time.Time) []commandInfo {
+	ordered := make([]commandInfo, len(catalog))
+	copy(ordered, catalog)
+	sort.SliceStable(ordered, func(i, j int) bool {
+		return usageScore(usage.Commands[ordered[i].name], now) > usageScore(usage.Commands[ordered[j].name], now)
+	})
+	return ordered
+}
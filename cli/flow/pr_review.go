@@ -0,0 +1,149 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os/exec"
+	"strconv"
+	"strings"
+
+	"github.com/dzonerzy/go-snap/snap"
+)
+
+const prReviewSystemPrompt = "You are a senior software engineer doing a first-pass code review of a GitHub pull request. Respond in Markdown with exactly three sections: `## Summary` (two or three sentences on what the change does), `## Concerns` (bugs, risky behaviour, missing tests; say \"None\" if there are none), and `## Suggestions` (concrete, actionable improvements referencing files or lines). Be specific and concise, and do not repeat the diff back. Never include secrets or credentials even if they appear in the diff."
+
+func runPRReview(ctx *snap.Context) error {
+	usage := func() {
+		fmt.Fprintf(ctx.Stderr(), "Usage: %s prReview <github-pr-url-or-owner/repo#num> [--post]\n", commandName)
+	}
+
+	ref := ""
+	post := false
+	for i := 0; i < ctx.NArgs(); i++ {
+		arg := strings.TrimSpace(ctx.Arg(i))
+		if arg == "" {
+			continue
+		}
+
+		switch {
+		case arg == "--post":
+			post = true
+		case strings.HasPrefix(arg, "-"):
+			usage()
+			return fmt.Errorf("unknown flag %q", arg)
+		case ref == "":
+			ref = arg
+		default:
+			usage()
+			return fmt.Errorf("unexpected argument %q", arg)
+		}
+	}
+	if ref == "" {
+		usage()
+		return fmt.Errorf("expected a pull request reference")
+	}
+
+	owner, repo, prNumber, err := parsePullRequestRef(ref)
+	if err != nil {
+		return reportError(ctx, err)
+	}
+
+	if _, err := exec.LookPath("gh"); err != nil {
+		return reportError(ctx, fmt.Errorf("gh CLI not found in PATH: %w", err))
+	}
+
+	apiKey, err := resolveOpenAIKey(ctx.Context())
+	if err != nil {
+		return reportError(ctx, err)
+	}
+
+	repoFull := fmt.Sprintf("%s/%s", owner, repo)
+	prRef := strconv.Itoa(prNumber)
+
+	title, body, err := pullRequestTitleAndBody(repoFull, prRef)
+	if err != nil {
+		return reportError(ctx, err)
+	}
+
+	diffOutput, err := flowCommand("gh", "pr", "diff", prRef, "--repo", repoFull).Output()
+	if err != nil {
+		return reportError(ctx, fmt.Errorf("gh pr diff: %w", err))
+	}
+	if strings.TrimSpace(string(diffOutput)) == "" {
+		return reportError(ctx, fmt.Errorf("%s#%d has an empty diff", repoFull, prNumber))
+	}
+
+	diff, truncated := truncateDiffForCommit(string(diffOutput))
+	diff, redacted := redactDiffSecrets(diff)
+	if redacted > 0 {
+		fmt.Fprintf(ctx.Stderr(), "ℹ️ Redacted %d likely secret(s) from the diff before sending it to the model\n", redacted)
+	}
+
+	var prompt strings.Builder
+	fmt.Fprintf(&prompt, "Review pull request %s#%d.\n\nTitle: %s\n", repoFull, prNumber, title)
+	if strings.TrimSpace(body) != "" {
+		prompt.WriteString("\nDescription:\n")
+		prompt.WriteString(strings.TrimSpace(body))
+		prompt.WriteString("\n")
+	}
+	prompt.WriteString("\nDiff:\n")
+	prompt.WriteString(diff)
+	if truncated {
+		prompt.WriteString("\n\n[Diff truncated to fit within prompt; mention that the review is partial]")
+	}
+
+	params := newChatParams(prReviewSystemPrompt, prompt.String())
+
+	fmt.Fprintf(ctx.Stdout(), "Reviewing %s#%d: %s\n\n", repoFull, prNumber, title)
+	review, streamed, err := runChatCompletion(ctx.Context(), apiKey, params, func(token string) {
+		fmt.Fprint(ctx.Stdout(), token)
+	})
+	if err != nil {
+		return reportError(ctx, fmt.Errorf("generate review: %w", err))
+	}
+	review = strings.TrimSpace(review)
+	if review == "" {
+		return reportError(ctx, fmt.Errorf("model returned an empty review"))
+	}
+	if streamed {
+		fmt.Fprintln(ctx.Stdout())
+	} else {
+		fmt.Fprintln(ctx.Stdout(), review)
+	}
+
+	if !post {
+		return nil
+	}
+
+	comment := review + "\n\n_Generated with `" + commandName + " prReview`._\n"
+	cmd := flowCommand("gh", "pr", "comment", prRef, "--repo", repoFull, "--body-file", "-")
+	cmd.Stdin = strings.NewReader(comment)
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return reportError(ctx, fmt.Errorf("gh pr comment: %s", msg))
+		}
+		return reportError(ctx, fmt.Errorf("gh pr comment: %w", err))
+	}
+
+	fmt.Fprintf(ctx.Stdout(), "\n✔️ Posted review comment on %s#%d\n", repoFull, prNumber)
+	return nil
+}
+
+func pullRequestTitleAndBody(repoFull, prRef string) (string, string, error) {
+	out, err := flowCommand("gh", "pr", "view", prRef, "--repo", repoFull, "--json", "title,body").Output()
+	if err != nil {
+		return "", "", fmt.Errorf("gh pr view: %w", err)
+	}
+
+	var info struct {
+		Title string `json:"title"`
+		Body  string `json:"body"`
+	}
+	if err := json.Unmarshal(out, &info); err != nil {
+		return "", "", fmt.Errorf("parse gh pr view output: %w", err)
+	}
+	return info.Title, info.Body, nil
+}
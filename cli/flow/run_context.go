@@ -0,0 +1,104 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"lang/cmdlog"
+	"lang/exitcode"
+)
+
+const flowTimeoutEnv = "FLOW_TIMEOUT"
+
+var errTimeout = exitcode.ErrTimeout
+
+// flowCtx is cancelled on Ctrl-C or SIGTERM, and after FLOW_TIMEOUT when
+// that is set. git, gh, osascript, and the other non-interactive tools run
+// under it via flowCommand, so a stuck subprocess is killed rather
+// than hanging fgo. It stays context.Background() outside main, e.g. in
+// tests.
+var flowCtx = context.Background()
+
+// flowCommand runs name under flowCtx and, with --verbose or FLOW_DEBUG=1,
+// logs it and its exit status to stderr. cmdlog's WaitDelay keeps Wait from
+// blocking on grandchildren, such as ssh under git fetch, that still hold
+// the output pipes after the command itself was killed.
+func flowCommand(name string, args ...string) *cmdlog.Cmd {
+	return cmdlog.Command(flowCtx, name, args...)
+}
+
+// flowTimeout returns the overall run timeout, or 0 for none.
+func flowTimeout() time.Duration {
+	value, ok := lookupSetting(flowTimeoutEnv)
+	if !ok {
+		return 0
+	}
+	timeout, err := time.ParseDuration(value)
+	if err != nil || timeout < 0 {
+		fmt.Fprintf(stderr, "ℹ️ Ignoring invalid %s=%q; running without a timeout\n", flowTimeoutEnv, value)
+		return 0
+	}
+	return timeout
+}
+
+// untimedCommands run for as long as the user asks them to, so
+// FLOW_TIMEOUT does not apply to them.
+var untimedCommands = map[string]bool{
+	"timer": true,
+}
+
+// runTimeout is the timeout for running args, a command and its arguments.
+func runTimeout(args []string) time.Duration {
+	if len(args) > 0 && untimedCommands[args[0]] {
+		return 0
+	}
+	return flowTimeout()
+}
+
+// newRunContext builds the context behind flowCtx. Its cause is
+// errUserAbort after a signal and wraps errTimeout after the timeout. After
+// the first signal the default handler is restored, so a second Ctrl-C
+// kills fgo outright.
+func newRunContext(timeout time.Duration) (context.Context, func()) {
+	ctx, cancel := context.WithCancelCause(context.Background())
+
+	stopTimer := func() bool { return false }
+	if timeout > 0 {
+		timer := time.AfterFunc(timeout, func() {
+			cancel(fmt.Errorf("%w after %s (%s)", errTimeout, timeout, flowTimeoutEnv))
+		})
+		stopTimer = timer.Stop
+	}
+
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, os.Interrupt, syscall.SIGTERM)
+	go func() {
+		select {
+		case <-signals:
+			signal.Stop(signals)
+			cancel(errUserAbort)
+		case <-ctx.Done():
+		}
+	}()
+
+	return ctx, func() {
+		stopTimer()
+		signal.Stop(signals)
+		cancel(nil)
+	}
+}
+
+// withRunCause attaches why flowCtx ended to an error from a command that
+// was cut short, since a killed subprocess only reports "signal: killed".
+func withRunCause(err error) error {
+	cause := context.Cause(flowCtx)
+	if err == nil || cause == nil || errors.Is(err, cause) {
+		return err
+	}
+	return fmt.Errorf("%w: %w", cause, err)
+}
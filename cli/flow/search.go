@@ -0,0 +1,224 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"strconv"
+	"strings"
+
+	"github.com/dzonerzy/go-snap/snap"
+	"github.com/ktr0731/go-fuzzyfinder"
+)
+
+// searchMatchLimit caps how many matches are handed to the picker so a very
+// common query stays responsive.
+const searchMatchLimit = 5000
+
+type searchMatch struct {
+	Path string
+	Line int
+	Text string
+}
+
+func runSearch(ctx *snap.Context) error {
+	usage := func() {
+		fmt.Fprintf(ctx.Stderr(), "Usage: %s search <query> [path]\n", commandName)
+	}
+
+	var query, scope string
+	for i := 0; i < ctx.NArgs(); i++ {
+		arg := ctx.Arg(i)
+		switch {
+		case strings.TrimSpace(arg) == "":
+		case query == "":
+			query = arg
+		case scope == "":
+			scope = strings.TrimSpace(arg)
+		default:
+			usage()
+			return reportError(ctx, fmt.Errorf("unexpected argument %q", arg))
+		}
+	}
+	if query == "" {
+		usage()
+		return reportError(ctx, fmt.Errorf("search query is required"))
+	}
+	if scope == "" {
+		scope = "."
+	}
+	if _, err := os.Stat(scope); err != nil {
+		return reportError(ctx, fmt.Errorf("search path %s: %w", scope, err))
+	}
+
+	matches, truncated, err := findSearchMatches(query, scope)
+	if err != nil {
+		return reportError(ctx, err)
+	}
+	if len(matches) == 0 {
+		fmt.Fprintf(ctx.Stdout(), "No matches for %q in %s\n", query, scope)
+		return nil
+	}
+	if truncated {
+		fmt.Fprintf(ctx.Stderr(), "ℹ️ Showing the first %d matches; narrow the query or path for more\n", searchMatchLimit)
+	}
+
+	fileCache := make(map[string][]string)
+	idx, err := fuzzyfinder.Find(
+		matches,
+		func(i int) string {
+			m := matches[i]
+			return fmt.Sprintf("%s:%d: %s", m.Path, m.Line, strings.TrimSpace(m.Text))
+		},
+		fuzzyfinder.WithPromptString("search> "),
+		fuzzyfinder.WithPreviewWindow(func(i, width, height int) string {
+			if i < 0 || i >= len(matches) {
+				return ""
+			}
+			return searchMatchPreview(matches[i], fileCache, height)
+		}),
+	)
+	if err != nil {
+		if errors.Is(err, fuzzyfinder.ErrAbort) {
+			return errUserAbort
+		}
+		return reportError(ctx, fmt.Errorf("select match: %w", err))
+	}
+
+	selected := matches[idx]
+	if err := openInEditorAtLine(ctx, selected.Path, selected.Line); err != nil {
+		return reportError(ctx, err)
+	}
+	fmt.Fprintf(ctx.Stdout(), "✔️ Opened %s:%d in %s\n", selected.Path, selected.Line, editorDisplayName())
+	return nil
+}
+
+// findSearchMatches runs ripgrep when available and grep otherwise. Both
+// exit 1 when nothing matches, which is not an error here.
+func findSearchMatches(query, scope string) ([]searchMatch, bool, error) {
+	if _, err := exec.LookPath("rg"); err == nil {
+		out, err := flowCommand("rg", "--json", "--smart-case", "--", query, scope).Output()
+		if err != nil && !isExitCode(err, 1) {
+			return nil, false, fmt.Errorf("rg: %w", err)
+		}
+		return parseRipgrepJSON(out)
+	}
+
+	out, err := flowCommand("grep", "-rnI", "--exclude-dir=.git", "-e", query, "--", scope).Output()
+	if err != nil && !isExitCode(err, 1) {
+		return nil, false, fmt.Errorf("grep: %w", err)
+	}
+	return parseGrepOutput(out)
+}
+
+func isExitCode(err error, code int) bool {
+	var exitErr *exec.ExitError
+	return errors.As(err, &exitErr) && exitErr.ExitCode() == code
+}
+
+// parseRipgrepJSON reads `rg --json` output, keeping only "match" events.
+// Paths or lines that aren't valid UTF-8 come back as base64 "bytes" and
+// are skipped.
+func parseRipgrepJSON(out []byte) ([]searchMatch, bool, error) {
+	type rgText struct {
+		Text *string `json:"text"`
+	}
+	type rgEvent struct {
+		Type string `json:"type"`
+		Data struct {
+			Path       rgText `json:"path"`
+			Lines      rgText `json:"lines"`
+			LineNumber int    `json:"line_number"`
+		} `json:"data"`
+	}
+
+	var matches []searchMatch
+	scanner := bufio.NewScanner(bytes.NewReader(out))
+	scanner.Buffer(make([]byte, 0, 64*1024), 16*1024*1024)
+	for scanner.Scan() {
+		var event rgEvent
+		if err := json.Unmarshal(scanner.Bytes(), &event); err != nil {
+			return nil, false, fmt.Errorf("parse rg output: %w", err)
+		}
+		if event.Type != "match" || event.Data.Path.Text == nil || event.Data.Lines.Text == nil {
+			continue
+		}
+		if len(matches) == searchMatchLimit {
+			return matches, true, nil
+		}
+		matches = append(matches, searchMatch{
+			Path: strings.TrimPrefix(*event.Data.Path.Text, "./"),
+			Line: event.Data.LineNumber,
+			Text: strings.TrimRight(*event.Data.Lines.Text, "\r\n"),
+		})
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, false, fmt.Errorf("read rg output: %w", err)
+	}
+	return matches, false, nil
+}
+
+func parseGrepOutput(out []byte) ([]searchMatch, bool, error) {
+	var matches []searchMatch
+	for _, line := range strings.Split(string(out), "\n") {
+		path, rest, ok := strings.Cut(line, ":")
+		if !ok {
+			continue
+		}
+		lineText, text, ok := strings.Cut(rest, ":")
+		if !ok {
+			continue
+		}
+		n, err := strconv.Atoi(lineText)
+		if err != nil {
+			continue
+		}
+		if len(matches) == searchMatchLimit {
+			return matches, true, nil
+		}
+		matches = append(matches, searchMatch{Path: strings.TrimPrefix(path, "./"), Line: n, Text: text})
+	}
+	return matches, false, nil
+}
+
+// searchMatchPreview shows the lines around a match, marking the match
+// itself. File contents are cached since neighbouring matches share files.
+func searchMatchPreview(m searchMatch, cache map[string][]string, height int) string {
+	lines, ok := cache[m.Path]
+	if !ok {
+		data, err := os.ReadFile(m.Path)
+		if err != nil {
+			return fmt.Sprintf("(cannot read %s: %v)", m.Path, err)
+		}
+		lines = strings.Split(string(data), "\n")
+		cache[m.Path] = lines
+	}
+
+	around := height/2 - 1
+	if around < 3 {
+		around = 3
+	}
+	start := m.Line - 1 - around
+	if start < 0 {
+		start = 0
+	}
+	end := m.Line + around
+	if end > len(lines) {
+		end = len(lines)
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "%s\n\n", m.Path)
+	for i := start; i < end; i++ {
+		marker := "  "
+		if i == m.Line-1 {
+			marker = "> "
+		}
+		fmt.Fprintf(&b, "%s%5d  %s\n", marker, i+1, lines[i])
+	}
+	return b.String()
+}
@@ -0,0 +1,289 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"lang/gitutil"
+
+	"github.com/dzonerzy/go-snap/snap"
+)
+
+const (
+	defaultSizeReportLimit = 20
+	// sizeReportThreshold is where a file earns a hint, the same 1MB that
+	// gitDiffSize marks as too big.
+	sizeReportThreshold = 1000000
+	// binarySniffLength is how much of a file is checked for a NUL byte,
+	// the same heuristic git uses to call a file binary.
+	binarySniffLength = 8000
+)
+
+// sizedPath is a blob in history or a file in the working tree.
+type sizedPath struct {
+	Path string
+	SHA  string
+	Size int64
+}
+
+func runSizeReport(ctx *snap.Context) error {
+	usage := func() {
+		fmt.Fprintf(ctx.Stderr(), "Usage: %s sizeReport [-n <limit>]\n", commandName)
+	}
+
+	limit := defaultSizeReportLimit
+	for i := 0; i < ctx.NArgs(); i++ {
+		arg := strings.TrimSpace(ctx.Arg(i))
+		name, value, hasValue := strings.Cut(arg, "=")
+		switch name {
+		case "":
+			continue
+		case "-n", "--limit":
+		default:
+			usage()
+			return fmt.Errorf("unexpected argument %q", arg)
+		}
+		if !hasValue {
+			if i+1 >= ctx.NArgs() || strings.TrimSpace(ctx.Arg(i+1)) == "" {
+				usage()
+				return fmt.Errorf("%s requires a value", name)
+			}
+			i++
+			value = ctx.Arg(i)
+		}
+		n, err := strconv.Atoi(strings.TrimSpace(value))
+		if err != nil || n <= 0 {
+			usage()
+			return fmt.Errorf("%s expects a positive number, got %q", name, value)
+		}
+		limit = n
+	}
+
+	if err := gitutil.EnsureRepository(flowCtx); err != nil {
+		return err
+	}
+	out, err := flowCommand("git", "rev-parse", "--show-toplevel").Output()
+	if err != nil {
+		return reportError(ctx, fmt.Errorf("git rev-parse --show-toplevel: %w", err))
+	}
+	root := strings.TrimSpace(string(out))
+
+	tracked, err := trackedFiles(root)
+	if err != nil {
+		return reportError(ctx, err)
+	}
+	blobs, err := historyBlobs(root)
+	if err != nil {
+		return reportError(ctx, err)
+	}
+	files, err := workingTreeFiles(root)
+	if err != nil {
+		return reportError(ctx, err)
+	}
+
+	w := ctx.Stdout()
+	fmt.Fprintf(w, "Largest blobs in history (%d blobs, %s uncompressed):\n", len(blobs), formatFileSize(totalSize(blobs)))
+	if len(blobs) == 0 {
+		fmt.Fprintln(w, "  none")
+	}
+	for _, blob := range topBySize(blobs, limit) {
+		note := ""
+		if blob.Path != "" && !tracked[blob.Path] && blob.Size >= sizeReportThreshold {
+			note = "  ← no longer tracked; only rewriting history removes it"
+		}
+		fmt.Fprintf(w, "  %8s  %s  %s%s\n", formatFileSize(blob.Size), shortHash(blob.SHA), blob.Path, note)
+	}
+
+	fmt.Fprintln(w)
+	fmt.Fprintf(w, "Largest files in the working tree (%d files, %s):\n", len(files), formatFileSize(totalSize(files)))
+	if len(files) == 0 {
+		fmt.Fprintln(w, "  none")
+	}
+	top := topBySize(files, limit)
+	var lfsCandidates []string
+	for _, file := range top {
+		if tracked[file.Path] && file.Size >= sizeReportThreshold {
+			lfsCandidates = append(lfsCandidates, file.Path)
+		}
+	}
+	inLFS := lfsTrackedFiles(root, lfsCandidates)
+	for _, file := range top {
+		binary := false
+		if file.Size >= sizeReportThreshold && tracked[file.Path] && !inLFS[file.Path] {
+			binary = looksBinary(filepath.Join(root, filepath.FromSlash(file.Path)))
+		}
+		note := ""
+		if hint := sizeHint(file.Size, tracked[file.Path], binary); hint != "" {
+			note = "  ← " + hint
+		}
+		fmt.Fprintf(w, "  %8s  %s%s\n", formatFileSize(file.Size), file.Path, note)
+	}
+	return nil
+}
+
+// sizeHint suggests what to do about a working tree file: large untracked
+// files probably belong in .gitignore, large tracked binaries in git lfs.
+func sizeHint(size int64, tracked, binary bool) string {
+	switch {
+	case size < sizeReportThreshold:
+		return ""
+	case !tracked:
+		return "untracked; add to .gitignore?"
+	case binary:
+		return "binary; move to git lfs?"
+	default:
+		return ""
+	}
+}
+
+// historyBlobs lists every blob reachable from any ref with its size,
+// by feeding git rev-list --objects into git cat-file --batch-check.
+func historyBlobs(root string) ([]sizedPath, error) {
+	revList := flowCommand("git", "rev-list", "--objects", "--all")
+	revList.Dir = root
+	objects, err := revList.Output()
+	if err != nil {
+		return nil, fmt.Errorf("git rev-list --objects --all: %w", err)
+	}
+
+	catFile := flowCommand("git", "cat-file", "--batch-check=%(objecttype) %(objectname) %(objectsize) %(rest)")
+	catFile.Dir = root
+	catFile.Stdin = bytes.NewReader(objects)
+	out, err := catFile.Output()
+	if err != nil {
+		return nil, fmt.Errorf("git cat-file --batch-check: %w", err)
+	}
+	return parseBatchCheck(string(out))
+}
+
+// parseBatchCheck reads "<type> <sha> <size> <path>" lines, keeping blobs.
+// Blobs reached only through a tree with no name have an empty path.
+func parseBatchCheck(out string) ([]sizedPath, error) {
+	var blobs []sizedPath
+	for _, line := range strings.Split(out, "\n") {
+		if line == "" {
+			continue
+		}
+		fields := strings.SplitN(line, " ", 4)
+		if len(fields) < 3 {
+			return nil, fmt.Errorf("unexpected cat-file line %q", line)
+		}
+		if fields[0] != "blob" {
+			continue
+		}
+		size, err := strconv.ParseInt(fields[2], 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("unexpected cat-file line %q", line)
+		}
+		blob := sizedPath{SHA: fields[1], Size: size}
+		if len(fields) == 4 {
+			blob.Path = fields[3]
+		}
+		blobs = append(blobs, blob)
+	}
+	return blobs, nil
+}
+
+// trackedFiles is the set of paths in the index, slash-separated and
+// relative to root.
+func trackedFiles(root string) (map[string]bool, error) {
+	cmd := flowCommand("git", "ls-files", "-z")
+	cmd.Dir = root
+	out, err := cmd.Output()
+	if err != nil {
+		return nil, fmt.Errorf("git ls-files: %w", err)
+	}
+	tracked := map[string]bool{}
+	for _, name := range strings.Split(string(out), "\x00") {
+		if name != "" {
+			tracked[name] = true
+		}
+	}
+	return tracked, nil
+}
+
+// workingTreeFiles sizes the files git sees: tracked ones plus untracked
+// ones that are not ignored. Paths are slash-separated.
+func workingTreeFiles(root string) ([]sizedPath, error) {
+	names, ok := gitVisibleFiles(root)
+	if !ok {
+		return nil, fmt.Errorf("git ls-files failed in %s", root)
+	}
+	var files []sizedPath
+	for _, name := range names {
+		// Tracked files deleted from the work tree are still listed.
+		info, err := os.Lstat(filepath.Join(root, name))
+		if err != nil || !info.Mode().IsRegular() {
+			continue
+		}
+		files = append(files, sizedPath{Path: filepath.ToSlash(name), Size: info.Size()})
+	}
+	return files, nil
+}
+
+// lfsTrackedFiles reports which of paths .gitattributes hands to the lfs
+// filter. Their history holds small pointers, so they need no hint.
+func lfsTrackedFiles(root string, paths []string) map[string]bool {
+	inLFS := map[string]bool{}
+	if len(paths) == 0 {
+		return inLFS
+	}
+	cmd := flowCommand("git", "check-attr", "-z", "--stdin", "filter")
+	cmd.Dir = root
+	cmd.Stdin = strings.NewReader(strings.Join(paths, "\x00") + "\x00")
+	out, err := cmd.Output()
+	if err != nil {
+		return inLFS
+	}
+	// Output is path, attribute, value triples, each NUL-terminated.
+	fields := strings.Split(string(out), "\x00")
+	for i := 0; i+2 < len(fields); i += 3 {
+		if fields[i+2] == "lfs" {
+			inLFS[fields[i]] = true
+		}
+	}
+	return inLFS
+}
+
+func looksBinary(path string) bool {
+	f, err := os.Open(path)
+	if err != nil {
+		return false
+	}
+	defer f.Close()
+	head := make([]byte, binarySniffLength)
+	n, err := io.ReadFull(f, head)
+	if err != nil && err != io.ErrUnexpectedEOF {
+		return false
+	}
+	return bytes.IndexByte(head[:n], 0) >= 0
+}
+
+// topBySize returns the limit largest entries, biggest first, ties by path.
+func topBySize(entries []sizedPath, limit int) []sizedPath {
+	sorted := append([]sizedPath(nil), entries...)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		if sorted[i].Size != sorted[j].Size {
+			return sorted[i].Size > sorted[j].Size
+		}
+		return sorted[i].Path < sorted[j].Path
+	})
+	if len(sorted) > limit {
+		sorted = sorted[:limit]
+	}
+	return sorted
+}
+
+func totalSize(entries []sizedPath) int64 {
+	var total int64
+	for _, entry := range entries {
+		total += entry.Size
+	}
+	return total
+}
@@ -0,0 +1,95 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestParseBatchCheck(t *testing.T) {
+	out := "commit 1111111111111111111111111111111111111111 240 \n" +
+		"tree 2222222222222222222222222222222222222222 90 \n" +
+		"blob 3333333333333333333333333333333333333333 5000000 assets/intro video.mp4\n" +
+		"blob 4444444444444444444444444444444444444444 12 \n"
+	got, err := parseBatchCheck(out)
+	if err != nil {
+		t.Fatalf("parseBatchCheck: %v", err)
+	}
+	want := []sizedPath{
+		{Path: "assets/intro video.mp4", SHA: "3333333333333333333333333333333333333333", Size: 5000000},
+		{Path: "", SHA: "4444444444444444444444444444444444444444", Size: 12},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("parseBatchCheck = %+v, want %+v", got, want)
+	}
+
+	for _, bad := range []string{"blob 3333", "blob 3333 big path"} {
+		if _, err := parseBatchCheck(bad); err == nil {
+			t.Errorf("parseBatchCheck(%q) should fail", bad)
+		}
+	}
+}
+
+func TestTopBySize(t *testing.T) {
+	entries := []sizedPath{{Path: "b", Size: 10}, {Path: "a", Size: 10}, {Path: "c", Size: 30}, {Path: "d", Size: 1}}
+	got := topBySize(entries, 3)
+	want := []sizedPath{{Path: "c", Size: 30}, {Path: "a", Size: 10}, {Path: "b", Size: 10}}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("topBySize = %+v, want %+v", got, want)
+	}
+	if entries[0].Path != "b" {
+		t.Errorf("topBySize reordered its input: %+v", entries)
+	}
+}
+
+func TestSizeHint(t *testing.T) {
+	cases := []struct {
+		size            int64
+		tracked, binary bool
+		want            string
+	}{
+		{999999, false, true, ""},
+		{2000000, false, false, "untracked; add to .gitignore?"},
+		{2000000, true, true, "binary; move to git lfs?"},
+		{2000000, true, false, ""},
+	}
+	for _, tc := range cases {
+		if got := sizeHint(tc.size, tc.tracked, tc.binary); got != tc.want {
+			t.Errorf("sizeHint(%d, %v, %v) = %q, want %q", tc.size, tc.tracked, tc.binary, got, tc.want)
+		}
+	}
+}
+
+func TestFormatFileSize(t *testing.T) {
+	cases := map[int64]string{
+		0:          "0B",
+		999:        "999B",
+		1500:       "1.5KB",
+		4500000:    "4.5MB",
+		1200000000: "1.2GB",
+	}
+	for bytes, want := range cases {
+		if got := formatFileSize(bytes); got != want {
+			t.Errorf("formatFileSize(%d) = %q, want %q", bytes, got, want)
+		}
+	}
+}
+
+func TestLooksBinary(t *testing.T) {
+	dir := t.TempDir()
+	text := filepath.Join(dir, "notes.txt")
+	binary := filepath.Join(dir, "image.png")
+	if err := os.WriteFile(text, []byte("plain text\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(binary, []byte{0x89, 'P', 'N', 'G', 0x00, 0x01}, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if looksBinary(text) {
+		t.Errorf("looksBinary(%s) = true, want false", text)
+	}
+	if !looksBinary(binary) {
+		t.Errorf("looksBinary(%s) = false, want true", binary)
+	}
+}
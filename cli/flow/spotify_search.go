@@ -0,0 +1,214 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/dzonerzy/go-snap/snap"
+	"github.com/ktr0731/go-fuzzyfinder"
+)
+
+const (
+	spotifyTokenEnv      = "SPOTIFY_TOKEN"
+	spotifySearchURL     = "https://api.spotify.com/v1/search"
+	spotifySearchLimit   = 20
+	spotifySearchTimeout = 15 * time.Second
+)
+
+var spotifySearchTypes = []string{"track", "album", "playlist"}
+
+type spotifySearchResult struct {
+	Kind     string
+	Name     string
+	Subtitle string
+	URI      string
+}
+
+func (r spotifySearchResult) label() string {
+	if r.Subtitle == "" {
+		return fmt.Sprintf("%-8s %s", r.Kind, r.Name)
+	}
+	return fmt.Sprintf("%-8s %s — %s", r.Kind, r.Name, r.Subtitle)
+}
+
+func runSpotifySearch(ctx *snap.Context) error {
+	usage := func() {
+		fmt.Fprintf(ctx.Stderr(), "Usage: %s spotifySearch [--type track|album|playlist] <query>\n", commandName)
+	}
+
+	types := spotifySearchTypes
+	var words []string
+	for i := 0; i < ctx.NArgs(); i++ {
+		arg := strings.TrimSpace(ctx.Arg(i))
+		switch {
+		case arg == "":
+		case arg == "--type" || arg == "-t":
+			if i+1 >= ctx.NArgs() {
+				usage()
+				return fmt.Errorf("%s requires a value", arg)
+			}
+			i++
+			kind := strings.ToLower(strings.TrimSpace(ctx.Arg(i)))
+			if !containsString(spotifySearchTypes, kind) {
+				usage()
+				return fmt.Errorf("invalid --type %q: expected %s", kind, strings.Join(spotifySearchTypes, ", "))
+			}
+			types = []string{kind}
+		case strings.HasPrefix(arg, "-"):
+			usage()
+			return fmt.Errorf("unknown flag %q", arg)
+		default:
+			words = append(words, arg)
+		}
+	}
+	query := strings.Join(words, " ")
+	if query == "" {
+		usage()
+		return fmt.Errorf("search query is required")
+	}
+
+	token, ok := lookupNonEmptyEnv(spotifyTokenEnv)
+	if !ok {
+		return reportError(ctx, fmt.Errorf("%s is not set; create a Web API access token at https://developer.spotify.com and export it", spotifyTokenEnv))
+	}
+
+	results, err := searchSpotify(flowCtx, token, query, types)
+	if err != nil {
+		return reportError(ctx, err)
+	}
+	if len(results) == 0 {
+		fmt.Fprintf(ctx.Stdout(), "No Spotify results for %q\n", query)
+		return nil
+	}
+
+	idx, err := fuzzyfinder.Find(
+		results,
+		func(i int) string { return results[i].label() },
+		fuzzyfinder.WithPromptString("spotify> "),
+	)
+	if err != nil {
+		if errors.Is(err, fuzzyfinder.ErrAbort) {
+			return errUserAbort
+		}
+		return reportError(ctx, fmt.Errorf("select result: %w", err))
+	}
+	selected := results[idx]
+
+	if err := playSpotifyURI(ctx, selected.URI); err != nil {
+		return reportError(ctx, err)
+	}
+	fmt.Fprintf(ctx.Stdout(), "✔️ Playing %s (%s)\n", selected.Name, selected.URI)
+	return nil
+}
+
+type spotifyArtist struct {
+	Name string `json:"name"`
+}
+
+// spotifySearchResponse covers the fields fgo shows. Spotify returns null
+// for playlists it can no longer serve, hence the pointers.
+type spotifySearchResponse struct {
+	Tracks struct {
+		Items []*struct {
+			Name    string          `json:"name"`
+			URI     string          `json:"uri"`
+			Artists []spotifyArtist `json:"artists"`
+			Album   struct {
+				Name string `json:"name"`
+			} `json:"album"`
+		} `json:"items"`
+	} `json:"tracks"`
+	Albums struct {
+		Items []*struct {
+			Name    string          `json:"name"`
+			URI     string          `json:"uri"`
+			Artists []spotifyArtist `json:"artists"`
+		} `json:"items"`
+	} `json:"albums"`
+	Playlists struct {
+		Items []*struct {
+			Name  string `json:"name"`
+			URI   string `json:"uri"`
+			Owner struct {
+				DisplayName string `json:"display_name"`
+			} `json:"owner"`
+		} `json:"items"`
+	} `json:"playlists"`
+}
+
+func searchSpotify(ctx context.Context, token, query string, types []string) ([]spotifySearchResult, error) {
+	ctx, cancel := context.WithTimeout(ctx, spotifySearchTimeout)
+	defer cancel()
+
+	params := url.Values{}
+	params.Set("q", query)
+	params.Set("type", strings.Join(types, ","))
+	params.Set("limit", fmt.Sprint(spotifySearchLimit))
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, spotifySearchURL+"?"+params.Encode(), nil)
+	if err != nil {
+		return nil, fmt.Errorf("search Spotify: %w", err)
+	}
+	req.Header.Set("Authorization", "Bearer "+token)
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("search Spotify: %w", err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, 8<<20))
+	if err != nil {
+		return nil, fmt.Errorf("search Spotify: %w", err)
+	}
+	switch {
+	case resp.StatusCode == http.StatusUnauthorized:
+		return nil, fmt.Errorf("search Spotify: %s was rejected (expired or invalid); tokens last an hour", spotifyTokenEnv)
+	case resp.StatusCode != http.StatusOK:
+		return nil, fmt.Errorf("search Spotify: %s: %s", resp.Status, strings.TrimSpace(string(body)))
+	}
+
+	var parsed spotifySearchResponse
+	if err := json.Unmarshal(body, &parsed); err != nil {
+		return nil, fmt.Errorf("decode Spotify search response: %w", err)
+	}
+
+	var results []spotifySearchResult
+	for _, item := range parsed.Tracks.Items {
+		if item == nil {
+			continue
+		}
+		subtitle := spotifyArtistNames(item.Artists)
+		if item.Album.Name != "" {
+			subtitle += " · " + item.Album.Name
+		}
+		results = append(results, spotifySearchResult{Kind: "track", Name: item.Name, Subtitle: subtitle, URI: item.URI})
+	}
+	for _, item := range parsed.Albums.Items {
+		if item == nil {
+			continue
+		}
+		results = append(results, spotifySearchResult{Kind: "album", Name: item.Name, Subtitle: spotifyArtistNames(item.Artists), URI: item.URI})
+	}
+	for _, item := range parsed.Playlists.Items {
+		if item == nil {
+			continue
+		}
+		results = append(results, spotifySearchResult{Kind: "playlist", Name: item.Name, Subtitle: item.Owner.DisplayName, URI: item.URI})
+	}
+	return results, nil
+}
+
+func spotifyArtistNames(artists []spotifyArtist) string {
+	names := make([]string, 0, len(artists))
+	for _, artist := range artists {
+		names = append(names, artist.Name)
+	}
+	return strings.Join(names, ", ")
+}
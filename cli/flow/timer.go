@@ -0,0 +1,175 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os/exec"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/dzonerzy/go-snap/snap"
+)
+
+const (
+	defaultTimerDuration = 25 * time.Minute
+	defaultTimerSound    = "Glass"
+)
+
+func runTimer(ctx *snap.Context) error {
+	usage := func() {
+		fmt.Fprintf(ctx.Stderr(), "Usage: %s timer [duration] [--label <text>] [--sound [name]]\n", commandName)
+	}
+
+	duration := defaultTimerDuration
+	label := ""
+	sound := ""
+	sawDuration := false
+	for i := 0; i < ctx.NArgs(); i++ {
+		arg := strings.TrimSpace(ctx.Arg(i))
+		switch {
+		case arg == "":
+		case arg == "--label" || arg == "-l":
+			if i+1 >= ctx.NArgs() || strings.TrimSpace(ctx.Arg(i+1)) == "" {
+				usage()
+				return fmt.Errorf("%s requires a value", arg)
+			}
+			i++
+			label = strings.TrimSpace(ctx.Arg(i))
+		case strings.HasPrefix(arg, "--label="):
+			label = strings.TrimSpace(strings.TrimPrefix(arg, "--label="))
+		case arg == "--sound":
+			sound = defaultTimerSound
+			if i+1 < ctx.NArgs() && !strings.HasPrefix(ctx.Arg(i+1), "-") {
+				if _, err := parseTimerDuration(ctx.Arg(i + 1)); err != nil {
+					i++
+					sound = strings.TrimSpace(ctx.Arg(i))
+				}
+			}
+		case strings.HasPrefix(arg, "--sound="):
+			sound = strings.TrimSpace(strings.TrimPrefix(arg, "--sound="))
+		case strings.HasPrefix(arg, "-"):
+			usage()
+			return fmt.Errorf("unknown flag %q", arg)
+		case sawDuration:
+			usage()
+			return fmt.Errorf("unexpected argument %q", arg)
+		default:
+			d, err := parseTimerDuration(arg)
+			if err != nil {
+				usage()
+				return err
+			}
+			duration = d
+			sawDuration = true
+		}
+	}
+
+	title := "Timer"
+	if label != "" {
+		title = label
+	}
+	end := time.Now().Add(duration)
+	fmt.Fprintf(ctx.Stdout(), "▶️ %s: %s, until %s (Ctrl-C to cancel)\n", title, formatCountdown(duration), end.Format("15:04"))
+
+	interactive := isTerminal(ctx.Stdout())
+	ticker := time.NewTicker(time.Second)
+	defer ticker.Stop()
+	lastMinute := -1
+	for {
+		remaining := time.Until(end).Round(time.Second)
+		if remaining <= 0 {
+			break
+		}
+		if interactive {
+			fmt.Fprintf(ctx.Stdout(), "\r%s remaining ", formatCountdown(remaining))
+		} else if minute := int((remaining + time.Minute - time.Second) / time.Minute); minute != lastMinute {
+			// Piped output gets a line per minute instead of a redrawn
+			// counter.
+			fmt.Fprintf(ctx.Stdout(), "%s remaining\n", formatCountdown(remaining))
+			lastMinute = minute
+		}
+
+		select {
+		case <-flowCtx.Done():
+			if interactive {
+				fmt.Fprintln(ctx.Stdout())
+			}
+			cause := context.Cause(flowCtx)
+			if errors.Is(cause, errUserAbort) {
+				fmt.Fprintf(ctx.Stdout(), "%s cancelled with %s left.\n", title, formatCountdown(remaining))
+				return errUserAbort
+			}
+			return reportError(ctx, cause)
+		case <-ticker.C:
+		}
+	}
+	if interactive {
+		fmt.Fprint(ctx.Stdout(), "\r\033[K")
+	}
+
+	message := fmt.Sprintf("%s finished after %s", title, formatCountdown(duration))
+	if err := timerNotification(title, message, sound); err != nil {
+		// Without a notification the terminal bell is the next best thing.
+		fmt.Fprint(ctx.Stdout(), "\a")
+		fmt.Fprintf(ctx.Stderr(), "ℹ️ Could not show a notification: %v\n", err)
+	}
+	fmt.Fprintf(ctx.Stdout(), "✔️ %s\n", message)
+	return nil
+}
+
+// parseTimerDuration accepts a Go duration such as 90s or 1h15m, or a bare
+// number of minutes.
+func parseTimerDuration(value string) (time.Duration, error) {
+	value = strings.TrimSpace(value)
+	if minutes, err := strconv.ParseFloat(value, 64); err == nil {
+		if minutes <= 0 {
+			return 0, fmt.Errorf("timer duration must be positive, got %q", value)
+		}
+		return time.Duration(minutes * float64(time.Minute)).Round(time.Second), nil
+	}
+	d, err := time.ParseDuration(value)
+	if err != nil {
+		return 0, fmt.Errorf("invalid duration %q: use minutes (25) or a duration like 90s or 1h15m", value)
+	}
+	if d <= 0 {
+		return 0, fmt.Errorf("timer duration must be positive, got %q", value)
+	}
+	return d, nil
+}
+
+// formatCountdown renders d as MM:SS, or H:MM:SS from an hour up.
+func formatCountdown(d time.Duration) string {
+	d = d.Round(time.Second)
+	if d < 0 {
+		d = 0
+	}
+	h := int(d / time.Hour)
+	m := int(d % time.Hour / time.Minute)
+	s := int(d % time.Minute / time.Second)
+	if h > 0 {
+		return fmt.Sprintf("%d:%02d:%02d", h, m, s)
+	}
+	return fmt.Sprintf("%02d:%02d", m, s)
+}
+
+// timerNotification posts a macOS notification, playing sound (a name from
+// /System/Library/Sounds) when it is not empty.
+func timerNotification(title, message, sound string) error {
+	if _, err := exec.LookPath("osascript"); err != nil {
+		return fmt.Errorf("osascript not found in PATH: %w", err)
+	}
+
+	script := fmt.Sprintf(`display notification "%s" with title "%s"`, escapeAppleScriptString(message), escapeAppleScriptString(title))
+	if sound != "" {
+		script += fmt.Sprintf(` sound name "%s"`, escapeAppleScriptString(sound))
+	}
+	if output, err := flowCommand("osascript", "-e", script).CombinedOutput(); err != nil {
+		if trimmed := strings.TrimSpace(string(output)); trimmed != "" {
+			return fmt.Errorf("osascript notification: %s", trimmed)
+		}
+		return fmt.Errorf("osascript notification: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,55 @@
+package main
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseTimerDuration(t *testing.T) {
+	cases := []struct {
+		value string
+		want  time.Duration
+	}{
+		{"25", 25 * time.Minute},
+		{"1.5", 90 * time.Second},
+		{"90s", 90 * time.Second},
+		{"1h15m", 75 * time.Minute},
+	}
+	for _, tc := range cases {
+		got, err := parseTimerDuration(tc.value)
+		if err != nil || got != tc.want {
+			t.Errorf("parseTimerDuration(%q) = %v, %v; want %v", tc.value, got, err, tc.want)
+		}
+	}
+
+	for _, value := range []string{"", "0", "-5", "soon", "-1m"} {
+		if got, err := parseTimerDuration(value); err == nil {
+			t.Errorf("parseTimerDuration(%q) = %v, want an error", value, got)
+		}
+	}
+}
+
+func TestFormatCountdown(t *testing.T) {
+	cases := map[time.Duration]string{
+		0:                "00:00",
+		59 * time.Second: "00:59",
+		25 * time.Minute: "25:00",
+		time.Hour + 2*time.Minute + 3*time.Second: "1:02:03",
+		-time.Second: "00:00",
+	}
+	for d, want := range cases {
+		if got := formatCountdown(d); got != want {
+			t.Errorf("formatCountdown(%v) = %q, want %q", d, got, want)
+		}
+	}
+}
+
+func TestTimerIgnoresRunTimeout(t *testing.T) {
+	t.Setenv(flowTimeoutEnv, "1m")
+	if got := runTimeout([]string{"timer", "25"}); got != 0 {
+		t.Errorf("runTimeout(timer) = %v, want no timeout", got)
+	}
+	if got := runTimeout([]string{"gitLog"}); got != time.Minute {
+		t.Errorf("runTimeout(gitLog) = %v, want %v", got, time.Minute)
+	}
+}
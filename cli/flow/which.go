@@ -0,0 +1,170 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/dzonerzy/go-snap/snap"
+)
+
+// browserCommands read tabs from the browser chosen by FLOW_BROWSER.
+var browserCommands = []string{"cloneAndOpen", "youtubeToSound", "openBrowserTabs"}
+
+type commandDependency struct {
+	Name  string `json:"name"`
+	Found bool   `json:"found"`
+	Path  string `json:"path,omitempty"`
+	Hint  string `json:"hint,omitempty"`
+	// Note says when the tool is used if not on every run, or which setting
+	// picked it.
+	Note string `json:"note,omitempty"`
+}
+
+func resolveCommandDependency(dep dependency, note string) commandDependency {
+	status := checkDependency(dep)
+	return commandDependency{Name: status.Name, Found: status.Found, Path: status.Path, Hint: status.Hint, Note: note}
+}
+
+// browserDependency is the browser FLOW_BROWSER selects, as a dependency.
+func browserDependency() (dependency, error) {
+	browser, err := configuredBrowser()
+	if err != nil {
+		return dependency{}, err
+	}
+	return dependency{
+		Name:    browser.Name,
+		AppPath: filepath.Join("/Applications", browser.Name+".app"),
+		Hint:    fmt.Sprintf("install it or change %s", flowBrowserEnv),
+	}, nil
+}
+
+// dependencyUsage matches command against a Commands entry such as
+// "notes --clip", returning the qualifier that limits when the tool is used.
+func dependencyUsage(entries []string, command string) (string, bool) {
+	for _, entry := range entries {
+		if entry == command {
+			return "", true
+		}
+		if qualifier, ok := strings.CutPrefix(entry, command+" "); ok {
+			return qualifier, true
+		}
+	}
+	return "", false
+}
+
+func usageNote(command, qualifier string) string {
+	if qualifier == "" {
+		return ""
+	}
+	return fmt.Sprintf("only for %s %s", command, qualifier)
+}
+
+// commandDependencies lists the external tools and apps command may run,
+// resolved on this machine.
+func commandDependencies(command string) ([]commandDependency, error) {
+	editor := editorDependency()
+	_, usesEditor := dependencyUsage(editorCommands, command)
+
+	var deps []commandDependency
+	for _, dep := range dependenciesWithEditor() {
+		qualifier, ok := dependencyUsage(dep.Commands, command)
+		if !ok {
+			continue
+		}
+		note := usageNote(command, qualifier)
+		if usesEditor && dep.Name == editor.Name {
+			if note == "" {
+				note = "editor"
+			}
+			note += ", from " + flowEditorEnv
+		}
+		deps = append(deps, resolveCommandDependency(dep, note))
+	}
+
+	if qualifier, ok := dependencyUsage(browserCommands, command); ok {
+		dep, err := browserDependency()
+		if err != nil {
+			return nil, err
+		}
+		note := usageNote(command, qualifier)
+		if note == "" {
+			note = "browser"
+		}
+		deps = append(deps, resolveCommandDependency(dep, note+", from "+flowBrowserEnv))
+	}
+	return deps, nil
+}
+
+func runWhich(ctx *snap.Context) error {
+	usage := func() {
+		fmt.Fprintf(ctx.Stderr(), "Usage: %s which [--json] <command>\n", commandName)
+	}
+
+	asJSON := false
+	command := ""
+	for i := 0; i < ctx.NArgs(); i++ {
+		arg := strings.TrimSpace(ctx.Arg(i))
+		switch {
+		case arg == "":
+		case arg == "--json":
+			asJSON = true
+		case strings.HasPrefix(arg, "-") || command != "":
+			usage()
+			return fmt.Errorf("unexpected argument %q", arg)
+		default:
+			command = arg
+		}
+	}
+	if command == "" {
+		usage()
+		return fmt.Errorf("command name is required")
+	}
+
+	if alias, ok := findCommandAlias(command); ok {
+		fmt.Fprintf(ctx.Stdout(), "ℹ️ %s is an alias for %s %s\n", command, commandName, alias.Expansion())
+		command = alias.Command
+	}
+	if !isBuiltinCommand(command) {
+		return reportError(ctx, fmt.Errorf("unknown command %q (see %s help)", command, commandName))
+	}
+
+	deps, err := commandDependencies(command)
+	if err != nil {
+		return reportError(ctx, err)
+	}
+
+	if asJSON {
+		if deps == nil {
+			deps = []commandDependency{}
+		}
+		encoder := json.NewEncoder(ctx.Stdout())
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(deps)
+	}
+
+	if len(deps) == 0 {
+		fmt.Fprintf(ctx.Stdout(), "%s needs no external tools\n", command)
+		return nil
+	}
+
+	width := 0
+	for _, dep := range deps {
+		if len(dep.Name) > width {
+			width = len(dep.Name)
+		}
+	}
+	for _, dep := range deps {
+		note := ""
+		if dep.Note != "" {
+			note = "  (" + dep.Note + ")"
+		}
+		if dep.Found {
+			fmt.Fprintf(ctx.Stdout(), "✔️ %-*s  %s%s\n", width, dep.Name, dep.Path, note)
+			continue
+		}
+		fmt.Fprintf(ctx.Stdout(), "   %-*s  missing (%s)%s\n", width, dep.Name, dep.Hint, note)
+	}
+	return nil
+}
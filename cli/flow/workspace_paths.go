@@ -11,6 +11,8 @@ import (
 	"path/filepath"
 	"strings"
 
+	"lang/userpath"
+
 	"github.com/dzonerzy/go-snap/snap"
 	"github.com/ktr0731/go-fuzzyfinder"
 )
@@ -280,8 +282,8 @@ func (w *workspaceDocument) set(kind workspaceList, values []string) error {
 func resolveWorkspaceFilePath(raw string) (string, error) {
 	path := strings.TrimSpace(raw)
 	if path == "" {
-		if env := os.Getenv("FLOW_WORKSPACE_FILE"); strings.TrimSpace(env) != "" {
-			path = env
+		if configured, ok := lookupSetting(workspaceFileEnv); ok {
+			path = configured
 		}
 	}
 	if path == "" {
@@ -300,7 +302,7 @@ func resolveWorkspaceFilePath(raw string) (string, error) {
 		)
 	}
 
-	expanded, err := expandUserPath(path)
+	expanded, err := userpath.Expand(path)
 	if err != nil {
 		return "", fmt.Errorf("expand workspace file path: %w", err)
 	}
@@ -312,7 +314,7 @@ func resolveWorkspaceFilePath(raw string) (string, error) {
 }
 
 func normalizeWorkspacePath(raw string) (string, error) {
-	expanded, err := expandUserPath(raw)
+	expanded, err := userpath.Expand(raw)
 	if err != nil {
 		return "", err
 	}
@@ -387,7 +389,7 @@ func workspaceRemovePath(ctx *snap.Context, doc *workspaceDocument, listKind wor
 		if err != nil {
 			if errors.Is(err, fuzzyfinder.ErrAbort) {
 				fmt.Fprintln(ctx.Stdout(), "Aborted.")
-				return nil
+				return errUserAbort
 			}
 			return fmt.Errorf("select path: %w", err)
 		}
@@ -449,31 +451,6 @@ func removeString(list []string, target string) ([]string, bool) {
 	return out, removed
 }
 
-func expandUserPath(path string) (string, error) {
-	trimmed := strings.TrimSpace(path)
-	if trimmed == "" {
-		return "", fmt.Errorf("path cannot be empty")
-	}
-	if trimmed[0] != '~' {
-		return trimmed, nil
-	}
-
-	home, err := os.UserHomeDir()
-	if err != nil {
-		return "", fmt.Errorf("determine home directory: %w", err)
-	}
-	if len(trimmed) == 1 {
-		return home, nil
-	}
-
-	switch trimmed[1] {
-	case '/', '\\':
-		return filepath.Join(home, trimmed[2:]), nil
-	default:
-		return "", fmt.Errorf("unsupported ~ expansion in %q", trimmed)
-	}
-}
-
 func promptWithDefault(out io.Writer, reader *bufio.Reader, label, defaultValue string) (string, error) {
 	prompt := label
 	if defaultValue != "" {
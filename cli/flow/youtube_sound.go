@@ -0,0 +1,353 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"lang/userpath"
+
+	"github.com/dzonerzy/go-snap/snap"
+)
+
+const (
+	youtubeSoundFormat          = "mp3"
+	defaultSponsorBlockCategory = "sponsor"
+)
+
+// thumbnailAudioFormats are the audio containers yt-dlp can embed cover art
+// into; for the others it fails after the download.
+var thumbnailAudioFormats = []string{"mp3", "m4a", "flac", "opus", "ogg"}
+
+// youtubeSoundOptions is a parsed youtubeToSound command line. Extra holds
+// everything after the URL, which goes to yt-dlp untouched.
+type youtubeSoundOptions struct {
+	URL                string
+	EmbedMetadata      bool
+	EmbedThumbnail     bool
+	SponsorBlockRemove string
+	OutputDir          string
+	Extra              []string
+}
+
+// parseYoutubeSoundArgs reads fgo's own flags up to the URL. A flag that
+// takes a value accepts both --flag value and --flag=value.
+func parseYoutubeSoundArgs(args []string) (youtubeSoundOptions, error) {
+	var opts youtubeSoundOptions
+	for i := 0; i < len(args); i++ {
+		arg := strings.TrimSpace(args[i])
+		if opts.URL != "" {
+			if arg != "" {
+				opts.Extra = append(opts.Extra, arg)
+			}
+			continue
+		}
+		switch {
+		case arg == "":
+		case arg == "--embed-metadata":
+			opts.EmbedMetadata = true
+		case arg == "--embed-thumbnail":
+			opts.EmbedThumbnail = true
+		case arg == "--sponsorblock-remove":
+			opts.SponsorBlockRemove = defaultSponsorBlockCategory
+			if i+1 < len(args) && !strings.HasPrefix(args[i+1], "-") && !looksLikeURL(args[i+1]) {
+				i++
+				opts.SponsorBlockRemove = strings.TrimSpace(args[i])
+			}
+		case arg == "--output-dir" || arg == "-o":
+			if i+1 >= len(args) || strings.TrimSpace(args[i+1]) == "" {
+				return opts, fmt.Errorf("%s requires a directory", arg)
+			}
+			i++
+			opts.OutputDir = strings.TrimSpace(args[i])
+		case strings.HasPrefix(arg, "--output-dir="):
+			opts.OutputDir = strings.TrimSpace(strings.TrimPrefix(arg, "--output-dir="))
+			if opts.OutputDir == "" {
+				return opts, fmt.Errorf("--output-dir requires a directory")
+			}
+		case strings.HasPrefix(arg, "--sponsorblock-remove="):
+			opts.SponsorBlockRemove = strings.TrimSpace(strings.TrimPrefix(arg, "--sponsorblock-remove="))
+			if opts.SponsorBlockRemove == "" {
+				return opts, fmt.Errorf("--sponsorblock-remove needs categories, e.g. sponsor,selfpromo")
+			}
+		case strings.HasPrefix(arg, "-"):
+			return opts, fmt.Errorf("unknown flag %q before the URL; yt-dlp flags go after it", arg)
+		default:
+			opts.URL = arg
+		}
+	}
+	return opts, nil
+}
+
+func looksLikeURL(arg string) bool {
+	return strings.HasPrefix(arg, "http://") || strings.HasPrefix(arg, "https://")
+}
+
+// youtubeSoundArgs assembles the yt-dlp command line. Warnings describe
+// options that were dropped because they cannot work as given.
+func youtubeSoundArgs(opts youtubeSoundOptions, outputTemplate, cookiesBrowser string) ([]string, []string) {
+	args := []string{"--extract-audio", "--audio-format", youtubeSoundFormat, "--audio-quality", "0", "--no-playlist", "-o", outputTemplate}
+	var warnings []string
+
+	if opts.EmbedMetadata {
+		args = append(args, "--embed-metadata")
+	}
+	if opts.EmbedThumbnail {
+		format := audioFormatArgument(opts.Extra)
+		if containsString(thumbnailAudioFormats, format) {
+			args = append(args, "--embed-thumbnail")
+		} else {
+			warnings = append(warnings, fmt.Sprintf("skipping --embed-thumbnail: %s files cannot hold cover art (use one of %s)", format, strings.Join(thumbnailAudioFormats, ", ")))
+		}
+	}
+	if opts.SponsorBlockRemove != "" {
+		args = append(args, "--sponsorblock-remove", opts.SponsorBlockRemove)
+	}
+	args = append(args, opts.Extra...)
+
+	if cookiesBrowser != "" && !strings.EqualFold(cookiesBrowser, "none") && !containsCookiesArgument(args) {
+		args = append(args, "--cookies-from-browser", cookiesBrowser)
+	}
+	return append(args, opts.URL), warnings
+}
+
+// audioFormatArgument is the container yt-dlp will write: the last
+// --audio-format in the forwarded arguments, or mp3.
+func audioFormatArgument(extra []string) string {
+	format := youtubeSoundFormat
+	for i, arg := range extra {
+		if value, ok := strings.CutPrefix(arg, "--audio-format="); ok {
+			format = value
+		} else if arg == "--audio-format" && i+1 < len(extra) {
+			format = extra[i+1]
+		}
+	}
+	return strings.ToLower(strings.TrimSpace(format))
+}
+
+func runYoutubeToSound(ctx *snap.Context) error {
+	usage := func() {
+		fmt.Fprintf(ctx.Stderr(), "Usage: %s youtubeToSound [-o <dir>] [--embed-metadata] [--embed-thumbnail] [--sponsorblock-remove [categories]] [youtube-url] [yt-dlp-args...]\n", commandName)
+	}
+
+	opts, err := parseYoutubeSoundArgs(ctx.Args())
+	if err != nil {
+		usage()
+		return reportError(ctx, err)
+	}
+
+	if opts.URL == "" {
+		opts.URL, _, err = frontmostBrowserURL()
+		if err != nil {
+			usage()
+			return reportError(ctx, fmt.Errorf("determine browser tab URL: %w", err))
+		}
+	}
+
+	if opts.URL == "" {
+		usage()
+		return reportError(ctx, fmt.Errorf("youtube url cannot be empty"))
+	}
+
+	if _, err := url.ParseRequestURI(opts.URL); err != nil {
+		return reportError(ctx, fmt.Errorf("validate url %q: %w", opts.URL, err))
+	}
+
+	downloader := "yt-dlp"
+	if _, err := exec.LookPath(downloader); err != nil {
+		return reportError(ctx, fmt.Errorf("%s not found in PATH: %w", downloader, err))
+	}
+
+	homeDir, err := os.UserHomeDir()
+	if err != nil {
+		return reportError(ctx, fmt.Errorf("determine home directory: %w", err))
+	}
+
+	// The manifest stays in ~/.flow/youtube-sound wherever the audio goes.
+	manifestDir := filepath.Join(homeDir, ".flow", "youtube-sound")
+	if err := os.MkdirAll(manifestDir, 0o755); err != nil {
+		return reportError(ctx, fmt.Errorf("create directory %s: %w", manifestDir, err))
+	}
+	targetDir := manifestDir
+	if opts.OutputDir != "" {
+		if targetDir, err = userpath.PrepareDir(opts.OutputDir); err != nil {
+			return reportError(ctx, err)
+		}
+	}
+
+	cookiesBrowser, ok := lookupSetting(youtubeCookiesBrowserEnv)
+	if !ok {
+		cookiesBrowser = "safari"
+	}
+	args, warnings := youtubeSoundArgs(opts, filepath.Join(targetDir, "%(title)s.%(ext)s"), cookiesBrowser)
+	for _, warning := range warnings {
+		fmt.Fprintf(ctx.Stderr(), "ℹ️ %s\n", warning)
+	}
+
+	// --print would imply --quiet and hide the progress bar, so the final
+	// paths go to a side file instead.
+	printed, err := os.CreateTemp("", "fgo-youtube-*.tsv")
+	if err != nil {
+		return reportError(ctx, fmt.Errorf("create temp file: %w", err))
+	}
+	printed.Close()
+	defer os.Remove(printed.Name())
+	args = append([]string{"--progress", "--print-to-file", youtubeManifestTemplate, printed.Name()}, args...)
+
+	cmd := flowCommand(downloader, args...)
+	cmd.Stdout = ctx.Stdout()
+	cmd.Stderr = ctx.Stderr()
+	cmd.Stdin = ctx.Stdin()
+	if err := cmd.Run(); err != nil {
+		return reportError(ctx, fmt.Errorf("%s failed: %w", downloader, err))
+	}
+
+	output, err := os.ReadFile(printed.Name())
+	if err != nil {
+		return reportError(ctx, fmt.Errorf("read downloaded paths: %w", err))
+	}
+	entries := parseYoutubeDownloads(string(output), opts.URL, time.Now())
+	if err := appendYoutubeManifest(filepath.Join(manifestDir, youtubeManifestName), entries); err != nil {
+		fmt.Fprintf(ctx.Stderr(), "ℹ️ Could not update the manifest: %v\n", err)
+	}
+
+	if len(entries) == 0 {
+		fmt.Fprintf(ctx.Stdout(), "✔️ Audio saved to %s\n", targetDir)
+	}
+	for _, entry := range entries {
+		fmt.Fprintf(ctx.Stdout(), "✔️ Audio saved to %s\n", entry.Path)
+	}
+	return nil
+}
+
+const (
+	youtubeManifestName = "manifest.json"
+	// youtubeManifestTemplate is yt-dlp's --print-to-file template for each
+	// finished file: path, title, and page URL, tab separated.
+	youtubeManifestTemplate = "after_move:%(filepath)s\t%(title)s\t%(webpage_url)s"
+)
+
+// youtubeDownload is one entry in ~/.flow/youtube-sound/manifest.json.
+type youtubeDownload struct {
+	URL          string    `json:"url"`
+	Title        string    `json:"title"`
+	Path         string    `json:"path"`
+	DownloadedAt time.Time `json:"downloadedAt"`
+}
+
+// parseYoutubeDownloads reads the lines youtubeManifestTemplate produced.
+// requestedURL stands in when yt-dlp did not know the page URL.
+func parseYoutubeDownloads(output, requestedURL string, now time.Time) []youtubeDownload {
+	var entries []youtubeDownload
+	for _, line := range strings.Split(output, "\n") {
+		fields := strings.Split(strings.TrimRight(line, "\r"), "\t")
+		path := strings.TrimSpace(fields[0])
+		if path == "" {
+			continue
+		}
+		entry := youtubeDownload{URL: requestedURL, Path: path, DownloadedAt: now.UTC()}
+		if len(fields) > 1 {
+			entry.Title = fields[1]
+		}
+		if len(fields) > 2 && fields[2] != "" && fields[2] != "NA" {
+			entry.URL = fields[2]
+		}
+		entries = append(entries, entry)
+	}
+	return entries
+}
+
+func readYoutubeManifest(path string) ([]youtubeDownload, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	var entries []youtubeDownload
+	if err := json.Unmarshal(data, &entries); err != nil {
+		return nil, fmt.Errorf("parse %s: %w", path, err)
+	}
+	return entries, nil
+}
+
+func appendYoutubeManifest(path string, entries []youtubeDownload) error {
+	if len(entries) == 0 {
+		return nil
+	}
+	existing, err := readYoutubeManifest(path)
+	if err != nil {
+		return err
+	}
+	data, err := json.MarshalIndent(append(existing, entries...), "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, append(data, '\n'), 0o644)
+}
+
+func runYoutubeList(ctx *snap.Context) error {
+	asJSON := false
+	for i := 0; i < ctx.NArgs(); i++ {
+		arg := strings.TrimSpace(ctx.Arg(i))
+		switch arg {
+		case "":
+		case "--json":
+			asJSON = true
+		default:
+			fmt.Fprintf(ctx.Stderr(), "Usage: %s youtubeList [--json]\n", commandName)
+			return fmt.Errorf("unexpected argument %q", arg)
+		}
+	}
+
+	homeDir, err := os.UserHomeDir()
+	if err != nil {
+		return reportError(ctx, fmt.Errorf("determine home directory: %w", err))
+	}
+	path := filepath.Join(homeDir, ".flow", "youtube-sound", youtubeManifestName)
+	entries, err := readYoutubeManifest(path)
+	if err != nil {
+		return reportError(ctx, err)
+	}
+
+	if asJSON {
+		if entries == nil {
+			entries = []youtubeDownload{}
+		}
+		encoder := json.NewEncoder(ctx.Stdout())
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(entries)
+	}
+
+	if len(entries) == 0 {
+		fmt.Fprintf(ctx.Stdout(), "No downloads recorded in %s\n", path)
+		return nil
+	}
+	for i := len(entries) - 1; i >= 0; i-- {
+		entry := entries[i]
+		title := entry.Title
+		if title == "" {
+			title = filepath.Base(entry.Path)
+		}
+		fmt.Fprintf(ctx.Stdout(), "%s  %s\n", entry.DownloadedAt.Local().Format("2006-01-02 15:04"), title)
+		fmt.Fprintf(ctx.Stdout(), "                  %s\n", entry.Path)
+		fmt.Fprintf(ctx.Stdout(), "                  %s\n", entry.URL)
+	}
+	return nil
+}
+
+func containsCookiesArgument(args []string) bool {
+	for _, arg := range args {
+		if strings.HasPrefix(arg, "--cookies-from-browser") || strings.HasPrefix(arg, "--cookies") {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,101 @@
+package main
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestParseYoutubeSoundArgs(t *testing.T) {
+	opts, err := parseYoutubeSoundArgs([]string{"--embed-metadata", "--sponsorblock-remove", "https://youtu.be/x", "--audio-format", "m4a"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !opts.EmbedMetadata || opts.EmbedThumbnail {
+		t.Fatalf("unexpected embed flags: %+v", opts)
+	}
+	if opts.SponsorBlockRemove != "sponsor" {
+		t.Fatalf("expected default sponsor category, got %q", opts.SponsorBlockRemove)
+	}
+	if opts.URL != "https://youtu.be/x" || strings.Join(opts.Extra, " ") != "--audio-format m4a" {
+		t.Fatalf("unexpected url/extra: %q %q", opts.URL, opts.Extra)
+	}
+
+	opts, err = parseYoutubeSoundArgs([]string{"--sponsorblock-remove", "sponsor,intro", "--embed-thumbnail"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if opts.SponsorBlockRemove != "sponsor,intro" || !opts.EmbedThumbnail || opts.URL != "" {
+		t.Fatalf("unexpected options: %+v", opts)
+	}
+
+	opts, err = parseYoutubeSoundArgs([]string{"-o", "~/music", "https://youtu.be/x", "-o", "%(id)s.%(ext)s"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if opts.OutputDir != "~/music" || strings.Join(opts.Extra, " ") != "-o %(id)s.%(ext)s" {
+		t.Fatalf("expected -o before the URL to set the output dir and after it to reach yt-dlp: %+v", opts)
+	}
+	if _, err := parseYoutubeSoundArgs([]string{"--output-dir"}); err == nil {
+		t.Fatal("expected an error for --output-dir without a directory")
+	}
+
+	if _, err := parseYoutubeSoundArgs([]string{"--format", "best"}); err == nil {
+		t.Fatal("expected an error for a yt-dlp flag before the URL")
+	}
+}
+
+func TestYoutubeSoundArgs(t *testing.T) {
+	opts := youtubeSoundOptions{URL: "https://youtu.be/x", EmbedMetadata: true, EmbedThumbnail: true, SponsorBlockRemove: "sponsor"}
+	args, warnings := youtubeSoundArgs(opts, "out/%(title)s.%(ext)s", "safari")
+	got := strings.Join(args, " ")
+	want := "--extract-audio --audio-format mp3 --audio-quality 0 --no-playlist -o out/%(title)s.%(ext)s --embed-metadata --embed-thumbnail --sponsorblock-remove sponsor --cookies-from-browser safari https://youtu.be/x"
+	if got != want || len(warnings) != 0 {
+		t.Fatalf("unexpected args:\n got %s\nwant %s\nwarnings %q", got, want, warnings)
+	}
+
+	opts = youtubeSoundOptions{URL: "https://youtu.be/x", EmbedThumbnail: true, Extra: []string{"--audio-format", "wav", "--cookies", "c.txt"}}
+	args, warnings = youtubeSoundArgs(opts, "o", "safari")
+	if strings.Contains(strings.Join(args, " "), "--embed-thumbnail") || len(warnings) != 1 {
+		t.Fatalf("expected thumbnail to be dropped for wav, got %q warnings %q", args, warnings)
+	}
+	if strings.Contains(strings.Join(args, " "), "--cookies-from-browser") {
+		t.Fatalf("expected explicit --cookies to suppress the browser default, got %q", args)
+	}
+
+	_, warnings = youtubeSoundArgs(youtubeSoundOptions{EmbedThumbnail: true, Extra: []string{"--audio-format=m4a"}}, "o", "none")
+	if len(warnings) != 0 {
+		t.Fatalf("expected m4a to accept thumbnails, got %q", warnings)
+	}
+}
+
+func TestYoutubeManifest(t *testing.T) {
+	now := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	output := "/music/Song.mp3\tSong\thttps://www.youtube.com/watch?v=abc\n/music/Other.mp3\tOther\tNA\n\n"
+	entries := parseYoutubeDownloads(output, "https://youtu.be/abc", now)
+	if len(entries) != 2 {
+		t.Fatalf("expected 2 entries, got %+v", entries)
+	}
+	if entries[0].Title != "Song" || entries[0].URL != "https://www.youtube.com/watch?v=abc" || entries[0].Path != "/music/Song.mp3" {
+		t.Fatalf("unexpected first entry: %+v", entries[0])
+	}
+	if entries[1].URL != "https://youtu.be/abc" {
+		t.Fatalf("expected the requested URL as a fallback, got %q", entries[1].URL)
+	}
+
+	path := filepath.Join(t.TempDir(), "manifest.json")
+	if err := appendYoutubeManifest(path, entries[:1]); err != nil {
+		t.Fatal(err)
+	}
+	if err := appendYoutubeManifest(path, entries[1:]); err != nil {
+		t.Fatal(err)
+	}
+	got, err := readYoutubeManifest(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != 2 || got[1].Path != "/music/Other.mp3" || !got[0].DownloadedAt.Equal(now) {
+		t.Fatalf("unexpected manifest: %+v", got)
+	}
+}
@@ -0,0 +1,153 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+const defaultCacheTTL = 5 * time.Minute
+
+// prData is everything runDiffDirect prints for a PR. It is what gets cached
+// under ~/.cache/ghx, so repeated lookups skip the gh subprocesses.
+type prData struct {
+	FetchedAt   time.Time         `json:"fetchedAt"`
+	Info        *prInfoResponse   `json:"info"`
+	HasComments bool              `json:"hasComments"`
+	Comments    []commentResponse `json:"comments,omitempty"`
+	Reviews     []reviewResponse  `json:"reviews,omitempty"`
+	Diff        string            `json:"diff"`
+}
+
+type cacheOptions struct {
+	Read  bool
+	Write bool
+	TTL   time.Duration
+}
+
+func defaultCacheOptions() (cacheOptions, error) {
+	opts := cacheOptions{Read: true, Write: true, TTL: defaultCacheTTL}
+	if raw := strings.TrimSpace(os.Getenv("GHX_CACHE_TTL")); raw != "" {
+		ttl, err := time.ParseDuration(raw)
+		if err != nil || ttl < 0 {
+			return opts, fmt.Errorf("invalid GHX_CACHE_TTL %q: expected a duration such as 10m", raw)
+		}
+		opts.TTL = ttl
+	}
+	return opts, nil
+}
+
+func prCachePath(owner, repo string, number int) (string, error) {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", fmt.Errorf("get home dir: %w", err)
+	}
+	name := fmt.Sprintf("%s-%s-%d.json", owner, repo, number)
+	return filepath.Join(home, ".cache", commandName, name), nil
+}
+
+// readPRCache returns the cached data when it is younger than ttl and holds
+// everything the caller needs. Any problem reading it counts as a miss.
+func readPRCache(path string, ttl time.Duration, needComments bool) (*prData, bool) {
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		return nil, false
+	}
+
+	var data prData
+	if err := json.Unmarshal(raw, &data); err != nil || data.Info == nil {
+		return nil, false
+	}
+	if time.Since(data.FetchedAt) > ttl {
+		return nil, false
+	}
+	if needComments && !data.HasComments {
+		return nil, false
+	}
+	return &data, true
+}
+
+func writePRCache(path string, data *prData) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return fmt.Errorf("create cache dir: %w", err)
+	}
+
+	raw, err := json.Marshal(data)
+	if err != nil {
+		return fmt.Errorf("encode cache: %w", err)
+	}
+
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, raw, 0o644); err != nil {
+		return fmt.Errorf("write cache: %w", err)
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		os.Remove(tmp)
+		return fmt.Errorf("write cache: %w", err)
+	}
+	return nil
+}
+
+func fetchPRData(repoFull, prRef string, includeComments bool) (*prData, error) {
+	info, err := getPRInfo(repoFull, prRef)
+	if err != nil {
+		return nil, err
+	}
+
+	data := &prData{FetchedAt: time.Now(), Info: info}
+	if includeComments {
+		// Comments and reviews are best effort, as before caching existed.
+		// HasComments stays false when either call failed, so a passing gh
+		// error is not cached as a PR without comments.
+		comments, commentsErr := getPRComments(repoFull, prRef)
+		if commentsErr == nil {
+			data.Comments = comments
+		}
+		reviews, reviewsErr := getPRReviews(repoFull, prRef)
+		if reviewsErr == nil {
+			data.Reviews = reviews
+		}
+		data.HasComments = commentsErr == nil && reviewsErr == nil
+	}
+
+	diff, err := getPRDiff(repoFull, prRef)
+	if err != nil {
+		return nil, err
+	}
+	data.Diff = string(diff)
+	return data, nil
+}
+
+// loadPRData serves a PR from the cache when allowed, otherwise fetches it
+// with gh and refreshes the cache. Cache write failures only warn.
+func loadPRData(owner, repo string, number int, includeComments bool, opts cacheOptions) (*prData, error) {
+	repoFull := fmt.Sprintf("%s/%s", owner, repo)
+	prRef := fmt.Sprintf("%d", number)
+
+	path, pathErr := prCachePath(owner, repo, number)
+	if pathErr == nil && opts.Read && opts.TTL > 0 {
+		if data, ok := readPRCache(path, opts.TTL, includeComments); ok {
+			return data, nil
+		}
+	}
+
+	if _, err := exec.LookPath("gh"); err != nil {
+		return nil, fmt.Errorf("gh CLI not found in PATH: %w", err)
+	}
+
+	data, err := fetchPRData(repoFull, prRef, includeComments)
+	if err != nil {
+		return nil, err
+	}
+
+	if pathErr == nil && opts.Write {
+		if err := writePRCache(path, data); err != nil {
+			fmt.Fprintf(os.Stderr, "warning: %v\n", err)
+		}
+	}
+	return data, nil
+}
@@ -0,0 +1,166 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"strings"
+)
+
+const (
+	ansiReset   = "\x1b[0m"
+	ansiBold    = "\x1b[1m"
+	ansiRed     = "\x1b[31m"
+	ansiGreen   = "\x1b[32m"
+	ansiYellow  = "\x1b[33m"
+	ansiMagenta = "\x1b[35m"
+	ansiCyan    = "\x1b[36m"
+)
+
+// colorMode is the value of --color. Output is plain unless asked for, and
+// "auto" (a bare --color) still stays plain when stdout is not a terminal.
+type colorMode int
+
+const (
+	colorNever colorMode = iota
+	colorAuto
+	colorAlways
+)
+
+// parseColorFlag handles --color and --color=auto|always|never. ok is false
+// when arg is some other flag.
+func parseColorFlag(arg string) (mode colorMode, ok bool, err error) {
+	if arg == "--color" {
+		return colorAuto, true, nil
+	}
+	value, ok := strings.CutPrefix(arg, "--color=")
+	if !ok {
+		return colorNever, false, nil
+	}
+	switch strings.ToLower(strings.TrimSpace(value)) {
+	case "auto", "":
+		return colorAuto, true, nil
+	case "always":
+		return colorAlways, true, nil
+	case "never":
+		return colorNever, true, nil
+	}
+	return colorNever, true, fmt.Errorf("invalid --color %q: expected auto, always, or never", value)
+}
+
+func (m colorMode) enabled(f *os.File) bool {
+	switch m {
+	case colorAlways:
+		return true
+	case colorAuto:
+		return isTerminal(f)
+	}
+	return false
+}
+
+// isTerminal reports whether f is a character device, the same check isatty
+// makes, without pulling in a dependency for it.
+func isTerminal(f *os.File) bool {
+	info, err := f.Stat()
+	return err == nil && info.Mode()&os.ModeCharDevice != 0
+}
+
+// colorizeMarkdown styles ghx's markdown: bold headers, colored PR and review
+// states, and colorizeDiff inside ```diff fences. Everything else, including
+// the text of descriptions and comments, passes through untouched.
+func colorizeMarkdown(md []byte) []byte {
+	var out bytes.Buffer
+	inDiff := false
+	var diff bytes.Buffer
+	for _, line := range bytes.SplitAfter(md, []byte("\n")) {
+		trimmed := bytes.TrimRight(line, "\n")
+		switch {
+		case inDiff && bytes.Equal(trimmed, []byte("```")):
+			out.Write(colorizeDiff(diff.Bytes()))
+			diff.Reset()
+			out.Write(line)
+			inDiff = false
+		case inDiff:
+			diff.Write(line)
+		case bytes.Equal(trimmed, []byte("```diff")):
+			out.Write(line)
+			inDiff = true
+		case bytes.HasPrefix(trimmed, []byte("#")):
+			out.WriteString(ansiBold + colorStates(string(trimmed)) + ansiReset)
+			out.Write(line[len(trimmed):])
+		case bytes.HasPrefix(trimmed, []byte("**State:** ")):
+			out.WriteString(colorStates(string(trimmed)))
+			out.Write(line[len(trimmed):])
+		default:
+			out.Write(line)
+		}
+	}
+	// An unterminated fence still gets its diff colored.
+	out.Write(colorizeDiff(diff.Bytes()))
+	return out.Bytes()
+}
+
+// colorizeDiff colors a unified diff: additions green, removals red, hunk
+// headers cyan, and file headers bold. Inside a hunk, "--- x" is a removed
+// line, not a file header.
+func colorizeDiff(diff []byte) []byte {
+	var out bytes.Buffer
+	inHunk := false
+	for _, line := range bytes.SplitAfter(diff, []byte("\n")) {
+		if len(line) == 0 {
+			continue
+		}
+		body := bytes.TrimRight(line, "\n")
+		color := ""
+		switch {
+		case bytes.HasPrefix(body, []byte("diff --git ")):
+			inHunk = false
+			color = ansiBold
+		case bytes.HasPrefix(body, []byte("@@")):
+			inHunk = true
+			color = ansiCyan
+		case !inHunk:
+			if len(body) > 0 {
+				color = ansiBold
+			}
+		case bytes.HasPrefix(body, []byte("+")):
+			color = ansiGreen
+		case bytes.HasPrefix(body, []byte("-")):
+			color = ansiRed
+		}
+		if color == "" {
+			out.Write(line)
+			continue
+		}
+		out.WriteString(color)
+		out.Write(body)
+		out.WriteString(ansiReset)
+		out.Write(line[len(body):])
+	}
+	return out.Bytes()
+}
+
+var stateColors = map[string]string{
+	"OPEN":              ansiGreen,
+	"APPROVED":          ansiGreen,
+	"MERGED":            ansiMagenta,
+	"CLOSED":            ansiRed,
+	"CHANGES_REQUESTED": ansiRed,
+	"COMMENTED":         ansiYellow,
+	"PENDING":           ansiYellow,
+	"DISMISSED":         ansiYellow,
+}
+
+// colorStates colors the PR, issue, and review state words in line, which
+// ghx only prints as "**State:** X" or "(X)" at the end of a review header.
+func colorStates(line string) string {
+	for state, color := range stateColors {
+		if rest, ok := strings.CutSuffix(line, "**State:** "+state); ok {
+			return rest + "**State:** " + color + state + ansiReset
+		}
+		if rest, ok := strings.CutSuffix(line, "("+state+")"); ok {
+			return rest + "(" + color + state + ansiReset + ")"
+		}
+	}
+	return line
+}
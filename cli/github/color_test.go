@@ -0,0 +1,75 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestColorizeDiff(t *testing.T) {
+	diff := strings.Join([]string{
+		"diff --git a/main.go b/main.go",
+		"index 1111111..2222222 100644",
+		"--- a/main.go",
+		"+++ b/main.go",
+		"@@ -1,3 +1,3 @@",
+		" package main",
+		"--- old separator",
+		"+added",
+		"",
+	}, "\n")
+
+	got := string(colorizeDiff([]byte(diff)))
+	want := strings.Join([]string{
+		ansiBold + "diff --git a/main.go b/main.go" + ansiReset,
+		ansiBold + "index 1111111..2222222 100644" + ansiReset,
+		ansiBold + "--- a/main.go" + ansiReset,
+		ansiBold + "+++ b/main.go" + ansiReset,
+		ansiCyan + "@@ -1,3 +1,3 @@" + ansiReset,
+		" package main",
+		ansiRed + "--- old separator" + ansiReset,
+		ansiGreen + "+added" + ansiReset,
+		"",
+	}, "\n")
+	if got != want {
+		t.Fatalf("colorizeDiff mismatch:\ngot  %q\nwant %q", got, want)
+	}
+}
+
+func TestColorizeMarkdownLeavesProseAlone(t *testing.T) {
+	md := "## Title\n\n**State:** MERGED\n\n- a list item\n+ not a diff\n\n### Review 1 by x (APPROVED)\n\n```diff\n@@ -1 +1 @@\n-a\n+b\n```\n"
+
+	got := string(colorizeMarkdown([]byte(md)))
+	for _, want := range []string{
+		ansiBold + "## Title" + ansiReset + "\n",
+		"**State:** " + ansiMagenta + "MERGED" + ansiReset + "\n",
+		"\n- a list item\n+ not a diff\n",
+		"(" + ansiGreen + "APPROVED" + ansiReset + ")",
+		ansiRed + "-a" + ansiReset + "\n" + ansiGreen + "+b" + ansiReset + "\n```\n",
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("missing %q in:\n%q", want, got)
+		}
+	}
+}
+
+func TestParseColorFlag(t *testing.T) {
+	cases := []struct {
+		arg  string
+		mode colorMode
+		ok   bool
+	}{
+		{"--color", colorAuto, true},
+		{"--color=always", colorAlways, true},
+		{"--color=never", colorNever, true},
+		{"--no-comments", colorNever, false},
+	}
+	for _, tc := range cases {
+		mode, ok, err := parseColorFlag(tc.arg)
+		if err != nil || mode != tc.mode || ok != tc.ok {
+			t.Errorf("parseColorFlag(%q) = %v, %v, %v", tc.arg, mode, ok, err)
+		}
+	}
+	if _, _, err := parseColorFlag("--color=sometimes"); err == nil {
+		t.Errorf("expected an error for an unknown mode")
+	}
+}
@@ -0,0 +1,133 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+)
+
+// fileDiff is one file's part of a unified diff, from its "diff --git" line
+// up to the next one.
+type fileDiff struct {
+	OldPath   string
+	NewPath   string
+	Status    string // modified, added, deleted, or renamed
+	Binary    bool
+	Additions int
+	Deletions int
+	Text      string
+}
+
+// Path is the file's name after the change, or before it for a deletion.
+func (f fileDiff) Path() string {
+	if f.Status == "deleted" || f.NewPath == "" {
+		return f.OldPath
+	}
+	return f.NewPath
+}
+
+// Summary is the one-line description under a file's heading.
+func (f fileDiff) Summary() string {
+	change := fmt.Sprintf("+%d -%d", f.Additions, f.Deletions)
+	if f.Binary {
+		change = "binary"
+	}
+	if f.Status == "renamed" {
+		return fmt.Sprintf("renamed from %s, %s", f.OldPath, change)
+	}
+	return fmt.Sprintf("%s, %s", f.Status, change)
+}
+
+// splitDiff cuts a unified diff from git or gh at its "diff --git"
+// boundaries. Text before the first boundary is dropped.
+func splitDiff(diff string) []fileDiff {
+	var files []fileDiff
+	var current *fileDiff
+	var text strings.Builder
+	inHunk := false
+
+	flush := func() {
+		if current != nil {
+			current.Text = text.String()
+			files = append(files, *current)
+		}
+		text.Reset()
+	}
+
+	for _, line := range strings.SplitAfter(diff, "\n") {
+		body := strings.TrimRight(line, "\n")
+		if strings.HasPrefix(body, "diff --git ") {
+			flush()
+			oldPath, newPath := parseDiffGitLine(body)
+			current = &fileDiff{OldPath: oldPath, NewPath: newPath, Status: "modified"}
+			inHunk = false
+		}
+		if current == nil {
+			continue
+		}
+		text.WriteString(line)
+
+		switch {
+		case strings.HasPrefix(body, "@@"):
+			inHunk = true
+		case inHunk && strings.HasPrefix(body, "+"):
+			current.Additions++
+		case inHunk && strings.HasPrefix(body, "-"):
+			current.Deletions++
+		case inHunk:
+		case strings.HasPrefix(body, "new file mode"):
+			current.Status = "added"
+		case strings.HasPrefix(body, "deleted file mode"):
+			current.Status = "deleted"
+		case strings.HasPrefix(body, "rename from "):
+			current.Status = "renamed"
+			current.OldPath = strings.TrimPrefix(body, "rename from ")
+		case strings.HasPrefix(body, "rename to "):
+			current.NewPath = strings.TrimPrefix(body, "rename to ")
+		case strings.HasPrefix(body, "Binary files "), body == "GIT binary patch":
+			current.Binary = true
+		case strings.HasPrefix(body, "--- a/"):
+			current.OldPath = strings.TrimPrefix(body, "--- a/")
+		case strings.HasPrefix(body, "+++ b/"):
+			current.NewPath = strings.TrimPrefix(body, "+++ b/")
+		}
+	}
+	flush()
+	return files
+}
+
+// parseDiffGitLine reads the paths from "diff --git a/x b/y". Paths with
+// spaces are ambiguous here; the ---/+++ or rename lines that follow win.
+func parseDiffGitLine(line string) (string, string) {
+	rest := strings.TrimPrefix(line, "diff --git ")
+	if i := strings.Index(rest, " b/"); i >= 0 && strings.HasPrefix(rest, "a/") {
+		return rest[2:i], rest[i+3:]
+	}
+	return rest, rest
+}
+
+// writePerFileDiff renders diff as one markdown section per file, each with
+// a summary line and its own fenced block.
+func writePerFileDiff(out *bytes.Buffer, diff string) {
+	files := splitDiff(diff)
+	if len(files) == 0 {
+		out.WriteString("```diff\n")
+		out.WriteString(diff)
+		out.WriteString("```\n")
+		return
+	}
+
+	for i, file := range files {
+		if i > 0 {
+			out.WriteString("\n")
+		}
+		out.WriteString(fmt.Sprintf("### %s\n\n", file.Path()))
+		out.WriteString(fmt.Sprintf("%s\n\n", file.Summary()))
+		out.WriteString("```diff\n")
+		out.WriteString(file.Text)
+		if !strings.HasSuffix(file.Text, "\n") {
+			out.WriteString("\n")
+		}
+		out.WriteString("```\n")
+	}
+}
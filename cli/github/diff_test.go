@@ -0,0 +1,76 @@
+package main
+
+import (
+	"bytes"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+const sampleDiff = `diff --git a/main.go b/main.go
+index 1111111..2222222 100644
+--- a/main.go
++++ b/main.go
+@@ -1,3 +1,4 @@
+ package main
+--- not a header
++import "fmt"
++
+diff --git a/old name.txt b/new name.txt
+similarity index 90%
+rename from old name.txt
+rename to new name.txt
+diff --git a/gone.go b/gone.go
+deleted file mode 100644
+index 3333333..0000000
+--- a/gone.go
++++ /dev/null
+@@ -1 +0,0 @@
+-package gone
+diff --git a/logo.png b/logo.png
+new file mode 100644
+index 0000000..4444444
+Binary files /dev/null and b/logo.png differ
+`
+
+func TestSplitDiff(t *testing.T) {
+	files := splitDiff(sampleDiff)
+
+	type summary struct {
+		Path, Summary string
+	}
+	var got []summary
+	for _, f := range files {
+		got = append(got, summary{f.Path(), f.Summary()})
+	}
+	want := []summary{
+		{"main.go", "modified, +2 -1"},
+		{"new name.txt", "renamed from old name.txt, +0 -0"},
+		{"gone.go", "deleted, +0 -1"},
+		{"logo.png", "added, binary"},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("splitDiff summaries = %+v, want %+v", got, want)
+	}
+
+	var joined strings.Builder
+	for _, f := range files {
+		joined.WriteString(f.Text)
+	}
+	if joined.String() != sampleDiff {
+		t.Fatalf("file texts should add back up to the whole diff")
+	}
+}
+
+func TestWritePerFileDiff(t *testing.T) {
+	var out bytes.Buffer
+	writePerFileDiff(&out, sampleDiff)
+
+	got := out.String()
+	if n := strings.Count(got, "```diff\n"); n != 4 {
+		t.Fatalf("expected 4 fenced blocks, got %d:\n%s", n, got)
+	}
+	if !strings.HasPrefix(got, "### main.go\n\nmodified, +2 -1\n\n```diff\ndiff --git a/main.go b/main.go\n") {
+		t.Fatalf("unexpected first section:\n%s", got)
+	}
+}
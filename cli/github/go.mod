@@ -3,3 +3,7 @@ module go/cli/github
 go 1.24.0
 
 require github.com/dzonerzy/go-snap v0.2.6
+
+require lang v0.0.0
+
+replace lang => ../..
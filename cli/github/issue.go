@@ -0,0 +1,117 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os/exec"
+	"strings"
+
+	"github.com/dzonerzy/go-snap/snap"
+)
+
+type issueResponse struct {
+	Title    string            `json:"title"`
+	Body     string            `json:"body"`
+	Author   authorResponse    `json:"author"`
+	State    string            `json:"state"`
+	Labels   []labelResponse   `json:"labels"`
+	Comments []commentResponse `json:"comments"`
+}
+
+type labelResponse struct {
+	Name string `json:"name"`
+}
+
+func runIssue(ctx *snap.Context) error {
+	if ctx.NArgs() < 1 {
+		fmt.Fprintf(ctx.Stderr(), "Usage: %s issue <issue-url|owner/repo#N> [--no-comments] [--color[=auto|always|never]]\n", commandName)
+		return fmt.Errorf("expected at least 1 argument")
+	}
+	return runIssueDirect(ctx.Arg(0), ctx.Args()[1:])
+}
+
+func runIssueDirect(ref string, extraArgs []string) error {
+	includeComments := true
+	color := colorNever
+	for _, arg := range extraArgs {
+		arg = strings.TrimSpace(arg)
+		if mode, ok, err := parseColorFlag(arg); ok {
+			if err != nil {
+				return err
+			}
+			color = mode
+			continue
+		}
+		if arg == "--no-comments" {
+			includeComments = false
+		}
+	}
+
+	owner, repo, number, err := parseIssueRef(ref)
+	if err != nil {
+		return err
+	}
+
+	if _, err := exec.LookPath("gh"); err != nil {
+		return fmt.Errorf("gh CLI not found in PATH: %w", err)
+	}
+
+	issue, err := getIssue(fmt.Sprintf("%s/%s", owner, repo), fmt.Sprintf("%d", number), includeComments)
+	if err != nil {
+		return err
+	}
+
+	var out bytes.Buffer
+
+	out.WriteString(fmt.Sprintf("# Issue: %s/%s#%d\n\n", owner, repo, number))
+
+	out.WriteString(fmt.Sprintf("## %s\n\n", issue.Title))
+	out.WriteString(fmt.Sprintf("**Author:** %s\n", issue.Author.Login))
+	out.WriteString(fmt.Sprintf("**State:** %s\n", issue.State))
+	if len(issue.Labels) > 0 {
+		names := make([]string, len(issue.Labels))
+		for i, label := range issue.Labels {
+			names[i] = label.Name
+		}
+		out.WriteString(fmt.Sprintf("**Labels:** %s\n", strings.Join(names, ", ")))
+	}
+	out.WriteString("\n")
+
+	if issue.Body != "" {
+		out.WriteString("## Description\n\n")
+		out.WriteString(issue.Body)
+		out.WriteString("\n\n")
+	}
+
+	if includeComments && len(issue.Comments) > 0 {
+		out.WriteString("## Comments\n\n")
+		for i, c := range issue.Comments {
+			out.WriteString(fmt.Sprintf("### Comment %d by %s\n\n", i+1, c.Author.Login))
+			out.WriteString(c.Body)
+			out.WriteString("\n\n")
+		}
+	}
+
+	printMarkdown(out.Bytes(), color)
+	return nil
+}
+
+func getIssue(repo, issueRef string, includeComments bool) (*issueResponse, error) {
+	fields := "title,body,author,state,labels"
+	if includeComments {
+		fields += ",comments"
+	}
+
+	cmd := command("gh", "issue", "view", issueRef, "--repo", repo, "--json", fields)
+	output, err := cmd.Output()
+	if err != nil {
+		return nil, fmt.Errorf("gh issue view: %w", err)
+	}
+
+	var issue issueResponse
+	if err := json.Unmarshal(output, &issue); err != nil {
+		return nil, fmt.Errorf("parse issue: %w", err)
+	}
+	return &issue, nil
+}
@@ -2,13 +2,18 @@ package main
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"fmt"
-	"net/url"
 	"os"
-	"os/exec"
-	"strconv"
+	"runtime"
 	"strings"
+	"time"
+
+	"lang/buildinfo"
+	"lang/cmdlog"
+	"lang/exitcode"
+	"lang/ghref"
 
 	"github.com/dzonerzy/go-snap/snap"
 )
@@ -19,23 +24,45 @@ const (
 )
 
 func main() {
-	// Handle default case: if first arg looks like a PR ref, run diff
+	os.Args = append(os.Args[:1], cmdlog.EnableFromArgs(os.Args[1:])...)
+
+	// Handle default case: an issue URL prints the issue, any other PR-like
+	// ref runs diff
+	if len(os.Args) > 1 && looksLikeIssueURL(os.Args[1]) {
+		if err := runIssueDirect(os.Args[1], os.Args[2:]); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(exitcode.For(err))
+		}
+		return
+	}
 	if len(os.Args) > 1 && looksLikePRRef(os.Args[1]) {
 		if err := runDiffDirect(os.Args[1], os.Args[2:]); err != nil {
 			fmt.Fprintln(os.Stderr, err)
-			os.Exit(1)
+			os.Exit(exitcode.For(err))
 		}
 		return
 	}
 
 	app := snap.New(commandName, "GitHub CLI for PR operations").
-		Version(version)
+		Version(version).
+		Use(exitcode.Middleware)
 
 	app.Command("diff", "Get full diff of a GitHub PR").
 		Action(runDiff)
 
+	app.Command("issue", "Get an issue with its comments").
+		Action(runIssue)
+
+	pr := app.Command("pr", "Pull request shortcuts")
+	pr.Command("open", "Open a PR in the browser").
+		Action(runPROpen)
+
 	app.Command("version", "Show version").
+		BoolFlag("json", "Print version and build metadata as JSON").Back().
 		Action(func(ctx *snap.Context) error {
+			if asJSON, _ := ctx.Bool("json"); asJSON {
+				return buildinfo.New(commandName, version, buildTime, gitCommit).WriteJSON(ctx.Stdout())
+			}
 			fmt.Fprintln(ctx.Stdout(), version)
 			return nil
 		})
@@ -56,25 +83,84 @@ func showHelpDirect() {
 	fmt.Println("Usage:")
 	fmt.Printf("  %s <pr-url>                    Get full diff of a PR\n", commandName)
 	fmt.Printf("  %s <pr-url> --no-comments      Get diff without comments/reviews\n", commandName)
+	fmt.Printf("  %s <pr-url> --refresh          Ignore the cached copy and fetch again\n", commandName)
+	fmt.Printf("  %s <pr-url> --no-cache         Neither read nor write the cache\n", commandName)
+	fmt.Printf("  %s <pr-url> --cache-ttl 10m    Accept cached data up to this age\n", commandName)
+	fmt.Printf("  %s <pr-url> --per-file         Give each file its own section and diff block\n", commandName)
+	fmt.Printf("  %s <pr-url> --color            Color headers, states, and the diff on a terminal\n", commandName)
 	fmt.Printf("  %s diff <pr-url>               Get full diff of a PR\n", commandName)
+	fmt.Printf("  %s <issue-url>                 Get an issue with its comments\n", commandName)
+	fmt.Printf("  %s issue <issue-ref>           Same, also for owner/repo#123\n", commandName)
+	fmt.Printf("  %s pr open <pr-ref>            Open the PR in the browser\n", commandName)
 	fmt.Printf("  %s deploy                      Build and install to ~/bin\n", commandName)
-	fmt.Printf("  %s version                     Show version\n", commandName)
+	fmt.Printf("  %s version [--json]            Show version (JSON includes build metadata)\n", commandName)
 	fmt.Println()
-	fmt.Println("PR reference formats:")
+	fmt.Println("Reference formats:")
 	fmt.Println("  https://github.com/owner/repo/pull/123")
+	fmt.Println("  https://github.com/owner/repo/issues/123")
 	fmt.Println("  owner/repo#123")
+	fmt.Println()
+	fmt.Printf("PR data is cached in ~/.cache/%s for %s (override with GHX_CACHE_TTL).\n", commandName, defaultCacheTTL)
+	fmt.Printf("Pass --verbose before anything else, or set %s=1, to log each gh/git call.\n", cmdlog.DebugEnv)
 }
 
+// command is exec.Command, logged in verbose mode.
+func command(name string, args ...string) *cmdlog.Cmd {
+	return cmdlog.Command(context.Background(), name, args...)
+}
+
+// Set at build time with -ldflags "-X main.buildTime=<RFC3339> -X main.gitCommit=<sha>".
+var (
+	buildTime = buildinfo.Unknown
+	gitCommit = buildinfo.Unknown
+)
+
 func runDiffDirect(ref string, extraArgs []string) error {
 	ref = strings.TrimSpace(ref)
 	if ref == "" {
 		return fmt.Errorf("PR reference cannot be empty")
 	}
 
+	cache, err := defaultCacheOptions()
+	if err != nil {
+		return err
+	}
+
 	includeComments := true
-	for _, arg := range extraArgs {
-		if strings.TrimSpace(arg) == "--no-comments" {
+	perFile := false
+	color := colorNever
+	for i := 0; i < len(extraArgs); i++ {
+		arg := strings.TrimSpace(extraArgs[i])
+		if mode, ok, err := parseColorFlag(arg); ok {
+			if err != nil {
+				return err
+			}
+			color = mode
+			continue
+		}
+		switch {
+		case arg == "--no-comments":
 			includeComments = false
+		case arg == "--per-file":
+			perFile = true
+		case arg == "--no-cache":
+			cache.Read, cache.Write = false, false
+		case arg == "--refresh":
+			cache.Read = false
+		case arg == "--cache-ttl" || strings.HasPrefix(arg, "--cache-ttl="):
+			value, ok := strings.CutPrefix(arg, "--cache-ttl=")
+			if !ok {
+				i++
+				if i >= len(extraArgs) {
+					return fmt.Errorf("--cache-ttl requires a duration")
+				}
+				value = extraArgs[i]
+			}
+			ttl, err := time.ParseDuration(strings.TrimSpace(value))
+			if err != nil || ttl < 0 {
+				return fmt.Errorf("invalid --cache-ttl %q: expected a duration such as 10m", value)
+			}
+			cache.TTL = ttl
 		}
 	}
 
@@ -83,21 +169,15 @@ func runDiffDirect(ref string, extraArgs []string) error {
 		return err
 	}
 
-	if _, err := exec.LookPath("gh"); err != nil {
-		return fmt.Errorf("gh CLI not found in PATH: %w", err)
+	data, err := loadPRData(owner, repo, prNumber, includeComments, cache)
+	if err != nil {
+		return err
 	}
-
-	repoFull := fmt.Sprintf("%s/%s", owner, repo)
-	prRef := fmt.Sprintf("%d", prNumber)
+	prInfo := data.Info
 
 	var out bytes.Buffer
 
-	out.WriteString(fmt.Sprintf("# Pull Request: %s#%d\n\n", repoFull, prNumber))
-
-	prInfo, err := getPRInfo(repoFull, prRef)
-	if err != nil {
-		return err
-	}
+	out.WriteString(fmt.Sprintf("# Pull Request: %s/%s#%d\n\n", owner, repo, prNumber))
 
 	out.WriteString(fmt.Sprintf("## %s\n\n", prInfo.Title))
 	out.WriteString(fmt.Sprintf("**Author:** %s\n", prInfo.Author.Login))
@@ -112,18 +192,18 @@ func runDiffDirect(ref string, extraArgs []string) error {
 	}
 
 	if includeComments {
-		if comments, err := getPRComments(repoFull, prRef); err == nil && len(comments) > 0 {
+		if len(data.Comments) > 0 {
 			out.WriteString("## Comments\n\n")
-			for i, c := range comments {
+			for i, c := range data.Comments {
 				out.WriteString(fmt.Sprintf("### Comment %d by %s\n\n", i+1, c.Author.Login))
 				out.WriteString(c.Body)
 				out.WriteString("\n\n")
 			}
 		}
 
-		if reviews, err := getPRReviews(repoFull, prRef); err == nil && len(reviews) > 0 {
+		if len(data.Reviews) > 0 {
 			out.WriteString("## Reviews\n\n")
-			for i, r := range reviews {
+			for i, r := range data.Reviews {
 				if r.Body == "" {
 					continue
 				}
@@ -135,20 +215,26 @@ func runDiffDirect(ref string, extraArgs []string) error {
 	}
 
 	out.WriteString("## Diff\n\n")
-	out.WriteString("```diff\n")
-
-	diffOutput, err := getPRDiff(repoFull, prRef)
-	if err != nil {
-		return err
+	if perFile {
+		writePerFileDiff(&out, data.Diff)
+	} else {
+		out.WriteString("```diff\n")
+		out.WriteString(data.Diff)
+		out.WriteString("```\n")
 	}
 
-	out.Write(diffOutput)
-	out.WriteString("```\n")
-
-	fmt.Print(out.String())
+	printMarkdown(out.Bytes(), color)
 	return nil
 }
 
+// printMarkdown writes md to stdout, styled when color is enabled for it.
+func printMarkdown(md []byte, color colorMode) {
+	if color.enabled(os.Stdout) {
+		md = colorizeMarkdown(md)
+	}
+	os.Stdout.Write(md)
+}
+
 func looksLikePRRef(s string) bool {
 	if strings.HasPrefix(s, "http://") || strings.HasPrefix(s, "https://") {
 		return strings.Contains(s, "/pull/")
@@ -156,14 +242,60 @@ func looksLikePRRef(s string) bool {
 	return strings.Contains(s, "#")
 }
 
+func looksLikeIssueURL(s string) bool {
+	if strings.HasPrefix(s, "http://") || strings.HasPrefix(s, "https://") {
+		return strings.Contains(s, "/issues/")
+	}
+	return false
+}
+
 func runDiff(ctx *snap.Context) error {
 	if ctx.NArgs() < 1 {
-		fmt.Fprintf(ctx.Stderr(), "Usage: %s diff <pr-url> [--no-comments]\n", commandName)
+		fmt.Fprintf(ctx.Stderr(), "Usage: %s diff <pr-url> [--no-comments] [--refresh|--no-cache] [--cache-ttl <dur>] [--per-file] [--color[=auto|always|never]]\n", commandName)
 		return fmt.Errorf("expected at least 1 argument")
 	}
 	return runDiffDirect(ctx.Arg(0), ctx.Args()[1:])
 }
 
+func runPROpen(ctx *snap.Context) error {
+	if ctx.NArgs() != 1 {
+		fmt.Fprintf(ctx.Stderr(), "Usage: %s pr open <pr-url|owner/repo#N>\n", commandName)
+		return fmt.Errorf("expected 1 argument, got %d", ctx.NArgs())
+	}
+
+	owner, repo, number, err := parsePRRef(ctx.Arg(0))
+	if err != nil {
+		return err
+	}
+
+	prURL := fmt.Sprintf("https://github.com/%s/%s/pull/%d", owner, repo, number)
+	if err := openURL(prURL); err != nil {
+		return err
+	}
+	fmt.Fprintf(ctx.Stdout(), "Opened: %s\n", prURL)
+	return nil
+}
+
+// openURL hands a URL to the platform's default browser.
+func openURL(target string) error {
+	var cmd *cmdlog.Cmd
+	switch runtime.GOOS {
+	case "darwin":
+		cmd = command("open", target)
+	case "windows":
+		cmd = command("rundll32", "url.dll,FileProtocolHandler", target)
+	default:
+		cmd = command("xdg-open", target)
+	}
+	if output, err := cmd.CombinedOutput(); err != nil {
+		if msg := strings.TrimSpace(string(output)); msg != "" {
+			return fmt.Errorf("open %s: %s", target, msg)
+		}
+		return fmt.Errorf("open %s: %w", target, err)
+	}
+	return nil
+}
+
 func runDeploy(ctx *snap.Context) error {
 	home, err := os.UserHomeDir()
 	if err != nil {
@@ -171,7 +303,7 @@ func runDeploy(ctx *snap.Context) error {
 	}
 	dest := home + "/bin/" + commandName
 
-	cmd := exec.Command("go", "build", "-o", dest, ".")
+	cmd := command("go", "build", "-o", dest, ".")
 	cmd.Stdout = ctx.Stdout()
 	cmd.Stderr = ctx.Stderr()
 	if err := cmd.Run(); err != nil {
@@ -210,7 +342,7 @@ type reviewResponse struct {
 }
 
 func getPRInfo(repo, prRef string) (*prInfoResponse, error) {
-	cmd := exec.Command("gh", "pr", "view", prRef, "--repo", repo, "--json",
+	cmd := command("gh", "pr", "view", prRef, "--repo", repo, "--json",
 		"title,body,author,state,baseRefName,headRefName,additions,deletions,changedFiles")
 	output, err := cmd.Output()
 	if err != nil {
@@ -225,7 +357,7 @@ func getPRInfo(repo, prRef string) (*prInfoResponse, error) {
 }
 
 func getPRComments(repo, prRef string) ([]commentResponse, error) {
-	cmd := exec.Command("gh", "pr", "view", prRef, "--repo", repo, "--json", "comments")
+	cmd := command("gh", "pr", "view", prRef, "--repo", repo, "--json", "comments")
 	output, err := cmd.Output()
 	if err != nil {
 		return nil, err
@@ -241,7 +373,7 @@ func getPRComments(repo, prRef string) ([]commentResponse, error) {
 }
 
 func getPRReviews(repo, prRef string) ([]reviewResponse, error) {
-	cmd := exec.Command("gh", "pr", "view", prRef, "--repo", repo, "--json", "reviews")
+	cmd := command("gh", "pr", "view", prRef, "--repo", repo, "--json", "reviews")
 	output, err := cmd.Output()
 	if err != nil {
 		return nil, err
@@ -257,7 +389,7 @@ func getPRReviews(repo, prRef string) ([]reviewResponse, error) {
 }
 
 func getPRDiff(repo, prRef string) ([]byte, error) {
-	cmd := exec.Command("gh", "pr", "diff", prRef, "--repo", repo)
+	cmd := command("gh", "pr", "diff", prRef, "--repo", repo)
 	output, err := cmd.Output()
 	if err != nil {
 		return nil, fmt.Errorf("gh pr diff: %w", err)
@@ -266,55 +398,36 @@ func getPRDiff(repo, prRef string) ([]byte, error) {
 }
 
 func parsePRRef(input string) (string, string, int, error) {
-	candidate := strings.TrimSpace(strings.TrimSuffix(input, "/"))
-	if candidate == "" {
-		return "", "", 0, fmt.Errorf("PR reference cannot be empty")
+	ref, err := parseGitHubRef(input)
+	if err != nil {
+		return "", "", 0, err
 	}
-
-	if strings.HasPrefix(candidate, "http://") || strings.HasPrefix(candidate, "https://") {
-		u, err := url.Parse(candidate)
-		if err != nil {
-			return "", "", 0, fmt.Errorf("parse url %q: %w", input, err)
-		}
-		if !strings.EqualFold(u.Host, "github.com") {
-			return "", "", 0, fmt.Errorf("expected github.com host, got %s", u.Host)
-		}
-		segments := strings.Split(strings.Trim(u.Path, "/"), "/")
-		if len(segments) < 4 {
-			return "", "", 0, fmt.Errorf("expected GitHub PR URL, got %q", input)
-		}
-		owner := segments[0]
-		repo := strings.TrimSuffix(segments[1], ".git")
-		number := 0
-		for i := 2; i < len(segments); i++ {
-			if segments[i] == "pull" || segments[i] == "pulls" {
-				if i+1 < len(segments) {
-					if n, err := strconv.Atoi(strings.TrimSpace(segments[i+1])); err == nil && n > 0 {
-						number = n
-						break
-					}
-				}
-			}
-		}
-		if owner == "" || repo == "" || number == 0 {
-			return "", "", 0, fmt.Errorf("unable to parse PR from %q", input)
-		}
-		return owner, repo, number, nil
+	if ref.Kind == ghref.Issue {
+		return "", "", 0, fmt.Errorf("%q is an issue, not a pull request; use %s issue", input, commandName)
 	}
+	return ref.Owner, ref.Repo, ref.Number, nil
+}
 
-	if hash := strings.Index(candidate, "#"); hash > 0 {
-		repoPart := strings.TrimSpace(candidate[:hash])
-		numberPart := strings.TrimSpace(candidate[hash+1:])
-		parts := strings.Split(repoPart, "/")
-		if len(parts) != 2 || parts[0] == "" || parts[1] == "" {
-			return "", "", 0, fmt.Errorf("invalid repo format %q, expected owner/repo", repoPart)
-		}
-		number, err := strconv.Atoi(numberPart)
-		if err != nil || number <= 0 {
-			return "", "", 0, fmt.Errorf("invalid PR number %q", numberPart)
-		}
-		return parts[0], parts[1], number, nil
+func parseIssueRef(input string) (string, string, int, error) {
+	ref, err := parseGitHubRef(input)
+	if err != nil {
+		return "", "", 0, err
+	}
+	if ref.Kind == ghref.Pull {
+		return "", "", 0, fmt.Errorf("%q is a pull request, not an issue; use %s diff", input, commandName)
 	}
+	return ref.Owner, ref.Repo, ref.Number, nil
+}
 
-	return "", "", 0, fmt.Errorf("unrecognized PR reference format: %q", input)
+// parseGitHubRef parses a PR or issue reference; unlike fgo, ghx has no
+// local checkout to fall back on, so the repository must be named.
+func parseGitHubRef(input string) (ghref.Ref, error) {
+	ref, err := ghref.Parse(input)
+	if err != nil {
+		return ghref.Ref{}, err
+	}
+	if !ref.HasRepo() {
+		return ghref.Ref{}, fmt.Errorf("reference %q must include owner/repo", input)
+	}
+	return ref, nil
 }
@@ -0,0 +1,14 @@
+//go:build !darwin && !linux
+
+package main
+
+import (
+	"os"
+	"time"
+)
+
+// accessTime falls back to the modification time where access times aren't
+// read from the platform stat data.
+func accessTime(info os.FileInfo) time.Time {
+	return info.ModTime()
+}
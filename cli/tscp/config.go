@@ -0,0 +1,167 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// profile holds the SSH details for a machine, read from a
+// [profiles.<name>] table in ~/.config/tscp/config.toml:
+//
+//	[profiles.macbook]
+//	host = "macbook"
+//	user = "nikiv"
+//	overwrite = true
+//	port = 22
+//	jump = "me@bastion.example.com"
+type profile struct {
+	Name      string
+	Host      string
+	User      string
+	Overwrite bool
+	Port      int
+	Jump      string
+}
+
+func configPath() (string, error) {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", fmt.Errorf("get home dir: %w", err)
+	}
+	return filepath.Join(home, ".config", "tscp", "config.toml"), nil
+}
+
+// loadProfile looks up a named profile. A profile without a host uses its
+// name as the machine, which matches how MagicDNS names resolve.
+func loadProfile(name string) (profile, error) {
+	path, err := configPath()
+	if err != nil {
+		return profile{}, err
+	}
+
+	profiles, err := readProfiles(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return profile{}, fmt.Errorf("profile %q requested but %s does not exist", name, path)
+		}
+		return profile{}, err
+	}
+
+	p, ok := profiles[name]
+	if !ok {
+		var known []string
+		for n := range profiles {
+			known = append(known, n)
+		}
+		if len(known) == 0 {
+			return profile{}, fmt.Errorf("profile %q not found: %s defines no profiles", name, path)
+		}
+		sort.Strings(known)
+		return profile{}, fmt.Errorf("profile %q not found in %s (known: %s)", name, path, strings.Join(known, ", "))
+	}
+	if p.Host == "" {
+		p.Host = p.Name
+	}
+	return p, nil
+}
+
+// readProfiles parses the small TOML subset the config uses: [profiles.<name>]
+// tables holding string, number, and bool keys. Anything else is reported with its
+// line number rather than silently ignored.
+func readProfiles(path string) (map[string]profile, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	profiles := make(map[string]profile)
+	var current string
+	scanner := bufio.NewScanner(f)
+	for lineNo := 1; scanner.Scan(); lineNo++ {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		if strings.HasPrefix(line, "[") {
+			if !strings.HasSuffix(line, "]") {
+				return nil, fmt.Errorf("%s:%d: malformed table header %q", path, lineNo, line)
+			}
+			header := strings.TrimSpace(line[1 : len(line)-1])
+			name, ok := strings.CutPrefix(header, "profiles.")
+			if !ok || name == "" {
+				return nil, fmt.Errorf("%s:%d: expected [profiles.<name>], got %q", path, lineNo, line)
+			}
+			name = strings.Trim(name, `"`)
+			current = name
+			profiles[name] = profile{Name: name}
+			continue
+		}
+
+		if current == "" {
+			return nil, fmt.Errorf("%s:%d: key outside a [profiles.<name>] table", path, lineNo)
+		}
+		key, rawValue, ok := strings.Cut(line, "=")
+		if !ok {
+			return nil, fmt.Errorf("%s:%d: expected key = value", path, lineNo)
+		}
+		key = strings.TrimSpace(key)
+		rawValue = stripComment(strings.TrimSpace(rawValue))
+
+		p := profiles[current]
+		switch key {
+		case "host", "user", "jump":
+			value, err := strconv.Unquote(rawValue)
+			if err != nil {
+				return nil, fmt.Errorf("%s:%d: %s must be a quoted string", path, lineNo, key)
+			}
+			switch key {
+			case "host":
+				p.Host = value
+			case "user":
+				p.User = value
+			default:
+				p.Jump = value
+			}
+		case "port":
+			value, err := strconv.Atoi(rawValue)
+			if err != nil || value < 1 || value > 65535 {
+				return nil, fmt.Errorf("%s:%d: port must be a number from 1 to 65535", path, lineNo)
+			}
+			p.Port = value
+		case "overwrite":
+			value, err := strconv.ParseBool(rawValue)
+			if err != nil {
+				return nil, fmt.Errorf("%s:%d: overwrite must be true or false", path, lineNo)
+			}
+			p.Overwrite = value
+		default:
+			return nil, fmt.Errorf("%s:%d: unknown key %q", path, lineNo, key)
+		}
+		profiles[current] = p
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("read %s: %w", path, err)
+	}
+	return profiles, nil
+}
+
+// stripComment drops a trailing # comment that isn't inside a string.
+func stripComment(value string) string {
+	inString := false
+	for i, r := range value {
+		switch {
+		case r == '"' && (i == 0 || value[i-1] != '\\'):
+			inString = !inString
+		case r == '#' && !inString:
+			return strings.TrimSpace(value[:i])
+		}
+	}
+	return value
+}
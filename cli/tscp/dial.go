@@ -0,0 +1,113 @@
+package main
+
+import (
+	"fmt"
+	"net"
+	"strconv"
+	"strings"
+
+	"golang.org/x/crypto/ssh"
+)
+
+// sshTarget is a machine to connect to, optionally reached through a jump
+// host (bastion) for machines that aren't directly on the tailnet.
+type sshTarget struct {
+	Host string
+	Port int
+	User string
+	Jump *sshTarget
+}
+
+func (t sshTarget) addr() string {
+	return net.JoinHostPort(t.Host, strconv.Itoa(t.Port))
+}
+
+// clientConfig authenticates with the ssh-agent; host keys aren't checked
+// because Tailscale handles auth.
+func (t sshTarget) clientConfig() *ssh.ClientConfig {
+	return &ssh.ClientConfig{
+		User: t.User,
+		Auth: []ssh.AuthMethod{
+			ssh.PublicKeysCallback(sshAgent),
+		},
+		HostKeyCallback: ssh.InsecureIgnoreHostKey(),
+	}
+}
+
+// parseJumpSpec parses [user@]host[:port]. The user defaults to defaultUser
+// and the port to 22.
+func parseJumpSpec(spec, defaultUser string) (sshTarget, error) {
+	invalid := func(reason string) error {
+		return fmt.Errorf("invalid -jump %q: %s (expected [user@]host[:port])", spec, reason)
+	}
+
+	spec = strings.TrimSpace(spec)
+	if spec == "" || strings.ContainsAny(spec, " \t/") {
+		return sshTarget{}, invalid("must be a single host")
+	}
+
+	target := sshTarget{User: defaultUser, Port: 22}
+	hostPart := spec
+	if at := strings.LastIndex(spec, "@"); at >= 0 {
+		target.User = spec[:at]
+		hostPart = spec[at+1:]
+		if target.User == "" {
+			return sshTarget{}, invalid("user before @ is empty")
+		}
+	}
+
+	if host, portText, err := net.SplitHostPort(hostPart); err == nil {
+		port, err := strconv.Atoi(portText)
+		if err != nil || port < 1 || port > 65535 {
+			return sshTarget{}, invalid(fmt.Sprintf("port %q is not 1-65535", portText))
+		}
+		hostPart, target.Port = host, port
+	} else if strings.Contains(hostPart, ":") && !strings.HasPrefix(hostPart, "[") {
+		return sshTarget{}, invalid("malformed host:port")
+	}
+
+	if hostPart == "" {
+		return sshTarget{}, invalid("host is empty")
+	}
+	target.Host = hostPart
+	return target, nil
+}
+
+// dialSSH connects to the target, going through its jump host when set:
+// the jump client opens a TCP channel to the target and a second SSH
+// handshake runs over it.
+func dialSSH(target sshTarget) (*ssh.Client, error) {
+	if target.Jump == nil {
+		client, err := ssh.Dial("tcp", target.addr(), target.clientConfig())
+		if err != nil {
+			return nil, fmt.Errorf("ssh connect to %s: %w", target.addr(), err)
+		}
+		return client, nil
+	}
+
+	jumpClient, err := dialSSH(*target.Jump)
+	if err != nil {
+		return nil, fmt.Errorf("jump host: %w", err)
+	}
+
+	conn, err := jumpClient.Dial("tcp", target.addr())
+	if err != nil {
+		jumpClient.Close()
+		return nil, fmt.Errorf("dial %s via %s: %w", target.addr(), target.Jump.addr(), err)
+	}
+
+	clientConn, chans, reqs, err := ssh.NewClientConn(conn, target.addr(), target.clientConfig())
+	if err != nil {
+		conn.Close()
+		jumpClient.Close()
+		return nil, fmt.Errorf("ssh connect to %s via %s: %w", target.addr(), target.Jump.addr(), err)
+	}
+
+	client := ssh.NewClient(clientConn, chans, reqs)
+	// Closing the target connection should also tear down the jump host.
+	go func() {
+		client.Wait()
+		jumpClient.Close()
+	}()
+	return client, nil
+}
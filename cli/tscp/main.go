@@ -14,18 +14,53 @@ import (
 
 func main() {
 	var (
-		src       = flag.String("src", "", "Source file path")
-		dst       = flag.String("dst", "", "Destination file path on remote machine")
-		machine   = flag.String("machine", "", "Target machine name in tailnet")
-		overwrite = flag.Bool("overwrite", false, "Overwrite existing file on remote")
-		user      = flag.String("user", "", "SSH user on remote machine (defaults to current user)")
+		src         = flag.String("src", "", "Source file path")
+		dst         = flag.String("dst", "", "Destination file path on remote machine")
+		machine     = flag.String("machine", "", "Target machine name in tailnet")
+		overwrite   = flag.Bool("overwrite", false, "Overwrite existing file on remote")
+		user        = flag.String("user", "", "SSH user on remote machine (defaults to current user)")
+		profileName = flag.String("profile", "", "Machine profile from ~/.config/tscp/config.toml")
+		port        = flag.Int("port", 22, "SSH port on the remote machine")
+		jump        = flag.String("jump", "", "Jump host to dial through, as [user@]host[:port]")
+		preserve    = flag.Bool("preserve-times", false, "Copy the source's access and modification times to the remote file")
+		dryRun      = flag.Bool("dry-run", false, "Connect and report what would be copied without copying")
 	)
 	flag.Parse()
 
+	// Profile values fill in whatever wasn't passed explicitly.
+	if *profileName != "" {
+		p, err := loadProfile(*profileName)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+		set := make(map[string]bool)
+		flag.Visit(func(f *flag.Flag) { set[f.Name] = true })
+		if !set["machine"] {
+			*machine = p.Host
+		}
+		if !set["user"] && p.User != "" {
+			*user = p.User
+		}
+		if !set["overwrite"] {
+			*overwrite = p.Overwrite
+		}
+		if !set["port"] && p.Port != 0 {
+			*port = p.Port
+		}
+		if !set["jump"] && p.Jump != "" {
+			*jump = p.Jump
+		}
+	}
+
 	if *src == "" || *dst == "" || *machine == "" {
-		fmt.Fprintf(os.Stderr, "Usage: tscp -src <file> -dst <remote-path> -machine <name> [-overwrite] [-user <name>]\n")
+		fmt.Fprintf(os.Stderr, "Usage: tscp -src <file> -dst <remote-path> (-machine <name> | -profile <name>) [-overwrite] [-user <name>] [-port <n>] [-jump [user@]host[:port]] [-preserve-times] [-dry-run]\n")
 		fmt.Fprintf(os.Stderr, "\nExample:\n")
 		fmt.Fprintf(os.Stderr, "  tscp -src ~/bin/f -dst ~/bin/f -machine macbook -overwrite\n")
+		fmt.Fprintf(os.Stderr, "  tscp -src ~/bin/f -dst ~/bin/f -profile macbook\n")
+		fmt.Fprintf(os.Stderr, "  tscp -src ./f -dst ~/f -machine 10.0.0.5 -port 2222 -jump me@bastion.example.com\n")
+		fmt.Fprintf(os.Stderr, "\nProfiles live in ~/.config/tscp/config.toml as [profiles.<name>] tables\n")
+		fmt.Fprintf(os.Stderr, "with host, user, overwrite, port, and jump keys; flags override them.\n")
 		os.Exit(1)
 	}
 
@@ -45,10 +80,29 @@ func main() {
 		// No suffix needed if MagicDNS is enabled
 	}
 
-	if err := copyFile(srcPath, *dst, host, sshUser, *overwrite); err != nil {
+	if *port < 1 || *port > 65535 {
+		fmt.Fprintf(os.Stderr, "Error: invalid -port %d: expected 1-65535\n", *port)
+		os.Exit(1)
+	}
+
+	target := sshTarget{Host: host, Port: *port, User: sshUser}
+	if *jump != "" {
+		jumpTarget, err := parseJumpSpec(*jump, sshUser)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+		target.Jump = &jumpTarget
+	}
+
+	opts := copyOptions{Overwrite: *overwrite, PreserveTimes: *preserve, DryRun: *dryRun}
+	if err := copyFile(srcPath, *dst, target, opts); err != nil {
 		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
 		os.Exit(1)
 	}
+	if opts.DryRun {
+		return
+	}
 
 	fmt.Printf("Successfully copied %s to %s:%s\n", srcPath, host, *dst)
 }
@@ -61,7 +115,17 @@ func expandPath(path string) string {
 	return path
 }
 
-func copyFile(src, dst, host, user string, overwrite bool) error {
+type copyOptions struct {
+	Overwrite     bool
+	PreserveTimes bool
+	// DryRun does everything up to the first remote change (connecting,
+	// resolving ~, the overwrite check) and prints the plan instead.
+	DryRun bool
+}
+
+func copyFile(src, dst string, target sshTarget, opts copyOptions) error {
+	host := target.Host
+
 	// Read source file
 	srcFile, err := os.Open(src)
 	if err != nil {
@@ -74,18 +138,9 @@ func copyFile(src, dst, host, user string, overwrite bool) error {
 		return fmt.Errorf("stat source: %w", err)
 	}
 
-	// Connect via SSH using Tailscale SSH (uses ssh-agent or keys)
-	config := &ssh.ClientConfig{
-		User: user,
-		Auth: []ssh.AuthMethod{
-			ssh.PublicKeysCallback(sshAgent),
-		},
-		HostKeyCallback: ssh.InsecureIgnoreHostKey(), // Tailscale handles auth
-	}
-
-	client, err := ssh.Dial("tcp", host+":22", config)
+	client, err := dialSSH(target)
 	if err != nil {
-		return fmt.Errorf("ssh connect to %s: %w", host, err)
+		return err
 	}
 	defer client.Close()
 
@@ -112,10 +167,18 @@ func copyFile(src, dst, host, user string, overwrite bool) error {
 	}
 
 	// Check if file exists
-	if _, err := sftpClient.Stat(remotePath); err == nil {
-		if !overwrite {
-			return fmt.Errorf("file %s already exists on %s (use -overwrite to replace)", remotePath, host)
+	remoteInfo, statErr := sftpClient.Stat(remotePath)
+	exists := statErr == nil
+	if exists && !opts.Overwrite {
+		return fmt.Errorf("file %s already exists on %s (use -overwrite to replace)", remotePath, host)
+	}
+
+	if opts.DryRun {
+		if !exists {
+			remoteInfo = nil
 		}
+		printDryRun(src, srcInfo, host, remotePath, remoteInfo, opts)
+		return nil
 	}
 
 	// Create parent directory if needed
@@ -134,15 +197,40 @@ func copyFile(src, dst, host, user string, overwrite bool) error {
 	if err != nil {
 		return fmt.Errorf("copy: %w", err)
 	}
+	// Close before touching metadata so no later write bumps the mtime.
+	if err := dstFile.Close(); err != nil {
+		return fmt.Errorf("close remote file: %w", err)
+	}
 
 	// Set permissions (preserve from source)
 	if err := sftpClient.Chmod(remotePath, srcInfo.Mode()); err != nil {
 		return fmt.Errorf("chmod: %w", err)
 	}
 
+	// Timestamps are best effort: some remote filesystems refuse them, and
+	// the file itself already arrived intact.
+	if opts.PreserveTimes {
+		if err := sftpClient.Chtimes(remotePath, accessTime(srcInfo), srcInfo.ModTime()); err != nil {
+			fmt.Fprintf(os.Stderr, "Warning: could not preserve timestamps on %s: %v\n", remotePath, err)
+		}
+	}
+
 	return nil
 }
 
+func printDryRun(src string, srcInfo os.FileInfo, host, remotePath string, remoteInfo os.FileInfo, opts copyOptions) {
+	fmt.Printf("Dry run: would copy %s (%d bytes, mode %s)\n", src, srcInfo.Size(), srcInfo.Mode().Perm())
+	fmt.Printf("  to %s:%s\n", host, remotePath)
+	if remoteInfo != nil {
+		fmt.Printf("  overwriting the existing remote file (%d bytes, modified %s)\n", remoteInfo.Size(), remoteInfo.ModTime().Format("2006-01-02 15:04:05"))
+	} else {
+		fmt.Println("  creating a new remote file")
+	}
+	if opts.PreserveTimes {
+		fmt.Printf("  and set its modification time to %s\n", srcInfo.ModTime().Format("2006-01-02 15:04:05"))
+	}
+}
+
 func sshAgent() ([]ssh.Signer, error) {
 	// Try to use SSH agent
 	socket := os.Getenv("SSH_AUTH_SOCK")
@@ -0,0 +1,253 @@
+package main
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"lang/userpath"
+)
+
+// configEnv points unite at a config file other than
+// ~/.config/unite/config.toml.
+const configEnv = "UNITE_CONFIG"
+
+// sourceConfig is one [source] table of the config file. Unset fields keep
+// the source's built-in behavior.
+type sourceConfig struct {
+	NoConfirm   *bool
+	ArgPatterns []string
+	Dir         string
+	Env         []string
+}
+
+func configPath() (string, error) {
+	if override := strings.TrimSpace(os.Getenv(configEnv)); override != "" {
+		return filepath.Clean(override), nil
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", fmt.Errorf("determine home directory: %w", err)
+	}
+	return filepath.Join(home, ".config", "unite", "config.toml"), nil
+}
+
+// applyConfig overlays the config file on sources. A file that fails to
+// parse is reported on warn and ignored as a whole, so a typo cannot half
+// apply; a missing file is not an error.
+func applyConfig(warn io.Writer) {
+	path, err := configPath()
+	if err != nil {
+		fmt.Fprintf(warn, "warning: ignoring config: %v\n", err)
+		return
+	}
+	configs, err := loadConfig(path)
+	if err != nil {
+		fmt.Fprintf(warn, "warning: ignoring config: %v\n", err)
+		return
+	}
+	for name, cfg := range configs {
+		src := findSource(name)
+		if src == nil {
+			fmt.Fprintf(warn, "warning: %s: no source named %q; available: %s\n", path, name, strings.Join(sourceNames(), ", "))
+			continue
+		}
+		if cfg.NoConfirm != nil {
+			src.NoConfirm = *cfg.NoConfirm
+		}
+		if cfg.ArgPatterns != nil {
+			src.ArgPatterns = cfg.ArgPatterns
+		}
+		if cfg.Dir != "" {
+			src.Dir = cfg.Dir
+		}
+		if cfg.Env != nil {
+			src.Env = cfg.Env
+		}
+	}
+}
+
+func loadConfig(path string) (map[string]sourceConfig, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("open %s: %w", path, err)
+	}
+	defer file.Close()
+
+	configs, err := parseConfig(bufio.NewScanner(file))
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return configs, nil
+}
+
+// parseConfig reads the subset of TOML unite needs: a [name] table per
+// source holding
+//
+//	no_confirm = true
+//	arg_patterns = ['^clone', 'checkout']
+//	dir = '~/src/app'
+//	env = ['FLOW_EDITOR=zed']
+//
+// An empty arg_patterns turns argument prompting off for that source. dir
+// must be an existing directory.
+func parseConfig(scanner *bufio.Scanner) (map[string]sourceConfig, error) {
+	configs := map[string]sourceConfig{}
+	current := ""
+	lineNumber := 0
+	for scanner.Scan() {
+		lineNumber++
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		if strings.HasPrefix(line, "[") {
+			name, rest, ok := strings.Cut(line[1:], "]")
+			if !ok || !isComment(rest) || strings.TrimSpace(name) == "" {
+				return nil, fmt.Errorf("line %d: expected [source-name]", lineNumber)
+			}
+			current = strings.TrimSpace(name)
+			if _, seen := configs[current]; seen {
+				return nil, fmt.Errorf("line %d: [%s] appears twice", lineNumber, current)
+			}
+			configs[current] = sourceConfig{}
+			continue
+		}
+		if current == "" {
+			return nil, fmt.Errorf("line %d: settings belong under a [source-name] table", lineNumber)
+		}
+
+		key, raw, ok := strings.Cut(line, "=")
+		if !ok {
+			return nil, fmt.Errorf("line %d: expected key = value", lineNumber)
+		}
+		key, raw = strings.TrimSpace(key), strings.TrimSpace(raw)
+		cfg := configs[current]
+		switch key {
+		case "no_confirm":
+			value, _, _ := strings.Cut(raw, "#")
+			enabled, err := strconv.ParseBool(strings.TrimSpace(value))
+			if err != nil {
+				return nil, fmt.Errorf("line %d: no_confirm expects true or false", lineNumber)
+			}
+			cfg.NoConfirm = &enabled
+		case "arg_patterns":
+			patterns, err := parseStringArray(raw)
+			if err != nil {
+				return nil, fmt.Errorf("line %d: arg_patterns: %w", lineNumber, err)
+			}
+			for _, pattern := range patterns {
+				if _, err := regexp.Compile(pattern); err != nil {
+					return nil, fmt.Errorf("line %d: invalid pattern %q: %w", lineNumber, pattern, err)
+				}
+			}
+			cfg.ArgPatterns = patterns
+		case "dir":
+			value, rest, err := parseString(raw)
+			if err != nil {
+				return nil, fmt.Errorf("line %d: dir: %w", lineNumber, err)
+			}
+			if !isComment(rest) {
+				return nil, fmt.Errorf("line %d: unexpected text after dir", lineNumber)
+			}
+			dir, err := userpath.Expand(value)
+			if err != nil {
+				return nil, fmt.Errorf("line %d: dir: %w", lineNumber, err)
+			}
+			if info, err := os.Stat(dir); err != nil {
+				return nil, fmt.Errorf("line %d: dir: %w", lineNumber, err)
+			} else if !info.IsDir() {
+				return nil, fmt.Errorf("line %d: dir %s is not a directory", lineNumber, dir)
+			}
+			cfg.Dir = dir
+		case "env":
+			entries, err := parseStringArray(raw)
+			if err != nil {
+				return nil, fmt.Errorf("line %d: env: %w", lineNumber, err)
+			}
+			for _, entry := range entries {
+				if name, _, ok := strings.Cut(entry, "="); !ok || name == "" {
+					return nil, fmt.Errorf("line %d: env entry %q is not KEY=value", lineNumber, entry)
+				}
+			}
+			cfg.Env = entries
+		default:
+			return nil, fmt.Errorf("line %d: unknown setting %q (known: no_confirm, arg_patterns, dir, env)", lineNumber, key)
+		}
+		configs[current] = cfg
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return configs, nil
+}
+
+// parseStringArray reads a one-line array of basic ("...") or literal
+// ('...') strings. The result is never nil, so [] can be told from unset.
+func parseStringArray(raw string) ([]string, error) {
+	if !strings.HasPrefix(raw, "[") {
+		return nil, fmt.Errorf("expected an array like ['pattern']")
+	}
+	values := []string{}
+	rest := strings.TrimSpace(raw[1:])
+	for !strings.HasPrefix(rest, "]") {
+		value, after, err := parseString(rest)
+		if err != nil {
+			return nil, err
+		}
+		values = append(values, value)
+		rest = strings.TrimSpace(after)
+		if trimmed, ok := strings.CutPrefix(rest, ","); ok {
+			rest = strings.TrimSpace(trimmed)
+		} else if !strings.HasPrefix(rest, "]") {
+			return nil, fmt.Errorf("expected , or ] after %q", value)
+		}
+	}
+	if !isComment(rest[1:]) {
+		return nil, fmt.Errorf("unexpected text after ]")
+	}
+	return values, nil
+}
+
+// parseString reads the string at the start of raw and returns what
+// follows it.
+func parseString(raw string) (string, string, error) {
+	switch {
+	case strings.HasPrefix(raw, `"`):
+		for i := 1; i < len(raw); i++ {
+			switch raw[i] {
+			case '\\':
+				i++
+			case '"':
+				value, err := strconv.Unquote(raw[:i+1])
+				if err != nil {
+					return "", "", fmt.Errorf("invalid string %s: %w", raw[:i+1], err)
+				}
+				return value, raw[i+1:], nil
+			}
+		}
+	case strings.HasPrefix(raw, "'"):
+		if value, rest, ok := strings.Cut(raw[1:], "'"); ok {
+			return value, rest, nil
+		}
+	default:
+		return "", "", fmt.Errorf("expected a quoted string, got %s", raw)
+	}
+	return "", "", fmt.Errorf("unterminated string %s", raw)
+}
+
+// isComment reports whether rest, what is left of a line after its value,
+// is blank or a comment.
+func isComment(rest string) bool {
+	rest = strings.TrimSpace(rest)
+	return rest == "" || strings.HasPrefix(rest, "#")
+}
@@ -0,0 +1,73 @@
+package main
+
+import (
+	"bufio"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestParseConfig(t *testing.T) {
+	dir := t.TempDir()
+	input := `# unite
+[fgo]
+no_confirm = true # skip the prompt
+arg_patterns = ['^clone', "check\\w+", ]
+dir = '` + dir + `'
+env = ["FLOW_EDITOR=zed", 'TOKEN=a=b']
+
+[rflow]
+arg_patterns = []
+`
+	got, err := parseConfig(bufio.NewScanner(strings.NewReader(input)))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	fgo := got["fgo"]
+	if fgo.NoConfirm == nil || !*fgo.NoConfirm {
+		t.Errorf("fgo no_confirm = %v, want true", fgo.NoConfirm)
+	}
+	if want := []string{"^clone", `check\w+`}; !reflect.DeepEqual(fgo.ArgPatterns, want) {
+		t.Errorf("fgo arg_patterns = %q, want %q", fgo.ArgPatterns, want)
+	}
+	if fgo.Dir != dir {
+		t.Errorf("fgo dir = %q, want %q", fgo.Dir, dir)
+	}
+	if want := []string{"FLOW_EDITOR=zed", "TOKEN=a=b"}; !reflect.DeepEqual(fgo.Env, want) {
+		t.Errorf("fgo env = %q, want %q", fgo.Env, want)
+	}
+
+	rflow := got["rflow"]
+	if rflow.ArgPatterns == nil || len(rflow.ArgPatterns) != 0 {
+		t.Errorf("rflow arg_patterns = %#v, want an empty, non-nil slice", rflow.ArgPatterns)
+	}
+	if rflow.NoConfirm != nil || rflow.Dir != "" || rflow.Env != nil {
+		t.Errorf("rflow set more than arg_patterns: %+v", rflow)
+	}
+}
+
+func TestParseConfigRejects(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "file")
+	if err := os.WriteFile(file, nil, 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, input := range []string{
+		"no_confirm = true",
+		"[fgo]\nno_confirm = maybe",
+		"[fgo]\narg_patterns = ['(x']",
+		"[fgo]\ndir = '" + filepath.Join(t.TempDir(), "missing") + "'",
+		"[fgo]\ndir = '" + file + "'",
+		"[fgo]\nenv = ['NOVALUE']",
+		"[fgo]\nenv = ['=x']",
+		"[fgo]\ncolor = true",
+		"[fgo]\n[fgo]",
+	} {
+		if _, err := parseConfig(bufio.NewScanner(strings.NewReader(input))); err == nil {
+			t.Errorf("parseConfig(%q) succeeded, want an error", input)
+		}
+	}
+}
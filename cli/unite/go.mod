@@ -4,14 +4,18 @@ go 1.24.0
 
 require github.com/dzonerzy/go-snap v0.2.6
 
-require github.com/junegunn/fzf v0.67.0
+require lang v0.0.0
+
+require (
+	github.com/junegunn/fzf v0.67.0
+	github.com/junegunn/go-shellwords v0.0.0-20250127100254-2aa3b3277741
+)
 
 require (
 	github.com/charlievieth/fastwalk v1.0.14 // indirect
 	github.com/clipperhouse/uax29/v2 v2.2.0 // indirect
 	github.com/gdamore/encoding v1.0.1 // indirect
 	github.com/gdamore/tcell/v2 v2.9.0 // indirect
-	github.com/junegunn/go-shellwords v0.0.0-20250127100254-2aa3b3277741 // indirect
 	github.com/lucasb-eyer/go-colorful v1.2.0 // indirect
 	github.com/mattn/go-isatty v0.0.20 // indirect
 	github.com/mattn/go-runewidth v0.0.19 // indirect
@@ -26,3 +30,5 @@ replace golang.org/x/sys => github.com/golang/sys v0.30.0
 replace golang.org/x/term => github.com/golang/term v0.29.0
 
 replace golang.org/x/text => github.com/golang/text v0.21.0
+
+replace lang => ../..
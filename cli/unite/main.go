@@ -2,16 +2,24 @@ package main
 
 import (
 	"bufio"
+	"errors"
 	"fmt"
 	"io"
 	"os"
 	"os/exec"
 	"path/filepath"
+	"regexp"
+	"strconv"
 	"strings"
+	"sync"
+
+	"lang/buildinfo"
+	"lang/exitcode"
 
 	"github.com/dzonerzy/go-snap/snap"
 	fzf "github.com/junegunn/fzf/src"
 	fzfutil "github.com/junegunn/fzf/src/util"
+	"github.com/junegunn/go-shellwords"
 )
 
 const (
@@ -25,9 +33,29 @@ var (
 	commandSummary = defaultSummary
 )
 
+// Set at build time with -ldflags "-X main.buildTime=<RFC3339> -X main.gitCommit=<sha>".
+var (
+	buildTime = buildinfo.Unknown
+	gitCommit = buildinfo.Unknown
+)
+
 type CommandSource struct {
-	Name     string
-	Binary   string
+	Name   string
+	Binary string
+	// NoConfirm runs a selected command right away instead of showing it and
+	// asking first. The source's table in the config file can set it.
+	NoConfirm bool
+	// ArgPatterns are regular expressions for the names of commands that
+	// usually need arguments; those prompt for them before running. Nil
+	// means defaultArgPatterns. The config file can replace them.
+	ArgPatterns []string
+	// Dir is the working directory the source's binary runs in, for help
+	// and commands alike. Empty means unite's own. Set by dir in the
+	// source's config table.
+	Dir string
+	// Env holds KEY=value pairs added to the inherited environment,
+	// overriding variables of the same name. Set by env in the config.
+	Env      []string
 	Commands []Command
 }
 
@@ -37,6 +65,20 @@ type Command struct {
 	Source      *CommandSource
 }
 
+var errBinaryNotFound = errors.New("binary not found")
+
+// noConfirmEnv skips the run confirmation for every source.
+const noConfirmEnv = "UNITE_NO_CONFIRM"
+
+// defaultArgPatterns catch the usual suspects: commands that clone, search,
+// check out, rename, or play something named on the command line. A false
+// match only costs an extra Enter at the prompt.
+var defaultArgPatterns = []string{
+	`(?i)^(clone|search|grep|spotify|youtube)`,
+	`(?i)checkout|rename|port$`,
+	`^openDoc$`,
+}
+
 var sources = []CommandSource{
 	{
 		Name:   "fgo",
@@ -60,13 +102,28 @@ func init() {
 }
 
 func main() {
+	applyConfig(os.Stderr)
+
 	app := snap.New(commandName, commandSummary).
 		Version(uniteVersion).
-		DisableHelp()
+		DisableHelp().
+		Use(exitcode.Middleware)
 
 	app.Command("search", "Fuzzy search across all command sources").
+		BoolFlag("search-desc", "Show the full command description in a preview window").Back().
 		Action(func(ctx *snap.Context) error {
-			return runSearch()
+			showDesc, _ := ctx.Bool("search-desc")
+			return runSearch(searchOptions{ShowDescription: showDesc})
+		})
+
+	app.Command("run", "Run a source's command directly: run <source> <command> [args...]").
+		RestArgs().
+		Action(func(ctx *snap.Context) error {
+			args := make([]string, 0, ctx.NArgs())
+			for i := 0; i < ctx.NArgs(); i++ {
+				args = append(args, ctx.Arg(i))
+			}
+			return runDirect(ctx.Stderr(), args)
 		})
 
 	app.Command("list", "List all available commands from all sources").
@@ -85,15 +142,21 @@ func main() {
 		})
 
 	app.Command("version", "Reports the current version").
+		BoolFlag("json", "Print version and build metadata as JSON").Back().
 		Action(func(ctx *snap.Context) error {
+			if asJSON, _ := ctx.Bool("json"); asJSON {
+				return buildinfo.New(commandName, uniteVersion, buildTime, gitCommit).WriteJSON(ctx.Stdout())
+			}
 			fmt.Fprintf(ctx.Stdout(), "%s version %s\n", commandName, uniteVersion)
 			return nil
 		})
 
 	if len(os.Args) < 2 {
-		if err := runSearch(); err != nil {
-			fmt.Fprintf(os.Stderr, "error: %v\n", err)
-			os.Exit(1)
+		if err := runSearch(searchOptions{}); err != nil {
+			if !errors.Is(err, exitcode.ErrUserAbort) {
+				fmt.Fprintf(os.Stderr, "error: %v\n", err)
+			}
+			os.Exit(exitcode.For(err))
 		}
 		return
 	}
@@ -101,32 +164,86 @@ func main() {
 	app.RunAndExit()
 }
 
-func loadAllCommands() ([]Command, error) {
-	var allCommands []Command
+// sourceStatus records how loading a single source went so callers can
+// surface sources whose commands are missing from the results.
+type sourceStatus struct {
+	Source  *CommandSource
+	Missing bool
+	Loaded  int
+	Err     error
+}
 
+func (s sourceStatus) OK() bool {
+	return s.Err == nil
+}
+
+// loadAllCommands never fails as a whole: a source that cannot be loaded is
+// reported through its sourceStatus and the others still count.
+func loadAllCommands() ([]Command, []sourceStatus) {
+	// Each source spawns a subprocess, so load them concurrently. Results are
+	// written by index to keep the output in configured source order.
+	statuses := make([]sourceStatus, len(sources))
+	results := make([][]Command, len(sources))
+
+	var wg sync.WaitGroup
+	var warnMu sync.Mutex
 	for i := range sources {
-		src := &sources[i]
-		commands, err := loadCommandsFromSource(src)
-		if err != nil {
-			fmt.Fprintf(os.Stderr, "warning: failed to load commands from %s: %v\n", src.Name, err)
-			continue
-		}
-		src.Commands = commands
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			src := &sources[i]
+			status := sourceStatus{Source: src}
+			commands, err := loadCommandsFromSource(src)
+			if err != nil {
+				warnMu.Lock()
+				fmt.Fprintf(os.Stderr, "warning: failed to load commands from %s: %v\n", src.Name, err)
+				warnMu.Unlock()
+				status.Err = err
+				status.Missing = errors.Is(err, errBinaryNotFound)
+				statuses[i] = status
+				return
+			}
+			src.Commands = commands
+			status.Loaded = len(commands)
+			statuses[i] = status
+			results[i] = commands
+		}(i)
+	}
+	wg.Wait()
+
+	var allCommands []Command
+	for _, commands := range results {
 		allCommands = append(allCommands, commands...)
 	}
 
-	return allCommands, nil
+	return allCommands, statuses
+}
+
+func failedSources(statuses []sourceStatus) []sourceStatus {
+	var failed []sourceStatus
+	for _, status := range statuses {
+		if !status.OK() {
+			failed = append(failed, status)
+		}
+	}
+	return failed
 }
 
 func loadCommandsFromSource(src *CommandSource) ([]Command, error) {
 	if _, err := os.Stat(src.Binary); err != nil {
-		return nil, fmt.Errorf("binary not found: %s", src.Binary)
+		return nil, fmt.Errorf("%w: %w: %s", exitcode.ErrMissingDependency, errBinaryNotFound, src.Binary)
 	}
 
-	cmd := exec.Command(src.Binary, "help")
+	cmd, err := sourceCommand(src, "help")
+	if err != nil {
+		return nil, err
+	}
 	output, err := cmd.Output()
 	if err != nil {
-		cmd = exec.Command(src.Binary, "--help")
+		cmd, err = sourceCommand(src, "--help")
+		if err != nil {
+			return nil, err
+		}
 		output, err = cmd.Output()
 		if err != nil {
 			return nil, fmt.Errorf("failed to get help output: %w", err)
@@ -212,29 +329,52 @@ func parseFallback(src *CommandSource, output string) []Command {
 	return commands
 }
 
-func runSearch() error {
+type searchOptions struct {
+	ShowDescription bool
+}
+
+func runSearch(opts searchOptions) error {
 	if !fzfutil.IsTty(os.Stdin) || !fzfutil.IsTty(os.Stdout) {
 		return fmt.Errorf("requires interactive terminal")
 	}
 
-	commands, err := loadAllCommands()
-	if err != nil {
-		return err
-	}
-
+	commands, statuses := loadAllCommands()
 	if len(commands) == 0 {
 		return fmt.Errorf("no commands found from any source")
 	}
 
-	options, err := fzf.ParseOptions(true, []string{
+	header := "Select a command (Enter to run, ESC to cancel)"
+	if failed := failedSources(statuses); len(failed) > 0 {
+		names := make([]string, 0, len(failed))
+		for _, status := range failed {
+			names = append(names, status.Source.Name)
+		}
+		header = fmt.Sprintf("%s\n%d source(s) failed to load: %s (see `%s sources`)",
+			header, len(failed), strings.Join(names, ", "), commandName)
+	}
+
+	// Lines are "[source] name<TAB>description". Matching covers both fields,
+	// so a multi-word query like "git sync fork" can hit the name and the
+	// description at once; only the first field is handed back on selection.
+	args := []string{
 		"--height=~50%",
 		"--layout=reverse",
 		"--border",
 		"--prompt", commandName + "> ",
 		"--info=inline",
 		"--no-multi",
-		"--header", "Select a command (Enter to run, ESC to cancel)",
-	})
+		"--delimiter", "\t",
+		"--nth", "1,2",
+		"--header", header,
+	}
+	if opts.ShowDescription {
+		args = append(args,
+			"--preview", "printf '%s\\n' {2}",
+			"--preview-window", "down,3,wrap",
+		)
+	}
+
+	options, err := fzf.ParseOptions(true, args)
 	if err != nil {
 		return fmt.Errorf("initialize fzf: %w", err)
 	}
@@ -251,8 +391,7 @@ func runSearch() error {
 
 	go func() {
 		for _, cmd := range commands {
-			line := fmt.Sprintf("[%s] %s\t%s", cmd.Source.Name, cmd.Name, cmd.Description)
-			input <- line
+			input <- formatSearchLine(cmd)
 		}
 		close(input)
 	}()
@@ -261,6 +400,9 @@ func runSearch() error {
 	if runErr != nil {
 		return fmt.Errorf("run fzf: %w", runErr)
 	}
+	if code == fzf.ExitInterrupt {
+		return exitcode.ErrUserAbort
+	}
 	if code != fzf.ExitOk {
 		return nil
 	}
@@ -272,35 +414,121 @@ func runSearch() error {
 	return executeSelection(selected)
 }
 
+func formatSearchLine(cmd Command) string {
+	desc := strings.ReplaceAll(cmd.Description, "\t", " ")
+	return fmt.Sprintf("[%s] %s\t%s", cmd.Source.Name, cmd.Name, desc)
+}
+
+// parseSearchLine extracts the source and command name from a line built by
+// formatSearchLine. Only the text before the first tab is inspected, so
+// brackets inside descriptions cannot be mistaken for the source prefix.
+func parseSearchLine(line string) (string, string, error) {
+	head, _, _ := strings.Cut(line, "\t")
+	if !strings.HasPrefix(head, "[") {
+		return "", "", fmt.Errorf("invalid selection format")
+	}
+	sourceName, cmdName, ok := strings.Cut(head[1:], "] ")
+	if !ok || sourceName == "" || strings.TrimSpace(cmdName) == "" {
+		return "", "", fmt.Errorf("invalid selection format")
+	}
+	return sourceName, strings.TrimSpace(cmdName), nil
+}
+
 func executeSelection(selection string) error {
-	if tab := strings.Index(selection, "\t"); tab >= 0 {
-		selection = selection[:tab]
+	sourceName, cmdName, err := parseSearchLine(selection)
+	if err != nil {
+		return err
 	}
 
-	selection = strings.TrimPrefix(selection, "[")
-	closeBracket := strings.Index(selection, "]")
-	if closeBracket == -1 {
-		return fmt.Errorf("invalid selection format")
+	src := findSource(sourceName)
+	if src == nil {
+		return fmt.Errorf("unknown source: %s", sourceName)
 	}
 
-	sourceName := selection[:closeBracket]
-	cmdName := strings.TrimSpace(selection[closeBracket+1:])
+	reader := bufio.NewReader(os.Stdin)
+	var args []string
+	if needsArgs(src, cmdName) {
+		if args, err = promptArgs(reader, cmdName); err != nil {
+			return err
+		}
+	}
 
-	var src *CommandSource
-	for i := range sources {
-		if sources[i].Name == sourceName {
-			src = &sources[i]
-			break
+	commandLine := formatCommandLine(src.Binary, append([]string{cmdName}, args...))
+	if !src.NoConfirm && !envFlagEnabled(noConfirmEnv) {
+		fmt.Printf("Run %s? [Y/n] ", commandLine)
+		answer, err := reader.ReadString('\n')
+		if err != nil && !errors.Is(err, io.EOF) {
+			return fmt.Errorf("read confirmation: %w", err)
 		}
+		switch strings.ToLower(strings.TrimSpace(answer)) {
+		case "", "y", "yes":
+		default:
+			fmt.Println("Cancelled.")
+			return exitcode.ErrUserAbort
+		}
+	} else {
+		fmt.Printf("Running: %s\n", commandLine)
+	}
+
+	return runSourceCommand(src, cmdName, args)
+}
+
+// runDirect is the scriptable form of search: it checks that the command is
+// in the source's catalog and runs it with the remaining arguments, without
+// prompting. The command's own exit code becomes unite's.
+func runDirect(stderr io.Writer, args []string) error {
+	if len(args) < 2 {
+		fmt.Fprintf(stderr, "Usage: %s run <source> <command> [args...]\n", commandName)
+		fmt.Fprintf(stderr, "Sources: %s\n", strings.Join(sourceNames(), ", "))
+		return fmt.Errorf("expected a source and a command")
 	}
 
+	src := findSource(args[0])
 	if src == nil {
-		return fmt.Errorf("unknown source: %s", sourceName)
+		err := fmt.Errorf("unknown source %q; available: %s", args[0], strings.Join(sourceNames(), ", "))
+		fmt.Fprintf(stderr, "error: %v\n", err)
+		return err
 	}
 
-	fmt.Printf("Running: %s %s\n", src.Binary, cmdName)
+	commands, err := loadCommandsFromSource(src)
+	if err != nil {
+		err = fmt.Errorf("load commands from %s: %w", src.Name, err)
+		fmt.Fprintf(stderr, "error: %v\n", err)
+		return err
+	}
+
+	cmdName := args[1]
+	found := false
+	for _, cmd := range commands {
+		if cmd.Name == cmdName {
+			found = true
+			break
+		}
+	}
+	if !found {
+		fmt.Fprintf(stderr, "error: %s has no command %q. Available commands:\n", src.Name, cmdName)
+		for _, cmd := range commands {
+			fmt.Fprintf(stderr, "  %s\n", cmd.Name)
+		}
+		return fmt.Errorf("unknown command %q in %s", cmdName, src.Name)
+	}
 
-	cmd := exec.Command(src.Binary, cmdName)
+	if err := runSourceCommand(src, cmdName, args[2:]); err != nil {
+		var exitErr *exec.ExitError
+		if errors.As(err, &exitErr) {
+			return &snap.ExitError{Code: exitErr.ExitCode(), Err: err}
+		}
+		fmt.Fprintf(stderr, "error: %v\n", err)
+		return err
+	}
+	return nil
+}
+
+func runSourceCommand(src *CommandSource, cmdName string, args []string) error {
+	cmd, err := sourceCommand(src, append([]string{cmdName}, args...)...)
+	if err != nil {
+		return err
+	}
 	cmd.Stdin = os.Stdin
 	cmd.Stdout = os.Stdout
 	cmd.Stderr = os.Stderr
@@ -308,11 +536,102 @@ func executeSelection(selection string) error {
 	return cmd.Run()
 }
 
-func runList(out io.Writer) error {
-	commands, err := loadAllCommands()
+// sourceCommand prepares src's binary with args in the source's configured
+// working directory and environment.
+func sourceCommand(src *CommandSource, args ...string) (*exec.Cmd, error) {
+	cmd := exec.Command(src.Binary, args...)
+	if src.Dir != "" {
+		info, err := os.Stat(src.Dir)
+		if err != nil {
+			return nil, fmt.Errorf("%s: working directory: %w", src.Name, err)
+		}
+		if !info.IsDir() {
+			return nil, fmt.Errorf("%s: working directory %s is not a directory", src.Name, src.Dir)
+		}
+		cmd.Dir = src.Dir
+	}
+	if len(src.Env) > 0 {
+		for _, entry := range src.Env {
+			if key, _, ok := strings.Cut(entry, "="); !ok || key == "" {
+				return nil, fmt.Errorf("%s: environment entry %q is not KEY=value", src.Name, entry)
+			}
+		}
+		// exec keeps the last value for a repeated key, so these win.
+		cmd.Env = append(os.Environ(), src.Env...)
+	}
+	return cmd, nil
+}
+
+func sourceNames() []string {
+	names := make([]string, 0, len(sources))
+	for _, src := range sources {
+		names = append(names, src.Name)
+	}
+	return names
+}
+
+func findSource(name string) *CommandSource {
+	for i := range sources {
+		if sources[i].Name == name {
+			return &sources[i]
+		}
+	}
+	return nil
+}
+
+// needsArgs reports whether cmdName matches one of src's argument patterns.
+// Patterns that fail to compile are warned about and skipped.
+func needsArgs(src *CommandSource, cmdName string) bool {
+	patterns := src.ArgPatterns
+	if patterns == nil {
+		patterns = defaultArgPatterns
+	}
+	for _, pattern := range patterns {
+		re, err := regexp.Compile(pattern)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "warning: %s: invalid argument pattern %q: %v\n", src.Name, pattern, err)
+			continue
+		}
+		if re.MatchString(cmdName) {
+			return true
+		}
+	}
+	return false
+}
+
+// promptArgs reads one line of shell-style words; an empty line means none.
+func promptArgs(reader *bufio.Reader, cmdName string) ([]string, error) {
+	fmt.Printf("Arguments for %s (Enter for none): ", cmdName)
+	line, err := reader.ReadString('\n')
+	if err != nil && !errors.Is(err, io.EOF) {
+		return nil, fmt.Errorf("read arguments: %w", err)
+	}
+	args, err := shellwords.Parse(strings.TrimSpace(line))
 	if err != nil {
-		return err
+		return nil, fmt.Errorf("parse arguments: %w", err)
 	}
+	return args, nil
+}
+
+// formatCommandLine renders a command the way it could be typed in a shell.
+func formatCommandLine(binary string, args []string) string {
+	parts := []string{binary}
+	for _, arg := range args {
+		if arg == "" || strings.ContainsAny(arg, " \t\n'\"\\$") {
+			arg = strconv.Quote(arg)
+		}
+		parts = append(parts, arg)
+	}
+	return strings.Join(parts, " ")
+}
+
+func envFlagEnabled(key string) bool {
+	enabled, err := strconv.ParseBool(strings.TrimSpace(os.Getenv(key)))
+	return err == nil && enabled
+}
+
+func runList(out io.Writer) error {
+	commands, statuses := loadAllCommands()
 
 	currentSource := ""
 	for _, cmd := range commands {
@@ -326,17 +645,52 @@ func runList(out io.Writer) error {
 		fmt.Fprintf(out, "  %s: %s\n", cmd.Name, cmd.Description)
 	}
 
+	if failed := failedSources(statuses); len(failed) > 0 {
+		fmt.Fprintf(out, "\n%d source(s) failed to load:\n", len(failed))
+		for _, status := range failed {
+			fmt.Fprintf(out, "  %s: %v\n", status.Source.Name, status.Err)
+		}
+	}
+
 	return nil
 }
 
 func runSources(out io.Writer) error {
+	_, statuses := loadAllCommands()
+
 	fmt.Fprintln(out, "Configured command sources:")
-	for _, src := range sources {
-		exists := "✓"
-		if _, err := os.Stat(src.Binary); err != nil {
-			exists = "✗"
+	for _, status := range statuses {
+		src := status.Source
+		switch {
+		case status.OK():
+			fmt.Fprintf(out, "  [✓] %s: %s (%d commands)\n", src.Name, src.Binary, status.Loaded)
+		case status.Missing:
+			fmt.Fprintf(out, "  [✗] %s: %s (missing)\n", src.Name, src.Binary)
+		default:
+			fmt.Fprintf(out, "  [!] %s: %s (failed: %v)\n", src.Name, src.Binary, status.Err)
+		}
+		if src.NoConfirm {
+			fmt.Fprintln(out, "      runs without confirming")
+		}
+		if src.ArgPatterns != nil {
+			if len(src.ArgPatterns) == 0 {
+				fmt.Fprintln(out, "      never prompts for arguments")
+			} else {
+				fmt.Fprintf(out, "      prompts for arguments on %s\n", strings.Join(src.ArgPatterns, ", "))
+			}
+		}
+		if src.Dir != "" {
+			fmt.Fprintf(out, "      runs in %s\n", src.Dir)
+		}
+		if len(src.Env) > 0 {
+			// Only names: values are often tokens.
+			keys := make([]string, 0, len(src.Env))
+			for _, entry := range src.Env {
+				key, _, _ := strings.Cut(entry, "=")
+				keys = append(keys, key)
+			}
+			fmt.Fprintf(out, "      sets %s\n", strings.Join(keys, ", "))
 		}
-		fmt.Fprintf(out, "  [%s] %s: %s\n", exists, src.Name, src.Binary)
 	}
 
 	return nil
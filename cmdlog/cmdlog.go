@@ -0,0 +1,153 @@
+// Package cmdlog runs external commands and, in verbose mode, logs each one
+// and how it exited. Verbose mode is on when FLOW_DEBUG is true or after
+// Enable, e.g. for a --verbose flag; otherwise commands run silently, as
+// plain exec.Cmds would.
+package cmdlog
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"time"
+)
+
+// DebugEnv turns verbose mode on when set to a true value such as 1.
+const DebugEnv = "FLOW_DEBUG"
+
+// VerboseFlag turns verbose mode on when it comes before the command name.
+const VerboseFlag = "--verbose"
+
+var enabled atomic.Bool
+
+// Log is where verbose mode writes.
+var Log io.Writer = os.Stderr
+
+func init() {
+	on, err := strconv.ParseBool(strings.TrimSpace(os.Getenv(DebugEnv)))
+	enabled.Store(err == nil && on)
+}
+
+// Enable turns verbose mode on for the rest of the process.
+func Enable() { enabled.Store(true) }
+
+// Enabled reports whether commands are being logged.
+func Enabled() bool { return enabled.Load() }
+
+// EnableFromArgs drops any leading --verbose flags from args, enabling
+// verbose mode if there were some. Later ones belong to the command.
+func EnableFromArgs(args []string) []string {
+	i := 0
+	for i < len(args) && args[i] == VerboseFlag {
+		i++
+	}
+	if i > 0 {
+		Enable()
+	}
+	return args[i:]
+}
+
+// Cmd is an exec.Cmd whose Run, Output, CombinedOutput, Start, and Wait log
+// in verbose mode. Set Dir, Stdin, and the rest on it as usual.
+type Cmd struct {
+	*exec.Cmd
+	started time.Time
+}
+
+// Command is exec.CommandContext with logging. WaitDelay keeps a cancelled
+// command from waiting on children that still hold its output pipes.
+func Command(ctx context.Context, name string, args ...string) *Cmd {
+	cmd := exec.CommandContext(ctx, name, args...)
+	cmd.WaitDelay = time.Second
+	return &Cmd{Cmd: cmd}
+}
+
+func (c *Cmd) Run() error {
+	c.logStart()
+	err := c.Cmd.Run()
+	c.logExit(err)
+	return err
+}
+
+func (c *Cmd) Output() ([]byte, error) {
+	c.logStart()
+	out, err := c.Cmd.Output()
+	c.logExit(err)
+	return out, err
+}
+
+func (c *Cmd) CombinedOutput() ([]byte, error) {
+	c.logStart()
+	out, err := c.Cmd.CombinedOutput()
+	c.logExit(err)
+	return out, err
+}
+
+func (c *Cmd) Start() error {
+	c.logStart()
+	err := c.Cmd.Start()
+	if err != nil {
+		c.logExit(err)
+	}
+	return err
+}
+
+func (c *Cmd) Wait() error {
+	err := c.Cmd.Wait()
+	c.logExit(err)
+	return err
+}
+
+func (c *Cmd) logStart() {
+	if !Enabled() {
+		return
+	}
+	c.started = time.Now()
+	line := "$ " + c.String()
+	if c.Dir != "" {
+		line += " (in " + c.Dir + ")"
+	}
+	fmt.Fprintln(Log, line)
+}
+
+func (c *Cmd) logExit(err error) {
+	if !Enabled() {
+		return
+	}
+	elapsed := time.Since(c.started).Round(time.Millisecond)
+
+	var exitErr *exec.ExitError
+	switch {
+	case err == nil:
+		fmt.Fprintf(Log, "  %s exited 0 after %s\n", c.name(), elapsed)
+	case errors.As(err, &exitErr):
+		fmt.Fprintf(Log, "  %s %s after %s\n", c.name(), exitErr.ProcessState, elapsed)
+	default:
+		fmt.Fprintf(Log, "  %s failed: %v\n", c.name(), err)
+	}
+}
+
+// String quotes arguments that contain spaces or quotes, so the logged
+// line can be pasted back into a shell.
+func (c *Cmd) String() string {
+	parts := make([]string, len(c.Args))
+	for i, arg := range c.Args {
+		if arg == "" || strings.ContainsAny(arg, " \t\n'\"\\$") {
+			arg = strconv.Quote(arg)
+		}
+		parts[i] = arg
+	}
+	return strings.Join(parts, " ")
+}
+
+func (c *Cmd) name() string {
+	if len(c.Args) > 0 {
+		return c.Args[0]
+	}
+	return c.Path
+}
@@ -0,0 +1,75 @@
+package cmdlog
+
+import (
+	"bytes"
+	"os/exec"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func verbose(t *testing.T) *bytes.Buffer {
+	t.Helper()
+	if _, err := exec.LookPath("sh"); err != nil {
+		t.Skip("sh not installed")
+	}
+	var buf bytes.Buffer
+	prevLog, prevEnabled := Log, Enabled()
+	Log = &buf
+	enabled.Store(true)
+	t.Cleanup(func() {
+		Log = prevLog
+		enabled.Store(prevEnabled)
+	})
+	return &buf
+}
+
+func TestCommandLogsExitStatus(t *testing.T) {
+	buf := verbose(t)
+
+	if out, err := Command(t.Context(), "sh", "-c", "echo hi").Output(); err != nil || string(out) != "hi\n" {
+		t.Fatalf("Output() = %q, %v", out, err)
+	}
+	if err := Command(t.Context(), "sh", "-c", "exit 3").Run(); err == nil {
+		t.Fatalf("expected exit 3 to fail")
+	}
+
+	got := buf.String()
+	for _, want := range []string{
+		"$ sh -c \"echo hi\"\n",
+		"  sh exited 0 after ",
+		"$ sh -c \"exit 3\"\n",
+		"  sh exit status 3 after ",
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("log missing %q:\n%s", want, got)
+		}
+	}
+}
+
+func TestCommandSilentByDefault(t *testing.T) {
+	buf := verbose(t)
+	enabled.Store(false)
+
+	if err := Command(t.Context(), "sh", "-c", "true").Run(); err != nil {
+		t.Fatalf("Run() error: %v", err)
+	}
+	if buf.Len() != 0 {
+		t.Fatalf("expected no log output, got %q", buf.String())
+	}
+}
+
+func TestEnableFromArgs(t *testing.T) {
+	verbose(t)
+	enabled.Store(false)
+
+	args := EnableFromArgs([]string{"gitSync", "--verbose"})
+	if !reflect.DeepEqual(args, []string{"gitSync", "--verbose"}) || Enabled() {
+		t.Fatalf("a --verbose after the command belongs to it, got %q (enabled %v)", args, Enabled())
+	}
+
+	args = EnableFromArgs([]string{"--verbose", "gitSync", "-n"})
+	if !reflect.DeepEqual(args, []string{"gitSync", "-n"}) || !Enabled() {
+		t.Fatalf("expected a leading --verbose to be dropped and enabled, got %q (enabled %v)", args, Enabled())
+	}
+}
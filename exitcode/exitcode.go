@@ -0,0 +1,75 @@
+// Package exitcode maps the errors of the CLIs in this repository to
+// process exit codes, so scripts can tell a cancelled picker from a real
+// failure. Commands exit 0 on success and 1 on failure, except:
+//
+//	3    a git command ran outside a repository
+//	124  the run timed out
+//	127  a required tool or app is missing
+//	130  the user closed a picker, declined a prompt, or pressed Ctrl-C
+package exitcode
+
+import (
+	"errors"
+	"os/exec"
+
+	"lang/gitutil"
+
+	"github.com/dzonerzy/go-snap/middleware"
+	"github.com/dzonerzy/go-snap/snap"
+)
+
+const (
+	Failure           = 1
+	NotGitRepo        = 3
+	Timeout           = 124
+	MissingDependency = 127
+	UserAbort         = 130
+)
+
+var (
+	// ErrUserAbort means the user closed a picker or declined a prompt.
+	// Commands print their own "cancelled" note, so it is never shown.
+	ErrUserAbort = errors.New("aborted")
+
+	// ErrMissingDependency marks a missing external tool or app. Errors
+	// wrapping exec.ErrNotFound from exec.LookPath count too.
+	ErrMissingDependency = errors.New("missing dependency")
+
+	// ErrTimeout marks a command cut short by a run timeout.
+	ErrTimeout = errors.New("timed out")
+)
+
+// For returns the exit code for err; errors outside the list exit 1.
+func For(err error) int {
+	switch {
+	case err == nil:
+		return 0
+	case errors.Is(err, ErrUserAbort):
+		return UserAbort
+	case errors.Is(err, ErrMissingDependency), errors.Is(err, exec.ErrNotFound):
+		return MissingDependency
+	case errors.Is(err, gitutil.ErrNotRepository):
+		return NotGitRepo
+	case errors.Is(err, ErrTimeout):
+		return Timeout
+	default:
+		return Failure
+	}
+}
+
+// Wrap wraps err so snap exits with the code For picks. An ExitError a
+// command already chose is kept as is.
+func Wrap(err error) error {
+	var exitErr *snap.ExitError
+	if err == nil || errors.As(err, &exitErr) {
+		return err
+	}
+	return &snap.ExitError{Code: For(err), Err: err}
+}
+
+// Middleware applies Wrap to every action of the app it is used on.
+func Middleware(next middleware.ActionFunc) middleware.ActionFunc {
+	return func(ctx middleware.Context) error {
+		return Wrap(next(ctx))
+	}
+}
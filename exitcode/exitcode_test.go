@@ -0,0 +1,49 @@
+package exitcode
+
+import (
+	"errors"
+	"fmt"
+	"os/exec"
+	"testing"
+
+	"lang/gitutil"
+
+	"github.com/dzonerzy/go-snap/snap"
+)
+
+func TestFor(t *testing.T) {
+	_, lookErr := exec.LookPath("exitcode-test-no-such-binary")
+	tests := []struct {
+		err  error
+		want int
+	}{
+		{nil, 0},
+		{errors.New("boom"), Failure},
+		{ErrUserAbort, UserAbort},
+		{fmt.Errorf("%w: TablePlus.app not found", ErrMissingDependency), MissingDependency},
+		{fmt.Errorf("gh CLI not found in PATH: %w", lookErr), MissingDependency},
+		{fmt.Errorf("wrapped: %w", gitutil.ErrNotRepository), NotGitRepo},
+		{fmt.Errorf("%w after 1s: signal: killed", ErrTimeout), Timeout},
+	}
+
+	for _, tt := range tests {
+		if got := For(tt.err); got != tt.want {
+			t.Errorf("For(%v) = %d, want %d", tt.err, got, tt.want)
+		}
+	}
+}
+
+func TestWrapKeepsChosenCode(t *testing.T) {
+	chosen := &snap.ExitError{Code: 42, Err: errors.New("child exited 42")}
+	if got := Wrap(chosen); got != chosen {
+		t.Fatalf("Wrap replaced %v with %v", chosen, got)
+	}
+
+	var exitErr *snap.ExitError
+	if !errors.As(Wrap(ErrUserAbort), &exitErr) || exitErr.Code != UserAbort {
+		t.Fatalf("Wrap(ErrUserAbort) = %v, want code %d", exitErr, UserAbort)
+	}
+	if Wrap(nil) != nil {
+		t.Fatal("Wrap(nil) should stay nil")
+	}
+}
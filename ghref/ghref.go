@@ -0,0 +1,202 @@
+// Package ghref parses references to GitHub pull requests and issues, so
+// every CLI in this repository accepts the same spellings.
+package ghref
+
+import (
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+// Kind says whether a reference names a pull request or an issue. Short
+// forms such as owner/repo#123 don't say, so they parse as Unknown and the
+// caller decides.
+type Kind int
+
+const (
+	Unknown Kind = iota
+	Pull
+	Issue
+)
+
+// Ref is a parsed reference. Owner and Repo are empty when the input was a
+// bare number such as "123" or "#123".
+type Ref struct {
+	Owner  string
+	Repo   string
+	Number int
+	Kind   Kind
+}
+
+// HasRepo reports whether the reference named its repository.
+func (r Ref) HasRepo() bool {
+	return r.Owner != "" && r.Repo != ""
+}
+
+// FullName returns "owner/repo".
+func (r Ref) FullName() string {
+	return r.Owner + "/" + r.Repo
+}
+
+// Parse accepts:
+//
+//	https://github.com/owner/repo/pull/123   (also /pulls/, /issues/, extra
+//	                                          path segments, query strings,
+//	                                          fragments, and a .git suffix)
+//	github.com/owner/repo/pull/123
+//	owner/repo/pull/123
+//	owner/repo#123
+//	123 or #123
+//
+// URLs must point at github.com.
+func Parse(input string) (Ref, error) {
+	candidate := strings.TrimSpace(input)
+	if candidate == "" {
+		return Ref{}, fmt.Errorf("reference cannot be empty")
+	}
+
+	if number, ok := ParseNumber(strings.TrimPrefix(candidate, "#")); ok && !strings.Contains(candidate, "/") {
+		return Ref{Number: number}, nil
+	}
+
+	hasScheme := strings.HasPrefix(candidate, "http://") || strings.HasPrefix(candidate, "https://")
+	if !hasScheme && isGitHubHostPrefix(candidate) {
+		candidate = "https://" + candidate
+		hasScheme = true
+	}
+
+	if hasScheme {
+		u, err := url.Parse(candidate)
+		if err != nil {
+			return Ref{}, fmt.Errorf("parse url %q: %w", input, err)
+		}
+		host := strings.TrimPrefix(strings.ToLower(u.Host), "www.")
+		if host != "github.com" {
+			return Ref{}, fmt.Errorf("expected github.com host, got %s", u.Host)
+		}
+		ref, ok := parsePath(strings.Split(strings.Trim(u.Path, "/"), "/"))
+		if !ok {
+			return Ref{}, fmt.Errorf("expected GitHub pull request or issue URL, got %q", input)
+		}
+		return ref, nil
+	}
+
+	if hash := strings.Index(candidate, "#"); hash > 0 {
+		owner, repo, err := SplitOwnerRepo(candidate[:hash])
+		if err != nil {
+			return Ref{}, err
+		}
+		numberPart := strings.TrimSpace(candidate[hash+1:])
+		number, ok := ParseNumber(numberPart)
+		if !ok {
+			return Ref{}, fmt.Errorf("invalid number %q", numberPart)
+		}
+		return Ref{Owner: owner, Repo: repo, Number: number}, nil
+	}
+
+	if ref, ok := parsePath(strings.Split(strings.Trim(candidate, "/"), "/")); ok {
+		return ref, nil
+	}
+
+	return Ref{}, fmt.Errorf("expected GitHub PR/issue URL, owner/repo#N, or a number, got %q", input)
+}
+
+// Guess extracts whatever it can from free text such as clipboard contents
+// or a half-typed prompt answer, on any host. Missing parts stay zero; use
+// HasRepo and Number to see what was found.
+func Guess(input string) Ref {
+	candidate := strings.TrimSpace(input)
+	if ref, err := Parse(candidate); err == nil {
+		return ref
+	}
+
+	var ref Ref
+	if hash := strings.Index(candidate, "#"); hash > 0 {
+		if owner, repo, err := SplitOwnerRepo(candidate[:hash]); err == nil {
+			ref.Owner, ref.Repo = owner, repo
+		}
+		if n, ok := ParseNumber(candidate[hash+1:]); ok {
+			ref.Number = n
+		}
+		return ref
+	}
+
+	path := candidate
+	if u, err := url.Parse(candidate); err == nil && u.Host != "" {
+		path = u.Path
+	}
+	segments := strings.Split(strings.Trim(path, "/"), "/")
+	if parsed, ok := parsePath(segments); ok {
+		return parsed
+	}
+	if len(segments) >= 2 {
+		if owner, repo, err := SplitOwnerRepo(segments[0] + "/" + segments[1]); err == nil {
+			ref.Owner, ref.Repo = owner, repo
+		}
+	}
+	return ref
+}
+
+// parsePath reads owner/repo/(pull|pulls|issues)/N[/...] path segments.
+func parsePath(segments []string) (Ref, bool) {
+	if len(segments) < 4 {
+		return Ref{}, false
+	}
+	owner, repo, err := SplitOwnerRepo(segments[0] + "/" + segments[1])
+	if err != nil {
+		return Ref{}, false
+	}
+
+	var kind Kind
+	switch segments[2] {
+	case "pull", "pulls":
+		kind = Pull
+	case "issues":
+		kind = Issue
+	default:
+		return Ref{}, false
+	}
+
+	number, ok := ParseNumber(segments[3])
+	if !ok {
+		return Ref{}, false
+	}
+	return Ref{Owner: owner, Repo: repo, Number: number, Kind: kind}, true
+}
+
+func isGitHubHostPrefix(candidate string) bool {
+	lower := strings.ToLower(candidate)
+	return strings.HasPrefix(lower, "github.com/") || strings.HasPrefix(lower, "www.github.com/")
+}
+
+// SplitOwnerRepo splits "owner/repo", dropping a .git suffix.
+func SplitOwnerRepo(value string) (string, string, error) {
+	parts := strings.Split(strings.Trim(strings.TrimSpace(value), "/"), "/")
+	if len(parts) != 2 {
+		return "", "", fmt.Errorf("invalid repo format %q, expected owner/repo", value)
+	}
+	owner := strings.TrimSpace(parts[0])
+	repo := strings.TrimSuffix(strings.TrimSpace(parts[1]), ".git")
+	if owner == "" || repo == "" || strings.ContainsAny(owner+repo, " \t") {
+		return "", "", fmt.Errorf("invalid repo format %q, expected owner/repo", value)
+	}
+	return owner, repo, nil
+}
+
+// ParseNumber parses a positive number, ignoring a trailing query string or
+// fragment as found in copied URLs.
+func ParseNumber(raw string) (int, bool) {
+	trimmed := strings.TrimSpace(raw)
+	if idx := strings.IndexAny(trimmed, "?#"); idx >= 0 {
+		trimmed = strings.TrimSpace(trimmed[:idx])
+	}
+	if trimmed == "" {
+		return 0, false
+	}
+	number, err := strconv.Atoi(trimmed)
+	if err != nil || number <= 0 {
+		return 0, false
+	}
+	return number, true
+}
@@ -0,0 +1,84 @@
+package ghref
+
+import "testing"
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		input string
+		want  Ref
+	}{
+		{"https://github.com/nikivdev/go/pull/12", Ref{"nikivdev", "go", 12, Pull}},
+		{"https://github.com/nikivdev/go/pull/12/", Ref{"nikivdev", "go", 12, Pull}},
+		{"https://github.com/nikivdev/go/pull/12/files", Ref{"nikivdev", "go", 12, Pull}},
+		{"https://github.com/nikivdev/go/pull/12?diff=split", Ref{"nikivdev", "go", 12, Pull}},
+		{"https://github.com/nikivdev/go/pull/12#discussion_r1", Ref{"nikivdev", "go", 12, Pull}},
+		{"https://github.com/nikivdev/go/pulls/12", Ref{"nikivdev", "go", 12, Pull}},
+		{"https://github.com/nikivdev/go.git/pull/12", Ref{"nikivdev", "go", 12, Pull}},
+		{"http://www.github.com/nikivdev/go/pull/12", Ref{"nikivdev", "go", 12, Pull}},
+		{"https://github.com/nikivdev/go/issues/7", Ref{"nikivdev", "go", 7, Issue}},
+		{"https://github.com/nikivdev/go/issues/7?q=1", Ref{"nikivdev", "go", 7, Issue}},
+		{"github.com/nikivdev/go/pull/12", Ref{"nikivdev", "go", 12, Pull}},
+		{"nikivdev/go/pull/12", Ref{"nikivdev", "go", 12, Pull}},
+		{"nikivdev/go/issues/7/", Ref{"nikivdev", "go", 7, Issue}},
+		{"nikivdev/go#12", Ref{"nikivdev", "go", 12, Unknown}},
+		{" nikivdev/go.git#12 ", Ref{"nikivdev", "go", 12, Unknown}},
+		{"nikivdev/go/#12", Ref{"nikivdev", "go", 12, Unknown}},
+		{"12", Ref{Number: 12}},
+		{"#12", Ref{Number: 12}},
+		{"12?x=1", Ref{Number: 12}},
+	}
+
+	for _, tt := range tests {
+		got, err := Parse(tt.input)
+		if err != nil {
+			t.Errorf("Parse(%q) error: %v", tt.input, err)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("Parse(%q) = %+v, want %+v", tt.input, got, tt.want)
+		}
+	}
+}
+
+func TestParseRejects(t *testing.T) {
+	for _, input := range []string{
+		"",
+		"   ",
+		"0",
+		"-3",
+		"abc",
+		"nikivdev/go",
+		"nikivdev/go#",
+		"nikivdev/go#abc",
+		"nikivdev#12",
+		"a/b/c#12",
+		"https://gitlab.com/nikivdev/go/pull/12",
+		"https://github.com/nikivdev/go",
+		"https://github.com/nikivdev/go/tree/main",
+		"https://github.com/nikivdev/go/pull/abc",
+	} {
+		if got, err := Parse(input); err == nil {
+			t.Errorf("Parse(%q) = %+v, want error", input, got)
+		}
+	}
+}
+
+func TestGuess(t *testing.T) {
+	tests := []struct {
+		input string
+		want  Ref
+	}{
+		{"https://gitlab.com/nikivdev/go/pull/12", Ref{"nikivdev", "go", 12, Pull}},
+		{"https://github.com/nikivdev/go", Ref{Owner: "nikivdev", Repo: "go"}},
+		{"nikivdev/go", Ref{Owner: "nikivdev", Repo: "go"}},
+		{"nikivdev/go#", Ref{Owner: "nikivdev", Repo: "go"}},
+		{"42", Ref{Number: 42}},
+		{"not a ref", Ref{}},
+	}
+
+	for _, tt := range tests {
+		if got := Guess(tt.input); got != tt.want {
+			t.Errorf("Guess(%q) = %+v, want %+v", tt.input, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,136 @@
+// Package gitutil holds the git helpers shared by the fgo binaries. Every
+// helper runs git in the current working directory.
+package gitutil
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os/exec"
+	"strings"
+
+	"lang/cmdlog"
+)
+
+// command runs git under ctx, logged in verbose mode.
+func command(ctx context.Context, args ...string) *cmdlog.Cmd {
+	return cmdlog.Command(ctx, "git", args...)
+}
+
+// ErrNotRepository matches, via errors.Is, the error EnsureRepository
+// returns outside a work tree.
+var ErrNotRepository = errors.New("not inside a git repository")
+
+// notRepositoryError keeps git's own message while matching
+// ErrNotRepository.
+type notRepositoryError struct {
+	msg string
+}
+
+func (e *notRepositoryError) Error() string { return e.msg }
+
+func (e *notRepositoryError) Unwrap() error { return ErrNotRepository }
+
+// EnsureRepository returns an error unless the working directory is inside
+// a git work tree.
+func EnsureRepository(ctx context.Context) error {
+	cmd := command(ctx, "rev-parse", "--is-inside-work-tree")
+	out, err := cmd.CombinedOutput()
+	if err != nil {
+		trimmed := strings.TrimSpace(string(out))
+		if trimmed != "" {
+			return &notRepositoryError{msg: trimmed}
+		}
+		return fmt.Errorf("git rev-parse --is-inside-work-tree: %w", err)
+	}
+
+	if strings.TrimSpace(string(out)) != "true" {
+		return ErrNotRepository
+	}
+
+	return nil
+}
+
+// RefExists reports whether ref resolves to an object. A missing ref is not
+// an error.
+func RefExists(ctx context.Context, ref string) (bool, error) {
+	cmd := command(ctx, "rev-parse", "--verify", "--quiet", ref)
+	if err := cmd.Run(); err != nil {
+		var exitErr *exec.ExitError
+		if errors.As(err, &exitErr) {
+			return false, nil
+		}
+		return false, err
+	}
+
+	return true, nil
+}
+
+// Remotes lists the configured remote names, failing when there are none.
+func Remotes(ctx context.Context) ([]string, error) {
+	out, err := command(ctx, "remote").Output()
+	if err != nil {
+		return nil, fmt.Errorf("git remote: %w", err)
+	}
+
+	trimmed := strings.TrimSpace(string(out))
+	if trimmed == "" {
+		return nil, fmt.Errorf("no git remotes configured")
+	}
+
+	lines := strings.Split(trimmed, "\n")
+	remotes := make([]string, 0, len(lines))
+	for _, line := range lines {
+		name := strings.TrimSpace(line)
+		if name != "" {
+			remotes = append(remotes, name)
+		}
+	}
+
+	if len(remotes) == 0 {
+		return nil, fmt.Errorf("no git remotes configured")
+	}
+
+	return remotes, nil
+}
+
+// CurrentBranch returns the checked-out branch name, or "HEAD" when
+// detached.
+func CurrentBranch(ctx context.Context) (string, error) {
+	out, err := command(ctx, "rev-parse", "--abbrev-ref", "HEAD").Output()
+	if err != nil {
+		trimmed := strings.TrimSpace(string(out))
+		if trimmed != "" {
+			return "", fmt.Errorf("%s", trimmed)
+		}
+		return "", fmt.Errorf("git rev-parse --abbrev-ref HEAD: %w", err)
+	}
+
+	branch := strings.TrimSpace(string(out))
+	return branch, nil
+}
+
+// DefaultBranch returns the current branch, else the branch origin/HEAD
+// points at, else "main".
+func DefaultBranch(ctx context.Context) string {
+	out, err := command(ctx, "rev-parse", "--abbrev-ref", "HEAD").Output()
+	if err == nil {
+		current := strings.TrimSpace(string(out))
+		if current != "" && current != "HEAD" {
+			return current
+		}
+	}
+
+	out, err = command(ctx, "symbolic-ref", "refs/remotes/origin/HEAD").Output()
+	if err == nil {
+		trimmed := strings.TrimSpace(string(out))
+		if trimmed != "" {
+			parts := strings.Split(trimmed, "/")
+			if len(parts) > 0 {
+				return parts[len(parts)-1]
+			}
+		}
+	}
+
+	return "main"
+}
@@ -0,0 +1,96 @@
+package gitutil
+
+import (
+	"errors"
+	"os/exec"
+	"reflect"
+	"testing"
+)
+
+func newRepo(t *testing.T) {
+	t.Helper()
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not installed")
+	}
+	t.Chdir(t.TempDir())
+	t.Setenv("GIT_CONFIG_GLOBAL", "/dev/null")
+	t.Setenv("GIT_AUTHOR_NAME", "test")
+	t.Setenv("GIT_AUTHOR_EMAIL", "test@example.com")
+	t.Setenv("GIT_COMMITTER_NAME", "test")
+	t.Setenv("GIT_COMMITTER_EMAIL", "test@example.com")
+	git(t, "init", "-q", "-b", "trunk")
+	git(t, "commit", "-q", "--allow-empty", "-m", "initial")
+}
+
+func git(t *testing.T, args ...string) {
+	t.Helper()
+	if out, err := exec.Command("git", args...).CombinedOutput(); err != nil {
+		t.Fatalf("git %v: %v\n%s", args, err, out)
+	}
+}
+
+func TestEnsureRepository(t *testing.T) {
+	t.Chdir(t.TempDir())
+	if err := EnsureRepository(t.Context()); !errors.Is(err, ErrNotRepository) {
+		t.Fatalf("expected ErrNotRepository outside a repository, got %v", err)
+	}
+
+	newRepo(t)
+	if err := EnsureRepository(t.Context()); err != nil {
+		t.Fatalf("EnsureRepository(t.Context()) error: %v", err)
+	}
+}
+
+func TestRefExists(t *testing.T) {
+	newRepo(t)
+	for ref, want := range map[string]bool{"HEAD": true, "trunk": true, "missing": false} {
+		got, err := RefExists(t.Context(), ref)
+		if err != nil {
+			t.Fatalf("RefExists(%q) error: %v", ref, err)
+		}
+		if got != want {
+			t.Errorf("RefExists(%q) = %v, want %v", ref, got, want)
+		}
+	}
+}
+
+func TestRemotes(t *testing.T) {
+	newRepo(t)
+	if _, err := Remotes(t.Context()); err == nil {
+		t.Fatalf("expected an error with no remotes")
+	}
+
+	git(t, "remote", "add", "origin", "https://example.com/a.git")
+	git(t, "remote", "add", "upstream", "https://example.com/b.git")
+	got, err := Remotes(t.Context())
+	if err != nil {
+		t.Fatalf("Remotes(t.Context()) error: %v", err)
+	}
+	if want := []string{"origin", "upstream"}; !reflect.DeepEqual(got, want) {
+		t.Fatalf("Remotes(t.Context()) = %q, want %q", got, want)
+	}
+}
+
+func TestCurrentAndDefaultBranch(t *testing.T) {
+	newRepo(t)
+	if got, err := CurrentBranch(t.Context()); err != nil || got != "trunk" {
+		t.Fatalf("CurrentBranch(t.Context()) = %q, %v; want trunk", got, err)
+	}
+	if got := DefaultBranch(t.Context()); got != "trunk" {
+		t.Fatalf("DefaultBranch(t.Context()) = %q, want trunk", got)
+	}
+
+	git(t, "checkout", "-q", "--detach")
+	if got, err := CurrentBranch(t.Context()); err != nil || got != "HEAD" {
+		t.Fatalf("detached CurrentBranch(t.Context()) = %q, %v; want HEAD", got, err)
+	}
+	if got := DefaultBranch(t.Context()); got != "main" {
+		t.Fatalf("detached DefaultBranch(t.Context()) without origin = %q, want main", got)
+	}
+
+	git(t, "update-ref", "refs/remotes/origin/develop", "HEAD")
+	git(t, "symbolic-ref", "refs/remotes/origin/HEAD", "refs/remotes/origin/develop")
+	if got := DefaultBranch(t.Context()); got != "develop" {
+		t.Fatalf("detached DefaultBranch(t.Context()) = %q, want develop from origin/HEAD", got)
+	}
+}
@@ -4,6 +4,7 @@ go 1.24
 
 require (
 	github.com/dzonerzy/go-snap v0.1.1
+	github.com/gomarkdown/markdown v0.0.0-20250810172220-2e2c11897d1a
 	github.com/ktr0731/go-fuzzyfinder v0.9.0
 	github.com/severity1/claude-code-sdk-go v0.0.0
 	gopkg.in/yaml.v3 v3.0.1
@@ -14,7 +15,6 @@ replace github.com/severity1/claude-code-sdk-go => /Users/nikiv/fork-i/severity1
 require (
 	github.com/gdamore/encoding v1.0.1 // indirect
 	github.com/gdamore/tcell/v2 v2.6.0 // indirect
-	github.com/gomarkdown/markdown v0.0.0-20250810172220-2e2c11897d1a // indirect
 	github.com/ktr0731/go-ansisgr v0.1.0 // indirect
 	github.com/lucasb-eyer/go-colorful v1.2.0 // indirect
 	github.com/mattn/go-runewidth v0.0.16 // indirect
@@ -3,6 +3,7 @@ package main
 import (
 	"bufio"
 	"bytes"
+	"context"
 	"errors"
 	"fmt"
 	"io"
@@ -18,6 +19,12 @@ import (
 	"syscall"
 	"time"
 
+	"lang/exitcode"
+	"lang/ghref"
+	"lang/ports"
+	"lang/skipdirs"
+	"lang/userpath"
+
 	"github.com/dzonerzy/go-snap/snap"
 	"github.com/gomarkdown/markdown"
 	"github.com/gomarkdown/markdown/html"
@@ -38,17 +45,8 @@ var (
 	errSymlinkCandidateLimit   = errors.New("symlink candidate limit reached")
 )
 
-var symlinkSkipDirectories = map[string]struct{}{
-	".git":         {},
-	".idea":        {},
-	".vscode":      {},
-	".cache":       {},
-	"node_modules": {},
-	"vendor":       {},
-	"dist":         {},
-	"build":        {},
-	"target":       {},
-}
+// skipDirs are the directory names tree walks never descend into.
+var skipDirs = skipdirs.FromEnv()
 
 type symlinkOption struct {
 	Path   string
@@ -59,7 +57,8 @@ type symlinkOption struct {
 func main() {
 	app := snap.New(flowName, "fgo is CLI to do things fast").
 		Version(flowVersion).
-		DisableHelp()
+		DisableHelp().
+		Use(exitcode.Middleware)
 
 	app.Command("updateGoVersion", "Upgrade Go using the workspace script").
 		Action(func(ctx *snap.Context) error {
@@ -89,7 +88,7 @@ func main() {
 				return fmt.Errorf("expected at most 1 argument, got %d", ctx.NArgs())
 			}
 
-			processes, err := listListeningProcesses()
+			processes, err := ports.Listening(context.Background())
 			if err != nil {
 				return err
 			}
@@ -133,7 +132,7 @@ func main() {
 			)
 			if err != nil {
 				if errors.Is(err, fuzzyfinder.ErrAbort) {
-					return nil
+					return exitcode.ErrUserAbort
 				}
 				return fmt.Errorf("select port: %w", err)
 			}
@@ -229,7 +228,7 @@ func main() {
 			}
 			if !proceed {
 				fmt.Fprintln(ctx.Stdout(), "Aborted.")
-				return nil
+				return exitcode.ErrUserAbort
 			}
 
 			if err := os.MkdirAll(filepath.Dir(dest), 0o755); err != nil {
@@ -278,7 +277,7 @@ func main() {
 				return fmt.Errorf("link path cannot be empty")
 			}
 
-			linkPath, err := expandUserPath(rawLink)
+			linkPath, err := userpath.Expand(rawLink)
 			if err != nil {
 				return fmt.Errorf("expand link path: %w", err)
 			}
@@ -289,7 +288,7 @@ func main() {
 			if err != nil {
 				if errors.Is(err, errSymlinkSelectionAborted) {
 					fmt.Fprintln(ctx.Stdout(), "Aborted.")
-					return nil
+					return exitcode.ErrUserAbort
 				}
 				return err
 			}
@@ -463,35 +462,29 @@ func main() {
 		})
 
 	app.Command("openMd", "Convert a markdown file to HTML and open it in the browser").
+		StringFlag("output-dir", "Directory to write the HTML to (default: the system temp dir)").Short('o').Back().
 		Action(func(ctx *snap.Context) error {
 			if ctx.NArgs() != 1 {
-				fmt.Fprintf(ctx.Stderr(), "Usage: %s openMd <path-to-file.md>\n", flowName)
+				fmt.Fprintf(ctx.Stderr(), "Usage: %s openMd [-o <dir>] <path-to-file.md>\n", flowName)
 				return fmt.Errorf("expected 1 argument, got %d", ctx.NArgs())
 			}
 
-			mdPath := strings.TrimSpace(ctx.Arg(0))
-			if mdPath == "" {
-				fmt.Fprintf(ctx.Stderr(), "Usage: %s openMd <path-to-file.md>\n", flowName)
-				return fmt.Errorf("file path cannot be empty")
-			}
-
-			if !strings.HasSuffix(mdPath, ".md") {
-				mdPath = mdPath + ".md"
-			}
-
-			mdContent, err := os.ReadFile(mdPath)
+			mdPath, err := markdownPath(ctx.Arg(0))
 			if err != nil {
-				return fmt.Errorf("read %s: %w", mdPath, err)
+				fmt.Fprintf(ctx.Stderr(), "Usage: %s openMd [-o <dir>] <path-to-file.md>\n", flowName)
+				return err
 			}
 
-			htmlContent := mdToHTML(mdContent)
-
-			baseName := filepath.Base(mdPath)
-			htmlName := strings.TrimSuffix(baseName, ".md") + ".html"
-			htmlPath := filepath.Join(os.TempDir(), htmlName)
+			htmlDir := os.TempDir()
+			if dir, ok := ctx.String("output-dir"); ok && strings.TrimSpace(dir) != "" {
+				if htmlDir, err = userpath.PrepareDir(dir); err != nil {
+					return err
+				}
+			}
+			htmlPath := filepath.Join(htmlDir, markdownHTMLName(mdPath))
 
-			if err := os.WriteFile(htmlPath, htmlContent, 0o644); err != nil {
-				return fmt.Errorf("write %s: %w", htmlPath, err)
+			if err := renderMarkdownFile(mdPath, htmlPath, nil); err != nil {
+				return err
 			}
 
 			openCmd := exec.Command("open", htmlPath)
@@ -501,6 +494,46 @@ func main() {
 				return fmt.Errorf("open %s: %w", htmlPath, err)
 			}
 
+			fmt.Fprintf(ctx.Stdout(), "Wrote %s\n", htmlPath)
+			return nil
+		})
+
+	app.Command("mdToHtml", "Convert a markdown file to HTML without opening it").
+		StringFlag("output", "File or directory to write the HTML to (default: next to the markdown file)").Short('o').Back().
+		BoolFlag("stdout", "Write the HTML to stdout instead of a file").Back().
+		Action(func(ctx *snap.Context) error {
+			usage := func() {
+				fmt.Fprintf(ctx.Stderr(), "Usage: %s mdToHtml [-o <path>] [--stdout] <path-to-file.md>\n", flowName)
+			}
+			if ctx.NArgs() != 1 {
+				usage()
+				return fmt.Errorf("expected 1 argument, got %d", ctx.NArgs())
+			}
+
+			mdPath, err := markdownPath(ctx.Arg(0))
+			if err != nil {
+				usage()
+				return err
+			}
+
+			output, _ := ctx.String("output")
+			output = strings.TrimSpace(output)
+			if toStdout, _ := ctx.Bool("stdout"); toStdout {
+				if output != "" {
+					usage()
+					return fmt.Errorf("--stdout and --output cannot be combined")
+				}
+				return renderMarkdownFile(mdPath, "", ctx.Stdout())
+			}
+
+			htmlPath, err := markdownOutputPath(mdPath, output)
+			if err != nil {
+				return err
+			}
+			if err := renderMarkdownFile(mdPath, htmlPath, nil); err != nil {
+				return err
+			}
+			fmt.Fprintf(ctx.Stdout(), "Wrote %s\n", htmlPath)
 			return nil
 		})
 
@@ -615,16 +648,31 @@ func printCommandHelp(name string, out io.Writer) bool {
 		fmt.Fprintln(out)
 		fmt.Fprintln(out, "Usage:")
 		fmt.Fprintf(out, "  %s workspacePaths [list] [list|add|remove] [path] [-f|--file workspace.json]\n", flowName)
+		fmt.Fprintf(out, "  %s workspacePaths [list] browse [root]\n", flowName)
+		fmt.Fprintf(out, "  %s workspacePaths check\n", flowName)
 		fmt.Fprintln(out)
 		fmt.Fprintln(out, "Lists: repo (default), expanded, selection, files")
+		fmt.Fprintln(out, "browse walks root (default: the current directory) and adds every directory marked")
+		fmt.Fprintln(out, "with Tab in the picker.")
+		fmt.Fprintln(out, "check lists entries in every list whose path no longer exists, without changing the file.")
 		return true
 	case "openMd":
 		fmt.Fprintln(out, "Convert a markdown file to HTML and open it in the browser")
 		fmt.Fprintln(out)
 		fmt.Fprintln(out, "Usage:")
-		fmt.Fprintf(out, "  %s openMd <path-to-file>\n", flowName)
+		fmt.Fprintf(out, "  %s openMd [-o <dir>] <path-to-file>\n", flowName)
+		fmt.Fprintln(out)
+		fmt.Fprintln(out, "The .md extension is added automatically if not provided. The HTML is written to the system")
+		fmt.Fprintln(out, "temp dir, or to --output-dir/-o (created if missing), and opened from there.")
+		return true
+	case "mdToHtml":
+		fmt.Fprintln(out, "Convert a markdown file to HTML without opening it")
+		fmt.Fprintln(out)
+		fmt.Fprintln(out, "Usage:")
+		fmt.Fprintf(out, "  %s mdToHtml [-o <path>] [--stdout] <path-to-file>\n", flowName)
 		fmt.Fprintln(out)
-		fmt.Fprintln(out, "The .md extension is added automatically if not provided.")
+		fmt.Fprintln(out, "Renders the same page as openMd. It goes next to the markdown file unless --output/-o names")
+		fmt.Fprintln(out, "a file, or a directory (an existing one, or a path ending in /), or --stdout prints it.")
 		return true
 	case "privateForkRepoAndOpen":
 		fmt.Fprintln(out, "Clone a public repo into ~/fork-i, set up remotes, and open in Zed")
@@ -655,6 +703,7 @@ func printRootHelp(out io.Writer) {
 	fmt.Fprintln(out, "  tasks            List Taskfile tasks with descriptions")
 	fmt.Fprintln(out, "  workspacePaths   List/add/remove path lists inside RepoPrompt workspace.json")
 	fmt.Fprintln(out, "  openMd           Convert a markdown file to HTML and open in browser")
+	fmt.Fprintln(out, "  mdToHtml         Convert a markdown file to HTML without opening it")
 	fmt.Fprintln(out, "  privateForkRepoAndOpen  Clone public repo to ~/fork-i and open in Zed")
 	fmt.Fprintln(out, "  version          Reports the current version of flow")
 	fmt.Fprintln(out)
@@ -765,7 +814,7 @@ func gatherSymlinkOptions(root string) ([]symlinkOption, error) {
 		if path == root {
 			return nil
 		}
-		if d.IsDir() && shouldSkipSymlinkDir(d.Name()) {
+		if d.IsDir() && shouldSkip(d.Name()) {
 			return filepath.SkipDir
 		}
 
@@ -799,9 +848,8 @@ func gatherSymlinkOptions(root string) ([]symlinkOption, error) {
 	return options, nil
 }
 
-func shouldSkipSymlinkDir(name string) bool {
-	_, skip := symlinkSkipDirectories[name]
-	return skip
+func shouldSkip(name string) bool {
+	return skipDirs.Skip(name)
 }
 
 func promptCustomSymlinkPath(out io.Writer, in io.Reader) (string, error) {
@@ -823,7 +871,7 @@ func promptCustomSymlinkPath(out io.Writer, in io.Reader) (string, error) {
 			fmt.Fprintln(out, "Path cannot be empty.")
 			continue
 		}
-		expanded, err := expandUserPath(path)
+		expanded, err := userpath.Expand(path)
 		if err != nil {
 			fmt.Fprintf(out, "Invalid path: %v\n", err)
 			continue
@@ -832,32 +880,6 @@ func promptCustomSymlinkPath(out io.Writer, in io.Reader) (string, error) {
 	}
 }
 
-func expandUserPath(path string) (string, error) {
-	path = strings.TrimSpace(path)
-	if path == "" {
-		return "", fmt.Errorf("path cannot be empty")
-	}
-	if path[0] != '~' {
-		return path, nil
-	}
-
-	home, err := os.UserHomeDir()
-	if err != nil {
-		return "", fmt.Errorf("determine home directory: %w", err)
-	}
-
-	if len(path) == 1 {
-		return home, nil
-	}
-
-	switch path[1] {
-	case '/', '\\':
-		return filepath.Join(home, path[2:]), nil
-	default:
-		return "", fmt.Errorf("unsupported ~ expansion in %q", path)
-	}
-}
-
 func determineNextTryBranchName() (string, error) {
 	branches, err := listGitBranches()
 	if err != nil {
@@ -922,73 +944,6 @@ func openCurrentDirectory(out io.Writer) error {
 	return cmd.Run()
 }
 
-type listeningProcess struct {
-	Command string
-	User    string
-	PID     int
-	Address string
-	Port    string
-	Raw     string
-}
-
-func listListeningProcesses() ([]listeningProcess, error) {
-	var stdout bytes.Buffer
-	var stderr bytes.Buffer
-
-	cmd := exec.Command("lsof", "-nP", "-iTCP", "-sTCP:LISTEN")
-	cmd.Stdout = &stdout
-	cmd.Stderr = &stderr
-
-	if err := cmd.Run(); err != nil {
-		msg := strings.TrimSpace(stderr.String())
-		if msg != "" {
-			return nil, fmt.Errorf("list listening ports: %s: %w", msg, err)
-		}
-		return nil, fmt.Errorf("list listening ports: %w", err)
-	}
-
-	scanner := bufio.NewScanner(&stdout)
-	var processes []listeningProcess
-	firstLine := true
-	for scanner.Scan() {
-		line := scanner.Text()
-		if firstLine {
-			firstLine = false
-			continue
-		}
-		fields := strings.Fields(line)
-		if len(fields) < 9 {
-			continue
-		}
-
-		pid, err := strconv.Atoi(fields[1])
-		if err != nil {
-			continue
-		}
-
-		address := fields[len(fields)-2]
-		port := address
-		if idx := strings.LastIndex(address, ":"); idx >= 0 && idx+1 < len(address) {
-			port = address[idx+1:]
-		}
-
-		processes = append(processes, listeningProcess{
-			Command: fields[0],
-			User:    fields[2],
-			PID:     pid,
-			Address: address,
-			Port:    port,
-			Raw:     line,
-		})
-	}
-
-	if err := scanner.Err(); err != nil {
-		return nil, fmt.Errorf("scan lsof output: %w", err)
-	}
-
-	return processes, nil
-}
-
 func killProcess(pid int) error {
 	if err := syscall.Kill(pid, syscall.SIGTERM); err != nil {
 		if errors.Is(err, syscall.ESRCH) {
@@ -999,8 +954,8 @@ func killProcess(pid int) error {
 	return nil
 }
 
-func filterProcessesByPort(processes []listeningProcess, targetPort string) []listeningProcess {
-	var filtered []listeningProcess
+func filterProcessesByPort(processes []ports.Process, targetPort string) []ports.Process {
+	var filtered []ports.Process
 	for _, p := range processes {
 		if p.Port == targetPort {
 			filtered = append(filtered, p)
@@ -1009,9 +964,9 @@ func filterProcessesByPort(processes []listeningProcess, targetPort string) []li
 	return filtered
 }
 
-func uniqueByPID(processes []listeningProcess) []listeningProcess {
+func uniqueByPID(processes []ports.Process) []ports.Process {
 	seen := make(map[int]struct{})
-	var unique []listeningProcess
+	var unique []ports.Process
 	for _, p := range processes {
 		if _, ok := seen[p.PID]; ok {
 			continue
@@ -1058,78 +1013,12 @@ func promptPullRequestDetails(out io.Writer, in io.Reader, initial string) (stri
 }
 
 func guessPullRequestDetails(input string) (string, int, bool, bool) {
-	candidate := strings.TrimSpace(strings.TrimSuffix(input, "/"))
-	if candidate == "" {
-		return "", 0, false, false
+	ref := ghref.Guess(input)
+	repo := ""
+	if ref.HasRepo() {
+		repo = ref.FullName()
 	}
-
-	var repo string
-	var number int
-	repoFound := false
-	numberFound := false
-
-	if idx := strings.Index(candidate, "#"); idx > 0 {
-		repoPart := strings.TrimSpace(candidate[:idx])
-		if isLikelyRepoSlug(repoPart) {
-			repo = repoPart
-			repoFound = true
-		}
-		if n, ok := parseNumericCandidate(candidate[idx+1:]); ok {
-			number = n
-			numberFound = true
-		}
-	}
-
-	if strings.HasPrefix(candidate, "http://") || strings.HasPrefix(candidate, "https://") {
-		if u, err := url.Parse(candidate); err == nil && u.Host != "" {
-			segments := strings.Split(strings.Trim(u.Path, "/"), "/")
-			if len(segments) >= 2 {
-				repoPath := segments[0] + "/" + segments[1]
-				if isLikelyRepoSlug(repoPath) {
-					repo = repoPath
-					repoFound = true
-				}
-			}
-			for i := 0; i < len(segments); i++ {
-				if segments[i] == "pull" || segments[i] == "pulls" {
-					if i+1 < len(segments) {
-						if n, ok := parseNumericCandidate(segments[i+1]); ok {
-							number = n
-							numberFound = true
-						}
-					}
-				}
-			}
-		}
-	}
-
-	parts := strings.Split(candidate, "/")
-	if len(parts) >= 2 {
-		repoPath := parts[0] + "/" + parts[1]
-		if isLikelyRepoSlug(repoPath) && !repoFound {
-			repo = repoPath
-			repoFound = true
-		}
-		for i := 0; i < len(parts); i++ {
-			if parts[i] == "pull" || parts[i] == "pulls" {
-				if i+1 < len(parts) {
-					if n, ok := parseNumericCandidate(parts[i+1]); ok {
-						number = n
-						numberFound = true
-					}
-				}
-			}
-		}
-	}
-
-	if !numberFound {
-		if n, ok := parseNumericCandidate(candidate); ok {
-			number = n
-			numberFound = true
-		}
-	}
-
-	return repo, number, repoFound, numberFound
+	return repo, ref.Number, ref.HasRepo(), ref.Number > 0
 }
 
 func isLikelyRepoSlug(repo string) bool {
@@ -1252,65 +1141,79 @@ func promptYesNo(out io.Writer, in io.Reader, label string, defaultYes bool) (bo
 }
 
 func extractPullRequestNumber(input string) (int, error) {
-	candidate := strings.TrimSpace(input)
-	candidate = strings.TrimSuffix(candidate, "/")
-	if candidate == "" {
-		return 0, fmt.Errorf("pull request reference cannot be empty")
+	ref, err := ghref.Parse(input)
+	if err != nil {
+		return 0, fmt.Errorf("unable to determine pull request number from %q: %w", input, err)
 	}
-
-	if number, ok := parseNumericCandidate(candidate); ok {
-		return number, nil
+	if ref.Kind == ghref.Issue {
+		return 0, fmt.Errorf("%q is an issue, not a pull request", input)
 	}
+	return ref.Number, nil
+}
 
-	if strings.HasPrefix(candidate, "http://") || strings.HasPrefix(candidate, "https://") {
-		u, err := url.Parse(candidate)
-		if err == nil && u.Host != "" {
-			segments := strings.Split(strings.Trim(u.Path, "/"), "/")
-			for i := 0; i < len(segments); i++ {
-				segment := segments[i]
-				if segment == "pull" || segment == "pulls" {
-					if i+1 < len(segments) {
-						if number, ok := parseNumericCandidate(segments[i+1]); ok {
-							return number, nil
-						}
-					}
-				}
-			}
-		}
-	}
+func parseNumericCandidate(raw string) (int, bool) {
+	return ghref.ParseNumber(raw)
+}
 
-	if idx := strings.LastIndex(candidate, "#"); idx >= 0 && idx+1 < len(candidate) {
-		if number, ok := parseNumericCandidate(candidate[idx+1:]); ok {
-			return number, nil
-		}
+// markdownPath trims arg and adds the .md extension when it is missing.
+func markdownPath(arg string) (string, error) {
+	mdPath := strings.TrimSpace(arg)
+	if mdPath == "" {
+		return "", fmt.Errorf("file path cannot be empty")
 	}
-
-	if idx := strings.LastIndex(candidate, "/"); idx >= 0 && idx+1 < len(candidate) {
-		if number, ok := parseNumericCandidate(candidate[idx+1:]); ok {
-			return number, nil
-		}
+	if !strings.HasSuffix(mdPath, ".md") {
+		mdPath += ".md"
 	}
+	return mdPath, nil
+}
 
-	return 0, fmt.Errorf("unable to determine pull request number from %q", input)
+func markdownHTMLName(mdPath string) string {
+	return strings.TrimSuffix(filepath.Base(mdPath), ".md") + ".html"
 }
 
-func parseNumericCandidate(raw string) (int, bool) {
-	trimmed := strings.TrimSpace(raw)
-	if trimmed == "" {
-		return 0, false
+// markdownOutputPath picks where mdToHtml writes: next to mdPath by default,
+// inside output when it is a directory or ends in a separator, or output
+// itself as a file.
+func markdownOutputPath(mdPath, output string) (string, error) {
+	if output == "" {
+		return strings.TrimSuffix(mdPath, ".md") + ".html", nil
 	}
-	if idx := strings.IndexAny(trimmed, "?#"); idx >= 0 {
-		trimmed = trimmed[:idx]
+	// Expanding ~/dir/ cleans the path, so the separator is checked first.
+	asDir := strings.HasSuffix(strings.TrimSpace(output), string(filepath.Separator))
+	expanded, err := userpath.Expand(output)
+	if err != nil {
+		return "", err
 	}
-	trimmed = strings.TrimSpace(trimmed)
-	if trimmed == "" {
-		return 0, false
+	if info, err := os.Stat(expanded); asDir || (err == nil && info.IsDir()) {
+		dir, err := userpath.PrepareDir(expanded)
+		if err != nil {
+			return "", err
+		}
+		return filepath.Join(dir, markdownHTMLName(mdPath)), nil
+	}
+	if _, err := userpath.PrepareDir(filepath.Dir(expanded)); err != nil {
+		return "", err
 	}
-	number, err := strconv.Atoi(trimmed)
-	if err != nil || number <= 0 {
-		return 0, false
+	return expanded, nil
+}
+
+// renderMarkdownFile converts mdPath to a complete HTML page and writes it
+// to htmlPath, or to w when htmlPath is empty.
+func renderMarkdownFile(mdPath, htmlPath string, w io.Writer) error {
+	mdContent, err := os.ReadFile(mdPath)
+	if err != nil {
+		return fmt.Errorf("read %s: %w", mdPath, err)
 	}
-	return number, true
+	htmlContent := mdToHTML(mdContent)
+
+	if htmlPath == "" {
+		_, err := w.Write(htmlContent)
+		return err
+	}
+	if err := os.WriteFile(htmlPath, htmlContent, 0o644); err != nil {
+		return fmt.Errorf("write %s: %w", htmlPath, err)
+	}
+	return nil
 }
 
 func mdToHTML(md []byte) []byte {
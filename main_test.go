@@ -0,0 +1,39 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestMarkdownOutputPath(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	existing := filepath.Join(home, "site")
+	if err := os.Mkdir(existing, 0o755); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name   string
+		output string
+		want   string
+	}{
+		{"default", "", "/notes/todo.html"},
+		{"file", filepath.Join(home, "out", "page.html"), filepath.Join(home, "out", "page.html")},
+		{"existing dir", existing, filepath.Join(existing, "todo.html")},
+		{"trailing slash", filepath.Join(home, "new") + "/", filepath.Join(home, "new", "todo.html")},
+		{"home file", "~/page.html", filepath.Join(home, "page.html")},
+		{"home dir", "~/site", filepath.Join(existing, "todo.html")},
+		{"home trailing slash", "~/fresh/", filepath.Join(home, "fresh", "todo.html")},
+	}
+	for _, tt := range tests {
+		got, err := markdownOutputPath("/notes/todo.md", tt.output)
+		if err != nil || got != tt.want {
+			t.Errorf("%s: markdownOutputPath(%q) = %q, %v; want %q", tt.name, tt.output, got, err, tt.want)
+		}
+	}
+	if info, err := os.Stat(filepath.Join(home, "fresh")); err != nil || !info.IsDir() {
+		t.Errorf("expected ~/fresh/ to be created: %v", err)
+	}
+}
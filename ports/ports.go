@@ -0,0 +1,101 @@
+// Package ports lists the processes listening on TCP ports, via lsof.
+package ports
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os/exec"
+	"strconv"
+	"strings"
+
+	"lang/cmdlog"
+)
+
+// Process is one listening socket reported by lsof.
+type Process struct {
+	Command string
+	User    string
+	PID     int
+	Address string
+	Port    string
+	Raw     string
+}
+
+// Listening runs lsof and returns every TCP listener. No listeners is not an
+// error.
+func Listening(ctx context.Context) ([]Process, error) {
+	if _, err := exec.LookPath("lsof"); err != nil {
+		return nil, fmt.Errorf("lsof not found in PATH: %w", err)
+	}
+
+	var stdout bytes.Buffer
+	var stderr bytes.Buffer
+
+	cmd := cmdlog.Command(ctx, "lsof", "-nP", "-iTCP", "-sTCP:LISTEN")
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+
+	if err := cmd.Run(); err != nil {
+		msg := strings.TrimSpace(stderr.String())
+		// lsof exits 1 without output when nothing is listening.
+		var exitErr *exec.ExitError
+		if errors.As(err, &exitErr) && exitErr.ExitCode() == 1 && msg == "" && stdout.Len() == 0 {
+			return nil, nil
+		}
+		if msg != "" {
+			return nil, fmt.Errorf("list listening ports: %s: %w", msg, err)
+		}
+		return nil, fmt.Errorf("list listening ports: %w", err)
+	}
+
+	return Parse(&stdout)
+}
+
+// Parse reads `lsof -nP -iTCP -sTCP:LISTEN` output, skipping the header and
+// any line it can't make sense of.
+func Parse(r io.Reader) ([]Process, error) {
+	scanner := bufio.NewScanner(r)
+	var processes []Process
+	firstLine := true
+	for scanner.Scan() {
+		line := scanner.Text()
+		if firstLine {
+			firstLine = false
+			continue
+		}
+		fields := strings.Fields(line)
+		if len(fields) < 9 {
+			continue
+		}
+
+		pid, err := strconv.Atoi(fields[1])
+		if err != nil {
+			continue
+		}
+
+		address := fields[len(fields)-2]
+		port := address
+		if idx := strings.LastIndex(address, ":"); idx >= 0 && idx+1 < len(address) {
+			port = address[idx+1:]
+		}
+
+		processes = append(processes, Process{
+			Command: fields[0],
+			User:    fields[2],
+			PID:     pid,
+			Address: address,
+			Port:    port,
+			Raw:     line,
+		})
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("scan lsof output: %w", err)
+	}
+
+	return processes, nil
+}
@@ -0,0 +1,45 @@
+package ports
+
+import (
+	"strings"
+	"testing"
+)
+
+const lsofOutput = `COMMAND   PID  USER   FD   TYPE             DEVICE SIZE/OFF NODE NAME
+node     4242 nikiv   23u  IPv4 0x1234567890abcdef      0t0  TCP 127.0.0.1:3000 (LISTEN)
+postgres  911 nikiv    7u  IPv6 0xfedcba0987654321      0t0  TCP [::1]:5432 (LISTEN)
+garbage line
+bad      abc  nikiv    7u  IPv6 0xfedcba0987654321      0t0  TCP *:80 (LISTEN)
+`
+
+func TestParse(t *testing.T) {
+	processes, err := Parse(strings.NewReader(lsofOutput))
+	if err != nil {
+		t.Fatalf("Parse() error: %v", err)
+	}
+	if len(processes) != 2 {
+		t.Fatalf("expected 2 processes, got %d: %+v", len(processes), processes)
+	}
+
+	node := processes[0]
+	if node.Command != "node" || node.PID != 4242 || node.User != "nikiv" {
+		t.Errorf("unexpected first process: %+v", node)
+	}
+	if node.Address != "127.0.0.1:3000" || node.Port != "3000" {
+		t.Errorf("unexpected address/port: %q %q", node.Address, node.Port)
+	}
+	if !strings.HasPrefix(node.Raw, "node") {
+		t.Errorf("Raw should keep the original line, got %q", node.Raw)
+	}
+
+	if pg := processes[1]; pg.Address != "[::1]:5432" || pg.Port != "5432" {
+		t.Errorf("unexpected IPv6 address/port: %q %q", pg.Address, pg.Port)
+	}
+}
+
+func TestParseEmpty(t *testing.T) {
+	processes, err := Parse(strings.NewReader(""))
+	if err != nil || len(processes) != 0 {
+		t.Fatalf("Parse(\"\") = %+v, %v; want no processes", processes, err)
+	}
+}
@@ -0,0 +1,68 @@
+// Package skipdirs is the one list of directory names that file pickers and
+// other tree walks never descend into, so every CLI in this repository skips
+// the same things. FLOW_SKIP_DIRS adds to it.
+package skipdirs
+
+import (
+	"os"
+	"sort"
+	"strings"
+)
+
+// Env holds extra names, separated by commas or spaces. A name prefixed
+// with "!" removes a default instead, e.g. "!vendor".
+const Env = "FLOW_SKIP_DIRS"
+
+// Defaults are skipped unless removed through Env.
+var Defaults = []string{
+	".cache",
+	".git",
+	".idea",
+	".vscode",
+	"build",
+	"dist",
+	"node_modules",
+	"target",
+	"vendor",
+}
+
+// Set is a set of directory names to skip.
+type Set map[string]struct{}
+
+// New returns Defaults merged with the additions and removals in extra,
+// which uses the same syntax as Env.
+func New(extra string) Set {
+	set := make(Set, len(Defaults))
+	for _, name := range Defaults {
+		set[name] = struct{}{}
+	}
+	for _, field := range strings.FieldsFunc(extra, func(r rune) bool { return r == ',' || r == ' ' || r == '\t' }) {
+		if name, ok := strings.CutPrefix(field, "!"); ok {
+			delete(set, name)
+		} else {
+			set[field] = struct{}{}
+		}
+	}
+	return set
+}
+
+// FromEnv is New with the value of Env.
+func FromEnv() Set {
+	return New(os.Getenv(Env))
+}
+
+// Skip reports whether a directory called name should be skipped.
+func (s Set) Skip(name string) bool {
+	_, ok := s[name]
+	return ok
+}
+
+// Names returns the set's names in order.
+func (s Set) Names() []string {
+	names := make([]string, 0, len(s))
+	for name := range s {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
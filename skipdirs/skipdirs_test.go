@@ -0,0 +1,34 @@
+package skipdirs
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestNewMergesDefaultsAndAdditions(t *testing.T) {
+	set := New(".venv, __pycache__ !vendor,,")
+	for _, name := range []string{".git", "node_modules", ".venv", "__pycache__"} {
+		if !set.Skip(name) {
+			t.Errorf("expected %s to be skipped", name)
+		}
+	}
+	if set.Skip("vendor") {
+		t.Error("expected !vendor to remove the default")
+	}
+	if set.Skip("src") {
+		t.Error("expected src to be walked")
+	}
+}
+
+func TestNewWithoutExtraIsDefaults(t *testing.T) {
+	if got := New("").Names(); !reflect.DeepEqual(got, Defaults) {
+		t.Fatalf("got %q, want %q", got, Defaults)
+	}
+}
+
+func TestFromEnv(t *testing.T) {
+	t.Setenv(Env, ".venv")
+	if !FromEnv().Skip(".venv") {
+		t.Fatal("expected FLOW_SKIP_DIRS to add .venv")
+	}
+}
@@ -7,6 +7,8 @@ import (
 	"sort"
 	"strings"
 
+	"lang/userpath"
+
 	"github.com/dzonerzy/go-snap/snap"
 	"gopkg.in/yaml.v3"
 )
@@ -81,7 +83,7 @@ func resolveTaskfilePath(ctx *snap.Context) (string, error) {
 	}
 
 	if fileFlag != "" {
-		path, err := expandUserPath(fileFlag)
+		path, err := userpath.Expand(fileFlag)
 		if err != nil {
 			return "", fmt.Errorf("expand taskfile path: %w", err)
 		}
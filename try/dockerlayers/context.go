@@ -0,0 +1,287 @@
+package dockerlayers
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+// intentionallyIgnoredDirs are directories that almost never belong in a
+// build context. Copying them makes every local install or build bust the
+// cache of the COPY layer, and usually inflates the image.
+var intentionallyIgnoredDirs = []string{
+	".git",
+	"node_modules",
+	".venv",
+	"venv",
+	"__pycache__",
+	"target",
+	"dist",
+	"build",
+	".next",
+	"coverage",
+}
+
+type ignoreRule struct {
+	Pattern string
+	Negate  bool
+}
+
+type contextReport struct {
+	Dir        string
+	IgnoreFile string
+	Rules      []ignoreRule
+	Findings   []string
+}
+
+// analyzeBuildContext checks COPY/ADD sources against the .dockerignore next
+// to the Dockerfile, which Docker treats as the build context by default.
+func analyzeBuildContext(rep *report) (*contextReport, error) {
+	ctxReport := &contextReport{Dir: filepath.Dir(rep.FilePath)}
+
+	ignorePath := filepath.Join(ctxReport.Dir, ".dockerignore")
+	rules, err := readDockerignore(ignorePath)
+	switch {
+	case err == nil:
+		ctxReport.IgnoreFile = ignorePath
+		ctxReport.Rules = rules
+	case errors.Is(err, os.ErrNotExist):
+	default:
+		return nil, err
+	}
+
+	for _, stage := range rep.Stages {
+		if stage == nil {
+			continue
+		}
+		for _, layer := range stage.Layers {
+			inst := layer.Instruction
+			if inst.Keyword != "COPY" && inst.Keyword != "ADD" {
+				continue
+			}
+			if detectCopySourceStage(inst.Args) != "" {
+				continue
+			}
+			for _, source := range copySources(inst.Args) {
+				if strings.Contains(source, "://") {
+					continue
+				}
+				ctxReport.Findings = append(ctxReport.Findings, contextFindings(ctxReport, inst, source)...)
+			}
+		}
+	}
+
+	return ctxReport, nil
+}
+
+func contextFindings(ctxReport *contextReport, inst parsedInstruction, source string) []string {
+	rel := path.Clean(strings.TrimPrefix(filepath.ToSlash(source), "/"))
+	if rel != "." {
+		if rule, ok := ignoredBy(ctxReport.Rules, rel); ok {
+			return []string{fmt.Sprintf("line %d: %s source %q is excluded by .dockerignore rule %q, so the build will not find it.", inst.Line, inst.Keyword, source, rule.Pattern)}
+		}
+	}
+
+	root := filepath.Join(ctxReport.Dir, filepath.FromSlash(rel))
+	info, err := os.Stat(root)
+	if err != nil || !info.IsDir() {
+		return nil
+	}
+
+	var findings []string
+	excluded := 0
+	_ = filepath.WalkDir(root, func(p string, d fs.DirEntry, err error) error {
+		if err != nil || p == root {
+			return nil
+		}
+		entry, err := filepath.Rel(ctxReport.Dir, p)
+		if err != nil {
+			return nil
+		}
+		if _, ok := ignoredBy(ctxReport.Rules, filepath.ToSlash(entry)); ok {
+			excluded++
+			if d.IsDir() {
+				return filepath.SkipDir
+			}
+		}
+		return nil
+	})
+	if excluded > 0 {
+		findings = append(findings, fmt.Sprintf("line %d: %s %q skips %d path(s) matched by .dockerignore; changes to them will not invalidate this layer.", inst.Line, inst.Keyword, source, excluded))
+	}
+
+	for _, dir := range intentionallyIgnoredDirs {
+		candidate := path.Join(rel, dir)
+		info, err := os.Stat(filepath.Join(ctxReport.Dir, filepath.FromSlash(candidate)))
+		if err != nil || !info.IsDir() {
+			continue
+		}
+		if _, ok := ignoredBy(ctxReport.Rules, candidate); ok {
+			continue
+		}
+		findings = append(findings, fmt.Sprintf("line %d: %s %q pulls in %s/ (%s) because .dockerignore does not exclude it; add %q to .dockerignore.",
+			inst.Line, inst.Keyword, source, candidate, formatBytes(dirSize(filepath.Join(ctxReport.Dir, filepath.FromSlash(candidate)))), candidate))
+	}
+
+	return findings
+}
+
+// copySources returns the source operands of a COPY/ADD instruction in
+// either shell or JSON form, skipping flags and the destination.
+func copySources(args string) []string {
+	var operands []string
+	if strings.HasPrefix(strings.TrimSpace(args), "[") {
+		if err := json.Unmarshal([]byte(args), &operands); err != nil {
+			return nil
+		}
+	} else {
+		for _, token := range strings.Fields(args) {
+			if len(operands) == 0 && strings.HasPrefix(token, "--") {
+				continue
+			}
+			operands = append(operands, token)
+		}
+	}
+	if len(operands) < 2 {
+		return nil
+	}
+	return operands[:len(operands)-1]
+}
+
+func readDockerignore(filename string) ([]ignoreRule, error) {
+	file, err := os.Open(filename)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	var rules []ignoreRule
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		rule := ignoreRule{}
+		if strings.HasPrefix(line, "!") {
+			rule.Negate = true
+			line = strings.TrimSpace(line[1:])
+		}
+		line = path.Clean(strings.TrimPrefix(filepath.ToSlash(line), "/"))
+		if line == "." {
+			continue
+		}
+		rule.Pattern = line
+		rules = append(rules, rule)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return rules, nil
+}
+
+// ignoredBy applies the rules in order like Docker does: the last matching
+// rule wins, and a match on a parent directory covers everything below it.
+func ignoredBy(rules []ignoreRule, rel string) (ignoreRule, bool) {
+	var (
+		last    ignoreRule
+		matched bool
+	)
+	for _, rule := range rules {
+		if matchIgnorePattern(rule.Pattern, rel) {
+			last = rule
+			matched = true
+		}
+	}
+	if !matched || last.Negate {
+		return ignoreRule{}, false
+	}
+	return last, true
+}
+
+// matchIgnorePattern is a minimal .dockerignore matcher: path.Match per
+// segment, ** spanning any number of segments, and prefix matches so a
+// pattern naming a directory also matches its contents.
+func matchIgnorePattern(pattern, rel string) bool {
+	patternParts := strings.Split(pattern, "/")
+	pathParts := strings.Split(rel, "/")
+	for end := 1; end <= len(pathParts); end++ {
+		if matchSegments(patternParts, pathParts[:end]) {
+			return true
+		}
+	}
+	return false
+}
+
+func matchSegments(pattern, parts []string) bool {
+	if len(pattern) == 0 {
+		return len(parts) == 0
+	}
+	if pattern[0] == "**" {
+		for skip := 0; skip <= len(parts); skip++ {
+			if matchSegments(pattern[1:], parts[skip:]) {
+				return true
+			}
+		}
+		return false
+	}
+	if len(parts) == 0 {
+		return false
+	}
+	ok, err := path.Match(pattern[0], parts[0])
+	if err != nil || !ok {
+		return false
+	}
+	return matchSegments(pattern[1:], parts[1:])
+}
+
+func dirSize(root string) int64 {
+	var total int64
+	_ = filepath.WalkDir(root, func(_ string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() {
+			return nil
+		}
+		if info, err := d.Info(); err == nil {
+			total += info.Size()
+		}
+		return nil
+	})
+	return total
+}
+
+func formatBytes(n int64) string {
+	const unit = 1024
+	if n < unit {
+		return fmt.Sprintf("%d B", n)
+	}
+	div, exp := int64(unit), 0
+	for v := n / unit; v >= unit; v /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f %ciB", float64(n)/float64(div), "KMGTPE"[exp])
+}
+
+func printContextReport(w io.Writer, ctxReport *contextReport) {
+	fmt.Fprintln(w, "Build context:")
+	fmt.Fprintf(w, "  Directory: %s\n", ctxReport.Dir)
+	if ctxReport.IgnoreFile == "" {
+		fmt.Fprintln(w, "  .dockerignore: none (everything in the directory is sent to the builder)")
+	} else {
+		fmt.Fprintf(w, "  .dockerignore: %s (%d rules)\n", ctxReport.IgnoreFile, len(ctxReport.Rules))
+	}
+	if len(ctxReport.Findings) == 0 {
+		fmt.Fprintln(w, "  No COPY/ADD sources are affected by ignore rules.")
+	}
+	for _, finding := range ctxReport.Findings {
+		fmt.Fprintf(w, "  - %s\n", finding)
+	}
+	fmt.Fprintln(w)
+}
@@ -2,16 +2,23 @@ package dockerlayers
 
 import (
 	"bufio"
+	"encoding/json"
 	"errors"
 	"flag"
 	"fmt"
 	"io"
 	"os"
 	"path/filepath"
+	"sort"
 	"strings"
 	"unicode"
 )
 
+const (
+	formExec  = "exec"
+	formShell = "shell"
+)
+
 const (
 	effectStageStart = "stage start"
 	effectFilesystem = "filesystem layer"
@@ -128,6 +135,10 @@ type parsedInstruction struct {
 	Keyword string
 	Args    string
 	Raw     string
+	// Form is formExec or formShell for CMD and ENTRYPOINT, empty otherwise.
+	// Exec holds the decoded JSON array for the exec form.
+	Form string
+	Exec []string
 }
 
 type layerReport struct {
@@ -151,18 +162,58 @@ type stageReport struct {
 	FsLayers       int
 	MetadataLayers int
 	BuildArgs      int
+	Notes          []string
+	Warnings       []string
 }
 
 type report struct {
-	FilePath string
-	Global   []layerReport
-	Stages   []*stageReport
+	FilePath    string
+	Global      []layerReport
+	Stages      []*stageReport
+	Suggestions []string
+	Context     *contextReport
+	Final       *imageSummary
+	// Secrets warns about credentials set with ENV or ARG, in file order.
+	Secrets []string
+}
+
+// imageSummary counts the filesystem layers the final image gets from its
+// Dockerfile, on top of whatever the base image brings.
+type imageSummary struct {
+	Stage     int
+	FsLayers  int
+	Threshold int
+	Warning   string
+}
+
+// analyzeOptions tunes the heuristics behind per-stage notes and suggestions.
+type analyzeOptions struct {
+	// RunMergeThreshold is how many adjacent RUN instructions trigger a
+	// suggestion to merge them. Values below 2 disable the check.
+	RunMergeThreshold int
+	// MaxLayers is how many filesystem layers the final image may add before
+	// the summary warns. Values below 1 disable the warning.
+	MaxLayers int
+	// CheckContext reads the build context and .dockerignore next to the
+	// Dockerfile to explain which COPY/ADD sources ignore rules affect.
+	CheckContext bool
+}
+
+func defaultAnalyzeOptions() analyzeOptions {
+	return analyzeOptions{
+		RunMergeThreshold: 2,
+		MaxLayers:         10,
+	}
 }
 
 func RunCLI(args []string, stdout, stderr io.Writer) error {
+	defaults := defaultAnalyzeOptions()
 	fs := flag.NewFlagSet("dockerlayers", flag.ContinueOnError)
 	fs.SetOutput(stderr)
-	dockerfilePath := fs.String("file", "Dockerfile", "path to the Dockerfile to inspect")
+	dockerfilePath := fs.String("file", "", "path to the Dockerfile or Containerfile to inspect (default: detect one in the current directory)")
+	mergeRuns := fs.Int("merge-runs", defaults.RunMergeThreshold, "flag this many adjacent RUN instructions as mergeable (0 disables)")
+	maxLayers := fs.Int("max-layers", defaults.MaxLayers, "warn when the final image adds more filesystem layers than this (0 disables)")
+	checkContext := fs.Bool("context", defaults.CheckContext, "inspect the build context and .dockerignore next to the Dockerfile")
 	if err := fs.Parse(args); err != nil {
 		if errors.Is(err, flag.ErrHelp) {
 			return nil
@@ -170,7 +221,27 @@ func RunCLI(args []string, stdout, stderr io.Writer) error {
 		return err
 	}
 
-	rep, err := analyzeDockerfile(*dockerfilePath)
+	path := *dockerfilePath
+	if path == "" {
+		candidates, err := FindDockerfiles(".")
+		if err != nil {
+			return err
+		}
+		if len(candidates) == 0 {
+			return fmt.Errorf("no Dockerfile, Containerfile, or *.dockerfile in the current directory; pass -file")
+		}
+		path = candidates[0]
+		if len(candidates) > 1 {
+			fmt.Fprintf(stderr, "Analyzing %s; pass -file to pick one of: %s\n", path, strings.Join(candidates[1:], ", "))
+		}
+	}
+
+	opts := defaults
+	opts.RunMergeThreshold = *mergeRuns
+	opts.MaxLayers = *maxLayers
+	opts.CheckContext = *checkContext
+
+	rep, err := analyzeDockerfileWithOptions(path, opts)
 	if err != nil {
 		return err
 	}
@@ -179,7 +250,47 @@ func RunCLI(args []string, stdout, stderr io.Writer) error {
 	return nil
 }
 
+// dockerfileNames are tried in order before any *.dockerfile.
+var dockerfileNames = []string{"Dockerfile", "dockerfile", "Containerfile", "containerfile"}
+
+// FindDockerfiles lists the build files in dir, most conventional first:
+// Dockerfile, then Containerfile (Podman's name), then *.dockerfile sorted
+// by name. Paths are joined to dir.
+func FindDockerfiles(dir string) ([]string, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+	present := map[string]bool{}
+	var suffixed []string
+	for _, entry := range entries {
+		if entry.IsDir() {
+			continue
+		}
+		present[entry.Name()] = true
+		if strings.HasSuffix(strings.ToLower(entry.Name()), ".dockerfile") {
+			suffixed = append(suffixed, entry.Name())
+		}
+	}
+
+	var found []string
+	for _, name := range dockerfileNames {
+		if present[name] {
+			found = append(found, filepath.Join(dir, name))
+		}
+	}
+	sort.Strings(suffixed)
+	for _, name := range suffixed {
+		found = append(found, filepath.Join(dir, name))
+	}
+	return found, nil
+}
+
 func analyzeDockerfile(path string) (*report, error) {
+	return analyzeDockerfileWithOptions(path, defaultAnalyzeOptions())
+}
+
+func analyzeDockerfileWithOptions(path string, opts analyzeOptions) (*report, error) {
 	fullPath, err := filepath.Abs(path)
 	if err != nil {
 		return nil, err
@@ -205,9 +316,15 @@ func analyzeDockerfile(path string) (*report, error) {
 	rep := &report{
 		FilePath: fullPath,
 	}
+	for _, inst := range instructions {
+		for _, finding := range detectSecrets(inst) {
+			rep.Secrets = append(rep.Secrets, finding.String())
+		}
+	}
 
 	var stageIndex = -1
 	stageAliases := map[string]int{}
+	execEntrypoints := stagesWithExecEntrypoint(instructions)
 
 	for _, inst := range instructions {
 		if inst.Keyword == "" {
@@ -267,6 +384,8 @@ func analyzeDockerfile(path string) (*report, error) {
 			layer.Notes = append(layer.Notes, "Cleanup temp files within the same RUN to prevent them from sticking in the layer.")
 		case "ARG":
 			layer.Notes = append(layer.Notes, "Only available during build; use ENV if the value is needed at runtime.")
+		case "CMD", "ENTRYPOINT":
+			layer.Notes = append(layer.Notes, commandFormNotes(inst, execEntrypoints[stageIndex])...)
 		}
 
 		switch layer.Effect {
@@ -282,9 +401,244 @@ func analyzeDockerfile(path string) (*report, error) {
 		stage.Layers = append(stage.Layers, layer)
 	}
 
+	for _, stage := range rep.Stages {
+		if stage == nil {
+			continue
+		}
+		stage.Notes = append(stage.Notes, adjacentRunNotes(stage, opts.RunMergeThreshold)...)
+		stage.Warnings = append(stage.Warnings, unpinnedBaseWarnings(rep, stage)...)
+	}
+	rep.Suggestions = append(rep.Suggestions, multiStageSuggestions(rep)...)
+	rep.Final = summarizeFinalImage(rep, opts.MaxLayers)
+
+	if opts.CheckContext {
+		ctxReport, err := analyzeBuildContext(rep)
+		if err != nil {
+			return nil, fmt.Errorf("analyze build context: %w", err)
+		}
+		rep.Context = ctxReport
+	}
+
 	return rep, nil
 }
 
+// stagesWithExecEntrypoint maps stage indexes to whether the stage's last
+// ENTRYPOINT uses the exec form. ENTRYPOINT may follow CMD, so this is
+// worked out before any notes are written.
+func stagesWithExecEntrypoint(instructions []parsedInstruction) map[int]bool {
+	result := map[int]bool{}
+	stage := -1
+	for _, inst := range instructions {
+		switch inst.Keyword {
+		case "FROM":
+			stage++
+		case "ENTRYPOINT":
+			result[stage] = inst.Form == formExec && len(inst.Exec) > 0
+		}
+	}
+	return result
+}
+
+// summarizeFinalImage counts the filesystem layers of the last stage plus
+// any earlier stages it builds FROM, since those layers ship too. Artifacts
+// pulled in with COPY --from are a single layer and already counted.
+func summarizeFinalImage(rep *report, threshold int) *imageSummary {
+	if len(rep.Stages) == 0 || rep.Stages[len(rep.Stages)-1] == nil {
+		return nil
+	}
+	final := rep.Stages[len(rep.Stages)-1]
+	summary := &imageSummary{Stage: final.Stage.Index, Threshold: threshold}
+
+	stage := final
+	for hops := 0; stage != nil && hops < len(rep.Stages); hops++ {
+		summary.FsLayers += stage.FsLayers
+		stage = findStageByName(rep, stage.Stage.Base, stage.Stage.Index)
+	}
+
+	if threshold > 0 && summary.FsLayers > threshold {
+		summary.Warning = fmt.Sprintf("the final image adds %d filesystem layers, more than %d. Every layer is a separate download on pull; merge RUN steps with && and group COPYs, or build in an earlier stage and COPY --from the result.", summary.FsLayers, threshold)
+	}
+	return summary
+}
+
+// unpinnedBaseWarnings flags a stage whose FROM image can change underneath
+// the build: :latest, no tag at all, or any tag without an @sha256 digest.
+// scratch, earlier stages, and bases built from ${ARGS} are skipped.
+func unpinnedBaseWarnings(rep *report, stage *stageReport) []string {
+	base := stage.Stage.Base
+	if base == "" || strings.EqualFold(base, "scratch") || strings.Contains(base, "$") || strings.Contains(base, "@sha256:") {
+		return nil
+	}
+	if findStageByName(rep, base, stage.Stage.Index) != nil {
+		return nil
+	}
+
+	line := 0
+	if len(stage.Layers) > 0 {
+		line = stage.Layers[0].Instruction.Line
+	}
+
+	_, tag := splitImageReference(base)
+	switch tag {
+	case "":
+		return []string{fmt.Sprintf("line %d: %q has no tag, so it means :latest and changes whenever the image is republished. Pin a version and a digest (image:tag@sha256:...) for reproducible builds.", line, base)}
+	case "latest":
+		return []string{fmt.Sprintf("line %d: %q follows :latest and changes whenever the image is republished. Pin a version and a digest (image:tag@sha256:...) for reproducible builds.", line, base)}
+	default:
+		return []string{fmt.Sprintf("line %d: tag %q is mutable and can be re-pushed with different contents. Append its digest (%s@sha256:...) to pin it.", line, tag, base)}
+	}
+}
+
+// adjacentRunNotes reports each streak of at least threshold back-to-back RUN
+// instructions. Every RUN commits its own layer, so chaining the commands with
+// && in one RUN keeps intermediate files out of the image and the layer count down.
+func adjacentRunNotes(stage *stageReport, threshold int) []string {
+	if threshold < 2 {
+		return nil
+	}
+
+	var notes []string
+	var streak []int
+	flush := func() {
+		if len(streak) >= threshold {
+			lines := make([]string, len(streak))
+			for i, line := range streak {
+				lines[i] = fmt.Sprintf("%d", line)
+			}
+			notes = append(notes, fmt.Sprintf("%d consecutive RUN instructions (lines %s) could be merged into one RUN with && \\ to save %d layers.",
+				len(streak), strings.Join(lines, ", "), len(streak)-1))
+		}
+		streak = streak[:0]
+	}
+
+	for _, layer := range stage.Layers {
+		if layer.Instruction.Keyword == "RUN" {
+			streak = append(streak, layer.Instruction.Line)
+			continue
+		}
+		flush()
+	}
+	flush()
+	return notes
+}
+
+// heavyBaseImages ship a compiler, package manager, or full distro userland.
+// They are fine for building but bloat a final runtime image.
+var heavyBaseImages = map[string]bool{
+	"golang":          true,
+	"node":            true,
+	"rust":            true,
+	"python":          true,
+	"ruby":            true,
+	"php":             true,
+	"openjdk":         true,
+	"maven":           true,
+	"gradle":          true,
+	"eclipse-temurin": true,
+	"gcc":             true,
+	"buildpack-deps":  true,
+	"ubuntu":          true,
+	"debian":          true,
+	"fedora":          true,
+	"centos":          true,
+}
+
+// slimTagMarkers identify variants of heavy images that drop the toolchain.
+var slimTagMarkers = []string{"slim", "alpine", "distroless", "jre", "minimal"}
+
+// multiStageSuggestions looks at the final stage, which is the one that ends
+// up as the image. Copying artifacts out of a builder stage onto a small base
+// is the pattern to aim for; a heavy final base means the toolchain ships too.
+func multiStageSuggestions(rep *report) []string {
+	if len(rep.Stages) == 0 {
+		return nil
+	}
+	final := rep.Stages[len(rep.Stages)-1]
+	if final == nil {
+		return nil
+	}
+
+	base := resolveStageBase(rep, final)
+	if !isHeavyBase(base) {
+		return nil
+	}
+
+	copiesFromStage := false
+	for _, layer := range final.Layers {
+		if layer.Instruction.Keyword == "COPY" && detectCopySourceStage(layer.Instruction.Args) != "" {
+			copiesFromStage = true
+			break
+		}
+	}
+
+	label := fmt.Sprintf("Stage %d", final.Stage.Index)
+	if final.Stage.Name != "" {
+		label = fmt.Sprintf("Stage %d (%s)", final.Stage.Index, final.Stage.Name)
+	}
+
+	switch {
+	case len(rep.Stages) == 1:
+		return []string{fmt.Sprintf("%s is the only stage and builds on %q, so the whole build toolchain ships in the image. Build in a named stage and COPY --from it into a slim, distroless, or scratch final stage.", label, base)}
+	case copiesFromStage:
+		return []string{fmt.Sprintf("%s copies artifacts from an earlier stage but still builds on %q. Switch the final stage to a slim, distroless, or scratch base so the toolchain stays in the builder.", label, base)}
+	default:
+		return []string{fmt.Sprintf("%s builds on %q without COPY --from, so the earlier stages only add build time and the toolchain ends up in the final image. Copy the built artifacts into a slim, distroless, or scratch final stage instead.", label, base)}
+	}
+}
+
+// resolveStageBase follows FROM <stage> chains back to the underlying image.
+func resolveStageBase(rep *report, stage *stageReport) string {
+	base := stage.Stage.Base
+	for hops := 0; hops < len(rep.Stages); hops++ {
+		parent := findStageByName(rep, base, stage.Stage.Index)
+		if parent == nil {
+			return base
+		}
+		stage = parent
+		base = parent.Stage.Base
+	}
+	return base
+}
+
+func findStageByName(rep *report, name string, before int) *stageReport {
+	for _, stage := range rep.Stages {
+		if stage == nil || stage.Stage.Index >= before {
+			continue
+		}
+		if stage.Stage.Name != "" && strings.EqualFold(stage.Stage.Name, name) {
+			return stage
+		}
+	}
+	return nil
+}
+
+func isHeavyBase(image string) bool {
+	name, tag := splitImageReference(image)
+	if !heavyBaseImages[name] {
+		return false
+	}
+	for _, marker := range slimTagMarkers {
+		if strings.Contains(tag, marker) {
+			return false
+		}
+	}
+	return true
+}
+
+// splitImageReference returns the lower-cased repository name without
+// registry or namespace, and the tag (empty when pinned only by digest).
+func splitImageReference(image string) (string, string) {
+	image = strings.ToLower(image)
+	if at := strings.Index(image, "@"); at >= 0 {
+		image = image[:at]
+	}
+	if slash := strings.LastIndex(image, "/"); slash >= 0 {
+		image = image[slash+1:]
+	}
+	name, tag, _ := strings.Cut(image, ":")
+	return name, tag
+}
+
 func buildLayer(inst parsedInstruction, desc descriptor, extraNotes []string) layerReport {
 	layer := layerReport{
 		Instruction: inst,
@@ -339,12 +693,61 @@ func parseInstruction(raw rawInstruction) (parsedInstruction, error) {
 		keyword = trimmed[:idx]
 		args = strings.TrimSpace(trimmed[idx:])
 	}
-	return parsedInstruction{
+	inst := parsedInstruction{
 		Line:    raw.line,
 		Keyword: strings.ToUpper(keyword),
 		Args:    args,
 		Raw:     trimmed,
-	}, nil
+	}
+	if inst.Keyword == "CMD" || inst.Keyword == "ENTRYPOINT" {
+		inst.Form = formShell
+		if exec, ok := parseExecForm(args); ok {
+			inst.Form = formExec
+			inst.Exec = exec
+		}
+	}
+	return inst, nil
+}
+
+// parseExecForm decodes the JSON-array form of CMD/ENTRYPOINT. Anything that
+// isn't a valid array of strings, such as ['single', 'quotes'], is run by
+// Docker as a shell command instead.
+func parseExecForm(args string) ([]string, bool) {
+	if !strings.HasPrefix(args, "[") {
+		return nil, false
+	}
+	var exec []string
+	if err := json.Unmarshal([]byte(args), &exec); err != nil {
+		return nil, false
+	}
+	return exec, true
+}
+
+// commandFormNotes explains what the exec or shell form means for a CMD or
+// ENTRYPOINT at runtime. execEntrypoint reports whether the stage sets an
+// exec-form ENTRYPOINT, which turns an exec-form CMD into its arguments.
+func commandFormNotes(inst parsedInstruction, execEntrypoint bool) []string {
+	switch inst.Form {
+	case formExec:
+		switch {
+		case len(inst.Exec) == 0:
+			return []string{"Empty exec form clears the command inherited from the base image."}
+		case inst.Keyword == "CMD" && execEntrypoint:
+			return []string{"Exec form: passed as default arguments to the ENTRYPOINT; docker run arguments replace them."}
+		}
+		return []string{fmt.Sprintf("Exec form: %q runs directly as PID 1 and receives SIGTERM from docker stop. No shell, so $VARS are not expanded.", inst.Exec[0])}
+	case formShell:
+		var notes []string
+		if strings.HasPrefix(inst.Args, "[") {
+			notes = append(notes, "Looks like a JSON array but isn't valid JSON (double quotes only), so Docker runs it as a shell command.")
+		}
+		notes = append(notes, `Shell form: runs under /bin/sh -c, which becomes PID 1 and does not forward SIGTERM, so docker stop waits out its timeout and then kills the process. Use the exec form ["cmd", "arg"] (or exec inside the command) for clean shutdowns.`)
+		if inst.Keyword == "ENTRYPOINT" {
+			notes = append(notes, "A shell-form ENTRYPOINT also ignores CMD and any arguments passed to docker run.")
+		}
+		return notes
+	}
+	return nil
 }
 
 func readInstructions(path string) ([]rawInstruction, error) {
@@ -406,9 +809,19 @@ func stripContinuation(line string) (string, bool) {
 }
 
 func removeInlineComment(line string) string {
-	// Docker ignores inline comments preceded by whitespace. We implement a light-weight check.
+	// Docker ignores inline comments preceded by whitespace. We implement a light-weight check
+	// that leaves a # inside a double-quoted string, e.g. in an exec-form array, alone.
+	inQuotes := false
 	for i := 0; i < len(line); i++ {
-		if line[i] == '#' && (i == 0 || unicode.IsSpace(rune(line[i-1]))) {
+		switch {
+		case line[i] == '\\' && inQuotes:
+			i++
+			continue
+		case line[i] == '"':
+			inQuotes = !inQuotes
+			continue
+		}
+		if !inQuotes && line[i] == '#' && (i == 0 || unicode.IsSpace(rune(line[i-1]))) {
 			return strings.TrimSpace(line[:i])
 		}
 	}
@@ -475,7 +888,7 @@ func detectCopySourceStage(args string) string {
 }
 
 func printReport(w io.Writer, rep *report) {
-	fmt.Fprintf(w, "Dockerfile insight for %s\n\n", rep.FilePath)
+	fmt.Fprintf(w, "%s insight for %s\n\n", buildFileKind(rep.FilePath), rep.FilePath)
 
 	if len(rep.Global) > 0 {
 		fmt.Fprintln(w, "Global build args (before first FROM):")
@@ -499,7 +912,42 @@ func printReport(w io.Writer, rep *report) {
 		for _, layer := range stage.Layers {
 			printLayer(w, layer.Number, layer)
 		}
-		fmt.Fprintf(w, "  Summary: %d filesystem layers | %d metadata steps | %d build args\n\n", stage.FsLayers, stage.MetadataLayers, stage.BuildArgs)
+		fmt.Fprintf(w, "  Summary: %d filesystem layers | %d metadata steps | %d build args\n", stage.FsLayers, stage.MetadataLayers, stage.BuildArgs)
+		for _, note := range stage.Notes {
+			fmt.Fprintf(w, "  Note: %s\n", note)
+		}
+		for _, warning := range stage.Warnings {
+			fmt.Fprintf(w, "  Warning: %s\n", warning)
+		}
+		fmt.Fprintln(w)
+	}
+
+	if rep.Final != nil {
+		fmt.Fprintf(w, "Final image (stage %d): %d filesystem layers on top of the base image\n", rep.Final.Stage, rep.Final.FsLayers)
+		if rep.Final.Warning != "" {
+			fmt.Fprintf(w, "  Warning: %s\n", rep.Final.Warning)
+		}
+		fmt.Fprintln(w)
+	}
+
+	if len(rep.Secrets) > 0 {
+		fmt.Fprintln(w, "Secrets:")
+		for _, warning := range rep.Secrets {
+			fmt.Fprintf(w, "  Warning: %s\n", warning)
+		}
+		fmt.Fprintln(w)
+	}
+
+	if rep.Context != nil {
+		printContextReport(w, rep.Context)
+	}
+
+	if len(rep.Suggestions) > 0 {
+		fmt.Fprintln(w, "Suggestions:")
+		for _, suggestion := range rep.Suggestions {
+			fmt.Fprintf(w, "  - %s\n", suggestion)
+		}
+		fmt.Fprintln(w)
 	}
 
 	fmt.Fprintln(w, "Legend:")
@@ -509,6 +957,15 @@ func printReport(w io.Writer, rep *report) {
 	fmt.Fprintf(w, "  %s: Build-only inputs that do not persist in the image.\n", effectBuildArg)
 }
 
+// buildFileKind names the file in the report header, so a Containerfile is
+// not called a Dockerfile.
+func buildFileKind(path string) string {
+	if strings.HasPrefix(strings.ToLower(filepath.Base(path)), "containerfile") {
+		return "Containerfile"
+	}
+	return "Dockerfile"
+}
+
 func printLayer(w io.Writer, number int, layer layerReport) {
 	fmt.Fprintf(w, "  %2d. %-12s %s\n", number, layer.Effect, layer.Instruction.Raw)
 	fmt.Fprintf(w, "      Why : %s\n", layer.Explanation)
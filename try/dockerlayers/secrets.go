@@ -0,0 +1,206 @@
+package dockerlayers
+
+import (
+	"fmt"
+	"math"
+	"strings"
+)
+
+// secretFinding is an ENV or ARG that looks like it carries a credential.
+type secretFinding struct {
+	Line    int
+	Keyword string
+	Name    string
+	Reason  string
+}
+
+func (f secretFinding) String() string {
+	switch f.Keyword {
+	case "ENV":
+		return fmt.Sprintf("line %d: ENV %s looks like a secret (%s). ENV values are stored in the image config, where anyone who can pull the image reads them with docker inspect. Pass it at run time, or use RUN --mount=type=secret if only the build needs it.", f.Line, f.Name, f.Reason)
+	default:
+		return fmt.Sprintf("line %d: ARG %s looks like a secret (%s). Build arg values are recorded in docker history for every RUN that uses them. Use RUN --mount=type=secret instead.", f.Line, f.Name, f.Reason)
+	}
+}
+
+// secretNameParts flag a variable by name. Names are split on _ and - and
+// compared part by part, so PWD or TOKENIZER_PATH do not match.
+var secretNameParts = []string{"PASSWORD", "PASSWD", "PASS", "SECRET", "TOKEN", "APIKEY", "CREDENTIAL", "CREDENTIALS"}
+
+// secretNamePairs are adjacent parts that only mean a secret together.
+var secretNamePairs = [][2]string{{"API", "KEY"}, {"ACCESS", "KEY"}, {"SECRET", "KEY"}, {"AUTH", "KEY"}, {"SIGNING", "KEY"}, {"PRIVATE", "KEY"}}
+
+// secretValuePrefixes are the fixed prefixes of well-known credentials.
+var secretValuePrefixes = []struct {
+	prefix, kind string
+}{
+	{"AKIA", "an AWS access key id"},
+	{"ASIA", "an AWS temporary access key id"},
+	{"ghp_", "a GitHub token"},
+	{"gho_", "a GitHub token"},
+	{"ghs_", "a GitHub token"},
+	{"github_pat_", "a GitHub token"},
+	{"glpat-", "a GitLab token"},
+	{"sk-", "an API secret key"},
+	{"xoxb-", "a Slack token"},
+	{"xoxp-", "a Slack token"},
+	{"-----BEGIN", "a PEM private key"},
+}
+
+const (
+	// minSecretEntropyLength and minSecretEntropy pick out random-looking
+	// values such as generated keys: long, and close to uniformly spread
+	// over a base64 or hex alphabet.
+	minSecretEntropyLength = 20
+	minSecretEntropy       = 4.0
+)
+
+// detectSecrets returns a finding for each variable an ENV or ARG
+// instruction sets that is named like a credential or whose value looks
+// like one. Other instructions yield nothing.
+func detectSecrets(inst parsedInstruction) []secretFinding {
+	if inst.Keyword != "ENV" && inst.Keyword != "ARG" {
+		return nil
+	}
+
+	var findings []secretFinding
+	for _, pair := range parseAssignments(inst.Keyword, inst.Args) {
+		name, value := pair[0], pair[1]
+		reason := ""
+		if kind, ok := secretValueKind(value); ok {
+			reason = "the value looks like " + kind
+		} else if part, ok := secretNamePart(name); ok {
+			// An ENV set to nothing carries no secret yet; an ARG without a
+			// default still receives one through --build-arg.
+			if inst.Keyword == "ENV" && value == "" {
+				continue
+			}
+			reason = fmt.Sprintf("the name contains %s", part)
+		}
+		if reason != "" {
+			findings = append(findings, secretFinding{Line: inst.Line, Keyword: inst.Keyword, Name: name, Reason: reason})
+		}
+	}
+	return findings
+}
+
+// parseAssignments splits ENV and ARG arguments into name/value pairs:
+// NAME=value pairs with optional quotes, a bare ARG NAME, and the legacy
+// ENV NAME value form.
+func parseAssignments(keyword, args string) [][2]string {
+	fields := splitQuoted(args)
+	if len(fields) == 0 {
+		return nil
+	}
+	if keyword == "ENV" && !strings.Contains(fields[0], "=") {
+		_, value, _ := strings.Cut(strings.TrimSpace(args), fields[0])
+		return [][2]string{{fields[0], unquote(strings.TrimSpace(value))}}
+	}
+
+	var pairs [][2]string
+	for _, field := range fields {
+		name, value, _ := strings.Cut(field, "=")
+		if name != "" {
+			pairs = append(pairs, [2]string{name, unquote(value)})
+		}
+	}
+	return pairs
+}
+
+// splitQuoted splits on whitespace outside single or double quotes, keeping
+// the quotes in the fields.
+func splitQuoted(s string) []string {
+	var fields []string
+	var current strings.Builder
+	var quote rune
+	escaped := false
+	for _, r := range s {
+		switch {
+		case escaped:
+			escaped = false
+		case r == '\\' && quote != '\'':
+			escaped = true
+		case quote != 0:
+			if r == quote {
+				quote = 0
+			}
+		case r == '"' || r == '\'':
+			quote = r
+		case r == ' ' || r == '\t':
+			if current.Len() > 0 {
+				fields = append(fields, current.String())
+				current.Reset()
+			}
+			continue
+		}
+		current.WriteRune(r)
+	}
+	if current.Len() > 0 {
+		fields = append(fields, current.String())
+	}
+	return fields
+}
+
+func unquote(value string) string {
+	if len(value) >= 2 && (value[0] == '"' || value[0] == '\'') && value[len(value)-1] == value[0] {
+		return value[1 : len(value)-1]
+	}
+	return value
+}
+
+func secretNamePart(name string) (string, bool) {
+	parts := strings.FieldsFunc(strings.ToUpper(name), func(r rune) bool { return r == '_' || r == '-' })
+	for i, part := range parts {
+		for _, secret := range secretNameParts {
+			if part == secret {
+				return secret, true
+			}
+		}
+		if i+1 < len(parts) {
+			for _, pair := range secretNamePairs {
+				if part == pair[0] && parts[i+1] == pair[1] {
+					return pair[0] + "_" + pair[1], true
+				}
+			}
+		}
+	}
+	return "", false
+}
+
+// secretValueKind recognizes a literal credential. Values that reference
+// other variables are left to the name check, since only the reference is
+// visible here.
+func secretValueKind(value string) (string, bool) {
+	if value == "" || strings.Contains(value, "$") {
+		return "", false
+	}
+	for _, known := range secretValuePrefixes {
+		if strings.HasPrefix(value, known.prefix) && len(value) > len(known.prefix)+8 {
+			return known.kind, true
+		}
+	}
+	// Paths, URLs, and sentences are not keys however varied their letters.
+	if strings.HasPrefix(value, "/") || strings.Contains(value, "://") || strings.ContainsAny(value, " \t") {
+		return "", false
+	}
+	if len(value) >= minSecretEntropyLength && shannonEntropy(value) >= minSecretEntropy {
+		return "a random key or token", true
+	}
+	return "", false
+}
+
+// shannonEntropy is the average information per character of s, in bits.
+func shannonEntropy(s string) float64 {
+	counts := map[rune]int{}
+	total := 0
+	for _, r := range s {
+		counts[r]++
+		total++
+	}
+	entropy := 0.0
+	for _, n := range counts {
+		p := float64(n) / float64(total)
+		entropy -= p * math.Log2(p)
+	}
+	return entropy
+}
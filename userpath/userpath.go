@@ -0,0 +1,62 @@
+// Package userpath resolves the paths users type for the fgo binaries:
+// a leading ~ for the home directory, and output directories that must
+// exist and be writable before a long download or render starts.
+package userpath
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// Expand trims path and replaces a leading ~ or ~/ with the home
+// directory. ~user forms are rejected rather than passed through.
+func Expand(path string) (string, error) {
+	path = strings.TrimSpace(path)
+	if path == "" {
+		return "", fmt.Errorf("path cannot be empty")
+	}
+	if path[0] != '~' {
+		return path, nil
+	}
+
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", fmt.Errorf("determine home directory: %w", err)
+	}
+	if len(path) == 1 {
+		return home, nil
+	}
+
+	switch path[1] {
+	case '/', '\\':
+		return filepath.Join(home, path[2:]), nil
+	default:
+		return "", fmt.Errorf("unsupported ~ expansion in %q", path)
+	}
+}
+
+// PrepareDir expands ~ in dir, makes it absolute, creates it when
+// missing, and checks that it is writable, so a bad directory fails before
+// any work is done rather than after.
+func PrepareDir(dir string) (string, error) {
+	expanded, err := Expand(dir)
+	if err != nil {
+		return "", err
+	}
+	abs, err := filepath.Abs(expanded)
+	if err != nil {
+		return "", fmt.Errorf("resolve %s: %w", dir, err)
+	}
+	if err := os.MkdirAll(abs, 0o755); err != nil {
+		return "", fmt.Errorf("create output directory %s: %w", abs, err)
+	}
+	probe, err := os.CreateTemp(abs, ".fgo-write-check-*")
+	if err != nil {
+		return "", fmt.Errorf("output directory %s is not writable: %w", abs, err)
+	}
+	probe.Close()
+	os.Remove(probe.Name())
+	return abs, nil
+}
@@ -0,0 +1,50 @@
+package userpath
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestExpand(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+
+	tests := []struct {
+		path string
+		want string
+	}{
+		{"~", home},
+		{"~/music", filepath.Join(home, "music")},
+		{"  ./out ", "./out"},
+		{"/tmp/x", "/tmp/x"},
+	}
+	for _, tt := range tests {
+		if got, err := Expand(tt.path); err != nil || got != tt.want {
+			t.Errorf("Expand(%q) = %q, %v; want %q", tt.path, got, err, tt.want)
+		}
+	}
+
+	for _, path := range []string{"", "  ", "~other/x"} {
+		if got, err := Expand(path); err == nil {
+			t.Errorf("Expand(%q) = %q, want an error", path, got)
+		}
+	}
+}
+
+func TestPrepareDir(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "a", "b")
+	got, err := PrepareDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got != dir {
+		t.Fatalf("PrepareDir(%q) = %q", dir, got)
+	}
+	if info, err := os.Stat(dir); err != nil || !info.IsDir() {
+		t.Fatalf("expected %s to be created: %v", dir, err)
+	}
+	if entries, _ := os.ReadDir(dir); len(entries) != 0 {
+		t.Fatalf("write check left files behind: %v", entries)
+	}
+}
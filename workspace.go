@@ -9,8 +9,11 @@ import (
 	"io/fs"
 	"os"
 	"path/filepath"
+	"sort"
 	"strings"
 
+	"lang/userpath"
+
 	"github.com/dzonerzy/go-snap/snap"
 	"github.com/ktr0731/go-fuzzyfinder"
 )
@@ -221,7 +224,7 @@ func cloneStrings(values []string) []string {
 }
 
 func normalizeWorkspacePath(raw string) (string, error) {
-	expanded, err := expandUserPath(raw)
+	expanded, err := userpath.Expand(raw)
 	if err != nil {
 		return "", err
 	}
@@ -255,7 +258,7 @@ func resolveWorkspaceFilePath(raw string) (string, error) {
 		)
 	}
 
-	expanded, err := expandUserPath(path)
+	expanded, err := userpath.Expand(path)
 	if err != nil {
 		return "", fmt.Errorf("expand workspace file path: %w", err)
 	}
@@ -277,7 +280,12 @@ func workspacePathsCmd(ctx *snap.Context) error {
 
 	var workspacePathArg string
 	var cleanedArgs []string
+	browse := false
 	for i := 0; i < len(args); i++ {
+		if args[i] == "--browse" || args[i] == "-b" {
+			browse = true
+			continue
+		}
 		if args[i] == "--file" || args[i] == "-f" {
 			if i+1 >= len(args) {
 				return fmt.Errorf("missing value for %s", args[i])
@@ -324,11 +332,18 @@ func workspacePathsCmd(ctx *snap.Context) error {
 	case "list":
 		return workspaceListPaths(ctx.Stdout(), doc.list(listKind), label, workspaceFile)
 	case "add":
+		if browse {
+			return workspaceBrowseAddPaths(ctx, doc, listKind, pathArg, workspaceFile)
+		}
 		return workspaceAddPath(ctx, doc, listKind, pathArg, workspaceFile)
+	case "browse":
+		return workspaceBrowseAddPaths(ctx, doc, listKind, pathArg, workspaceFile)
 	case "remove", "rm", "delete":
 		return workspaceRemovePath(ctx, doc, listKind, pathArg, workspaceFile)
+	case "check":
+		return workspaceCheckPaths(ctx.Stdout(), doc, workspaceFile)
 	default:
-		return fmt.Errorf("unknown action %q (use list, add, remove)", action)
+		return fmt.Errorf("unknown action %q (use list, add, browse, remove, check)", action)
 	}
 }
 
@@ -379,6 +394,160 @@ func workspaceAddPath(ctx *snap.Context, doc *workspaceDocument, listKind worksp
 	return nil
 }
 
+// workspaceBrowseAddPaths walks rawRoot (default: the current directory) the
+// same way the symlink picker does and adds every directory picked in a
+// multi-select finder to the list.
+func workspaceBrowseAddPaths(ctx *snap.Context, doc *workspaceDocument, listKind workspaceList, rawRoot, workspaceFile string) error {
+	root := strings.TrimSpace(rawRoot)
+	if root == "" {
+		current, err := os.Getwd()
+		if err != nil {
+			return fmt.Errorf("determine working directory: %w", err)
+		}
+		root = current
+	}
+	root, err := normalizeWorkspacePath(root)
+	if err != nil {
+		return fmt.Errorf("normalize path: %w", err)
+	}
+
+	candidates, err := gatherWorkspaceDirectories(root)
+	if err != nil {
+		return fmt.Errorf("gather directories: %w", err)
+	}
+
+	existing := doc.list(listKind)
+	labels := make([]string, len(candidates))
+	for i, dir := range candidates {
+		rel, err := filepath.Rel(root, dir)
+		if err != nil {
+			rel = dir
+		}
+		label := filepath.ToSlash(rel) + "/"
+		if rel == "." {
+			label = "./ (" + filepath.Base(root) + ")"
+		}
+		if containsString(existing, dir) {
+			label += "  (already added)"
+		}
+		labels[i] = label
+	}
+
+	indices, err := fuzzyfinder.FindMulti(
+		candidates,
+		func(i int) string { return labels[i] },
+		fuzzyfinder.WithPromptString(fmt.Sprintf("add to %s (Tab to mark)> ", workspaceListLabels[listKind])),
+	)
+	if err != nil {
+		if errors.Is(err, fuzzyfinder.ErrAbort) {
+			fmt.Fprintln(ctx.Stdout(), "Aborted.")
+			return nil
+		}
+		return fmt.Errorf("select directories: %w", err)
+	}
+
+	var added []string
+	for _, idx := range indices {
+		dir := candidates[idx]
+		if containsString(existing, dir) {
+			continue
+		}
+		existing = append(existing, dir)
+		added = append(added, dir)
+	}
+	if len(added) == 0 {
+		fmt.Fprintf(ctx.Stdout(), "Nothing new to add to %s\n", workspaceListLabels[listKind])
+		return nil
+	}
+
+	if err := doc.set(listKind, existing); err != nil {
+		return err
+	}
+	if err := doc.save(workspaceFile); err != nil {
+		return fmt.Errorf("save workspace: %w", err)
+	}
+
+	fmt.Fprintf(ctx.Stdout(), "Added %d path(s) to %s:\n", len(added), workspaceListLabels[listKind])
+	for _, dir := range added {
+		fmt.Fprintf(ctx.Stdout(), "  %s\n", dir)
+	}
+	return nil
+}
+
+// gatherWorkspaceDirectories lists root and the directories below it,
+// skipping the same noisy directories and stopping at the same candidate
+// limit as gatherSymlinkOptions.
+func gatherWorkspaceDirectories(root string) ([]string, error) {
+	dirs := []string{root}
+	err := filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			if path != root && errors.Is(err, fs.ErrPermission) {
+				return filepath.SkipDir
+			}
+			return err
+		}
+		if path == root || !d.IsDir() {
+			return nil
+		}
+		if shouldSkip(d.Name()) {
+			return filepath.SkipDir
+		}
+		dirs = append(dirs, filepath.Clean(path))
+		if len(dirs) >= symlinkCandidateLimit {
+			return errSymlinkCandidateLimit
+		}
+		return nil
+	})
+	if err != nil && !errors.Is(err, errSymlinkCandidateLimit) {
+		return nil, err
+	}
+
+	sort.Strings(dirs[1:])
+	return dirs, nil
+}
+
+var workspaceListOrder = []workspaceList{
+	workspaceListRepoPaths,
+	workspaceListExpanded,
+	workspaceListSelection,
+	workspaceListFileBuffer,
+}
+
+// workspaceCheckPaths reports entries in every list that no longer exist on
+// disk. It never modifies the workspace file.
+func workspaceCheckPaths(out io.Writer, doc *workspaceDocument, workspaceFile string) error {
+	fmt.Fprintf(out, "Checking %s\n", workspaceFile)
+
+	total, missing := 0, 0
+	for _, kind := range workspaceListOrder {
+		paths := doc.list(kind)
+		total += len(paths)
+
+		var broken []string
+		for _, p := range paths {
+			if _, err := os.Stat(p); err != nil {
+				broken = append(broken, p)
+			}
+		}
+		if len(broken) == 0 {
+			continue
+		}
+
+		missing += len(broken)
+		fmt.Fprintf(out, "\n%s (%d of %d missing):\n", workspaceListLabels[kind], len(broken), len(paths))
+		for _, p := range broken {
+			fmt.Fprintf(out, "  %s\n", p)
+		}
+	}
+
+	if missing == 0 {
+		fmt.Fprintf(out, "All %d paths exist.\n", total)
+		return nil
+	}
+	fmt.Fprintf(out, "\n%d of %d paths are missing. Remove them with: %s workspacePaths <list> remove <path>\n", missing, total, flowName)
+	return nil
+}
+
 func workspaceRemovePath(ctx *snap.Context, doc *workspaceDocument, listKind workspaceList, rawPath, workspaceFile string) error {
 	paths := doc.list(listKind)
 	if len(paths) == 0 {